@@ -62,6 +62,7 @@ func SetupHandlers(r *mux.Router) *mux.Router {
 	r.HandleFunc("/{component}/configs", componentConfigHandler).Methods("GET")
 	r.HandleFunc("/gui/csrf-token", getCSRFToken).Methods("GET")
 	r.HandleFunc("/config-check", getConfigCheck).Methods("GET")
+	r.HandleFunc("/config-check/explain", getConfigCheckExplain).Methods("GET")
 	r.HandleFunc("/config", settingshttp.Server.GetFull("")).Methods("GET")
 	r.HandleFunc("/config/list-runtime", settingshttp.Server.ListConfigurable).Methods("GET")
 	r.HandleFunc("/config/{setting}", settingshttp.Server.GetValue).Methods("GET")
@@ -358,6 +359,15 @@ func getConfigCheck(w http.ResponseWriter, r *http.Request) {
 	response.Configs = configSlice
 	response.ResolveWarnings = autodiscovery.GetResolveWarnings()
 	response.ConfigErrors = autodiscovery.GetConfigErrors()
+	response.ProviderErrors = make(map[string]map[string][]string)
+	for provider, resources := range common.AC.GetAutodiscoveryErrors() {
+		response.ProviderErrors[provider] = make(map[string][]string)
+		for resource, errors := range resources {
+			for msg := range errors {
+				response.ProviderErrors[provider][resource] = append(response.ProviderErrors[provider][resource], msg)
+			}
+		}
+	}
 	response.Unresolved = common.AC.GetUnresolvedTemplates()
 
 	jsonConfig, err := json.Marshal(response)
@@ -371,6 +381,30 @@ func getConfigCheck(w http.ResponseWriter, r *http.Request) {
 	w.Write(jsonConfig)
 }
 
+func getConfigCheckExplain(w http.ResponseWriter, r *http.Request) {
+	var response response.ConfigCheckExplainResponse
+
+	if common.AC == nil {
+		log.Errorf("Trying to use /config-check/explain before the agent has been initialized.")
+		body, _ := json.Marshal(map[string]string{"error": "agent not initialized"})
+		http.Error(w, string(body), 503)
+		return
+	}
+
+	response.Query = r.URL.Query().Get("query")
+	response.Events = common.AC.ExplainResolution(response.Query)
+
+	jsonExplain, err := json.Marshal(response)
+	if err != nil {
+		log.Errorf("Unable to marshal config check explain response: %s", err)
+		body, _ := json.Marshal(map[string]string{"error": err.Error()})
+		http.Error(w, string(body), 500)
+		return
+	}
+
+	w.Write(jsonExplain)
+}
+
 func getTaggerList(w http.ResponseWriter, r *http.Request) {
 	// query at the highest cardinality between checks and dogstatsd cardinalities
 	cardinality := collectors.TagCardinality(max(int(tagger.ChecksCardinality), int(tagger.DogstatsdCardinality)))
@@ -14,9 +14,16 @@ type ConfigCheckResponse struct {
 	Configs         []integration.Config            `json:"configs"`
 	ResolveWarnings map[string][]string             `json:"resolve_warnings"`
 	ConfigErrors    map[string]string               `json:"config_errors"`
+	ProviderErrors  map[string]map[string][]string  `json:"provider_errors"`
 	Unresolved      map[string][]integration.Config `json:"unresolved"`
 }
 
+// ConfigCheckExplainResponse holds the resolution history of autodiscovery templates
+type ConfigCheckExplainResponse struct {
+	Query  string                        `json:"query"`
+	Events []integration.ResolutionEvent `json:"events"`
+}
+
 // TaggerListResponse holds the tagger list response
 type TaggerListResponse struct {
 	Entities map[string]TaggerListEntity `json:"entities"`
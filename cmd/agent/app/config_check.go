@@ -18,12 +18,16 @@ import (
 	"github.com/spf13/cobra"
 )
 
-var withDebug bool
+var (
+	withDebug bool
+	explain   string
+)
 
 func init() {
 	AgentCmd.AddCommand(configCheckCommand)
 
 	configCheckCommand.Flags().BoolVarP(&withDebug, "verbose", "v", false, "print additional debug info")
+	configCheckCommand.Flags().StringVar(&explain, "explain", "", "explain why the templates matching a template name, AD identifier or service ID did or did not resolve")
 }
 
 var configCheckCommand = &cobra.Command{
@@ -49,7 +53,11 @@ var configCheckCommand = &cobra.Command{
 		}
 		var b bytes.Buffer
 		color.Output = &b
-		err = flare.GetConfigCheck(color.Output, withDebug)
+		if explain != "" {
+			err = flare.GetConfigCheckExplain(color.Output, explain)
+		} else {
+			err = flare.GetConfigCheck(color.Output, withDebug)
+		}
 		if err != nil {
 			return fmt.Errorf("unable to get config: %v", err)
 		}
@@ -67,6 +67,8 @@ var (
 	discoveryTimeout       uint
 	discoveryRetryInterval uint
 	discoveryMinInstances  uint
+	recordPath             string
+	diffPath               string
 )
 
 func setupCmd(cmd *cobra.Command) {
@@ -84,6 +86,8 @@ func setupCmd(cmd *cobra.Command) {
 	cmd.Flags().UintVarP(&discoveryTimeout, "discovery-timeout", "", 5, "max retry duration until Autodiscovery resolves the check template (in seconds)")
 	cmd.Flags().UintVarP(&discoveryRetryInterval, "discovery-retry-interval", "", 1, "duration between retries until Autodiscovery resolves the check template (in seconds)")
 	cmd.Flags().UintVarP(&discoveryMinInstances, "discovery-min-instances", "", 1, "minimum number of config instances to be discovered before running the check(s)")
+	cmd.Flags().StringVar(&recordPath, "record", "", "save the metrics, service checks and events submitted by the check to a file, to compare them with --diff")
+	cmd.Flags().StringVar(&diffPath, "diff", "", "compare the metrics, service checks and events submitted by the check to a file saved with --record, and fail if they differ")
 	config.Datadog.BindPFlag("cmd.check.fullsketches", cmd.Flags().Lookup("full-sketches")) //nolint:errcheck
 
 	// Power user flags - mark as hidden
@@ -130,6 +134,22 @@ func Check(loggerName config.LoggerName, confFilePath *string, flagNoColor *bool
 				return nil
 			}
 
+			if profileMemory && (recordPath != "" || diffPath != "") {
+				return fmt.Errorf("--record and --diff cannot be used with --profile-memory")
+			}
+
+			var previousRecording, recording *checkRecording
+			if diffPath != "" {
+				previousRecording, err = loadCheckRecording(diffPath)
+				if err != nil {
+					fmt.Printf("Cannot load the check recording: %v\n", err)
+					return err
+				}
+			}
+			if recordPath != "" || diffPath != "" {
+				recording = newCheckRecording(checkName)
+			}
+
 			hostname, err := util.GetHostname(context.TODO())
 			if err != nil {
 				fmt.Printf("Cannot get hostname, exiting: %v\n", err)
@@ -319,7 +339,7 @@ func Check(loggerName config.LoggerName, confFilePath *string, flagNoColor *bool
 				time.Sleep(time.Duration(checkDelay) * time.Millisecond)
 
 				if formatJSON {
-					aggregatorData := getMetricsData(demux)
+					aggregatorData := getMetricsData(demux, recording)
 					var collectorData map[string]interface{}
 
 					collectorJSON, _ := status.GetCheckStatusJSON(c, s)
@@ -397,7 +417,7 @@ func Check(loggerName config.LoggerName, confFilePath *string, flagNoColor *bool
 						return fmt.Errorf("no diff data found in %s", profileDataDir)
 					}
 				} else {
-					printMetrics(demux, &checkFileOutput)
+					printMetrics(demux, &checkFileOutput, recording)
 					checkStatus, _ := status.GetCheckStatus(c, s)
 					statusString := string(checkStatus)
 					fmt.Println(statusString)
@@ -434,6 +454,19 @@ func Check(loggerName config.LoggerName, confFilePath *string, flagNoColor *bool
 				writeCheckToFile(checkName, &checkFileOutput)
 			}
 
+			if recordPath != "" {
+				if err := recording.save(recordPath); err != nil {
+					fmt.Printf("Cannot save the check recording: %v\n", err)
+					return err
+				}
+				fmt.Println("check recording written to:", recordPath)
+			}
+
+			if diffPath != "" {
+				recording.finalize()
+				return printCheckDiff(color.Output, diffPath, diffCheckRecordings(previousRecording, recording))
+			}
+
 			return nil
 		},
 	}
@@ -469,9 +502,13 @@ func runCheck(c check.Check, demux aggregator.Demultiplexer) *check.Stats {
 	return s
 }
 
-func printMetrics(demux aggregator.Demultiplexer, checkFileOutput *bytes.Buffer) {
+func printMetrics(demux aggregator.Demultiplexer, checkFileOutput *bytes.Buffer, recording *checkRecording) {
 	agg := demux.Aggregator()
 	series, sketches := agg.GetSeriesAndSketches(time.Now())
+	serviceChecks := agg.GetServiceChecks()
+	events := agg.GetEvents()
+	recording.add(series, sketches, serviceChecks, events)
+
 	if len(series) != 0 {
 		fmt.Fprintln(color.Output, fmt.Sprintf("=== %s ===", color.BlueString("Series")))
 
@@ -510,7 +547,6 @@ func printMetrics(demux aggregator.Demultiplexer, checkFileOutput *bytes.Buffer)
 		checkFileOutput.WriteString(string(j) + "\n")
 	}
 
-	serviceChecks := agg.GetServiceChecks()
 	if len(serviceChecks) != 0 {
 		fmt.Fprintln(color.Output, fmt.Sprintf("=== %s ===", color.BlueString("Service Checks")))
 
@@ -543,7 +579,6 @@ func printMetrics(demux aggregator.Demultiplexer, checkFileOutput *bytes.Buffer)
 		}
 	}
 
-	events := agg.GetEvents()
 	if len(events) != 0 {
 		fmt.Fprintln(color.Output, fmt.Sprintf("=== %s ===", color.BlueString("Events")))
 		checkFileOutput.WriteString("=== Events ===\n")
@@ -610,12 +645,16 @@ func toDebugEpEvents(events map[string][]*message.Message) map[string][]eventPla
 	return result
 }
 
-func getMetricsData(demux aggregator.Demultiplexer) map[string]interface{} {
+func getMetricsData(demux aggregator.Demultiplexer, recording *checkRecording) map[string]interface{} {
 	aggData := make(map[string]interface{})
 
 	agg := demux.Aggregator()
 
 	series, sketches := agg.GetSeriesAndSketches(time.Now())
+	serviceChecks := agg.GetServiceChecks()
+	events := agg.GetEvents()
+	recording.add(series, sketches, serviceChecks, events)
+
 	if len(series) != 0 {
 		metrics := make([]interface{}, len(series))
 		// Workaround to get the sequence of metrics as plain interface{}
@@ -631,12 +670,10 @@ func getMetricsData(demux aggregator.Demultiplexer) map[string]interface{} {
 		aggData["sketches"] = sketches
 	}
 
-	serviceChecks := agg.GetServiceChecks()
 	if len(serviceChecks) != 0 {
 		aggData["service_checks"] = serviceChecks
 	}
 
-	events := agg.GetEvents()
 	if len(events) != 0 {
 		aggData["events"] = events
 	}
@@ -0,0 +1,398 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sort"
+	"strings"
+
+	"github.com/fatih/color"
+
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+)
+
+// checkRecording holds what a check submitted during an `agent check` run,
+// without the values, timestamps and hostnames which change from a run to
+// another. It's saved with --record and compared to a new run with --diff.
+type checkRecording struct {
+	Check         string                 `json:"check"`
+	Metrics       []recordedMetric       `json:"metrics"`
+	ServiceChecks []recordedServiceCheck `json:"service_checks"`
+	Events        []recordedEvent        `json:"events"`
+	metrics       map[string]*recordedMetric
+	serviceChecks map[string]*recordedServiceCheck
+	events        map[string]*recordedEvent
+}
+
+// recordedMetric holds the types and the tags of all the contexts of a metric
+type recordedMetric struct {
+	Name  string   `json:"name"`
+	Types []string `json:"types"`
+	Tags  []string `json:"tags"`
+}
+
+// recordedServiceCheck holds the statuses and the tags of a service check
+type recordedServiceCheck struct {
+	Name     string   `json:"name"`
+	Statuses []string `json:"statuses"`
+	Tags     []string `json:"tags"`
+}
+
+// recordedEvent holds the tags of the events of a source with a given title
+type recordedEvent struct {
+	Title          string   `json:"title"`
+	SourceTypeName string   `json:"source_type_name,omitempty"`
+	AlertType      string   `json:"alert_type,omitempty"`
+	Tags           []string `json:"tags"`
+}
+
+func newCheckRecording(checkName string) *checkRecording {
+	return &checkRecording{
+		Check:         checkName,
+		metrics:       make(map[string]*recordedMetric),
+		serviceChecks: make(map[string]*recordedServiceCheck),
+		events:        make(map[string]*recordedEvent),
+	}
+}
+
+// add records the series, sketches, service checks and events flushed from the
+// aggregator. It's a no-op on a nil recording.
+func (r *checkRecording) add(series metrics.Series, sketches metrics.SketchSeriesList, serviceChecks metrics.ServiceChecks, events metrics.Events) {
+	if r == nil {
+		return
+	}
+
+	for _, serie := range series {
+		m := r.metric(serie.Name)
+		m.Types = appendUnique(m.Types, serie.MType.String())
+		serie.Tags.ForEach(func(tag string) {
+			m.Tags = appendUnique(m.Tags, tag)
+		})
+		if serie.Device != "" {
+			m.Tags = appendUnique(m.Tags, "device:"+serie.Device)
+		}
+	}
+
+	for _, sketch := range sketches {
+		m := r.metric(sketch.Name)
+		m.Types = appendUnique(m.Types, "distribution")
+		sketch.Tags.ForEach(func(tag string) {
+			m.Tags = appendUnique(m.Tags, tag)
+		})
+	}
+
+	for _, sc := range serviceChecks {
+		s, found := r.serviceChecks[sc.CheckName]
+		if !found {
+			s = &recordedServiceCheck{Name: sc.CheckName}
+			r.serviceChecks[sc.CheckName] = s
+		}
+		s.Statuses = appendUnique(s.Statuses, sc.Status.String())
+		for _, tag := range sc.Tags {
+			s.Tags = appendUnique(s.Tags, tag)
+		}
+	}
+
+	for _, event := range events {
+		e := &recordedEvent{
+			Title:          event.Title,
+			SourceTypeName: event.SourceTypeName,
+			AlertType:      string(event.AlertType),
+		}
+		key := e.key()
+		if existing, found := r.events[key]; found {
+			e = existing
+		} else {
+			r.events[key] = e
+		}
+		for _, tag := range event.Tags {
+			e.Tags = appendUnique(e.Tags, tag)
+		}
+	}
+}
+
+func (r *checkRecording) metric(name string) *recordedMetric {
+	m, found := r.metrics[name]
+	if !found {
+		m = &recordedMetric{Name: name}
+		r.metrics[name] = m
+	}
+	return m
+}
+
+// finalize sorts the recorded items so that the recording can be saved and
+// diffed deterministically
+func (r *checkRecording) finalize() {
+	r.Metrics = make([]recordedMetric, 0, len(r.metrics))
+	for _, m := range r.metrics {
+		sort.Strings(m.Types)
+		sort.Strings(m.Tags)
+		r.Metrics = append(r.Metrics, *m)
+	}
+	sort.Slice(r.Metrics, func(i, j int) bool { return r.Metrics[i].Name < r.Metrics[j].Name })
+
+	r.ServiceChecks = make([]recordedServiceCheck, 0, len(r.serviceChecks))
+	for _, s := range r.serviceChecks {
+		sort.Strings(s.Statuses)
+		sort.Strings(s.Tags)
+		r.ServiceChecks = append(r.ServiceChecks, *s)
+	}
+	sort.Slice(r.ServiceChecks, func(i, j int) bool { return r.ServiceChecks[i].Name < r.ServiceChecks[j].Name })
+
+	r.Events = make([]recordedEvent, 0, len(r.events))
+	for _, e := range r.events {
+		sort.Strings(e.Tags)
+		r.Events = append(r.Events, *e)
+	}
+	sort.Slice(r.Events, func(i, j int) bool { return r.Events[i].key() < r.Events[j].key() })
+}
+
+func (e recordedEvent) key() string {
+	return fmt.Sprintf("%s|%s|%s", e.SourceTypeName, e.AlertType, e.Title)
+}
+
+func (e recordedEvent) String() string {
+	s := fmt.Sprintf("%q", e.Title)
+	if e.SourceTypeName != "" {
+		s += " from " + e.SourceTypeName
+	}
+	if e.AlertType != "" {
+		s += " (" + e.AlertType + ")"
+	}
+	return s
+}
+
+// save writes the recording to path as JSON
+func (r *checkRecording) save(path string) error {
+	r.finalize()
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, append(data, '\n'), 0644)
+}
+
+// loadCheckRecording reads a recording saved with --record
+func loadCheckRecording(path string) (*checkRecording, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &checkRecording{}
+	if err := json.Unmarshal(data, r); err != nil {
+		return nil, fmt.Errorf("invalid check recording %s: %v", path, err)
+	}
+	return r, nil
+}
+
+// checkDiffEntry is a difference between a recording and a new run
+type checkDiffEntry struct {
+	// Op is "+" for an added item, "-" for a removed one and "~" for a change
+	Op      string
+	Kind    string
+	Name    string
+	Details string
+}
+
+func (e checkDiffEntry) String() string {
+	s := fmt.Sprintf("%s %s %s", e.Op, e.Kind, e.Name)
+	if e.Details != "" {
+		s += ": " + e.Details
+	}
+	return s
+}
+
+// diffCheckRecordings returns the metrics, service checks and events added,
+// removed or changed between the previous and the current recordings. Both
+// must be finalized.
+func diffCheckRecordings(prev, curr *checkRecording) []checkDiffEntry {
+	var entries []checkDiffEntry
+
+	prevMetrics := make(map[string]recordedMetric, len(prev.Metrics))
+	for _, m := range prev.Metrics {
+		prevMetrics[m.Name] = m
+	}
+	currMetrics := make(map[string]recordedMetric, len(curr.Metrics))
+	for _, m := range curr.Metrics {
+		currMetrics[m.Name] = m
+	}
+	var metricNames []string
+	for name := range prevMetrics {
+		metricNames = append(metricNames, name)
+	}
+	for name := range currMetrics {
+		metricNames = append(metricNames, name)
+	}
+	for _, name := range sortedUnique(metricNames) {
+		p, inPrev := prevMetrics[name]
+		c, inCurr := currMetrics[name]
+		switch {
+		case !inPrev:
+			entries = append(entries, checkDiffEntry{Op: "+", Kind: "metric", Name: name, Details: "type " + strings.Join(c.Types, ",")})
+		case !inCurr:
+			entries = append(entries, checkDiffEntry{Op: "-", Kind: "metric", Name: name, Details: "type " + strings.Join(p.Types, ",")})
+		default:
+			if !equalStrings(p.Types, c.Types) {
+				entries = append(entries, checkDiffEntry{Op: "~", Kind: "metric", Name: name, Details: fmt.Sprintf("type %s -> %s", strings.Join(p.Types, ","), strings.Join(c.Types, ","))})
+			}
+			entries = append(entries, diffTags("metric", name, p.Tags, c.Tags)...)
+		}
+	}
+
+	prevServiceChecks := make(map[string]recordedServiceCheck, len(prev.ServiceChecks))
+	for _, s := range prev.ServiceChecks {
+		prevServiceChecks[s.Name] = s
+	}
+	currServiceChecks := make(map[string]recordedServiceCheck, len(curr.ServiceChecks))
+	for _, s := range curr.ServiceChecks {
+		currServiceChecks[s.Name] = s
+	}
+	var serviceCheckNames []string
+	for name := range prevServiceChecks {
+		serviceCheckNames = append(serviceCheckNames, name)
+	}
+	for name := range currServiceChecks {
+		serviceCheckNames = append(serviceCheckNames, name)
+	}
+	for _, name := range sortedUnique(serviceCheckNames) {
+		p, inPrev := prevServiceChecks[name]
+		c, inCurr := currServiceChecks[name]
+		switch {
+		case !inPrev:
+			entries = append(entries, checkDiffEntry{Op: "+", Kind: "service check", Name: name, Details: "status " + strings.Join(c.Statuses, ",")})
+		case !inCurr:
+			entries = append(entries, checkDiffEntry{Op: "-", Kind: "service check", Name: name, Details: "status " + strings.Join(p.Statuses, ",")})
+		default:
+			if !equalStrings(p.Statuses, c.Statuses) {
+				entries = append(entries, checkDiffEntry{Op: "~", Kind: "service check", Name: name, Details: fmt.Sprintf("status %s -> %s", strings.Join(p.Statuses, ","), strings.Join(c.Statuses, ","))})
+			}
+			entries = append(entries, diffTags("service check", name, p.Tags, c.Tags)...)
+		}
+	}
+
+	prevEvents := make(map[string]recordedEvent, len(prev.Events))
+	for _, e := range prev.Events {
+		prevEvents[e.key()] = e
+	}
+	currEvents := make(map[string]recordedEvent, len(curr.Events))
+	for _, e := range curr.Events {
+		currEvents[e.key()] = e
+	}
+	var eventKeys []string
+	for key := range prevEvents {
+		eventKeys = append(eventKeys, key)
+	}
+	for key := range currEvents {
+		eventKeys = append(eventKeys, key)
+	}
+	for _, key := range sortedUnique(eventKeys) {
+		p, inPrev := prevEvents[key]
+		c, inCurr := currEvents[key]
+		switch {
+		case !inPrev:
+			entries = append(entries, checkDiffEntry{Op: "+", Kind: "event", Name: c.String()})
+		case !inCurr:
+			entries = append(entries, checkDiffEntry{Op: "-", Kind: "event", Name: p.String()})
+		default:
+			entries = append(entries, diffTags("event", c.String(), p.Tags, c.Tags)...)
+		}
+	}
+
+	return entries
+}
+
+// diffTags returns the tags added to and removed from an item
+func diffTags(kind, name string, prev, curr []string) []checkDiffEntry {
+	prevTags := make(map[string]struct{}, len(prev))
+	for _, tag := range prev {
+		prevTags[tag] = struct{}{}
+	}
+	currTags := make(map[string]struct{}, len(curr))
+	for _, tag := range curr {
+		currTags[tag] = struct{}{}
+	}
+
+	var added, removed []string
+	for _, tag := range curr {
+		if _, found := prevTags[tag]; !found {
+			added = append(added, tag)
+		}
+	}
+	for _, tag := range prev {
+		if _, found := currTags[tag]; !found {
+			removed = append(removed, tag)
+		}
+	}
+
+	var entries []checkDiffEntry
+	if len(added) > 0 {
+		entries = append(entries, checkDiffEntry{Op: "~", Kind: kind, Name: name, Details: "added tags " + strings.Join(added, ", ")})
+	}
+	if len(removed) > 0 {
+		entries = append(entries, checkDiffEntry{Op: "~", Kind: kind, Name: name, Details: "removed tags " + strings.Join(removed, ", ")})
+	}
+	return entries
+}
+
+// printCheckDiff prints the differences with a recording, and returns an
+// error if there are some so that `agent check --diff` can be used in CI
+func printCheckDiff(w io.Writer, path string, entries []checkDiffEntry) error {
+	fmt.Fprintln(w, fmt.Sprintf("=== %s ===", color.BlueString("Diff with %s", path)))
+	if len(entries) == 0 {
+		fmt.Fprintln(w, color.GreenString("No difference"))
+		return nil
+	}
+
+	for _, entry := range entries {
+		switch entry.Op {
+		case "+":
+			fmt.Fprintln(w, color.GreenString(entry.String()))
+		case "-":
+			fmt.Fprintln(w, color.RedString(entry.String()))
+		default:
+			fmt.Fprintln(w, color.YellowString(entry.String()))
+		}
+	}
+	return fmt.Errorf("the check output differs from %s: %d difference(s)", path, len(entries))
+}
+
+func appendUnique(values []string, value string) []string {
+	for _, v := range values {
+		if v == value {
+			return values
+		}
+	}
+	return append(values, value)
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// sortedUnique sorts values and removes the duplicates
+func sortedUnique(values []string) []string {
+	sort.Strings(values)
+	unique := values[:0]
+	for i, v := range values {
+		if i == 0 || v != values[i-1] {
+			unique = append(unique, v)
+		}
+	}
+	return unique
+}
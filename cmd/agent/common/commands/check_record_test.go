@@ -0,0 +1,113 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package commands
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+	"github.com/DataDog/datadog-agent/pkg/tagset"
+)
+
+func recordRun(series metrics.Series, serviceChecks metrics.ServiceChecks, events metrics.Events) *checkRecording {
+	r := newCheckRecording("my_check")
+	r.add(series, nil, serviceChecks, events)
+	r.finalize()
+	return r
+}
+
+func TestCheckRecordingSaveLoad(t *testing.T) {
+	r := newCheckRecording("my_check")
+	r.add(
+		metrics.Series{
+			{Name: "my.gauge", MType: metrics.APIGaugeType, Tags: tagset.CompositeTagsFromSlice([]string{"b:2", "a:1"}), Host: "host1", Points: []metrics.Point{{Ts: 1, Value: 3}}},
+			{Name: "my.gauge", MType: metrics.APIGaugeType, Tags: tagset.CompositeTagsFromSlice([]string{"a:1", "c:3"}), Device: "sda"},
+		},
+		metrics.SketchSeriesList{{Name: "my.distribution", Tags: tagset.CompositeTagsFromSlice([]string{"a:1"})}},
+		metrics.ServiceChecks{{CheckName: "my_check.can_connect", Status: metrics.ServiceCheckOK, Tags: []string{"port:80"}}},
+		metrics.Events{{Title: "restarted", SourceTypeName: "my_check", Tags: []string{"a:1"}}, {Title: "restarted", SourceTypeName: "my_check", Tags: []string{"b:2"}}},
+	)
+
+	path := filepath.Join(t.TempDir(), "recording.json")
+	require.NoError(t, r.save(path))
+
+	loaded, err := loadCheckRecording(path)
+	require.NoError(t, err)
+
+	assert.Equal(t, "my_check", loaded.Check)
+	assert.Equal(t, []recordedMetric{
+		{Name: "my.distribution", Types: []string{"distribution"}, Tags: []string{"a:1"}},
+		{Name: "my.gauge", Types: []string{"gauge"}, Tags: []string{"a:1", "b:2", "c:3", "device:sda"}},
+	}, loaded.Metrics)
+	assert.Equal(t, []recordedServiceCheck{
+		{Name: "my_check.can_connect", Statuses: []string{"OK"}, Tags: []string{"port:80"}},
+	}, loaded.ServiceChecks)
+	assert.Equal(t, []recordedEvent{
+		{Title: "restarted", SourceTypeName: "my_check", Tags: []string{"a:1", "b:2"}},
+	}, loaded.Events)
+
+	assert.Empty(t, diffCheckRecordings(loaded, r))
+}
+
+func TestDiffCheckRecordings(t *testing.T) {
+	prev := recordRun(
+		metrics.Series{
+			{Name: "my.requests", MType: metrics.APIGaugeType, Tags: tagset.CompositeTagsFromSlice([]string{"code:200", "pod:web"})},
+			{Name: "my.removed", MType: metrics.APIGaugeType},
+		},
+		metrics.ServiceChecks{{CheckName: "my_check.can_connect", Status: metrics.ServiceCheckOK}},
+		metrics.Events{{Title: "restarted", SourceTypeName: "my_check"}},
+	)
+	curr := recordRun(
+		metrics.Series{
+			{Name: "my.requests", MType: metrics.APICountType, Tags: tagset.CompositeTagsFromSlice([]string{"code:200", "kube_pod:web"})},
+			{Name: "my.added", MType: metrics.APIRateType},
+		},
+		metrics.ServiceChecks{{CheckName: "my_check.can_connect", Status: metrics.ServiceCheckCritical}},
+		nil,
+	)
+
+	entries := diffCheckRecordings(prev, curr)
+	var lines []string
+	for _, entry := range entries {
+		lines = append(lines, entry.String())
+	}
+	assert.Equal(t, []string{
+		"+ metric my.added: type rate",
+		"- metric my.removed: type gauge",
+		"~ metric my.requests: type gauge -> count",
+		"~ metric my.requests: added tags kube_pod:web",
+		"~ metric my.requests: removed tags pod:web",
+		"~ service check my_check.can_connect: status OK -> CRITICAL",
+		`- event "restarted" from my_check`,
+	}, lines)
+
+	var out bytes.Buffer
+	err := printCheckDiff(&out, "recording.json", entries)
+	assert.EqualError(t, err, "the check output differs from recording.json: 7 difference(s)")
+	assert.Contains(t, out.String(), "+ metric my.added: type rate")
+
+	out.Reset()
+	assert.NoError(t, printCheckDiff(&out, "recording.json", diffCheckRecordings(curr, curr)))
+	assert.Contains(t, out.String(), "No difference")
+}
+
+func TestLoadCheckRecordingInvalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "recording.json")
+	require.NoError(t, os.WriteFile(path, []byte("my.gauge 1"), 0644))
+
+	_, err := loadCheckRecording(path)
+	assert.EqualError(t, err, "invalid check recording "+path+": invalid character 'm' looking for beginning of value")
+
+	_, err = loadCheckRecording(filepath.Join(t.TempDir(), "missing.json"))
+	assert.Error(t, err)
+}
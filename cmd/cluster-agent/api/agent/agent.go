@@ -154,6 +154,15 @@ func getConfigCheck(w http.ResponseWriter, r *http.Request) {
 	response.Configs = configSlice
 	response.ResolveWarnings = autodiscovery.GetResolveWarnings()
 	response.ConfigErrors = autodiscovery.GetConfigErrors()
+	response.ProviderErrors = make(map[string]map[string][]string)
+	for provider, resources := range common.AC.GetAutodiscoveryErrors() {
+		response.ProviderErrors[provider] = make(map[string][]string)
+		for resource, errors := range resources {
+			for msg := range errors {
+				response.ProviderErrors[provider][resource] = append(response.ProviderErrors[provider][resource], msg)
+			}
+		}
+	}
 	response.Unresolved = common.AC.GetUnresolvedTemplates()
 
 	jsonConfig, err := json.Marshal(response)
@@ -11,13 +11,17 @@ package app
 import (
 	"context"
 	"fmt"
+	"io"
 	"os"
+	"path/filepath"
 	"time"
 
 	"github.com/DataDog/datadog-agent/cmd/security-agent/common"
+	"github.com/DataDog/datadog-agent/pkg/compliance"
 	"github.com/DataDog/datadog-agent/pkg/compliance/agent"
 	"github.com/DataDog/datadog-agent/pkg/compliance/checks"
 	"github.com/DataDog/datadog-agent/pkg/compliance/event"
+	"github.com/DataDog/datadog-agent/pkg/compliance/output"
 	"github.com/DataDog/datadog-agent/pkg/config"
 	coreconfig "github.com/DataDog/datadog-agent/pkg/config"
 	"github.com/DataDog/datadog-agent/pkg/logs/restart"
@@ -29,6 +33,12 @@ import (
 	"github.com/spf13/cobra"
 )
 
+const (
+	formatJSON  = "json"
+	formatSARIF = "sarif"
+	formatJUnit = "junit"
+)
+
 var (
 	checkArgs = struct {
 		framework         string
@@ -38,6 +48,8 @@ var (
 		overrideRegoInput string
 		dumpRegoInput     string
 		dumpReports       string
+		rootfs            string
+		format            string
 	}{}
 )
 
@@ -49,6 +61,8 @@ func setupCheckCmd(cmd *cobra.Command) {
 	cmd.Flags().StringVarP(&checkArgs.overrideRegoInput, "override-rego-input", "", "", "Rego input to use when running rego checks")
 	cmd.Flags().StringVarP(&checkArgs.dumpRegoInput, "dump-rego-input", "", "", "Path to file where to dump the Rego input JSON")
 	cmd.Flags().StringVarP(&checkArgs.dumpReports, "dump-reports", "", "", "Path to file where to dump reports")
+	cmd.Flags().StringVarP(&checkArgs.rootfs, "rootfs", "", "", "Path to a root filesystem, like an extracted container image, to evaluate the checks against")
+	cmd.Flags().StringVarP(&checkArgs.format, "format", "", "", "Output format of the results, one of json, sarif or junit")
 }
 
 // CheckCmd returns a cobra command to run security agent checks
@@ -66,6 +80,16 @@ func CheckCmd(confPathArrayGetter func() []string) *cobra.Command {
 }
 
 func runCheck(cmd *cobra.Command, confPathArray []string, args []string) error {
+	if checkArgs.format == "" && checkArgs.rootfs != "" {
+		checkArgs.format = formatJSON
+	}
+
+	switch checkArgs.format {
+	case "", formatJSON, formatSARIF, formatJUnit:
+	default:
+		return fmt.Errorf("unsupported output format: %s", checkArgs.format)
+	}
+
 	err := configureLogger()
 	if err != nil {
 		return err
@@ -84,7 +108,11 @@ func runCheck(cmd *cobra.Command, confPathArray []string, args []string) error {
 
 	options := []checks.BuilderOption{}
 
-	if flavor.GetFlavor() == flavor.ClusterAgent {
+	if checkArgs.rootfs != "" {
+		// Only the content of the root filesystem is inspected, the host is left untouched
+		log.Infof("Running checks against root filesystem: path=%s", checkArgs.rootfs)
+		options = append(options, checks.WithRootFS(checkArgs.rootfs))
+	} else if flavor.GetFlavor() == flavor.ClusterAgent {
 		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 		defer cancel()
 
@@ -130,6 +158,7 @@ func runCheck(cmd *cobra.Command, confPathArray []string, args []string) error {
 	if err != nil {
 		return err
 	}
+	reporter.format = checkArgs.format
 
 	if ruleID != "" {
 		log.Infof("Looking for rule with ID=%s", ruleID)
@@ -150,10 +179,13 @@ func runCheck(cmd *cobra.Command, confPathArray []string, args []string) error {
 		options = append(options, checks.WithRegoInputDumpPath(checkArgs.dumpRegoInput))
 	}
 
+	var suiteFiles []string
 	if checkArgs.file != "" {
+		suiteFiles = []string{checkArgs.file}
 		err = agent.RunChecksFromFile(reporter, checkArgs.file, options...)
 	} else {
 		configDir := config.Datadog.GetString("compliance_config.dir")
+		suiteFiles, _ = filepath.Glob(filepath.Join(configDir, "*.yaml"))
 		err = agent.RunChecks(reporter, configDir, options...)
 	}
 
@@ -167,6 +199,13 @@ func runCheck(cmd *cobra.Command, confPathArray []string, args []string) error {
 		return err
 	}
 
+	if reporter.format != "" {
+		if err := reporter.writeResults(os.Stdout, suiteFiles); err != nil {
+			log.Errorf("Failed to write results: %v", err)
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -180,7 +219,12 @@ func configureLogger() error {
 		logFormat = fmt.Sprintf("%%Date(%s) | %%LEVEL | (%%ShortFilePath:%%Line in %%FuncShort) | %%Msg%%n", logDateFormat)
 		logLevel = "trace"
 	}
-	logger, err := seelog.LoggerFromWriterWithMinLevelAndFormat(os.Stdout, seelog.DebugLvl, logFormat)
+	// Keep stdout for the results document when an output format is requested
+	output := os.Stdout
+	if checkArgs.format != "" {
+		output = os.Stderr
+	}
+	logger, err := seelog.LoggerFromWriterWithMinLevelAndFormat(output, seelog.DebugLvl, logFormat)
 	if err != nil {
 		return err
 	}
@@ -193,6 +237,7 @@ type RunCheckReporter struct {
 	reporter        event.Reporter
 	events          map[string][]*event.Event
 	dumpReportsPath string
+	format          string
 }
 
 func NewCheckReporter(stopper restart.Stopper, report bool, dumpReportsPath string) (*RunCheckReporter, error) {
@@ -222,13 +267,15 @@ func NewCheckReporter(stopper restart.Stopper, report bool, dumpReportsPath stri
 func (r *RunCheckReporter) Report(event *event.Event) {
 	r.events[event.AgentRuleID] = append(r.events[event.AgentRuleID], event)
 
-	eventJSON, err := checks.PrettyPrintJSON(event, "  ")
-	if err != nil {
-		log.Errorf("Failed to marshal rule event: %v", err)
-		return
-	}
+	if r.format == "" {
+		eventJSON, err := checks.PrettyPrintJSON(event, "  ")
+		if err != nil {
+			log.Errorf("Failed to marshal rule event: %v", err)
+			return
+		}
 
-	r.ReportRaw(eventJSON, "")
+		r.ReportRaw(eventJSON, "")
+	}
 
 	if r.reporter != nil {
 		r.reporter.Report(event)
@@ -251,6 +298,38 @@ func (r *RunCheckReporter) dumpReports() error {
 	return nil
 }
 
+// writeResults renders all the reported events in the configured format
+func (r *RunCheckReporter) writeResults(w io.Writer, suiteFiles []string) error {
+	if r.format == formatJSON {
+		reportsJSON, err := checks.PrettyPrintJSON(r.events, "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(w, string(reportsJSON))
+		return err
+	}
+
+	rules := make(output.Rules)
+	for _, file := range suiteFiles {
+		suite, err := compliance.ParseSuite(file)
+		if err != nil {
+			log.Warnf("Failed to load rules metadata from %s: %v", file, err)
+			continue
+		}
+		rules.AddSuite(suite)
+	}
+
+	var events []*event.Event
+	for _, ruleEvents := range r.events {
+		events = append(events, ruleEvents...)
+	}
+
+	if r.format == formatSARIF {
+		return output.WriteSARIF(w, events, rules)
+	}
+	return output.WriteJUnit(w, events, rules)
+}
+
 func init() {
 	complianceCmd.AddCommand(CheckCmd(func() []string {
 		return confPathArray
@@ -136,6 +136,10 @@ func startCompliance(hostname string, stopper restart.Stopper, statsdClient *ddg
 		checks.MayFail(checks.WithAudit()),
 	}
 
+	if coreconfig.Datadog.GetBool("compliance_config.drift_detection.enabled") {
+		options = append(options, checks.WithDriftDetection())
+	}
+
 	if coreconfig.IsKubernetes() {
 		nodeLabels, err := agent.WaitGetNodeLabels()
 		if err != nil {
@@ -20,7 +20,9 @@ import (
 	"io"
 	"os"
 	"path"
+	"sort"
 	"time"
+	"unsafe"
 
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
@@ -34,6 +36,7 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/logs/pipeline"
 	"github.com/DataDog/datadog-agent/pkg/logs/restart"
 	secagent "github.com/DataDog/datadog-agent/pkg/security/agent"
+	"github.com/DataDog/datadog-agent/pkg/security/api"
 	secconfig "github.com/DataDog/datadog-agent/pkg/security/config"
 	seclog "github.com/DataDog/datadog-agent/pkg/security/log"
 	sprobe "github.com/DataDog/datadog-agent/pkg/security/probe"
@@ -108,6 +111,23 @@ var (
 		RunE:  checkPolicies,
 	}
 
+	testPoliciesCmd = &cobra.Command{
+		Use:   "test",
+		Short: "Evaluate policies against event fixtures and report the matching rules",
+		Long: `Evaluate policies against event fixtures and report the matching rules.
+
+Fixtures are YAML or JSON files holding a list of events described by the values
+of their fields. When a fixture lists the rules it is expected to trigger, the
+command exits with an error if the matching rules differ.`,
+		RunE: testPolicies,
+	}
+
+	testPoliciesArgs = struct {
+		dir      string
+		fixtures string
+		json     bool
+	}{}
+
 	downloadPolicyCmd = &cobra.Command{
 		Use:   "download",
 		Short: "Download policies",
@@ -123,6 +143,46 @@ var (
 		Use:   "policy",
 		Short: "Policy related commands",
 	}
+
+	activityDumpCmd = &cobra.Command{
+		Use:   "activity-dump",
+		Short: "Activity dump related commands",
+	}
+
+	activityDumpArgs = struct {
+		containerID string
+		image       string
+		timeout     string
+	}{}
+
+	startActivityDumpCmd = &cobra.Command{
+		Use:   "start",
+		Short: "Start recording the activity of a container, or of the containers of an image",
+		RunE:  startActivityDump,
+	}
+
+	listActivityDumpsCmd = &cobra.Command{
+		Use:   "list",
+		Short: "List the running activity dumps",
+		RunE:  listActivityDumps,
+	}
+
+	stopActivityDumpCmd = &cobra.Command{
+		Use:   "stop",
+		Short: "Stop an activity dump and write it to disk",
+		RunE:  stopActivityDump,
+	}
+
+	activityDumpToPolicyCmd = &cobra.Command{
+		Use:   "to-policy",
+		Short: "Generate a policy from an activity dump file",
+		RunE:  activityDumpToPolicy,
+	}
+
+	activityDumpToPolicyArgs = struct {
+		input  string
+		output string
+	}{}
 )
 
 func init() {
@@ -145,7 +205,31 @@ func init() {
 
 	commonPolicyCmd.AddCommand(commonReloadPoliciesCmd)
 
+	testPoliciesCmd.Flags().StringVar(&testPoliciesArgs.dir, "policies-dir", coreconfig.DefaultRuntimePoliciesDir, "Path to policies directory")
+	testPoliciesCmd.Flags().StringVar(&testPoliciesArgs.fixtures, "fixtures", "", "Path to an event fixtures file or directory")
+	testPoliciesCmd.Flags().BoolVar(&testPoliciesArgs.json, "json", false, "Output the report in JSON")
+	_ = testPoliciesCmd.MarkFlagRequired("fixtures")
+	commonPolicyCmd.AddCommand(testPoliciesCmd)
+
 	runtimeCmd.AddCommand(commonPolicyCmd)
+
+	startActivityDumpCmd.Flags().StringVar(&activityDumpArgs.containerID, "container-id", "", "ID of the container to record")
+	startActivityDumpCmd.Flags().StringVar(&activityDumpArgs.image, "image", "", "Image of the containers to record")
+	startActivityDumpCmd.Flags().StringVar(&activityDumpArgs.timeout, "timeout", "", "Duration of the dump (for example 10m), defaults to the configured timeout")
+	activityDumpCmd.AddCommand(startActivityDumpCmd)
+
+	activityDumpCmd.AddCommand(listActivityDumpsCmd)
+
+	stopActivityDumpCmd.Flags().StringVar(&activityDumpArgs.containerID, "container-id", "", "ID of the recorded container")
+	stopActivityDumpCmd.Flags().StringVar(&activityDumpArgs.image, "image", "", "Image of the recorded containers")
+	activityDumpCmd.AddCommand(stopActivityDumpCmd)
+
+	activityDumpToPolicyCmd.Flags().StringVar(&activityDumpToPolicyArgs.input, "input", "", "Path to the activity dump file")
+	activityDumpToPolicyCmd.Flags().StringVar(&activityDumpToPolicyArgs.output, "output", "", "Path of the generated policy, defaults to the standard output")
+	_ = activityDumpToPolicyCmd.MarkFlagRequired("input")
+	activityDumpCmd.AddCommand(activityDumpToPolicyCmd)
+
+	runtimeCmd.AddCommand(activityDumpCmd)
 }
 
 func dumpProcessCache(cmd *cobra.Command, args []string) error {
@@ -165,15 +249,102 @@ func dumpProcessCache(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
-func checkPoliciesInner(dir string) error {
-	cfg := &secconfig.Config{
-		PoliciesDir:         dir,
-		EnableKernelFilters: true,
-		EnableApprovers:     true,
-		EnableDiscarders:    true,
-		PIDCacheSize:        1,
+func startActivityDump(cmd *cobra.Command, args []string) error {
+	client, err := secagent.NewRuntimeSecurityClient()
+	if err != nil {
+		return errors.Wrap(err, "unable to create a runtime security client instance")
+	}
+	defer client.Close()
+
+	msg, err := client.DumpActivity(activityDumpArgs.containerID, activityDumpArgs.image, activityDumpArgs.timeout)
+	if err != nil {
+		return errors.Wrap(err, "unable to start an activity dump")
+	}
+	if msg.Error != "" {
+		return fmt.Errorf("activity dump error: %s", msg.Error)
+	}
+
+	fmt.Printf("Activity dump started: %s\n", formatActivityDump(msg))
+
+	return nil
+}
+
+func listActivityDumps(cmd *cobra.Command, args []string) error {
+	client, err := secagent.NewRuntimeSecurityClient()
+	if err != nil {
+		return errors.Wrap(err, "unable to create a runtime security client instance")
+	}
+	defer client.Close()
+
+	msg, err := client.ListActivityDumps()
+	if err != nil {
+		return errors.Wrap(err, "unable to list activity dumps")
+	}
+	if msg.Error != "" {
+		return fmt.Errorf("activity dump error: %s", msg.Error)
+	}
+
+	if len(msg.Dumps) == 0 {
+		fmt.Println("No activity dump running")
+		return nil
+	}
+
+	for _, dump := range msg.Dumps {
+		fmt.Println(formatActivityDump(dump))
+	}
+
+	return nil
+}
+
+func stopActivityDump(cmd *cobra.Command, args []string) error {
+	client, err := secagent.NewRuntimeSecurityClient()
+	if err != nil {
+		return errors.Wrap(err, "unable to create a runtime security client instance")
+	}
+	defer client.Close()
+
+	msg, err := client.StopActivityDump(activityDumpArgs.containerID, activityDumpArgs.image)
+	if err != nil {
+		return errors.Wrap(err, "unable to stop the activity dump")
+	}
+	if msg.Error != "" {
+		return fmt.Errorf("activity dump error: %s", msg.Error)
+	}
+
+	fmt.Printf("Activity dump written: %s\n", msg.OutputFile)
+
+	return nil
+}
+
+func formatActivityDump(msg *api.ActivityDumpMessage) string {
+	selector := "container_id:" + msg.ContainerID
+	if msg.ContainerID == "" {
+		selector = "image:" + msg.Image
+	}
+	return fmt.Sprintf("%s started at %s for %s", selector, msg.Start, msg.Timeout)
+}
+
+func activityDumpToPolicy(cmd *cobra.Command, args []string) error {
+	ad, err := sprobe.LoadActivityDump(activityDumpToPolicyArgs.input)
+	if err != nil {
+		return err
 	}
 
+	policy, err := ad.GeneratePolicy()
+	if err != nil {
+		return errors.Wrap(err, "unable to generate a policy")
+	}
+
+	if activityDumpToPolicyArgs.output == "" || activityDumpToPolicyArgs.output == "-" {
+		_, err = os.Stdout.Write(policy)
+		return err
+	}
+
+	return os.WriteFile(activityDumpToPolicyArgs.output, policy, 0644)
+}
+
+// loadPolicies returns a ruleset holding all the rules of the policies of dir
+func loadPolicies(dir string) (*rules.RuleSet, error) {
 	// enabled all the rules
 	enabled := map[eval.EventType]bool{"*": true}
 
@@ -185,12 +356,38 @@ func checkPoliciesInner(dir string) error {
 		WithEventTypeEnabled(enabled).
 		WithReservedRuleIDs(sprobe.AllCustomRuleIDs()).
 		WithLegacyFields(model.SECLLegacyFields).
+		WithSequenceScopes(sprobe.SequenceScopes).
+		WithStateScopes(map[rules.Scope]rules.VariableProviderFactory{
+			"process": func() rules.VariableProvider {
+				return eval.NewScopedVariables(func(ctx *eval.Context) unsafe.Pointer {
+					return unsafe.Pointer(&(*model.Event)(ctx.Object).ProcessContext)
+				}, nil)
+			},
+			"container": sprobe.NewContainerVariables,
+		}).
 		WithLogger(&seclog.PatternLogger{})
 
 	model := &model.Model{}
 	ruleSet := rules.NewRuleSet(model, model.NewEvent, &opts)
 
-	if err := rules.LoadPolicies(cfg.PoliciesDir, ruleSet); err.ErrorOrNil() != nil {
+	if err := rules.LoadPolicies(dir, ruleSet); err.ErrorOrNil() != nil {
+		return nil, err
+	}
+
+	return ruleSet, nil
+}
+
+func checkPoliciesInner(dir string) error {
+	cfg := &secconfig.Config{
+		PoliciesDir:         dir,
+		EnableKernelFilters: true,
+		EnableApprovers:     true,
+		EnableDiscarders:    true,
+		PIDCacheSize:        1,
+	}
+
+	ruleSet, err := loadPolicies(cfg.PoliciesDir)
+	if err != nil {
 		return err
 	}
 
@@ -216,6 +413,100 @@ func checkPolicies(cmd *cobra.Command, args []string) error {
 	return checkPoliciesInner(checkPoliciesArgs.dir)
 }
 
+func testPolicies(cmd *cobra.Command, args []string) error {
+	ruleSet, err := loadPolicies(testPoliciesArgs.dir)
+	if err != nil {
+		return err
+	}
+
+	fixtures, err := rules.LoadEventFixtures(testPoliciesArgs.fixtures)
+	if err != nil {
+		return err
+	}
+
+	results := ruleSet.EvaluateFixtures(fixtures, func(eventType eval.EventType) (eval.Event, error) {
+		kind := model.ParseEvalEventType(eventType)
+		if kind == model.UnknownEventType {
+			return nil, fmt.Errorf("unknown event type `%s`", eventType)
+		}
+		return &model.Event{Type: uint64(kind)}, nil
+	})
+
+	var failed int
+	for _, result := range results {
+		if !result.Passed() {
+			failed++
+		}
+	}
+
+	if testPoliciesArgs.json {
+		content, _ := json.MarshalIndent(results, "", "\t")
+		fmt.Printf("%s\n", string(content))
+	} else {
+		printFixtureResults(os.Stdout, results)
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d fixtures failed", failed, len(results))
+	}
+
+	return nil
+}
+
+func printFixtureResults(w io.Writer, results []*rules.FixtureResult) {
+	for _, result := range results {
+		status := "PASS"
+		if !result.Passed() {
+			status = "FAIL"
+		}
+		fmt.Fprintf(w, "%s %s\n", status, result.Name)
+
+		if result.Error != "" {
+			fmt.Fprintf(w, "  error: %s\n", result.Error)
+			continue
+		}
+
+		missing := make(map[rules.RuleID]bool)
+		for _, id := range result.Missing {
+			missing[id] = true
+		}
+
+		for _, report := range result.Rules {
+			if report.Matched {
+				fmt.Fprintf(w, "  matched %s\n", report.ID)
+			} else if missing[report.ID] {
+				fmt.Fprintf(w, "  didn't match %s\n", report.ID)
+			} else {
+				continue
+			}
+
+			macros := make([]string, 0, len(report.Macros))
+			for id := range report.Macros {
+				macros = append(macros, id)
+			}
+			sort.Strings(macros)
+			for _, id := range macros {
+				fmt.Fprintf(w, "    macro %s: %t\n", id, report.Macros[id])
+			}
+
+			fields := make([]string, 0, len(report.Fields))
+			for field := range report.Fields {
+				fields = append(fields, field)
+			}
+			sort.Strings(fields)
+			for _, field := range fields {
+				fmt.Fprintf(w, "    %s: %v\n", field, report.Fields[field])
+			}
+		}
+		for _, id := range result.Missing {
+			fmt.Fprintf(w, "  expected %s to match\n", id)
+		}
+		for _, id := range result.Unexpected {
+			fmt.Fprintf(w, "  unexpected match of %s\n", id)
+		}
+	}
+}
+
 func runRuntimeSelfTest(cmd *cobra.Command, args []string) error {
 	client, err := secagent.NewRuntimeSecurityClient()
 	if err != nil {
@@ -505,6 +505,7 @@ func (ac *AutoConfig) resolveTemplate(tpl integration.Config) []integration.Conf
 		if !found {
 			s := fmt.Sprintf("No service found with this AD identifier: %s", id)
 			errorStats.setResolveWarning(tpl.Name, s)
+			ac.recordResolution(tpl, nil, integration.ResolutionNoService, s, "")
 			log.Debugf(s)
 			continue
 		}
@@ -539,13 +540,17 @@ func (ac *AutoConfig) resolveTemplateForService(tpl integration.Config, svc list
 	if err != nil {
 		newErr := fmt.Errorf("error resolving template %s for service %s: %v", tpl.Name, svc.GetServiceID(), err)
 		errorStats.setResolveWarning(tpl.Name, newErr.Error())
+		ac.recordResolution(tpl, svc, integration.ResolutionFailed, err.Error(), "")
 		return tpl, log.Warn(newErr)
 	}
 	resolvedConfig, err := decryptConfig(config)
 	if err != nil {
 		newErr := fmt.Errorf("error decrypting secrets in config %s for service %s: %v", config.Name, svc.GetServiceID(), err)
+		ac.recordResolution(tpl, svc, integration.ResolutionSecretsFailed, err.Error(), "")
 		return config, log.Warn(newErr)
 	}
+	outcome, reason := exclusionOutcome(resolvedConfig)
+	ac.recordResolution(tpl, svc, outcome, reason, resolvedConfig.Digest())
 	ac.store.setLoadedConfig(resolvedConfig)
 	ac.store.addConfigForService(svc.GetServiceID(), resolvedConfig)
 	ac.store.addConfigForTemplate(tpl.Digest(), resolvedConfig)
@@ -630,6 +635,11 @@ func (ac *AutoConfig) processNewService(ctx context.Context, svc listeners.Servi
 		templates = append(templates, tpls...)
 	}
 
+	if len(templates) == 0 {
+		ac.recordResolution(integration.Config{}, svc, integration.ResolutionNoTemplate,
+			fmt.Sprintf("No template found for the AD identifiers %v", ADIdentifiers), "")
+	}
+
 	for _, template := range templates {
 		// resolve the template
 		resolvedConfig, err := ac.resolveTemplateForService(template, svc)
@@ -671,6 +681,62 @@ func (ac *AutoConfig) processDelService(svc listeners.Service) {
 	})
 }
 
+// ExplainResolution returns the recorded resolutions of templates for services
+// matching the query, which can be a template name, a template digest, an AD
+// identifier or a service ID. An empty query returns all the recorded resolutions.
+func (ac *AutoConfig) ExplainResolution(query string) []integration.ResolutionEvent {
+	return mergeResolutionEvents(ac.store.resolutions.find(query), ac.store.noTemplates.find(query))
+}
+
+// recordResolution adds the outcome of the resolution of a template for a service
+// to the resolution history, svc is nil when no service matched the template
+func (ac *AutoConfig) recordResolution(tpl integration.Config, svc listeners.Service, outcome integration.ResolutionOutcome, reason string, configDigest string) {
+	event := integration.ResolutionEvent{
+		Timestamp:    time.Now(),
+		Outcome:      outcome,
+		Reason:       reason,
+		ConfigDigest: configDigest,
+	}
+
+	if tpl.Name != "" {
+		event.Template = tpl.Name
+		event.TemplateDigest = tpl.Digest()
+		event.TemplateSource = tpl.Source
+		event.ADIdentifiers = tpl.ADIdentifiers
+	}
+
+	if svc != nil {
+		event.ServiceID = svc.GetServiceID()
+		if adIDs, err := svc.GetADIdentifiers(context.TODO()); err == nil {
+			event.ServiceADIdentifiers = adIDs
+		}
+	}
+
+	if outcome == integration.ResolutionNoTemplate {
+		ac.store.noTemplates.add(event)
+		return
+	}
+	ac.store.resolutions.add(event)
+}
+
+// exclusionOutcome returns whether a resolved config is scheduled or excluded by the container filters
+func exclusionOutcome(config integration.Config) (integration.ResolutionOutcome, string) {
+	metricsExcluded := config.IsCheckConfig() && config.MetricsExcluded
+	logsExcluded := config.IsLogConfig() && config.LogsExcluded
+
+	switch {
+	case metricsExcluded && (logsExcluded || !config.IsLogConfig()):
+		return integration.ResolutionExcluded, "service excluded from metrics collection by the container filters"
+	case logsExcluded && !config.IsCheckConfig():
+		return integration.ResolutionExcluded, "service excluded from logs collection by the container filters"
+	case metricsExcluded:
+		return integration.ResolutionScheduled, "service excluded from metrics collection by the container filters, only logs are collected"
+	case logsExcluded:
+		return integration.ResolutionScheduled, "service excluded from logs collection by the container filters, only metrics are collected"
+	}
+	return integration.ResolutionScheduled, ""
+}
+
 // GetAutodiscoveryErrors fetches AD errors from each ConfigProvider.  The
 // resulting data structure maps provider name to resource name to a set of
 // unique error messages.  The resource names do not match other identifiers
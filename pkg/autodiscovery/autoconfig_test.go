@@ -325,6 +325,99 @@ func TestResolveTemplate(t *testing.T) {
 	assert.Len(t, res, 1)
 }
 
+func TestExplainResolution(t *testing.T) {
+	ctx := context.Background()
+
+	ac := NewAutoConfig(scheduler.NewMetaScheduler())
+	tpl := integration.Config{
+		Name:          "redisdb",
+		ADIdentifiers: []string{"redis"},
+		Instances:     []integration.Data{integration.Data("host: %%host%%")},
+	}
+
+	// no services
+	assert.Len(t, ac.processNewConfig(tpl), 0)
+
+	// service without template
+	ac.processNewService(ctx, &dummyService{
+		ID:            "docker://nginx",
+		ADIdentifiers: []string{"nginx"},
+	})
+
+	// service without host
+	ac.processNewService(ctx, &dummyService{
+		ID:            "docker://redis",
+		ADIdentifiers: []string{"redis"},
+	})
+
+	ac.processNewService(ctx, &dummyService{
+		ID:            "docker://redis",
+		ADIdentifiers: []string{"redis"},
+		Hosts:         map[string]string{"bridge": "172.17.0.2"},
+	})
+	res := ac.LoadedConfigs()
+	require.Len(t, res, 1)
+
+	events := ac.ExplainResolution("redisdb")
+	require.Len(t, events, 3)
+	assert.Equal(t, integration.ResolutionNoService, events[0].Outcome)
+	assert.Equal(t, "No service found with this AD identifier: redis", events[0].Reason)
+	assert.Empty(t, events[0].ServiceID)
+	assert.Equal(t, integration.ResolutionFailed, events[1].Outcome)
+	assert.Equal(t, "docker://redis", events[1].ServiceID)
+	assert.Equal(t, tpl.Digest(), events[1].TemplateDigest)
+	assert.NotEmpty(t, events[1].Reason)
+	assert.Equal(t, integration.ResolutionScheduled, events[2].Outcome)
+	assert.Equal(t, res[0].Digest(), events[2].ConfigDigest)
+
+	// a service can be queried by ID or AD identifier
+	assert.Equal(t, events[1:], ac.ExplainResolution("docker://redis"))
+	events = ac.ExplainResolution("nginx")
+	require.Len(t, events, 1)
+	assert.Equal(t, integration.ResolutionNoTemplate, events[0].Outcome)
+	assert.Empty(t, events[0].Template)
+
+	assert.Len(t, ac.ExplainResolution(""), 4)
+	assert.Empty(t, ac.ExplainResolution("postgres"))
+
+	// services without template don't evict the other resolutions
+	for i := 0; i < resolutionHistorySize; i++ {
+		ac.processNewService(ctx, &dummyService{
+			ID:            fmt.Sprintf("docker://nginx-%d", i),
+			ADIdentifiers: []string{"nginx"},
+		})
+	}
+	assert.Len(t, ac.ExplainResolution("redisdb"), 3)
+	assert.Len(t, ac.ExplainResolution("nginx"), noTemplateHistorySize)
+}
+
+func TestExclusionOutcome(t *testing.T) {
+	check := integration.Config{Instances: []integration.Data{integration.Data("{}")}}
+	logs := integration.Config{LogsConfig: integration.Data("[{}]")}
+	both := integration.Config{Instances: check.Instances, LogsConfig: logs.LogsConfig}
+
+	for _, test := range []struct {
+		config          integration.Config
+		metricsExcluded bool
+		logsExcluded    bool
+		expected        integration.ResolutionOutcome
+	}{
+		{check, false, false, integration.ResolutionScheduled},
+		{check, true, false, integration.ResolutionExcluded},
+		{logs, false, true, integration.ResolutionExcluded},
+		{logs, true, false, integration.ResolutionScheduled},
+		{both, true, false, integration.ResolutionScheduled},
+		{both, false, true, integration.ResolutionScheduled},
+		{both, true, true, integration.ResolutionExcluded},
+	} {
+		config := test.config
+		config.MetricsExcluded = test.metricsExcluded
+		config.LogsExcluded = test.logsExcluded
+		outcome, _ := exclusionOutcome(config)
+		assert.Equal(t, test.expected, outcome, "metrics excluded: %v, logs excluded: %v", test.metricsExcluded, test.logsExcluded)
+	}
+}
+
 func countLoadedConfigs(ac *AutoConfig) int {
 	count := -1 // -1 would indicate f was not called
 	ac.MapOverLoadedConfigs(func(loadedConfigs map[string]integration.Config) {
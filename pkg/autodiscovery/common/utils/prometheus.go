@@ -15,10 +15,20 @@ import (
 )
 
 const (
-	openmetricsCheckName  = "openmetrics"
-	openmetricsInitConfig = "{}"
+	openmetricsCheckName = "openmetrics"
+	// openmetricsCoreInitConfig makes the core check loader load the check,
+	// the Python loader comes first otherwise
+	openmetricsCoreInitConfig = `{"loader":"core"}`
 )
 
+// openmetricsInitConfig returns the init_config of the scheduled openmetrics checks
+func openmetricsInitConfig() integration.Data {
+	if config.Datadog.GetBool("prometheus_scrape.use_core_check") {
+		return integration.Data(openmetricsCoreInitConfig)
+	}
+	return integration.Data("{}")
+}
+
 // buildInstances generates check config instances based on the Prometheus config and the object annotations
 // The second returned value is true if more than one instance is found
 func buildInstances(pc *types.PrometheusCheck, annotations map[string]string, namespacedName string) ([]integration.Data, bool) {
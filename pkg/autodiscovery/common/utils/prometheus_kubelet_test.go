@@ -22,12 +22,13 @@ import (
 
 func TestConfigsForPod(t *testing.T) {
 	tests := []struct {
-		name    string
-		check   *types.PrometheusCheck
-		version int
-		pod     *kubelet.Pod
-		want    []integration.Config
-		matched bool
+		name         string
+		check        *types.PrometheusCheck
+		version      int
+		useCoreCheck bool
+		pod          *kubelet.Pod
+		want         []integration.Config
+		matched      bool
 	}{
 		{
 			name:    "nominal case v1",
@@ -99,6 +100,42 @@ func TestConfigsForPod(t *testing.T) {
 				},
 			},
 		},
+		{
+			name:         "nominal case with core check",
+			check:        types.DefaultPrometheusCheck,
+			version:      2,
+			useCoreCheck: true,
+			pod: &kubelet.Pod{
+				Metadata: kubelet.PodMetadata{
+					Name:        "foo-pod",
+					Annotations: map[string]string{"prometheus.io/scrape": "true"},
+				},
+				Status: kubelet.Status{
+					Containers: []kubelet.ContainerStatus{
+						{
+							Name: "foo-ctr",
+							ID:   "foo-ctr-id",
+						},
+					},
+					AllContainers: []kubelet.ContainerStatus{
+						{
+							Name: "foo-ctr",
+							ID:   "foo-ctr-id",
+						},
+					},
+				},
+			},
+			want: []integration.Config{
+				{
+					Name:          "openmetrics",
+					InitConfig:    integration.Data(`{"loader":"core"}`),
+					Instances:     []integration.Data{integration.Data(`{"namespace":"","metrics":[".*"],"openmetrics_endpoint":"http://%%host%%:%%port%%/metrics"}`)},
+					Provider:      names.PrometheusPods,
+					Source:        "prometheus_pods:foo-ctr-id",
+					ADIdentifiers: []string{"foo-ctr-id"},
+				},
+			},
+		},
 		{
 			name: "custom openmetrics_endpoint",
 			check: &types.PrometheusCheck{
@@ -408,6 +445,7 @@ func TestConfigsForPod(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			config.Datadog.Set("prometheus_scrape.version", tt.version)
+			config.Datadog.Set("prometheus_scrape.use_core_check", tt.useCoreCheck)
 			tt.check.Init()
 			assert.ElementsMatch(t, tt.want, ConfigsForPod(tt.check, tt.pod))
 		})
@@ -24,12 +24,15 @@ import (
 type variableGetter func(ctx context.Context, key []byte, svc listeners.Service) ([]byte, error)
 
 var templateVariables = map[string]variableGetter{
-	"host":     getHost,
-	"pid":      getPid,
-	"port":     getPort,
-	"hostname": getHostname,
-	"extra":    getAdditionalTplVariables,
-	"kube":     getAdditionalTplVariables,
+	"host":       getHost,
+	"pid":        getPid,
+	"port":       getPort,
+	"hostname":   getHostname,
+	"extra":      getAdditionalTplVariables,
+	"kube":       getAdditionalTplVariables,
+	"label":      getLabel,
+	"annotation": getAnnotation,
+	"container":  getContainerVar,
 }
 
 // SubstituteTemplateEnvVars replaces %%ENV_VARIABLE%% from environment
@@ -160,7 +163,11 @@ func resolveDataWithTemplateVars(ctx context.Context, data integration.Data, svc
 		if f, found := templateVariables[string(tVar.Name)]; found {
 			resolvedVar, err := f(ctx, tVar.Key, svc)
 			if err != nil {
-				return res, err
+				if !tVar.HasDefault {
+					return res, err
+				}
+				log.Debugf("Using the default value of %s: %s", tVar.Raw, err)
+				resolvedVar = tVar.Default
 			}
 			res = bytes.Replace(res, tVar.Raw, resolvedVar, -1)
 		}
@@ -177,6 +184,9 @@ func resolveDataWithEnvs(data integration.Data) ([]byte, error) {
 	for _, tVar := range templateVars {
 		if "env" == string(tVar.Name) {
 			resolvedVar, err := getEnvvar(tVar.Key)
+			if err != nil && tVar.HasDefault {
+				resolvedVar, err = tVar.Default, nil
+			}
 			if err != nil {
 				log.Warnf("variable not replaced: %s", err)
 				if retErr == nil {
@@ -306,6 +316,43 @@ func getAdditionalTplVariables(_ context.Context, tplVar []byte, svc listeners.S
 	return value, nil
 }
 
+// getLabel returns a label of the container or pod behind the service
+func getLabel(_ context.Context, tplVar []byte, svc listeners.Service) ([]byte, error) {
+	return getWorkloadMetadata(tplVar, svc, "label", listeners.WorkloadMetadataService.GetLabel)
+}
+
+// getAnnotation returns an annotation of the container or pod behind the service
+func getAnnotation(_ context.Context, tplVar []byte, svc listeners.Service) ([]byte, error) {
+	return getWorkloadMetadata(tplVar, svc, "annotation", listeners.WorkloadMetadataService.GetAnnotation)
+}
+
+// getContainerVar resolves the container_ template variables, only container_env_<NAME>
+// is supported to return an environment variable of the container behind the service
+func getContainerVar(_ context.Context, tplVar []byte, svc listeners.Service) ([]byte, error) {
+	name := bytes.TrimPrefix(tplVar, []byte("env_"))
+	if len(name) == len(tplVar) {
+		return nil, fmt.Errorf("unsupported template variable container_%s for service %s", tplVar, svc.GetServiceID())
+	}
+	return getWorkloadMetadata(name, svc, "container environment variable", listeners.WorkloadMetadataService.GetContainerEnv)
+}
+
+func getWorkloadMetadata(tplVar []byte, svc listeners.Service, kind string, get func(listeners.WorkloadMetadataService, string) (string, error)) ([]byte, error) {
+	if len(tplVar) == 0 {
+		return nil, fmt.Errorf("%s name is missing", kind)
+	}
+
+	metadataSvc, ok := svc.(listeners.WorkloadMetadataService)
+	if !ok {
+		return nil, fmt.Errorf("%s template variables are not supported for service %s", kind, svc.GetServiceID())
+	}
+
+	value, err := get(metadataSvc, string(tplVar))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get %s for service %s, skipping config - %s", kind, svc.GetServiceID(), err)
+	}
+	return []byte(value), nil
+}
+
 // getEnvvar returns a system environment variable if found
 func getEnvvar(envVar []byte) ([]byte, error) {
 	if len(envVar) == 0 {
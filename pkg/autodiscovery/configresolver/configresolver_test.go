@@ -93,6 +93,36 @@ func (s *dummyService) GetExtraConfig(key []byte) ([]byte, error) {
 	return []byte(s.ExtraConfig[string(key)]), nil
 }
 
+// metadataService is a dummyService backed by workload metadata
+type metadataService struct {
+	dummyService
+	Labels      map[string]string
+	Annotations map[string]string
+	Env         map[string]string
+}
+
+func lookup(kind string, m map[string]string, name string) (string, error) {
+	if value, found := m[name]; found {
+		return value, nil
+	}
+	return "", fmt.Errorf("%s %q not found", kind, name)
+}
+
+// GetLabel returns a dummy label
+func (s *metadataService) GetLabel(name string) (string, error) {
+	return lookup("label", s.Labels, name)
+}
+
+// GetAnnotation returns a dummy annotation
+func (s *metadataService) GetAnnotation(name string) (string, error) {
+	return lookup("annotation", s.Annotations, name)
+}
+
+// GetContainerEnv returns a dummy environment variable
+func (s *metadataService) GetContainerEnv(name string) (string, error) {
+	return lookup("environment variable", s.Env, name)
+}
+
 func TestGetFallbackHost(t *testing.T) {
 	ip, err := getFallbackHost(map[string]string{"bridge": "172.17.0.1"})
 	assert.Equal(t, "172.17.0.1", ip)
@@ -700,6 +730,95 @@ func TestResolve(t *testing.T) {
 				ServiceID:     "a5901276aed1",
 			},
 		},
+		//// workload metadata testing
+		{
+			testName: "label, annotation and container env",
+			svc: &metadataService{
+				dummyService: dummyService{
+					ID:            "a5901276aed1",
+					ADIdentifiers: []string{"postgres"},
+				},
+				Labels:      map[string]string{"app.kubernetes.io/name": "billing"},
+				Annotations: map[string]string{"db.example.com/credentials": "/etc/secrets/pg"},
+				Env:         map[string]string{"POSTGRES_DB": "invoices"},
+			},
+			tpl: integration.Config{
+				Name:          "postgres",
+				ADIdentifiers: []string{"postgres"},
+				Instances:     []integration.Data{integration.Data("app: %%label_app.kubernetes.io/name%%\ncredentials: %%annotation_db.example.com/credentials%%\ndbname: %%container_env_POSTGRES_DB%%")},
+			},
+			out: integration.Config{
+				Name:          "postgres",
+				ADIdentifiers: []string{"postgres"},
+				Instances:     []integration.Data{integration.Data("app: billing\ncredentials: /etc/secrets/pg\ndbname: invoices\ntags:\n- foo:bar\n")},
+				ServiceID:     "a5901276aed1",
+			},
+		},
+		{
+			testName: "missing label without default",
+			svc: &metadataService{
+				dummyService: dummyService{
+					ID:            "a5901276aed1",
+					ADIdentifiers: []string{"postgres"},
+				},
+			},
+			tpl: integration.Config{
+				Name:          "postgres",
+				ADIdentifiers: []string{"postgres"},
+				Instances:     []integration.Data{integration.Data("app: %%label_app%%")},
+			},
+			errorString: "failed to get label for service a5901276aed1, skipping config - label \"app\" not found",
+		},
+		{
+			testName: "default values",
+			svc: &metadataService{
+				dummyService: dummyService{
+					ID:            "a5901276aed1",
+					ADIdentifiers: []string{"postgres"},
+					Ports:         []listeners.ContainerPort{{Port: 5432, Name: "pg"}},
+				},
+				Env: map[string]string{"POSTGRES_DB": "invoices"},
+			},
+			tpl: integration.Config{
+				Name:          "postgres",
+				ADIdentifiers: []string{"postgres"},
+				Instances:     []integration.Data{integration.Data("app: %%label_app|unknown%%\nport: %%port_pg|5433%%\nmetrics_port: %%port_metrics|9187%%\ndbname: %%container_env_POSTGRES_DB|postgres%%\nuser: \"%%container_env_POSTGRES_USER|%%\"\nenv: %%env_test_envvar_not_set|dev%%")},
+			},
+			out: integration.Config{
+				Name:          "postgres",
+				ADIdentifiers: []string{"postgres"},
+				Instances:     []integration.Data{integration.Data("app: unknown\ndbname: invoices\nenv: dev\nmetrics_port: 9187\nport: 5432\ntags:\n- foo:bar\nuser: \"\"\n")},
+				ServiceID:     "a5901276aed1",
+			},
+		},
+		{
+			testName: "labels of a service without workload metadata",
+			svc: &dummyService{
+				ID:            "a5901276aed1",
+				ADIdentifiers: []string{"postgres"},
+			},
+			tpl: integration.Config{
+				Name:          "postgres",
+				ADIdentifiers: []string{"postgres"},
+				Instances:     []integration.Data{integration.Data("app: %%label_app%%")},
+			},
+			errorString: "label template variables are not supported for service a5901276aed1",
+		},
+		{
+			testName: "unsupported container variable",
+			svc: &metadataService{
+				dummyService: dummyService{
+					ID:            "a5901276aed1",
+					ADIdentifiers: []string{"postgres"},
+				},
+			},
+			tpl: integration.Config{
+				Name:          "postgres",
+				ADIdentifiers: []string{"postgres"},
+				Instances:     []integration.Data{integration.Data("name: %%container_name%%")},
+			},
+			errorString: "unsupported template variable container_name for service a5901276aed1",
+		},
 	}
 
 	for i, tc := range testCases {
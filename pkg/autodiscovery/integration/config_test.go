@@ -273,6 +273,15 @@ func TestSetNameForInstance(t *testing.T) {
 	err := config.Instances[0].SetNameForInstance("new-name")
 	assert.NoError(t, err)
 	assert.Equal(t, config.Instances[0].GetNameForInstance(), "new-name")
+
+	// the instance is marshalled back, unset optional fields must not be added
+	// as they would change the ID of the check
+	assert.Equal(t, Data("min_collection_interval: 0\nempty_default_hostname: false\ntags: []\nservice: \"\"\nname: new-name\nnamespace: \"\"\n"), config.Instances[0])
+
+	instance := Data("name: foobar\ncheck_timeout: 30")
+	err = instance.SetNameForInstance("new-name")
+	assert.NoError(t, err)
+	assert.Contains(t, string(instance), "check_timeout: 30\n")
 }
 
 // this is here to prevent compiler optimization on the benchmarking code
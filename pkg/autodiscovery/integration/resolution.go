@@ -0,0 +1,72 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package integration
+
+import (
+	"time"
+)
+
+// ResolutionOutcome is the result of the resolution of a template for a service
+type ResolutionOutcome string
+
+const (
+	// ResolutionScheduled means the template was resolved and the config scheduled
+	ResolutionScheduled ResolutionOutcome = "scheduled"
+	// ResolutionExcluded means the template was resolved but the service is
+	// excluded from metrics and logs collection by the container filters
+	ResolutionExcluded ResolutionOutcome = "excluded"
+	// ResolutionNoService means no known service matches the AD identifiers of the template
+	ResolutionNoService ResolutionOutcome = "no_matching_service"
+	// ResolutionNoTemplate means no template matches the AD identifiers of the service
+	ResolutionNoTemplate ResolutionOutcome = "no_matching_template"
+	// ResolutionFailed means the template variables couldn't be resolved for the service
+	ResolutionFailed ResolutionOutcome = "resolution_failed"
+	// ResolutionSecretsFailed means the secrets of the resolved config couldn't be decrypted
+	ResolutionSecretsFailed ResolutionOutcome = "secrets_failed"
+)
+
+// ResolutionEvent records why the resolution of a template for a service did or
+// did not produce a config
+type ResolutionEvent struct {
+	Timestamp time.Time         `json:"timestamp"`
+	Outcome   ResolutionOutcome `json:"outcome"`
+	Reason    string            `json:"reason,omitempty"`
+
+	// Template is the name of the check of the template, empty when no template matched the service
+	Template       string   `json:"template,omitempty"`
+	TemplateDigest string   `json:"template_digest,omitempty"`
+	TemplateSource string   `json:"template_source,omitempty"`
+	ADIdentifiers  []string `json:"ad_identifiers,omitempty"`
+
+	// ServiceID is the ID of the service, empty when no service matched the template
+	ServiceID            string   `json:"service_id,omitempty"`
+	ServiceADIdentifiers []string `json:"service_ad_identifiers,omitempty"`
+
+	// ConfigDigest is the digest of the scheduled config
+	ConfigDigest string `json:"config_digest,omitempty"`
+}
+
+// Matches returns whether the event relates to the given template name, digest
+// or AD identifier, or to the given service ID
+func (e *ResolutionEvent) Matches(query string) bool {
+	if query == "" {
+		return true
+	}
+	if query == e.Template || query == e.TemplateDigest || query == e.ServiceID {
+		return true
+	}
+	for _, id := range e.ADIdentifiers {
+		if query == id {
+			return true
+		}
+	}
+	for _, id := range e.ServiceADIdentifiers {
+		if query == id {
+			return true
+		}
+	}
+	return false
+}
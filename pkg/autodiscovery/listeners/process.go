@@ -0,0 +1,442 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build linux
+// +build linux
+
+package listeners
+
+import (
+	"bufio"
+	"context"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/util/containers"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const (
+	processServicePrefix = "process://"
+	// tcpListenState is the state of listening sockets in /proc/net/tcp
+	tcpListenState = "0A"
+)
+
+func init() {
+	Register("process", NewProcessListener)
+}
+
+// ProcessListener discovers the processes of the host listening on TCP ports
+type ProcessListener struct {
+	sync.RWMutex
+	procRoot   string
+	interval   time.Duration
+	newService chan<- Service
+	delService chan<- Service
+	services   map[string]*ProcessService
+	stop       chan bool
+}
+
+// ProcessService implements the Service interface for a process of the host
+type ProcessService struct {
+	serviceID    string
+	adIdentifier string
+	pid          int
+	hosts        map[string]string
+	ports        []ContainerPort
+}
+
+// Make sure ProcessService implements the Service interface
+var _ Service = &ProcessService{}
+
+// hostProcess holds the attributes of a process read from procfs
+type hostProcess struct {
+	pid   int
+	ppid  int
+	name  string
+	pidNS string
+	netNS string
+	// inodes of the sockets opened by the process
+	sockets map[uint64]struct{}
+}
+
+// listeningSocket is a TCP socket in the LISTEN state
+type listeningSocket struct {
+	ip   net.IP
+	port int
+}
+
+// defaultProcessListenerInterval is used when process_listener_polling_interval is not a positive duration
+const defaultProcessListenerInterval = 30 * time.Second
+
+// NewProcessListener creates a ProcessListener
+func NewProcessListener(Config) (ServiceListener, error) {
+	interval := time.Duration(config.Datadog.GetInt("process_listener_polling_interval")) * time.Second
+	if interval <= 0 {
+		log.Warnf("Invalid process_listener_polling_interval %v, using default value %v", interval, defaultProcessListenerInterval)
+		interval = defaultProcessListenerInterval
+	}
+
+	return &ProcessListener{
+		procRoot: config.Datadog.GetString("container_proc_root"),
+		interval: interval,
+		services: make(map[string]*ProcessService),
+		stop:     make(chan bool),
+	}, nil
+}
+
+// Listen periodically lists the processes listening on TCP ports
+func (l *ProcessListener) Listen(newSvc chan<- Service, delSvc chan<- Service) {
+	l.newService = newSvc
+	l.delService = delSvc
+
+	go func() {
+		ticker := time.NewTicker(l.interval)
+		defer ticker.Stop()
+
+		l.refreshServices()
+		for {
+			select {
+			case <-l.stop:
+				return
+			case <-ticker.C:
+				l.refreshServices()
+			}
+		}
+	}()
+}
+
+// Stop queues a shutdown of ProcessListener
+func (l *ProcessListener) Stop() {
+	l.stop <- true
+}
+
+func (l *ProcessListener) refreshServices() {
+	l.Lock()
+	defer l.Unlock()
+
+	services, err := l.discoverServices()
+	if err != nil {
+		log.Warnf("Failed to discover processes: %v", err)
+		return
+	}
+
+	for id, svc := range l.services {
+		if current, found := services[id]; !found || !reflect.DeepEqual(current.ports, svc.ports) {
+			log.Debugf("Process %d (%s) is no longer listening on the same ports", svc.pid, svc.adIdentifier)
+			l.delService <- svc
+			delete(l.services, id)
+		}
+	}
+
+	for id, svc := range services {
+		if _, found := l.services[id]; found {
+			continue
+		}
+		log.Debugf("Process %d (%s) discovered, listening on %v", svc.pid, svc.adIdentifier, svc.ports)
+		l.services[id] = svc
+		l.newService <- svc
+	}
+}
+
+// discoverServices returns the services of the processes of the host pid namespace that
+// listen on TCP ports. Children having the same executable as their parent, like the
+// workers of a server, are merged into it.
+func (l *ProcessListener) discoverServices() (map[string]*ProcessService, error) {
+	processes, err := l.readProcesses()
+	if err != nil {
+		return nil, err
+	}
+
+	hostPidNS := ""
+	if init, found := processes[1]; found {
+		hostPidNS = init.pidNS
+	}
+
+	socketsPerNetNS := make(map[string]map[uint64]listeningSocket)
+	services := make(map[string]*ProcessService)
+
+	for _, p := range processes {
+		// processes running in containers are discovered by the container listeners
+		if p.pidNS != hostPidNS {
+			continue
+		}
+
+		if parent, found := processes[p.ppid]; found && parent.name == p.name {
+			continue
+		}
+
+		sockets, found := socketsPerNetNS[p.netNS]
+		if !found {
+			sockets = l.readListeningSockets(p.pid)
+			socketsPerNetNS[p.netNS] = sockets
+		}
+
+		svc := newProcessService(p, sockets)
+		if svc != nil {
+			services[svc.serviceID] = svc
+		}
+	}
+
+	return services, nil
+}
+
+func newProcessService(p *hostProcess, sockets map[uint64]listeningSocket) *ProcessService {
+	var host string
+	seen := make(map[int]bool)
+	var ports []ContainerPort
+
+	for inode := range p.sockets {
+		socket, found := sockets[inode]
+		if !found {
+			continue
+		}
+
+		if !seen[socket.port] {
+			seen[socket.port] = true
+			ports = append(ports, ContainerPort{Port: socket.port})
+		}
+
+		// prefer the loopback address when the process listens on all interfaces
+		ip := socket.ip.String()
+		if socket.ip.IsUnspecified() {
+			ip = "127.0.0.1"
+		}
+		if host == "" || ip < host {
+			host = ip
+		}
+	}
+
+	if len(ports) == 0 {
+		return nil
+	}
+
+	sort.Slice(ports, func(i, j int) bool {
+		return ports[i].Port < ports[j].Port
+	})
+
+	return &ProcessService{
+		serviceID:    fmt.Sprintf("%s%d", processServicePrefix, p.pid),
+		adIdentifier: p.name,
+		pid:          p.pid,
+		hosts:        map[string]string{"host": host},
+		ports:        ports,
+	}
+}
+
+// readProcesses lists the processes having an executable, which excludes kernel threads
+func (l *ProcessListener) readProcesses() (map[int]*hostProcess, error) {
+	entries, err := os.ReadDir(l.procRoot)
+	if err != nil {
+		return nil, err
+	}
+
+	processes := make(map[int]*hostProcess)
+	for _, entry := range entries {
+		pid, err := strconv.Atoi(entry.Name())
+		if err != nil {
+			continue
+		}
+
+		if p := l.readProcess(pid); p != nil {
+			processes[pid] = p
+		}
+	}
+	return processes, nil
+}
+
+func (l *ProcessListener) readProcess(pid int) *hostProcess {
+	pidPath := filepath.Join(l.procRoot, strconv.Itoa(pid))
+
+	exe, err := os.Readlink(filepath.Join(pidPath, "exe"))
+	if err != nil || exe == "" {
+		return nil
+	}
+
+	stat, err := os.ReadFile(filepath.Join(pidPath, "stat"))
+	if err != nil {
+		return nil
+	}
+
+	// the command name can hold spaces and parentheses, fields start after the last one
+	statFields := strings.Fields(string(stat[strings.LastIndexByte(string(stat), ')')+1:]))
+	if len(statFields) < 2 {
+		return nil
+	}
+	ppid, _ := strconv.Atoi(statFields[1])
+
+	pidNS, _ := os.Readlink(filepath.Join(pidPath, "ns", "pid"))
+	netNS, _ := os.Readlink(filepath.Join(pidPath, "ns", "net"))
+
+	p := &hostProcess{
+		pid:     pid,
+		ppid:    ppid,
+		name:    filepath.Base(strings.TrimSuffix(exe, " (deleted)")),
+		pidNS:   pidNS,
+		netNS:   netNS,
+		sockets: make(map[uint64]struct{}),
+	}
+
+	fds, err := os.ReadDir(filepath.Join(pidPath, "fd"))
+	if err != nil {
+		return p
+	}
+	for _, fd := range fds {
+		link, err := os.Readlink(filepath.Join(pidPath, "fd", fd.Name()))
+		if err != nil || !strings.HasPrefix(link, "socket:[") {
+			continue
+		}
+		inode, err := strconv.ParseUint(strings.TrimSuffix(strings.TrimPrefix(link, "socket:["), "]"), 10, 64)
+		if err == nil {
+			p.sockets[inode] = struct{}{}
+		}
+	}
+
+	return p
+}
+
+// readListeningSockets returns the TCP listening sockets of the network namespace of a process, by inode
+func (l *ProcessListener) readListeningSockets(pid int) map[uint64]listeningSocket {
+	sockets := make(map[uint64]listeningSocket)
+	for _, file := range []string{"tcp", "tcp6"} {
+		path := filepath.Join(l.procRoot, strconv.Itoa(pid), "net", file)
+		if err := readProcNetTCP(path, sockets); err != nil && !os.IsNotExist(err) {
+			log.Debugf("Failed to read %s: %v", path, err)
+		}
+	}
+	return sockets
+}
+
+// readProcNetTCP parses a /proc/net/tcp or /proc/net/tcp6 file
+func readProcNetTCP(path string, sockets map[uint64]listeningSocket) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	// skip the header
+	scanner.Scan()
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 10 || fields[3] != tcpListenState {
+			continue
+		}
+
+		ip, port, err := parseProcNetAddress(fields[1])
+		if err != nil {
+			continue
+		}
+
+		inode, err := strconv.ParseUint(fields[9], 10, 64)
+		if err != nil {
+			continue
+		}
+
+		sockets[inode] = listeningSocket{ip: ip, port: port}
+	}
+	return scanner.Err()
+}
+
+// parseProcNetAddress parses an address like 0100007F:1F90, the IP being made of
+// 32 bits words in host byte order
+func parseProcNetAddress(address string) (net.IP, int, error) {
+	parts := strings.SplitN(address, ":", 2)
+	if len(parts) != 2 {
+		return nil, 0, fmt.Errorf("invalid address %s", address)
+	}
+
+	raw, err := hex.DecodeString(parts[0])
+	if err != nil || (len(raw) != net.IPv4len && len(raw) != net.IPv6len) {
+		return nil, 0, fmt.Errorf("invalid address %s", address)
+	}
+
+	ip := make(net.IP, len(raw))
+	for i := 0; i < len(raw); i += 4 {
+		ip[i], ip[i+1], ip[i+2], ip[i+3] = raw[i+3], raw[i+2], raw[i+1], raw[i]
+	}
+
+	port, err := strconv.ParseUint(parts[1], 16, 16)
+	if err != nil {
+		return nil, 0, fmt.Errorf("invalid port in address %s", address)
+	}
+
+	if ip4 := ip.To4(); ip4 != nil {
+		ip = ip4
+	}
+
+	return ip, int(port), nil
+}
+
+// GetServiceID returns the unique entity name linked to that service
+func (s *ProcessService) GetServiceID() string {
+	return s.serviceID
+}
+
+// GetTaggerEntity returns the unique entity name linked to that service
+func (s *ProcessService) GetTaggerEntity() string {
+	return s.serviceID
+}
+
+// GetADIdentifiers returns the name of the executable of the process
+func (s *ProcessService) GetADIdentifiers(context.Context) ([]string, error) {
+	return []string{s.adIdentifier}, nil
+}
+
+// GetHosts returns the address the process listens on
+func (s *ProcessService) GetHosts(context.Context) (map[string]string, error) {
+	return s.hosts, nil
+}
+
+// GetPorts returns the ports the process listens on
+func (s *ProcessService) GetPorts(context.Context) ([]ContainerPort, error) {
+	return s.ports, nil
+}
+
+// GetTags returns no tags as processes aren't tagged
+func (s *ProcessService) GetTags() ([]string, string, error) {
+	return []string{}, "", nil
+}
+
+// GetPid returns the process identifier
+func (s *ProcessService) GetPid(context.Context) (int, error) {
+	return s.pid, nil
+}
+
+// GetHostname returns nil and an error because hostnames are not supported for processes
+func (s *ProcessService) GetHostname(context.Context) (string, error) {
+	return "", ErrNotSupported
+}
+
+// IsReady always returns true for processes
+func (s *ProcessService) IsReady(context.Context) bool {
+	return true
+}
+
+// GetCheckNames always returns empty slice for processes
+func (s *ProcessService) GetCheckNames(context.Context) []string {
+	return []string{}
+}
+
+// HasFilter returns false for processes
+func (s *ProcessService) HasFilter(filter containers.FilterType) bool {
+	return false
+}
+
+// GetExtraConfig isn't supported
+func (s *ProcessService) GetExtraConfig(key []byte) ([]byte, error) {
+	return []byte{}, ErrNotSupported
+}
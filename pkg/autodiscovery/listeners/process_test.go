@@ -0,0 +1,201 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build linux
+// +build linux
+
+package listeners
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	fakeProcNetTCP = `  sl  local_address rem_address   st tx_queue rx_queue tr tm->when retrnsmt   uid  timeout inode
+   0: 00000000:0050 00000000:0000 0A 00000000:00000000 00:00000000 00000000     0        0 1000 1 0000000000000000 100 0 0 10 0
+   1: 0100007F:18EB 00000000:0000 0A 00000000:00000000 00:00000000 00000000   999        0 2000 1 0000000000000000 100 0 0 10 0
+   2: 0100007F:18EB 0100007F:D431 01 00000000:00000000 00:00000000 00000000   999        0 2002 1 0000000000000000 20 4 30 10 -1
+`
+	fakeProcNetTCP6 = `  sl  local_address                         remote_address                        st tx_queue rx_queue tr tm->when retrnsmt   uid  timeout inode
+   0: 00000000000000000000000000000000:18EB 00000000000000000000000000000000:0000 0A 00000000:00000000 00:00000000 00000000   999        0 2001 1 0000000000000000 100 0 0 10 0
+`
+)
+
+type fakeProcess struct {
+	pid     int
+	ppid    int
+	exe     string
+	pidNS   string
+	sockets []int
+}
+
+func setupFakeProc(t *testing.T, processes []fakeProcess) string {
+	root := t.TempDir()
+
+	for _, p := range processes {
+		pidPath := filepath.Join(root, strconv.Itoa(p.pid))
+		for _, dir := range []string{"fd", "ns", "net"} {
+			require.NoError(t, os.MkdirAll(filepath.Join(pidPath, dir), 0755))
+		}
+
+		if p.exe != "" {
+			require.NoError(t, os.Symlink(p.exe, filepath.Join(pidPath, "exe")))
+		}
+		stat := fmt.Sprintf("%d (%s) S %d %d %d 0 -1", p.pid, filepath.Base(p.exe), p.ppid, p.pid, p.pid)
+		require.NoError(t, os.WriteFile(filepath.Join(pidPath, "stat"), []byte(stat), 0644))
+		require.NoError(t, os.Symlink(p.pidNS, filepath.Join(pidPath, "ns", "pid")))
+		require.NoError(t, os.Symlink("net:[4026531992]", filepath.Join(pidPath, "ns", "net")))
+		require.NoError(t, os.WriteFile(filepath.Join(pidPath, "net", "tcp"), []byte(fakeProcNetTCP), 0644))
+		require.NoError(t, os.WriteFile(filepath.Join(pidPath, "net", "tcp6"), []byte(fakeProcNetTCP6), 0644))
+
+		require.NoError(t, os.Symlink("/dev/null", filepath.Join(pidPath, "fd", "0")))
+		for i, inode := range p.sockets {
+			require.NoError(t, os.Symlink(fmt.Sprintf("socket:[%d]", inode), filepath.Join(pidPath, "fd", strconv.Itoa(i+3))))
+		}
+	}
+
+	return root
+}
+
+func TestProcessListenerDiscoverServices(t *testing.T) {
+	hostNS := "pid:[4026531836]"
+	root := setupFakeProc(t, []fakeProcess{
+		{pid: 1, ppid: 0, exe: "/usr/lib/systemd/systemd", pidNS: hostNS},
+		// kernel thread
+		{pid: 2, ppid: 0, pidNS: hostNS},
+		{pid: 100, ppid: 1, exe: "/usr/sbin/nginx", pidNS: hostNS, sockets: []int{1000}},
+		// nginx worker sharing the listening socket of its parent
+		{pid: 101, ppid: 100, exe: "/usr/sbin/nginx", pidNS: hostNS, sockets: []int{1000}},
+		// the established connection is ignored
+		{pid: 200, ppid: 1, exe: "/usr/bin/redis-server", pidNS: hostNS, sockets: []int{2000, 2001, 2002}},
+		// containerized process
+		{pid: 300, ppid: 1, exe: "/usr/bin/postgres", pidNS: "pid:[4026532201]", sockets: []int{1000}},
+		// process not listening
+		{pid: 400, ppid: 1, exe: "/usr/sbin/cron", pidNS: hostNS},
+	})
+
+	l := &ProcessListener{
+		procRoot: root,
+		services: make(map[string]*ProcessService),
+	}
+
+	services, err := l.discoverServices()
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]*ProcessService{
+		"process://100": {
+			serviceID:    "process://100",
+			adIdentifier: "nginx",
+			pid:          100,
+			hosts:        map[string]string{"host": "127.0.0.1"},
+			ports:        []ContainerPort{{Port: 80}},
+		},
+		"process://200": {
+			serviceID:    "process://200",
+			adIdentifier: "redis-server",
+			pid:          200,
+			hosts:        map[string]string{"host": "127.0.0.1"},
+			ports:        []ContainerPort{{Port: 6379}},
+		},
+	}, services)
+}
+
+func TestProcessListenerRefreshServices(t *testing.T) {
+	hostNS := "pid:[4026531836]"
+	root := setupFakeProc(t, []fakeProcess{
+		{pid: 1, ppid: 0, exe: "/usr/lib/systemd/systemd", pidNS: hostNS},
+		{pid: 100, ppid: 1, exe: "/usr/sbin/nginx", pidNS: hostNS, sockets: []int{1000}},
+	})
+
+	newSvc := make(chan Service, 10)
+	delSvc := make(chan Service, 10)
+	l := &ProcessListener{
+		procRoot:   root,
+		newService: newSvc,
+		delService: delSvc,
+		services:   make(map[string]*ProcessService),
+	}
+
+	l.refreshServices()
+	require.Len(t, newSvc, 1)
+	svc := <-newSvc
+	assert.Equal(t, "process://100", svc.GetServiceID())
+
+	// nothing changed
+	l.refreshServices()
+	assert.Len(t, newSvc, 0)
+	assert.Len(t, delSvc, 0)
+
+	// nginx now also listens on 6379
+	require.NoError(t, os.Symlink("socket:[2000]", filepath.Join(root, "100", "fd", "9")))
+	l.refreshServices()
+	require.Len(t, delSvc, 1)
+	assert.Equal(t, "process://100", (<-delSvc).GetServiceID())
+	require.Len(t, newSvc, 1)
+	svc = <-newSvc
+	ports, err := svc.GetPorts(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, []ContainerPort{{Port: 80}, {Port: 6379}}, ports)
+
+	// nginx exited
+	require.NoError(t, os.RemoveAll(filepath.Join(root, "100")))
+	l.refreshServices()
+	require.Len(t, delSvc, 1)
+	assert.Equal(t, "process://100", (<-delSvc).GetServiceID())
+	assert.Len(t, newSvc, 0)
+}
+
+func TestNewProcessListenerInterval(t *testing.T) {
+	cfg := config.Mock()
+	defer cfg.Set("process_listener_polling_interval", 30)
+
+	for _, test := range []struct {
+		setting  int
+		expected time.Duration
+	}{
+		{setting: 10, expected: 10 * time.Second},
+		{setting: 0, expected: defaultProcessListenerInterval},
+		{setting: -5, expected: defaultProcessListenerInterval},
+	} {
+		cfg.Set("process_listener_polling_interval", test.setting)
+
+		l, err := NewProcessListener(nil)
+		require.NoError(t, err)
+		assert.Equal(t, test.expected, l.(*ProcessListener).interval)
+	}
+}
+
+func TestParseProcNetAddress(t *testing.T) {
+	for _, test := range []struct {
+		address string
+		ip      net.IP
+		port    int
+	}{
+		{"0100007F:1F90", net.IPv4(127, 0, 0, 1).To4(), 8080},
+		{"00000000:0050", net.IPv4zero.To4(), 80},
+		{"0000000000000000FFFF00000100007F:01BB", net.IPv4(127, 0, 0, 1).To4(), 443},
+		{"00000000000000000000000001000000:0016", net.IPv6loopback, 22},
+	} {
+		ip, port, err := parseProcNetAddress(test.address)
+		assert.NoError(t, err, test.address)
+		assert.Equal(t, test.ip, ip, test.address)
+		assert.Equal(t, test.port, port, test.address)
+	}
+
+	_, _, err := parseProcNetAddress("0100007F")
+	assert.Error(t, err)
+}
@@ -34,6 +34,7 @@ type service struct {
 }
 
 var _ Service = &service{}
+var _ WorkloadMetadataService = &service{}
 
 // GetServiceID returns the AD entity ID of the service.
 func (s *service) GetServiceID() string {
@@ -126,6 +127,56 @@ func (s *service) GetExtraConfig(key []byte) ([]byte, error) {
 	return []byte(result), nil
 }
 
+// GetLabel returns a label of the service. The labels of a container fall back
+// on the labels of its pod.
+func (s *service) GetLabel(name string) (string, error) {
+	return s.getMetadata("label", name, func(meta workloadmeta.EntityMeta) map[string]string {
+		return meta.Labels
+	})
+}
+
+// GetAnnotation returns an annotation of the service. The annotations of a
+// container fall back on the annotations of its pod.
+func (s *service) GetAnnotation(name string) (string, error) {
+	return s.getMetadata("annotation", name, func(meta workloadmeta.EntityMeta) map[string]string {
+		return meta.Annotations
+	})
+}
+
+// GetContainerEnv returns an environment variable of the container of the service.
+func (s *service) GetContainerEnv(name string) (string, error) {
+	container, ok := s.entity.(*workloadmeta.Container)
+	if !ok {
+		return "", fmt.Errorf("environment variables are only available for containers")
+	}
+
+	value, found := container.EnvVars[name]
+	if !found {
+		return "", fmt.Errorf("environment variable %q not found", name)
+	}
+	return value, nil
+}
+
+func (s *service) getMetadata(kind string, name string, get func(workloadmeta.EntityMeta) map[string]string) (string, error) {
+	switch e := s.entity.(type) {
+	case *workloadmeta.Container:
+		if value, found := get(e.EntityMeta)[name]; found {
+			return value, nil
+		}
+		if pod, err := workloadmeta.GetGlobalStore().GetKubernetesPodForContainer(e.ID); err == nil {
+			if value, found := get(pod.EntityMeta)[name]; found {
+				return value, nil
+			}
+		}
+	case *workloadmeta.KubernetesPod:
+		if value, found := get(e.EntityMeta)[name]; found {
+			return value, nil
+		}
+	}
+
+	return "", fmt.Errorf("%s %q not found", kind, name)
+}
+
 // svcEqual checks that two Services are equal to each other by doing a deep
 // equality check on data returned by most of Service's methods. Methods not
 // checked are HasFilter and GetExtraConfig.
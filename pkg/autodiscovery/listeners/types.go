@@ -37,6 +37,14 @@ type Service interface {
 	GetExtraConfig([]byte) ([]byte, error)               // Extra configuration values
 }
 
+// WorkloadMetadataService is implemented by the services backed by a workloadmeta
+// entity, it exposes the entity metadata template variables are resolved from.
+type WorkloadMetadataService interface {
+	GetLabel(name string) (string, error)        // label of the container or pod
+	GetAnnotation(name string) (string, error)   // annotation of the container or pod
+	GetContainerEnv(name string) (string, error) // environment variable of the container
+}
+
 // ServiceListener monitors running services and triggers check (un)scheduling
 //
 // It holds a cache of running services, listens to new/killed services and
@@ -0,0 +1,346 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package providers
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/providers/names"
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/secrets"
+	httputils "github.com/DataDog/datadog-agent/pkg/util/http"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// httpMaxResponseSize is the maximum size of the templates list served by the endpoint
+const httpMaxResponseSize = 10 * 1024 * 1024
+
+// httpTemplate is an integration template as served by the endpoint
+type httpTemplate struct {
+	Name         string `yaml:"name"`
+	configFormat `yaml:",inline"`
+}
+
+// httpResponse is a templates list fetched from the endpoint
+type httpResponse struct {
+	etag string
+	hash string
+	body []byte
+}
+
+// HTTPConfigProvider implements the ConfigProvider interface.
+// It polls an HTTP(S) endpoint serving a JSON or YAML list of integration templates.
+type HTTPConfigProvider struct {
+	sync.RWMutex
+	client         *http.Client
+	url            string
+	source         string
+	headers        map[string]string
+	username       string
+	password       string
+	lastETag       string
+	lastHash       string
+	pending        *httpResponse
+	fetchError     error
+	templateErrors map[string]ErrorMsgSet
+}
+
+// NewHTTPConfigProvider creates a new HTTPConfigProvider
+func NewHTTPConfigProvider(providerConfig *config.ConfigurationProviders) (ConfigProvider, error) {
+	if providerConfig == nil || providerConfig.TemplateURL == "" {
+		return nil, errors.New("missing template_url for the http config provider")
+	}
+
+	templateURL, err := url.Parse(providerConfig.TemplateURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid template_url for the http config provider: %w", err)
+	}
+	if templateURL.Scheme != "http" && templateURL.Scheme != "https" {
+		return nil, fmt.Errorf("unsupported scheme %q for the http config provider", templateURL.Scheme)
+	}
+
+	headers, err := resolveHTTPHeaders(providerConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	transport := httputils.CreateHTTPTransport()
+	if templateURL.Scheme == "https" {
+		if err := setupHTTPTLS(transport, providerConfig); err != nil {
+			return nil, err
+		}
+	}
+
+	return &HTTPConfigProvider{
+		client: &http.Client{
+			Transport: transport,
+			Timeout:   time.Duration(config.Datadog.GetInt("autoconf_template_url_timeout")) * time.Second,
+		},
+		url:            providerConfig.TemplateURL,
+		source:         templateURL.Redacted(),
+		headers:        headers,
+		username:       providerConfig.Username,
+		password:       providerConfig.Password,
+		templateErrors: make(map[string]ErrorMsgSet),
+	}, nil
+}
+
+// resolveHTTPHeaders builds the headers sent to the endpoint, decrypting the
+// ENC[] values with the secrets backend
+func resolveHTTPHeaders(providerConfig *config.ConfigurationProviders) (map[string]string, error) {
+	headers := make(map[string]string, len(providerConfig.Headers)+1)
+	for name, value := range providerConfig.Headers {
+		headers[name] = value
+	}
+	if providerConfig.Token != "" {
+		headers["Authorization"] = "Bearer " + providerConfig.Token
+	}
+
+	raw, err := yaml.Marshal(headers)
+	if err != nil {
+		return nil, err
+	}
+	decrypted, err := secrets.Decrypt(raw, names.HTTP)
+	if err != nil {
+		return nil, fmt.Errorf("unable to decrypt the headers of the http config provider: %w", err)
+	}
+
+	resolved := make(map[string]string, len(headers))
+	if err := yaml.Unmarshal(decrypted, &resolved); err != nil {
+		return nil, err
+	}
+	return resolved, nil
+}
+
+func setupHTTPTLS(transport *http.Transport, providerConfig *config.ConfigurationProviders) error {
+	if transport.TLSClientConfig == nil {
+		transport.TLSClientConfig = &tls.Config{}
+	}
+
+	if providerConfig.CAFile != "" {
+		ca, err := os.ReadFile(providerConfig.CAFile)
+		if err != nil {
+			return fmt.Errorf("unable to read the CA file of the http config provider: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(ca) {
+			return fmt.Errorf("no valid certificate found in %s", providerConfig.CAFile)
+		}
+		transport.TLSClientConfig.RootCAs = pool
+	}
+
+	if providerConfig.CertFile != "" || providerConfig.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(providerConfig.CertFile, providerConfig.KeyFile)
+		if err != nil {
+			return fmt.Errorf("unable to load the client certificate of the http config provider: %w", err)
+		}
+		transport.TLSClientConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	return nil
+}
+
+// String returns a string representation of the HTTPConfigProvider
+func (p *HTTPConfigProvider) String() string {
+	return names.HTTP
+}
+
+// IsUpToDate queries the endpoint with the ETag of the last collected templates.
+// When the endpoint doesn't return ETags, the content of the templates is compared.
+// The fetched templates are kept for the next call to Collect.
+func (p *HTTPConfigProvider) IsUpToDate(ctx context.Context) (bool, error) {
+	p.Lock()
+	defer p.Unlock()
+
+	resp, err := p.fetch(ctx, p.lastETag)
+	p.fetchError = err
+	if err != nil {
+		// keep the configurations collected last time
+		return true, err
+	}
+
+	if resp == nil || (resp.etag == "" && resp.hash == p.lastHash) {
+		return true, nil
+	}
+
+	p.pending = resp
+	return false, nil
+}
+
+// Collect retrieves the templates from the endpoint and returns them
+func (p *HTTPConfigProvider) Collect(ctx context.Context) ([]integration.Config, error) {
+	p.Lock()
+	defer p.Unlock()
+
+	resp := p.pending
+	p.pending = nil
+	if resp == nil {
+		var err error
+		resp, err = p.fetch(ctx, "")
+		p.fetchError = err
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	configs, templateErrors, err := parseHTTPTemplates(resp.body, p.source)
+	if err != nil {
+		p.fetchError = fmt.Errorf("failed to parse the templates: %w", err)
+		return nil, p.fetchError
+	}
+
+	p.lastETag = resp.etag
+	p.lastHash = resp.hash
+	p.templateErrors = templateErrors
+
+	return configs, nil
+}
+
+// GetConfigErrors returns the errors that occurred fetching or parsing the templates
+func (p *HTTPConfigProvider) GetConfigErrors() map[string]ErrorMsgSet {
+	p.RLock()
+	defer p.RUnlock()
+
+	configErrors := make(map[string]ErrorMsgSet, len(p.templateErrors)+1)
+	for resource, errs := range p.templateErrors {
+		configErrors[resource] = errs
+	}
+	if p.fetchError != nil {
+		configErrors[p.source] = ErrorMsgSet{p.fetchError.Error(): struct{}{}}
+	}
+	return configErrors
+}
+
+// fetch gets the templates from the endpoint, it returns nil if they weren't modified since etag
+func (p *HTTPConfigProvider) fetch(ctx context.Context, etag string) (*httpResponse, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Accept", "application/json, application/yaml")
+	for name, value := range p.headers {
+		req.Header.Set(name, value)
+	}
+	if p.username != "" {
+		req.SetBasicAuth(p.username, p.password)
+	}
+	if etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotModified {
+		return nil, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, httpMaxResponseSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(body) > httpMaxResponseSize {
+		return nil, fmt.Errorf("response exceeds %d bytes", httpMaxResponseSize)
+	}
+
+	hash := sha256.Sum256(body)
+	return &httpResponse{
+		etag: resp.Header.Get("ETag"),
+		hash: hex.EncodeToString(hash[:]),
+		body: body,
+	}, nil
+}
+
+// parseHTTPTemplates parses a JSON or YAML list of templates. Invalid templates
+// are skipped and reported in the returned errors, indexed by template name.
+func parseHTTPTemplates(body []byte, source string) ([]integration.Config, map[string]ErrorMsgSet, error) {
+	var templates []httpTemplate
+	if err := yaml.Unmarshal(body, &templates); err != nil {
+		return nil, nil, err
+	}
+
+	configs := make([]integration.Config, 0, len(templates))
+	configErrors := make(map[string]ErrorMsgSet)
+
+	for i, tpl := range templates {
+		conf, err := tpl.toConfig()
+		if err != nil {
+			resource := tpl.Name
+			if resource == "" {
+				resource = fmt.Sprintf("template #%d", i)
+			}
+			if _, found := configErrors[resource]; !found {
+				configErrors[resource] = make(ErrorMsgSet)
+			}
+			configErrors[resource][err.Error()] = struct{}{}
+			log.Warnf("Skipping invalid template %s from %s: %v", resource, source, err)
+			continue
+		}
+
+		conf.Source = names.HTTP + ":" + source
+		configs = append(configs, conf)
+	}
+
+	return configs, configErrors, nil
+}
+
+func (t *httpTemplate) toConfig() (integration.Config, error) {
+	conf := integration.Config{Name: t.Name}
+
+	if t.Name == "" {
+		return conf, errors.New("template has no name")
+	}
+	if t.MetricConfig == nil && t.LogsConfig == nil && len(t.Instances) < 1 {
+		return conf, errors.New("template contains no valid instances")
+	}
+
+	if t.InitConfig != nil {
+		conf.InitConfig, _ = yaml.Marshal(t.InitConfig)
+	}
+	for _, instance := range t.Instances {
+		rawConf, _ := yaml.Marshal(instance)
+		conf.Instances = append(conf.Instances, rawConf)
+	}
+	if t.MetricConfig != nil {
+		conf.MetricConfig, _ = yaml.Marshal(t.MetricConfig)
+	}
+	if t.LogsConfig != nil {
+		conf.LogsConfig, _ = yaml.Marshal(map[string]interface{}{"logs": t.LogsConfig})
+	}
+
+	conf.ADIdentifiers = t.ADIdentifiers
+	conf.AdvancedADIdentifiers = t.AdvancedADIdentifiers
+	conf.ClusterCheck = t.ClusterCheck
+	conf.IgnoreAutodiscoveryTags = t.IgnoreAutodiscoveryTags
+
+	return conf, nil
+}
+
+func init() {
+	RegisterProvider(names.HTTPRegisterName, NewHTTPConfigProvider)
+}
@@ -0,0 +1,216 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package providers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+	"github.com/DataDog/datadog-agent/pkg/config"
+)
+
+const httpTemplatesJSON = `[
+	{
+		"name": "nginx",
+		"ad_identifiers": ["nginx"],
+		"init_config": {},
+		"instances": [{"nginx_status_url": "http://%%host%%:%%port%%/status"}]
+	},
+	{
+		"name": "redisdb",
+		"ad_identifiers": ["redis"],
+		"instances": []
+	}
+]`
+
+const httpTemplatesYAML = `
+- name: postgres
+  ad_identifiers:
+    - postgres
+  instances:
+    - host: "%%host%%"
+      port: 5432
+  logs:
+    - type: file
+      path: /var/log/postgresql/postgresql.log
+`
+
+type fakeTemplatesServer struct {
+	sync.Mutex
+	body     string
+	etag     string
+	status   int
+	requests []*http.Request
+}
+
+func (s *fakeTemplatesServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.Lock()
+	defer s.Unlock()
+
+	s.requests = append(s.requests, r)
+	if s.status != 0 {
+		w.WriteHeader(s.status)
+		return
+	}
+	if s.etag != "" {
+		if r.Header.Get("If-None-Match") == s.etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", s.etag)
+	}
+	w.Write([]byte(s.body))
+}
+
+func (s *fakeTemplatesServer) set(body, etag string, status int) {
+	s.Lock()
+	defer s.Unlock()
+	s.body, s.etag, s.status = body, etag, status
+}
+
+func newTestHTTPProvider(t *testing.T, server *httptest.Server, providerConfig config.ConfigurationProviders) *HTTPConfigProvider {
+	providerConfig.TemplateURL = server.URL + "/templates"
+	provider, err := NewHTTPConfigProvider(&providerConfig)
+	require.NoError(t, err)
+	return provider.(*HTTPConfigProvider)
+}
+
+func TestHTTPConfigProviderCollect(t *testing.T) {
+	fake := &fakeTemplatesServer{body: httpTemplatesJSON, etag: `"v1"`}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	provider := newTestHTTPProvider(t, server, config.ConfigurationProviders{
+		Token:   "secret-token",
+		Headers: map[string]string{"X-Team": "web"},
+	})
+	ctx := context.Background()
+
+	upToDate, err := provider.IsUpToDate(ctx)
+	assert.NoError(t, err)
+	assert.False(t, upToDate)
+
+	configs, err := provider.Collect(ctx)
+	require.NoError(t, err)
+	require.Len(t, configs, 1)
+	assert.Equal(t, "nginx", configs[0].Name)
+	assert.Equal(t, []string{"nginx"}, configs[0].ADIdentifiers)
+	assert.Equal(t, integration.Data("{}\n"), configs[0].InitConfig)
+	assert.Equal(t, []integration.Data{integration.Data("nginx_status_url: http://%%host%%:%%port%%/status\n")}, configs[0].Instances)
+	assert.Equal(t, "http:"+server.URL+"/templates", configs[0].Source)
+
+	// the templates were fetched once by IsUpToDate
+	require.Len(t, fake.requests, 1)
+	assert.Equal(t, "Bearer secret-token", fake.requests[0].Header.Get("Authorization"))
+	assert.Equal(t, "web", fake.requests[0].Header.Get("X-Team"))
+
+	assert.Equal(t, map[string]ErrorMsgSet{
+		"redisdb": {"template contains no valid instances": struct{}{}},
+	}, provider.GetConfigErrors())
+
+	upToDate, err = provider.IsUpToDate(ctx)
+	assert.NoError(t, err)
+	assert.True(t, upToDate)
+	assert.Equal(t, `"v1"`, fake.requests[1].Header.Get("If-None-Match"))
+
+	fake.set(httpTemplatesYAML, `"v2"`, 0)
+	upToDate, err = provider.IsUpToDate(ctx)
+	assert.NoError(t, err)
+	assert.False(t, upToDate)
+
+	configs, err = provider.Collect(ctx)
+	require.NoError(t, err)
+	require.Len(t, configs, 1)
+	assert.Equal(t, "postgres", configs[0].Name)
+	assert.Equal(t, integration.Data("logs:\n- path: /var/log/postgresql/postgresql.log\n  type: file\n"), configs[0].LogsConfig)
+	assert.Empty(t, provider.GetConfigErrors())
+}
+
+func TestHTTPConfigProviderWithoutETag(t *testing.T) {
+	fake := &fakeTemplatesServer{body: httpTemplatesYAML}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	provider := newTestHTTPProvider(t, server, config.ConfigurationProviders{
+		Username: "datadog",
+		Password: "password",
+	})
+	ctx := context.Background()
+
+	configs, err := provider.Collect(ctx)
+	require.NoError(t, err)
+	assert.Len(t, configs, 1)
+
+	username, password, ok := fake.requests[0].BasicAuth()
+	assert.True(t, ok)
+	assert.Equal(t, "datadog", username)
+	assert.Equal(t, "password", password)
+
+	upToDate, err := provider.IsUpToDate(ctx)
+	assert.NoError(t, err)
+	assert.True(t, upToDate)
+
+	fake.set(httpTemplatesJSON, "", 0)
+	upToDate, err = provider.IsUpToDate(ctx)
+	assert.NoError(t, err)
+	assert.False(t, upToDate)
+}
+
+func TestHTTPConfigProviderErrors(t *testing.T) {
+	fake := &fakeTemplatesServer{body: httpTemplatesYAML, etag: `"v1"`}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	provider := newTestHTTPProvider(t, server, config.ConfigurationProviders{})
+	source := server.URL + "/templates"
+	ctx := context.Background()
+
+	_, err := provider.Collect(ctx)
+	require.NoError(t, err)
+
+	// the endpoint is unavailable, the previous configs are kept
+	fake.set(httpTemplatesYAML, `"v1"`, http.StatusServiceUnavailable)
+	upToDate, err := provider.IsUpToDate(ctx)
+	assert.Error(t, err)
+	assert.True(t, upToDate)
+	assert.Equal(t, map[string]ErrorMsgSet{
+		source: {"unexpected status code 503": struct{}{}},
+	}, provider.GetConfigErrors())
+
+	// the endpoint serves an invalid document
+	fake.set("instances: {", `"v2"`, 0)
+	upToDate, err = provider.IsUpToDate(ctx)
+	assert.NoError(t, err)
+	assert.False(t, upToDate)
+	_, err = provider.Collect(ctx)
+	assert.Error(t, err)
+	assert.Contains(t, provider.GetConfigErrors(), source)
+
+	// the endpoint recovers
+	fake.set(httpTemplatesYAML, `"v3"`, 0)
+	upToDate, err = provider.IsUpToDate(ctx)
+	assert.NoError(t, err)
+	assert.False(t, upToDate)
+	configs, err := provider.Collect(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, configs, 1)
+	assert.Empty(t, provider.GetConfigErrors())
+}
+
+func TestNewHTTPConfigProviderInvalidURL(t *testing.T) {
+	_, err := NewHTTPConfigProvider(&config.ConfigurationProviders{})
+	assert.Error(t, err)
+
+	_, err = NewHTTPConfigProvider(&config.ConfigurationProviders{TemplateURL: "ftp://127.0.0.1/templates"})
+	assert.Error(t, err)
+}
@@ -15,6 +15,7 @@ const (
 	EndpointsChecks    = "endpoints-checks"
 	Etcd               = "etcd"
 	File               = "file"
+	HTTP               = "http"
 	Kubernetes         = "kubernetes"
 	KubeServices       = "kubernetes-services"
 	KubeServicesFile   = "kubernetes-services-file"
@@ -35,6 +36,7 @@ const (
 	ClusterChecksRegisterName      = "clusterchecks"
 	EndpointsChecksRegisterName    = "endpointschecks"
 	EtcdRegisterName               = "etcd"
+	HTTPRegisterName               = "http"
 	KubeletRegisterName            = "kubelet"
 	KubeServicesRegisterName       = "kube_services"
 	KubeServicesFileRegisterName   = "kube_services_file"
@@ -0,0 +1,85 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package autodiscovery
+
+import (
+	"sync"
+
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+)
+
+// resolutionHistorySize is the number of resolution events kept for `configcheck --explain`
+const resolutionHistorySize = 1000
+
+// noTemplateHistorySize is the number of events kept for the services without template,
+// they are recorded for every service and are kept apart not to evict the other outcomes
+const noTemplateHistorySize = 100
+
+// resolutionHistory is a bounded history of the template resolutions, the oldest
+// events are dropped first
+type resolutionHistory struct {
+	events []integration.ResolutionEvent
+	next   int
+	full   bool
+	m      sync.RWMutex
+}
+
+// newResolutionHistory creates a resolutionHistory holding up to size events
+func newResolutionHistory(size int) *resolutionHistory {
+	return &resolutionHistory{
+		events: make([]integration.ResolutionEvent, size),
+	}
+}
+
+// add records an event, overwriting the oldest one when the history is full
+func (h *resolutionHistory) add(event integration.ResolutionEvent) {
+	h.m.Lock()
+	defer h.m.Unlock()
+
+	if len(h.events) == 0 {
+		return
+	}
+
+	h.events[h.next] = event
+	h.next = (h.next + 1) % len(h.events)
+	if h.next == 0 {
+		h.full = true
+	}
+}
+
+// find returns the events matching the query, from the oldest to the most recent
+func (h *resolutionHistory) find(query string) []integration.ResolutionEvent {
+	h.m.RLock()
+	defer h.m.RUnlock()
+
+	start, count := 0, h.next
+	if h.full {
+		start, count = h.next, len(h.events)
+	}
+
+	events := []integration.ResolutionEvent{}
+	for i := 0; i < count; i++ {
+		event := h.events[(start+i)%len(h.events)]
+		if event.Matches(query) {
+			events = append(events, event)
+		}
+	}
+	return events
+}
+
+// mergeResolutionEvents merges two lists of events sorted from the oldest to the most recent
+func mergeResolutionEvents(a, b []integration.ResolutionEvent) []integration.ResolutionEvent {
+	events := make([]integration.ResolutionEvent, 0, len(a)+len(b))
+	for len(a) > 0 && len(b) > 0 {
+		if b[0].Timestamp.Before(a[0].Timestamp) {
+			events, b = append(events, b[0]), b[1:]
+		} else {
+			events, a = append(events, a[0]), a[1:]
+		}
+	}
+	events = append(events, a...)
+	return append(events, b...)
+}
@@ -0,0 +1,60 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package autodiscovery
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+)
+
+func TestResolutionHistory(t *testing.T) {
+	h := newResolutionHistory(3)
+	assert.Empty(t, h.find(""))
+
+	h.add(integration.ResolutionEvent{Template: "redisdb", ServiceID: "docker://a"})
+	h.add(integration.ResolutionEvent{Template: "nginx", ServiceID: "docker://b"})
+	assert.Equal(t, []integration.ResolutionEvent{
+		{Template: "redisdb", ServiceID: "docker://a"},
+		{Template: "nginx", ServiceID: "docker://b"},
+	}, h.find(""))
+
+	// the oldest events are dropped
+	h.add(integration.ResolutionEvent{Template: "redisdb", ServiceID: "docker://c"})
+	h.add(integration.ResolutionEvent{Template: "redisdb", ServiceID: "docker://d"})
+	assert.Equal(t, []integration.ResolutionEvent{
+		{Template: "nginx", ServiceID: "docker://b"},
+		{Template: "redisdb", ServiceID: "docker://c"},
+		{Template: "redisdb", ServiceID: "docker://d"},
+	}, h.find(""))
+
+	assert.Equal(t, []integration.ResolutionEvent{
+		{Template: "redisdb", ServiceID: "docker://c"},
+		{Template: "redisdb", ServiceID: "docker://d"},
+	}, h.find("redisdb"))
+	assert.Equal(t, []integration.ResolutionEvent{
+		{Template: "redisdb", ServiceID: "docker://d"},
+	}, h.find("docker://d"))
+}
+
+func TestMergeResolutionEvents(t *testing.T) {
+	now := time.Now()
+	a := []integration.ResolutionEvent{
+		{Template: "redisdb", Timestamp: now},
+		{Template: "redisdb", Timestamp: now.Add(2 * time.Second)},
+	}
+	b := []integration.ResolutionEvent{
+		{ServiceID: "docker://a", Timestamp: now.Add(time.Second)},
+		{ServiceID: "docker://b", Timestamp: now.Add(3 * time.Second)},
+	}
+
+	assert.Equal(t, []integration.ResolutionEvent{a[0], b[0], a[1], b[1]}, mergeResolutionEvents(a, b))
+	assert.Equal(t, a, mergeResolutionEvents(a, nil))
+	assert.Equal(t, b, mergeResolutionEvents([]integration.ResolutionEvent{}, b))
+}
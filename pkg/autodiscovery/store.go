@@ -22,6 +22,8 @@ type store struct {
 	adIDToServices    map[string]map[string]bool
 	entityToService   map[string]listeners.Service
 	templateCache     *templateCache
+	resolutions       *resolutionHistory
+	noTemplates       *resolutionHistory
 	m                 sync.RWMutex
 }
 
@@ -36,6 +38,8 @@ func newStore() *store {
 		adIDToServices:    make(map[string]map[string]bool),
 		entityToService:   make(map[string]listeners.Service),
 		templateCache:     newTemplateCache(),
+		resolutions:       newResolutionHistory(resolutionHistorySize),
+		noTemplates:       newResolutionHistory(noTemplateHistorySize),
 	}
 
 	return &s
@@ -27,6 +27,14 @@ func TestIdentify(t *testing.T) {
 	assert.NotEqual(t, Identify(testCheck, instance1, initConfig1), Identify(testCheck, instance3, initConfig3))
 }
 
+func TestBuildIDAfterSetName(t *testing.T) {
+	instance := integration.Data("name: foobar\nip_address: 10.0.0.1")
+	assert.NoError(t, instance.SetNameForInstance("snmp-device"))
+
+	// the IDs of the checks setting their instance name must not change across versions
+	assert.Equal(t, ID("snmp:snmp-device:f83e304c0b19d97b"), BuildID("snmp", instance, integration.Data("{}")))
+}
+
 func TestIDToCheckName(t *testing.T) {
 	testCases := []struct {
 		in  string
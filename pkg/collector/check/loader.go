@@ -17,3 +17,10 @@ type Loader interface {
 	Name() string
 	Load(config integration.Config, instance integration.Data) (Check, error)
 }
+
+// WithLoaderName is implemented by the checks reporting the name of the
+// loader they were loaded with
+type WithLoaderName interface {
+	// LoaderName returns the `Name()` of the loader of the check
+	LoaderName() string
+}
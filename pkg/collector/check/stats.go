@@ -79,6 +79,8 @@ type Stats struct {
 	TotalRuns                uint64
 	TotalErrors              uint64
 	TotalWarnings            uint64
+	TotalTimeouts            uint64
+	ConsecutiveTimeouts      uint64 // number of runs in a row that timed out
 	MetricSamples            int64
 	Events                   int64
 	ServiceChecks            int64
@@ -120,6 +122,15 @@ func NewStats(c Check) *Stats {
 	return &stats
 }
 
+// TimeoutState returns the number of the last runs that timed out in a row, and
+// the date of the last run
+func (cs *Stats) TimeoutState() (uint64, time.Time) {
+	cs.m.Lock()
+	defer cs.m.Unlock()
+
+	return cs.ConsecutiveTimeouts, time.Unix(cs.UpdateTimestamp, 0)
+}
+
 // Add tracks a new execution time
 func (cs *Stats) Add(t time.Duration, err error, warnings []error, metricStats SenderStats) {
 	cs.m.Lock()
@@ -142,6 +153,12 @@ func (cs *Stats) Add(t time.Duration, err error, warnings []error, metricStats S
 		totalExecutionTime += cs.ExecutionTimes[i]
 	}
 	cs.AverageExecutionTime = totalExecutionTime / int64(ringSize)
+	if IsTimeoutError(err) {
+		cs.TotalTimeouts++
+		cs.ConsecutiveTimeouts++
+	} else {
+		cs.ConsecutiveTimeouts = 0
+	}
 	if err != nil {
 		cs.TotalErrors++
 		if cs.telemetry {
@@ -0,0 +1,47 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package check
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// TimeoutStatus is the status of the runs that didn't complete within the
+// timeout of the check
+const TimeoutStatus = "check_timeout"
+
+// WithTimeout is implemented by the checks supporting a run timeout, set with
+// the `check_timeout` option of their instances
+type WithTimeout interface {
+	// Timeout returns the maximum duration of a run, 0 when it isn't set
+	Timeout() time.Duration
+}
+
+// Interruptible is implemented by the checks whose runs can be interrupted when they
+// time out. Checks that can't be interrupted, like Python checks, keep running in the
+// background until their run returns.
+type Interruptible interface {
+	// InterruptRun asks the current run to return as soon as possible
+	InterruptRun()
+}
+
+// TimeoutError is the error of a run that didn't complete within the timeout of the check
+type TimeoutError struct {
+	Timeout time.Duration
+}
+
+// Error implements the error interface
+func (e *TimeoutError) Error() string {
+	return fmt.Sprintf("%s: the check run didn't complete within %s", TimeoutStatus, e.Timeout)
+}
+
+// IsTimeoutError returns whether err is the error of a run that timed out
+func IsTimeoutError(err error) bool {
+	var timeoutErr *TimeoutError
+	return errors.As(err, &timeoutErr)
+}
@@ -44,6 +44,8 @@ func NewCollector(paths ...string) *Collector {
 
 	// let the runner some visibility into the scheduler
 	run.SetScheduler(sched)
+	// and the scheduler into the running checks, to enforce the loaders concurrency limits
+	sched.SetRunningChecksFunc(run.RunningChecks)
 	sched.Run()
 
 	c := &Collector{
@@ -6,7 +6,9 @@
 package corechecks
 
 import (
+	"context"
 	"fmt"
+	"sync"
 	"time"
 
 	yaml "gopkg.in/yaml.v2"
@@ -37,13 +39,25 @@ import (
 //
 // If custom tags are set in the instance configuration, they will
 // be automatically appended to each send done by this check.
+//
+// Checks doing I/O should use the context returned by RunContext in
+// Run, so that their runs are interrupted when they time out.
 type CheckBase struct {
 	checkName      string
 	checkID        check.ID
 	latestWarnings []error
 	checkInterval  time.Duration
+	checkTimeout   time.Duration
 	source         string
 	telemetry      bool
+	run            *runContext
+}
+
+// runContext holds the context of the current run of a check
+type runContext struct {
+	m      sync.Mutex
+	ctx    context.Context
+	cancel context.CancelFunc
 }
 
 // NewCheckBase returns a check base struct with a given check name
@@ -57,6 +71,7 @@ func NewCheckBaseWithInterval(name string, defaultInterval time.Duration) CheckB
 		checkName:     name,
 		checkID:       check.ID(name),
 		checkInterval: defaultInterval,
+		run:           &runContext{},
 		telemetry:     telemetry_utils.IsCheckEnabled(name),
 	}
 }
@@ -118,6 +133,11 @@ func (c *CheckBase) CommonConfigure(instance integration.Data, source string) er
 		c.checkInterval = time.Duration(commonOptions.MinCollectionInterval) * time.Second
 	}
 
+	// See if a run timeout was specified
+	if commonOptions.CheckTimeout > 0 {
+		c.checkTimeout = time.Duration(commonOptions.CheckTimeout) * time.Second
+	}
+
 	// Disable default hostname if specified
 	if commonOptions.EmptyDefaultHostname {
 		s, err := aggregator.GetSender(c.checkID)
@@ -183,15 +203,56 @@ func (c *CheckBase) Cancel() {
 // CommonCancel cleans up common resources. Must be called from Cancel
 // when checks implement it.
 func (c *CheckBase) CommonCancel() {
+	c.InterruptRun()
 	aggregator.DestroySender(c.checkID)
 }
 
+// RunContext returns the context of the current run, it's cancelled when the run
+// is interrupted. It must be called once at the beginning of Run, as the next runs
+// get a new context.
+func (c *CheckBase) RunContext() context.Context {
+	if c.run == nil {
+		return context.Background()
+	}
+
+	c.run.m.Lock()
+	defer c.run.m.Unlock()
+	if c.run.ctx == nil {
+		c.run.ctx, c.run.cancel = context.WithCancel(context.Background())
+	}
+	return c.run.ctx
+}
+
+// InterruptRun cancels the context returned by RunContext to the current run
+func (c *CheckBase) InterruptRun() {
+	if c.run == nil {
+		return
+	}
+
+	c.run.m.Lock()
+	defer c.run.m.Unlock()
+	if c.run.cancel != nil {
+		c.run.cancel()
+		c.run.ctx, c.run.cancel = nil, nil
+	}
+}
+
 // Interval returns the scheduling time for the check.
 // Long-running checks should override to return 0.
 func (c *CheckBase) Interval() time.Duration {
 	return c.checkInterval
 }
 
+// Timeout returns the run timeout of the check, 0 if it isn't set
+func (c *CheckBase) Timeout() time.Duration {
+	return c.checkTimeout
+}
+
+// LoaderName returns the name of the core check loader
+func (c *CheckBase) LoaderName() string {
+	return "core"
+}
+
 // String returns the name of the check, the same for every instance
 func (c *CheckBase) String() string {
 	return c.checkName
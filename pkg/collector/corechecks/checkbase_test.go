@@ -6,6 +6,7 @@
 package corechecks
 
 import (
+	"context"
 	"testing"
 	"time"
 
@@ -68,3 +69,26 @@ func TestCommonConfigureCustomID(t *testing.T) {
 	assert.Equal(t, string(mycheck.ID()), "test:foobar:bd63a7031add5db9")
 	mockSender.AssertExpectations(t)
 }
+
+func TestRunContext(t *testing.T) {
+	mycheck := &dummyCheck{
+		CheckBase: NewCheckBase("test"),
+	}
+
+	ctx := mycheck.RunContext()
+	assert.Same(t, ctx, mycheck.RunContext())
+	assert.NoError(t, ctx.Err())
+
+	// the interrupted run is cancelled, the next run gets a new context
+	mycheck.InterruptRun()
+	assert.Error(t, ctx.Err())
+	next := mycheck.RunContext()
+	assert.NoError(t, next.Err())
+
+	// unscheduling the check interrupts its run
+	mycheck.Cancel()
+	assert.Error(t, next.Err())
+
+	// checks built without NewCheckBase can't be interrupted
+	assert.Equal(t, context.Background(), (&dummyCheck{}).RunContext())
+}
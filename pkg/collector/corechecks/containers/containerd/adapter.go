@@ -20,6 +20,7 @@ var metricsNameMapping = map[string]string{
 	"container.cpu.throttled":                  "containerd.cpu.throttled.time",
 	"container.cpu.throttled.periods":          "containerd.cpu.throttled.periods",
 	"container.cpu.limit":                      "containerd.cpu.limit",
+	"container.cpu.partial_stall":              "", // Not present in legacy check
 	"container.memory.usage":                   "containerd.mem.current.usage",
 	"container.memory.kernel":                  "containerd.mem.kernel.usage",
 	"container.memory.limit":                   "containerd.mem.current.limit",
@@ -31,10 +32,14 @@ var metricsNameMapping = map[string]string{
 	"container.memory.working_set":             "containerd.mem.private_working_set",
 	"container.memory.commit":                  "containerd.mem.commit",
 	"container.memory.commit.peak":             "containerd.mem.commit_peak",
+	"container.memory.partial_stall":           "", // Not present in legacy check
+	"container.memory.full_stall":              "", // Not present in legacy check
 	"container.io.read":                        "", // Remapping requires retagging, handled in extension
 	"container.io.read.operations":             "", // Remapping requires retagging, handled in extension
 	"container.io.write":                       "", // Remapping requires retagging, handled in extension
 	"container.io.write.operations":            "", // Remapping requires retagging, handled in extension
+	"container.io.partial_stall":               "", // Not present in legacy check
+	"container.io.full_stall":                  "", // Not present in legacy check
 	"container.pid.thread_count":               "",
 	"container.pid.thread_limit":               "",
 	"container.pid.open_files":                 "containerd.proc.open_fds",
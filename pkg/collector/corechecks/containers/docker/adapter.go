@@ -21,6 +21,7 @@ var metricsNameMapping = map[string]string{
 	"container.cpu.throttled":         "docker.cpu.throttled.time",
 	"container.cpu.throttled.periods": "docker.cpu.throttled",
 	"container.cpu.limit":             "docker.cpu.limit",
+	"container.cpu.partial_stall":     "", // Not present in legacy Docker check
 	"container.memory.usage":          "", // Not present in legacy Docker check
 	"container.memory.kernel":         "docker.kmem.usage",
 	"container.memory.limit":          "docker.mem.limit",
@@ -32,10 +33,14 @@ var metricsNameMapping = map[string]string{
 	"container.memory.working_set":    "docker.mem.private_working_set",
 	"container.memory.commit":         "docker.mem.commit_bytes",
 	"container.memory.commit.peak":    "docker.mem.commit_peak_bytes",
+	"container.memory.partial_stall":  "", // Not present in legacy Docker check
+	"container.memory.full_stall":     "", // Not present in legacy Docker check
 	"container.io.read":               "docker.io.read_bytes",
 	"container.io.read.operations":    "docker.io.read_operations",
 	"container.io.write":              "docker.io.write_bytes",
 	"container.io.write.operations":   "docker.io.write_operations",
+	"container.io.partial_stall":      "", // Not present in legacy Docker check
+	"container.io.full_stall":         "", // Not present in legacy Docker check
 	"container.pid.thread_count":      "docker.thread.count",
 	"container.pid.thread_limit":      "docker.thread.limit",
 	"container.pid.open_files":        "docker.container.open_fds",
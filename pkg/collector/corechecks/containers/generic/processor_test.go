@@ -11,7 +11,9 @@ import (
 	"time"
 
 	taggerUtils "github.com/DataDog/datadog-agent/pkg/tagger/utils"
+	"github.com/DataDog/datadog-agent/pkg/util/containers/v2/metrics"
 	"github.com/DataDog/datadog-agent/pkg/util/containers/v2/metrics/mock"
+	"github.com/DataDog/datadog-agent/pkg/util/pointer"
 	"github.com/DataDog/datadog-agent/pkg/workloadmeta"
 	"github.com/stretchr/testify/assert"
 )
@@ -88,6 +90,41 @@ func TestProcessorRunFullStatsLinux(t *testing.T) {
 	mockSender.AssertMetric(t, "Rate", "container.net.rcvd.packets", 421, "", expectedEth42Tags)
 }
 
+func TestProcessorRunPSIStats(t *testing.T) {
+	containersMeta := []*workloadmeta.Container{
+		createContainerMeta("containerd", "cID300"),
+	}
+
+	containersStats := map[string]mock.ContainerEntry{
+		"cID300": {
+			ContainerStats: metrics.ContainerStats{
+				CPU: &metrics.ContainerCPUStats{
+					PartialStallTime: pointer.Float64Ptr(100),
+				},
+				Memory: &metrics.ContainerMemStats{
+					PartialStallTime: pointer.Float64Ptr(200),
+					FullStallTime:    pointer.Float64Ptr(150),
+				},
+				IO: &metrics.ContainerIOStats{
+					PartialStallTime: pointer.Float64Ptr(300),
+					FullStallTime:    pointer.Float64Ptr(250),
+				},
+			},
+		},
+	}
+
+	mockSender, processor, _ := CreateTestProcessor(containersMeta, nil, containersStats, GenericMetricsAdapter{}, nil)
+	err := processor.Run(mockSender, 0)
+	assert.ErrorIs(t, err, nil)
+
+	expectedTags := []string{"runtime:containerd"}
+	mockSender.AssertMetric(t, "Rate", "container.cpu.partial_stall", 100, "", expectedTags)
+	mockSender.AssertMetric(t, "Rate", "container.memory.partial_stall", 200, "", expectedTags)
+	mockSender.AssertMetric(t, "Rate", "container.memory.full_stall", 150, "", expectedTags)
+	mockSender.AssertMetric(t, "Rate", "container.io.partial_stall", 300, "", expectedTags)
+	mockSender.AssertMetric(t, "Rate", "container.io.full_stall", 250, "", expectedTags)
+}
+
 func TestProcessorRunPartialStats(t *testing.T) {
 	containersMeta := []*workloadmeta.Container{
 		// Container without stats
@@ -0,0 +1,275 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package openmetrics
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+const (
+	defaultTimeout         = 10 * time.Second
+	defaultBearerTokenPath = "/var/run/secrets/kubernetes.io/serviceaccount/token"
+
+	typeGauge   = "gauge"
+	typeCounter = "counter"
+)
+
+// instanceConfig holds the instance options. Both the options of the
+// `prometheus_url` (v1) and `openmetrics_endpoint` (v2) flavors of the Python
+// check are accepted, so that the configurations generated by the prometheus
+// config providers can be scheduled as is.
+type instanceConfig struct {
+	OpenMetricsEndpoint string            `yaml:"openmetrics_endpoint"`
+	PrometheusURL       string            `yaml:"prometheus_url"`
+	Namespace           string            `yaml:"namespace"`
+	Metrics             []interface{}     `yaml:"metrics"`
+	ExcludeMetrics      []string          `yaml:"exclude_metrics"`
+	IgnoreMetrics       []string          `yaml:"ignore_metrics"`
+	RawPrefix           string            `yaml:"raw_metric_prefix"`
+	PromPrefix          string            `yaml:"prometheus_metrics_prefix"`
+	TypeOverrides       map[string]string `yaml:"type_overrides"`
+
+	RenameLabels  map[string]string `yaml:"rename_labels"`
+	LabelsMapper  map[string]string `yaml:"labels_mapper"`
+	ExcludeLabels []string          `yaml:"exclude_labels"`
+
+	EnableHealthCheck             *bool `yaml:"enable_health_service_check"`
+	HealthCheck                   *bool `yaml:"health_service_check"`
+	CollectHistogramBuckets       *bool `yaml:"collect_histogram_buckets"`
+	SendHistogramBuckets          *bool `yaml:"send_histograms_buckets"`
+	BucketsAsDistributions        bool  `yaml:"histogram_buckets_as_distributions"`
+	DistributionBuckets           bool  `yaml:"send_distribution_buckets"`
+	MonotonicCounter              *bool `yaml:"send_monotonic_counter"`
+	DistributionCountsAsMonotonic bool  `yaml:"send_distribution_counts_as_monotonic"`
+	DistributionSumsAsMonotonic   bool  `yaml:"send_distribution_sums_as_monotonic"`
+
+	Timeout         int               `yaml:"timeout"`
+	Headers         map[string]string `yaml:"headers"`
+	ExtraHeaders    map[string]string `yaml:"extra_headers"`
+	BearerTokenAuth bool              `yaml:"bearer_token_auth"`
+	BearerTokenPath string            `yaml:"bearer_token_path"`
+	Username        string            `yaml:"username"`
+	Password        string            `yaml:"password"`
+	TLSVerify       *bool             `yaml:"tls_verify"`
+	TLSCert         string            `yaml:"tls_cert"`
+	TLSPrivateKey   string            `yaml:"tls_private_key"`
+	TLSCACert       string            `yaml:"tls_ca_cert"`
+}
+
+// metricMatcher selects metrics by raw name, and optionally renames them or
+// overrides their type
+type metricMatcher struct {
+	pattern *regexp.Regexp
+	name    string
+	typ     string
+}
+
+// scrapeConfig is the validated configuration of an instance
+type scrapeConfig struct {
+	endpoint string
+	// legacy is true for `prometheus_url` instances, whose metrics are named
+	// and typed like the v1 Python check
+	legacy bool
+
+	namespace     string
+	rawPrefix     string
+	metrics       []metricMatcher
+	exclude       []*regexp.Regexp
+	typeOverrides map[string]string
+	renameLabels  map[string]string
+	excludeLabels map[string]struct{}
+
+	healthCheck         bool
+	histogramBuckets    bool
+	distributionBuckets bool
+	monotonicCounter    bool
+	countsAsMonotonic   bool
+	sumsAsMonotonic     bool
+
+	timeout         time.Duration
+	headers         map[string]string
+	bearerTokenPath string
+	username        string
+	password        string
+	tlsVerify       bool
+	tlsCert         string
+	tlsPrivateKey   string
+	tlsCACert       string
+}
+
+func parseConfig(data []byte) (*scrapeConfig, error) {
+	instance := instanceConfig{}
+	if err := yaml.Unmarshal(data, &instance); err != nil {
+		return nil, err
+	}
+
+	conf := &scrapeConfig{
+		endpoint:            instance.OpenMetricsEndpoint,
+		namespace:           instance.Namespace,
+		rawPrefix:           instance.RawPrefix,
+		typeOverrides:       instance.TypeOverrides,
+		renameLabels:        instance.RenameLabels,
+		excludeLabels:       make(map[string]struct{}, len(instance.ExcludeLabels)),
+		timeout:             defaultTimeout,
+		headers:             instance.Headers,
+		username:            instance.Username,
+		password:            instance.Password,
+		tlsVerify:           boolOption(instance.TLSVerify, true),
+		tlsCert:             instance.TLSCert,
+		tlsPrivateKey:       instance.TLSPrivateKey,
+		tlsCACert:           instance.TLSCACert,
+		healthCheck:         boolOption(instance.EnableHealthCheck, true),
+		histogramBuckets:    boolOption(instance.CollectHistogramBuckets, true),
+		distributionBuckets: instance.BucketsAsDistributions,
+		monotonicCounter:    true,
+	}
+
+	excludePatterns := instance.ExcludeMetrics
+	if conf.endpoint == "" {
+		conf.endpoint = instance.PrometheusURL
+		conf.legacy = true
+		conf.rawPrefix = instance.PromPrefix
+		conf.renameLabels = instance.LabelsMapper
+		conf.healthCheck = boolOption(instance.HealthCheck, true)
+		conf.histogramBuckets = boolOption(instance.SendHistogramBuckets, true)
+		conf.distributionBuckets = instance.DistributionBuckets
+		conf.monotonicCounter = boolOption(instance.MonotonicCounter, true)
+		conf.countsAsMonotonic = instance.DistributionCountsAsMonotonic
+		conf.sumsAsMonotonic = instance.DistributionSumsAsMonotonic
+		excludePatterns = instance.IgnoreMetrics
+	}
+	if conf.endpoint == "" {
+		return nil, fmt.Errorf("either `openmetrics_endpoint` or `prometheus_url` must be set")
+	}
+	if len(instance.Metrics) == 0 {
+		return nil, fmt.Errorf("instance config `metrics` must not be empty")
+	}
+
+	for _, item := range instance.Metrics {
+		matchers, err := parseMetricsItem(item, conf.legacy)
+		if err != nil {
+			return nil, err
+		}
+		conf.metrics = append(conf.metrics, matchers...)
+	}
+
+	for _, pattern := range excludePatterns {
+		re, err := compilePattern(pattern, conf.legacy)
+		if err != nil {
+			return nil, err
+		}
+		conf.exclude = append(conf.exclude, re)
+	}
+
+	for name, typ := range conf.typeOverrides {
+		if err := validateType(typ); err != nil {
+			return nil, fmt.Errorf("invalid type override for metric %s: %w", name, err)
+		}
+	}
+
+	for _, label := range instance.ExcludeLabels {
+		conf.excludeLabels[label] = struct{}{}
+	}
+
+	if instance.Timeout > 0 {
+		conf.timeout = time.Duration(instance.Timeout) * time.Second
+	}
+
+	if len(instance.ExtraHeaders) > 0 {
+		headers := make(map[string]string, len(conf.headers)+len(instance.ExtraHeaders))
+		for k, v := range conf.headers {
+			headers[k] = v
+		}
+		for k, v := range instance.ExtraHeaders {
+			headers[k] = v
+		}
+		conf.headers = headers
+	}
+
+	if instance.BearerTokenAuth {
+		conf.bearerTokenPath = instance.BearerTokenPath
+		if conf.bearerTokenPath == "" {
+			conf.bearerTokenPath = defaultBearerTokenPath
+		}
+	}
+
+	return conf, nil
+}
+
+// parseMetricsItem parses an item of the `metrics` option, which is either a
+// pattern or a map of raw names to new names. With v2 the new name can also be
+// a map holding the `name` and the `type` of the metric.
+func parseMetricsItem(item interface{}, legacy bool) ([]metricMatcher, error) {
+	switch v := item.(type) {
+	case string:
+		re, err := compilePattern(v, legacy)
+		if err != nil {
+			return nil, err
+		}
+		return []metricMatcher{{pattern: re}}, nil
+	case map[interface{}]interface{}:
+		matchers := make([]metricMatcher, 0, len(v))
+		for rawName, target := range v {
+			matcher := metricMatcher{
+				pattern: regexp.MustCompile("^" + regexp.QuoteMeta(fmt.Sprint(rawName)) + "$"),
+			}
+			switch t := target.(type) {
+			case string:
+				matcher.name = t
+			case map[interface{}]interface{}:
+				if name, ok := t["name"]; ok {
+					matcher.name = fmt.Sprint(name)
+				}
+				if typ, ok := t["type"]; ok {
+					matcher.typ = fmt.Sprint(typ)
+					if err := validateType(matcher.typ); err != nil {
+						return nil, fmt.Errorf("invalid type for metric %v: %w", rawName, err)
+					}
+				}
+			default:
+				return nil, fmt.Errorf("invalid renaming of metric %v: %v", rawName, target)
+			}
+			matchers = append(matchers, matcher)
+		}
+		return matchers, nil
+	default:
+		return nil, fmt.Errorf("invalid item in `metrics`: %v", item)
+	}
+}
+
+// compilePattern compiles a metric pattern, which is a regular expression
+// matching the whole raw name, or a wildcard pattern for v1 instances
+func compilePattern(pattern string, legacy bool) (*regexp.Regexp, error) {
+	if legacy {
+		pattern = strings.ReplaceAll(regexp.QuoteMeta(pattern), `\*`, ".*")
+	}
+	re, err := regexp.Compile("^(?:" + pattern + ")$")
+	if err != nil {
+		return nil, fmt.Errorf("invalid metric pattern %q: %w", pattern, err)
+	}
+	return re, nil
+}
+
+func validateType(typ string) error {
+	switch typ {
+	case typeGauge, typeCounter:
+		return nil
+	default:
+		return fmt.Errorf("unsupported type %q, must be %s or %s", typ, typeGauge, typeCounter)
+	}
+}
+
+func boolOption(value *bool, defaultValue bool) bool {
+	if value == nil {
+		return defaultValue
+	}
+	return *value
+}
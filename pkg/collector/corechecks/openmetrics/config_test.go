@@ -0,0 +1,94 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package openmetrics
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseConfig(t *testing.T) {
+	conf, err := parseConfig([]byte(`
+openmetrics_endpoint: http://localhost:9090/metrics
+prometheus_url: http://localhost:9091/metrics
+metrics: [".*"]
+timeout: 3
+headers:
+  Accept-Encoding: gzip
+extra_headers:
+  X-Tenant: team
+`))
+	require.NoError(t, err)
+
+	assert.Equal(t, "http://localhost:9090/metrics", conf.endpoint)
+	assert.False(t, conf.legacy)
+	assert.True(t, conf.healthCheck)
+	assert.True(t, conf.histogramBuckets)
+	assert.True(t, conf.tlsVerify)
+	assert.Equal(t, 3*time.Second, conf.timeout)
+	assert.Equal(t, map[string]string{"Accept-Encoding": "gzip", "X-Tenant": "team"}, conf.headers)
+	assert.Empty(t, conf.bearerTokenPath)
+}
+
+func TestParseConfigFromAutodiscovery(t *testing.T) {
+	// instances generated by the prometheus config providers are JSON
+	conf, err := parseConfig([]byte(`{"prometheus_url":"http://10.0.0.1:8080/metrics","namespace":"","metrics":["*"],"bearer_token_auth":true}`))
+	require.NoError(t, err)
+
+	assert.True(t, conf.legacy)
+	assert.Equal(t, defaultBearerTokenPath, conf.bearerTokenPath)
+	require.Len(t, conf.metrics, 1)
+	assert.True(t, conf.metrics[0].pattern.MatchString("go_goroutines"))
+}
+
+func TestParseConfigErrors(t *testing.T) {
+	for name, tc := range map[string]struct {
+		instance string
+		err      string
+	}{
+		"no endpoint": {
+			instance: `metrics: [".*"]`,
+			err:      "either `openmetrics_endpoint` or `prometheus_url` must be set",
+		},
+		"no metrics": {
+			instance: `openmetrics_endpoint: http://localhost/metrics`,
+			err:      "instance config `metrics` must not be empty",
+		},
+		"invalid pattern": {
+			instance: `{"openmetrics_endpoint": "http://localhost/metrics", "metrics": ["go_("]}`,
+			err:      "invalid metric pattern \"go_(\": error parsing regexp: missing closing ): `^(?:go_()$`",
+		},
+		"invalid type": {
+			instance: `{"openmetrics_endpoint": "http://localhost/metrics", "metrics": [{"go_goroutines": {"type": "histogram"}}]}`,
+			err:      "invalid type for metric go_goroutines: unsupported type \"histogram\", must be gauge or counter",
+		},
+		"invalid type override": {
+			instance: `{"prometheus_url": "http://localhost/metrics", "metrics": ["*"], "type_overrides": {"go_goroutines": "summary"}}`,
+			err:      "invalid type override for metric go_goroutines: unsupported type \"summary\", must be gauge or counter",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := parseConfig([]byte(tc.instance))
+			assert.EqualError(t, err, tc.err)
+		})
+	}
+}
+
+func TestCompilePattern(t *testing.T) {
+	legacy, err := compilePattern("go_*_total", true)
+	require.NoError(t, err)
+	assert.True(t, legacy.MatchString("go_gc_total"))
+	assert.False(t, legacy.MatchString("go_gc_total_seconds"))
+	assert.False(t, legacy.MatchString("gogc_total"))
+
+	re, err := compilePattern("go_(gc|memstats)_.+", false)
+	require.NoError(t, err)
+	assert.True(t, re.MatchString("go_gc_duration"))
+	assert.False(t, re.MatchString("process_go_gc_duration"))
+}
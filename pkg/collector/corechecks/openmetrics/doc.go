@@ -0,0 +1,10 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+/*
+Package openmetrics implements the openmetrics core check, which scrapes
+Prometheus and OpenMetrics endpoints without going through the Python check.
+*/
+package openmetrics
@@ -0,0 +1,98 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package openmetrics
+
+import (
+	"github.com/DataDog/datadog-agent/pkg/aggregator"
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+	"github.com/DataDog/datadog-agent/pkg/collector/check"
+	core "github.com/DataDog/datadog-agent/pkg/collector/corechecks"
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+)
+
+const checkName = "openmetrics"
+
+// Check scrapes a Prometheus or OpenMetrics endpoint
+type Check struct {
+	core.CheckBase
+	conf    *scrapeConfig
+	scraper *scraper
+
+	// names caches the resolution of the raw metric names, the check runs
+	// are never concurrent
+	names map[string]*resolvedName
+}
+
+// Configure parses the check configuration and initializes the check
+func (c *Check) Configure(data integration.Data, initConfig integration.Data, source string) error {
+	// Must be called before CommonConfigure that uses checkID
+	c.BuildID(data, initConfig)
+
+	if err := c.CommonConfigure(data, source); err != nil {
+		return err
+	}
+
+	conf, err := parseConfig(data)
+	if err != nil {
+		return err
+	}
+
+	scraper, err := newScraper(conf)
+	if err != nil {
+		return err
+	}
+
+	c.conf = conf
+	c.scraper = scraper
+	c.names = make(map[string]*resolvedName)
+	return nil
+}
+
+// Run scrapes the endpoint and submits the selected metrics
+func (c *Check) Run() error {
+	// the scrape is cancelled if the run times out
+	ctx := c.RunContext()
+
+	sender, err := aggregator.GetSender(c.ID())
+	if err != nil {
+		return err
+	}
+
+	families, err := c.scraper.scrape(ctx)
+	if err != nil {
+		c.submitHealth(sender, metrics.ServiceCheckCritical, err.Error())
+		sender.Commit()
+		return err
+	}
+
+	c.submitHealth(sender, metrics.ServiceCheckOK, "")
+	c.submitFamilies(sender, families)
+
+	sender.Commit()
+	return nil
+}
+
+func (c *Check) submitHealth(sender aggregator.Sender, status metrics.ServiceCheckStatus, message string) {
+	if !c.conf.healthCheck {
+		return
+	}
+
+	name := "openmetrics.health"
+	if c.conf.legacy {
+		name = "prometheus.health"
+	}
+	sender.ServiceCheck(c.metricName(name), status, "", []string{"endpoint:" + c.conf.endpoint}, message)
+}
+
+func factory() check.Check {
+	return &Check{
+		CheckBase: core.NewCheckBase(checkName),
+	}
+}
+
+func init() {
+	core.RegisterCheck(checkName, factory)
+}
@@ -0,0 +1,248 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package openmetrics
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/common/expfmt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator/mocksender"
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+	"github.com/DataDog/datadog-agent/pkg/collector/check"
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+)
+
+const payload = `# HELP go_goroutines Number of goroutines that currently exist.
+# TYPE go_goroutines gauge
+go_goroutines 42
+# TYPE http_requests_total counter
+http_requests_total{code="200",pod="web"} 1027
+# TYPE http_requests_created gauge
+http_requests_created{code="200",pod="web"} 1.6358976e+09
+# TYPE request_duration_seconds histogram
+request_duration_seconds_bucket{pod="web",le="0.1"} 5
+request_duration_seconds_bucket{pod="web",le="1"} 8
+request_duration_seconds_bucket{pod="web",le="+Inf"} 10
+request_duration_seconds_sum{pod="web"} 12.5
+request_duration_seconds_count{pod="web"} 10
+# TYPE rpc_duration_seconds summary
+rpc_duration_seconds{quantile="0.5"} 0.2
+rpc_duration_seconds{quantile="0.99"} NaN
+rpc_duration_seconds_sum 30
+rpc_duration_seconds_count 100
+# TYPE ignored_metric gauge
+ignored_metric 1
+`
+
+func newServer(t *testing.T, protobuf bool) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !protobuf {
+			w.Header().Set("Content-Type", string(expfmt.FmtText))
+			fmt.Fprint(w, payload)
+			return
+		}
+
+		assert.Contains(t, r.Header.Get("Accept"), "application/vnd.google.protobuf")
+		families, err := (&expfmt.TextParser{}).TextToMetricFamilies(strings.NewReader(payload))
+		require.NoError(t, err)
+
+		w.Header().Set("Content-Type", string(expfmt.FmtProtoDelim))
+		encoder := expfmt.NewEncoder(w, expfmt.FmtProtoDelim)
+		for _, family := range families {
+			require.NoError(t, encoder.Encode(family))
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func configureCheck(t *testing.T, instance string) (*Check, *mocksender.MockSender) {
+	c := factory().(*Check)
+	sender := mocksender.NewMockSender(check.BuildID(checkName, integration.Data(instance), nil))
+	sender.SetupAcceptAll()
+
+	require.NoError(t, c.Configure(integration.Data(instance), nil, "test"))
+	return c, sender
+}
+
+func TestRun(t *testing.T) {
+	for _, protobuf := range []bool{false, true} {
+		t.Run(fmt.Sprintf("protobuf=%t", protobuf), func(t *testing.T) {
+			server := newServer(t, protobuf)
+			c, sender := configureCheck(t, fmt.Sprintf(`
+openmetrics_endpoint: %s/metrics
+namespace: test
+metrics:
+  - go_goroutines: goroutines
+  - http_requests_total:
+      name: requests
+  - (request|rpc)_duration_seconds
+rename_labels:
+  pod: kube_pod
+exclude_labels:
+  - code
+`, server.URL))
+
+			require.NoError(t, c.Run())
+
+			sender.AssertServiceCheck(t, "test.openmetrics.health", metrics.ServiceCheckOK, "", []string{"endpoint:" + server.URL + "/metrics"}, "")
+			sender.AssertMetric(t, "Gauge", "test.goroutines", 42, "", nil)
+
+			// counters are submitted as monotonic counts with the .count suffix
+			sender.AssertMetric(t, "MonotonicCount", "test.requests.count", 1027, "", []string{"kube_pod:web"})
+			sender.AssertMetricNotTaggedWith(t, "MonotonicCount", "test.requests.count", []string{"code:200", "pod:web"})
+
+			sender.AssertMetric(t, "MonotonicCount", "test.request_duration_seconds.sum", 12.5, "", []string{"kube_pod:web"})
+			sender.AssertMetric(t, "MonotonicCount", "test.request_duration_seconds.count", 10, "", []string{"kube_pod:web"})
+			sender.AssertMetric(t, "MonotonicCount", "test.request_duration_seconds.bucket", 5, "", []string{"kube_pod:web", "upper_bound:0.1"})
+			sender.AssertMetric(t, "MonotonicCount", "test.request_duration_seconds.bucket", 8, "", []string{"kube_pod:web", "upper_bound:1"})
+			sender.AssertMetric(t, "MonotonicCount", "test.request_duration_seconds.bucket", 10, "", []string{"kube_pod:web", "upper_bound:inf"})
+
+			sender.AssertMetric(t, "MonotonicCount", "test.rpc_duration_seconds.sum", 30, "", nil)
+			sender.AssertMetric(t, "MonotonicCount", "test.rpc_duration_seconds.count", 100, "", nil)
+			sender.AssertMetric(t, "Gauge", "test.rpc_duration_seconds.quantile", 0.2, "", []string{"quantile:0.5"})
+			sender.AssertNotCalled(t, "Gauge", "test.rpc_duration_seconds.quantile", mock.Anything, mock.Anything, []string{"quantile:0.99"})
+
+			sender.AssertNotCalled(t, "Gauge", "test.ignored_metric", mock.Anything, mock.Anything, mock.Anything)
+			sender.AssertNumberOfCalls(t, "Commit", 1)
+		})
+	}
+}
+
+func TestRunCounterNames(t *testing.T) {
+	for _, protobuf := range []bool{false, true} {
+		t.Run(fmt.Sprintf("protobuf=%t", protobuf), func(t *testing.T) {
+			server := newServer(t, protobuf)
+			c, sender := configureCheck(t, fmt.Sprintf(`
+openmetrics_endpoint: %s/metrics
+namespace: test
+metrics: [".*"]
+`, server.URL))
+
+			require.NoError(t, c.Run())
+
+			// the _total suffix of counters is replaced by .count
+			sender.AssertMetric(t, "MonotonicCount", "test.http_requests.count", 1027, "", []string{"code:200", "pod:web"})
+			sender.AssertNotCalled(t, "MonotonicCount", "test.http_requests_total.count", mock.Anything, mock.Anything, mock.Anything)
+
+			// the creation timestamps of the counters are not collected
+			sender.AssertNotCalled(t, "Gauge", "test.http_requests_created", mock.Anything, mock.Anything, mock.Anything)
+			sender.AssertMetric(t, "Gauge", "test.ignored_metric", 1, "", nil)
+		})
+	}
+
+	// counters can be selected by their name without suffix
+	server := newServer(t, false)
+	c, sender := configureCheck(t, fmt.Sprintf(`
+openmetrics_endpoint: %s/metrics
+metrics: [http_requests]
+`, server.URL))
+
+	require.NoError(t, c.Run())
+	sender.AssertMetric(t, "MonotonicCount", "http_requests.count", 1027, "", []string{"code:200", "pod:web"})
+}
+
+func TestRunLegacy(t *testing.T) {
+	server := newServer(t, false)
+	c, sender := configureCheck(t, fmt.Sprintf(`
+prometheus_url: %s/metrics
+namespace: test
+metrics:
+  - "*_duration_seconds"
+  - http_requests_total: requests
+  - go_goroutines
+type_overrides:
+  go_goroutines: counter
+send_monotonic_counter: false
+send_distribution_counts_as_monotonic: true
+labels_mapper:
+  pod: kube_pod
+`, server.URL))
+
+	require.NoError(t, c.Run())
+
+	sender.AssertServiceCheck(t, "test.prometheus.health", metrics.ServiceCheckOK, "", nil, "")
+	sender.AssertMetric(t, "Gauge", "test.requests", 1027, "", []string{"code:200", "kube_pod:web"})
+	sender.AssertMetric(t, "Gauge", "test.go_goroutines", 42, "", nil)
+
+	sender.AssertMetric(t, "Gauge", "test.request_duration_seconds.sum", 12.5, "", []string{"kube_pod:web"})
+	sender.AssertMetric(t, "MonotonicCount", "test.request_duration_seconds.count", 10, "", []string{"kube_pod:web"})
+	sender.AssertMetric(t, "MonotonicCount", "test.request_duration_seconds.count", 5, "", []string{"kube_pod:web", "upper_bound:0.1"})
+	sender.AssertMetric(t, "MonotonicCount", "test.request_duration_seconds.count", 10, "", []string{"kube_pod:web", "upper_bound:none"})
+	sender.AssertMetric(t, "Gauge", "test.rpc_duration_seconds.quantile", 0.2, "", []string{"quantile:0.5"})
+}
+
+func TestRunDistributionBuckets(t *testing.T) {
+	server := newServer(t, true)
+	c, sender := configureCheck(t, fmt.Sprintf(`
+openmetrics_endpoint: %s/metrics
+metrics:
+  - request_duration_seconds
+histogram_buckets_as_distributions: true
+`, server.URL))
+
+	require.NoError(t, c.Run())
+
+	tags := []string{"pod:web"}
+	sender.AssertHistogramBucket(t, "HistogramBucket", "request_duration_seconds.bucket", 5, 0, 0.1, true, "", tags, false)
+	sender.AssertHistogramBucket(t, "HistogramBucket", "request_duration_seconds.bucket", 3, 0.1, 1, true, "", tags, false)
+	sender.AssertHistogramBucket(t, "HistogramBucket", "request_duration_seconds.bucket", 2, 1, math.Inf(1), true, "", tags, false)
+	sender.AssertMetric(t, "MonotonicCount", "request_duration_seconds.count", 10, "", tags)
+	sender.AssertNotCalled(t, "MonotonicCount", "request_duration_seconds.bucket", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestRunEndpointError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	c, sender := configureCheck(t, fmt.Sprintf(`
+openmetrics_endpoint: %s/metrics
+metrics: [".*"]
+`, server.URL))
+
+	err := c.Run()
+	assert.EqualError(t, err, "unexpected status code 503")
+	sender.AssertServiceCheck(t, "openmetrics.health", metrics.ServiceCheckCritical, "", nil, "unexpected status code 503")
+	sender.AssertNumberOfCalls(t, "Commit", 1)
+}
+
+func TestRunBearerToken(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" || r.Header.Get("X-Tenant") != "team" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		fmt.Fprint(w, payload)
+	}))
+	defer server.Close()
+
+	tokenPath := t.TempDir() + "/token"
+	require.NoError(t, os.WriteFile(tokenPath, []byte("secret\n"), 0600))
+
+	c, sender := configureCheck(t, fmt.Sprintf(`
+openmetrics_endpoint: %s/metrics
+metrics: [go_goroutines]
+bearer_token_auth: true
+bearer_token_path: %s
+headers:
+  X-Tenant: team
+`, server.URL, tokenPath))
+
+	require.NoError(t, c.Run())
+	sender.AssertMetric(t, "Gauge", "go_goroutines", 42, "", nil)
+}
@@ -0,0 +1,115 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package openmetrics
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+)
+
+// acceptHeader prefers the protobuf exposition format, and falls back to the text one
+const acceptHeader = `application/vnd.google.protobuf;proto=io.prometheus.client.MetricFamily;encoding=delimited;q=0.7,text/plain;version=0.0.4;q=0.3,*/*;q=0.1`
+
+// scraper fetches and decodes the metric families exposed by an endpoint
+type scraper struct {
+	conf   *scrapeConfig
+	client *http.Client
+}
+
+func newScraper(conf *scrapeConfig) (*scraper, error) {
+	tlsConfig := &tls.Config{
+		InsecureSkipVerify: !conf.tlsVerify,
+	}
+
+	if conf.tlsCACert != "" {
+		caCert, err := os.ReadFile(conf.tlsCACert)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read the CA certificate: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(caCert) {
+			return nil, fmt.Errorf("no certificate found in %s", conf.tlsCACert)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if conf.tlsCert != "" {
+		cert, err := tls.LoadX509KeyPair(conf.tlsCert, conf.tlsPrivateKey)
+		if err != nil {
+			return nil, fmt.Errorf("unable to load the client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+
+	return &scraper{
+		conf: conf,
+		client: &http.Client{
+			Transport: transport,
+			Timeout:   conf.timeout,
+		},
+	}, nil
+}
+
+// scrape returns the metric families exposed by the endpoint
+func (s *scraper) scrape(ctx context.Context) ([]*dto.MetricFamily, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.conf.endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Accept", acceptHeader)
+	for k, v := range s.conf.headers {
+		req.Header.Set(k, v)
+	}
+	if s.conf.username != "" {
+		req.SetBasicAuth(s.conf.username, s.conf.password)
+	}
+	if s.conf.bearerTokenPath != "" {
+		// the token is read on each scrape as it's rotated by kubernetes
+		token, err := os.ReadFile(s.conf.bearerTokenPath)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read the bearer token: %w", err)
+		}
+		req.Header.Set("Authorization", "Bearer "+strings.TrimSpace(string(token)))
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		io.Copy(io.Discard, resp.Body) //nolint:errcheck
+		return nil, fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+
+	var families []*dto.MetricFamily
+	decoder := expfmt.NewDecoder(resp.Body, expfmt.ResponseFormat(resp.Header))
+	for {
+		family := &dto.MetricFamily{}
+		if err := decoder.Decode(family); err != nil {
+			if errors.Is(err, io.EOF) {
+				return families, nil
+			}
+			return nil, fmt.Errorf("unable to decode the payload: %w", err)
+		}
+		families = append(families, family)
+	}
+}
@@ -0,0 +1,288 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package openmetrics
+
+import (
+	"math"
+	"strconv"
+	"strings"
+
+	dto "github.com/prometheus/client_model/go"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator"
+)
+
+// resolvedName is the result of matching a raw metric name against the
+// `metrics` and exclusion options, it's nil for metrics that aren't collected
+type resolvedName struct {
+	name string
+	typ  string
+}
+
+// counterSuffix ends the names of the counter families, the `.count` suffix replaces it
+const counterSuffix = "_total"
+
+// createdSuffix ends the names of the families holding the creation timestamp of a counter
+const createdSuffix = "_created"
+
+// resolve returns how the metric family named raw is collected. The `_total` suffix
+// of counters is stripped, unless for v1 instances, but their raw name is also
+// matched against the `metrics` option.
+func (c *Check) resolve(raw string, counter bool) *resolvedName {
+	if resolved, found := c.names[raw]; found {
+		return resolved
+	}
+
+	var resolved *resolvedName
+	name := strings.TrimPrefix(raw, c.conf.rawPrefix)
+	candidates := []string{name}
+	if counter && !c.conf.legacy && strings.HasSuffix(name, counterSuffix) {
+		candidates = []string{strings.TrimSuffix(name, counterSuffix), name}
+	}
+
+	if !c.isExcluded(candidates...) {
+		for _, matcher := range c.conf.metrics {
+			for _, candidate := range candidates {
+				if !matcher.pattern.MatchString(candidate) {
+					continue
+				}
+				resolved = &resolvedName{name: matcher.name, typ: matcher.typ}
+				if resolved.name == "" {
+					resolved.name = candidates[0]
+				}
+				if resolved.typ == "" {
+					resolved.typ = c.conf.typeOverrides[candidate]
+				}
+				break
+			}
+			if resolved != nil {
+				break
+			}
+		}
+	}
+
+	c.names[raw] = resolved
+	return resolved
+}
+
+func (c *Check) isExcluded(names ...string) bool {
+	for _, re := range c.conf.exclude {
+		for _, name := range names {
+			if re.MatchString(name) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// metricName prefixes name with the namespace
+func (c *Check) metricName(name string) string {
+	if c.conf.namespace == "" {
+		return name
+	}
+	return c.conf.namespace + "." + name
+}
+
+// submitFamilies submits the samples of the metric families of a scrape. Unless for v1
+// instances, the creation timestamps of the counters, exposed next to them by some
+// clients as `<name>_created` gauges, are not collected.
+func (c *Check) submitFamilies(sender aggregator.Sender, families []*dto.MetricFamily) {
+	created := make(map[string]struct{})
+	if !c.conf.legacy {
+		for _, family := range families {
+			if family.GetType() == dto.MetricType_COUNTER && strings.HasSuffix(family.GetName(), counterSuffix) {
+				created[strings.TrimSuffix(family.GetName(), counterSuffix)+createdSuffix] = struct{}{}
+			}
+		}
+	}
+
+	for _, family := range families {
+		if _, found := created[family.GetName()]; found && family.GetType() != dto.MetricType_COUNTER {
+			continue
+		}
+		c.submitFamily(sender, family)
+	}
+}
+
+// submitFamily submits the samples of a metric family according to its type
+func (c *Check) submitFamily(sender aggregator.Sender, family *dto.MetricFamily) {
+	resolved := c.resolve(family.GetName(), family.GetType() == dto.MetricType_COUNTER)
+	if resolved == nil {
+		return
+	}
+	name := c.metricName(resolved.name)
+
+	for _, metric := range family.GetMetric() {
+		tags := c.labelsToTags(metric.GetLabel())
+
+		switch family.GetType() {
+		case dto.MetricType_HISTOGRAM:
+			c.submitHistogram(sender, name, metric.GetHistogram(), tags)
+		case dto.MetricType_SUMMARY:
+			c.submitSummary(sender, name, metric.GetSummary(), tags)
+		default:
+			value, ok := scalarValue(metric)
+			if !ok {
+				continue
+			}
+			typ := resolved.typ
+			if typ == "" && family.GetType() == dto.MetricType_COUNTER {
+				typ = typeCounter
+			}
+			if typ == typeCounter {
+				c.submitCounter(sender, name, value, tags)
+			} else {
+				sender.Gauge(name, value, "", tags)
+			}
+		}
+	}
+}
+
+func (c *Check) submitCounter(sender aggregator.Sender, name string, value float64, tags []string) {
+	switch {
+	case !c.conf.legacy:
+		sender.MonotonicCount(name+".count", value, "", tags)
+	case c.conf.monotonicCounter:
+		sender.MonotonicCount(name, value, "", tags)
+	default:
+		sender.Gauge(name, value, "", tags)
+	}
+}
+
+// submitAggregate submits the sum or the count of a histogram or a summary,
+// monotonic tells whether v1 instances submit it as a monotonic count
+func (c *Check) submitAggregate(sender aggregator.Sender, name string, value float64, tags []string, monotonic bool) {
+	if !c.conf.legacy || monotonic {
+		sender.MonotonicCount(name, value, "", tags)
+	} else {
+		sender.Gauge(name, value, "", tags)
+	}
+}
+
+func (c *Check) submitSummary(sender aggregator.Sender, name string, summary *dto.Summary, tags []string) {
+	c.submitAggregate(sender, name+".sum", summary.GetSampleSum(), tags, c.conf.sumsAsMonotonic)
+	c.submitAggregate(sender, name+".count", float64(summary.GetSampleCount()), tags, c.conf.countsAsMonotonic)
+
+	for _, quantile := range summary.GetQuantile() {
+		if !isFinite(quantile.GetValue()) {
+			continue
+		}
+		sender.Gauge(name+".quantile", quantile.GetValue(), "", withTag(tags, "quantile:"+formatFloat(quantile.GetQuantile())))
+	}
+}
+
+func (c *Check) submitHistogram(sender aggregator.Sender, name string, histogram *dto.Histogram, tags []string) {
+	c.submitAggregate(sender, name+".sum", histogram.GetSampleSum(), tags, c.conf.sumsAsMonotonic)
+	c.submitAggregate(sender, name+".count", float64(histogram.GetSampleCount()), tags, c.conf.countsAsMonotonic)
+
+	buckets := histogram.GetBucket()
+	// the +Inf bucket is implicit in the protobuf format
+	if len(buckets) == 0 || !math.IsInf(buckets[len(buckets)-1].GetUpperBound(), 1) {
+		infBound, count := math.Inf(1), histogram.GetSampleCount()
+		buckets = append(buckets[:len(buckets):len(buckets)], &dto.Bucket{UpperBound: &infBound, CumulativeCount: &count})
+	}
+
+	if c.conf.distributionBuckets {
+		c.submitDistributionBuckets(sender, name+".bucket", buckets, tags)
+		return
+	}
+	if !c.conf.histogramBuckets {
+		return
+	}
+
+	for _, bucket := range buckets {
+		value := float64(bucket.GetCumulativeCount())
+		bucketTags := withTag(tags, "upper_bound:"+c.formatUpperBound(bucket.GetUpperBound()))
+		if c.conf.legacy {
+			c.submitAggregate(sender, name+".count", value, bucketTags, c.conf.countsAsMonotonic)
+		} else {
+			sender.MonotonicCount(name+".bucket", value, "", bucketTags)
+		}
+	}
+}
+
+// submitDistributionBuckets submits the buckets to be aggregated into a
+// distribution, their cumulative counts are turned into per bucket counts
+func (c *Check) submitDistributionBuckets(sender aggregator.Sender, name string, buckets []*dto.Bucket, tags []string) {
+	var lowerBound float64
+	var previousCount uint64
+	for i, bucket := range buckets {
+		upperBound := bucket.GetUpperBound()
+		if i == 0 && upperBound < 0 {
+			lowerBound = upperBound
+		}
+
+		count := bucket.GetCumulativeCount()
+		if count < previousCount {
+			// the buckets are inconsistent, they're submitted on the next run
+			return
+		}
+		sender.HistogramBucket(name, int64(count-previousCount), lowerBound, upperBound, true, "", tags, false)
+
+		lowerBound = upperBound
+		previousCount = count
+	}
+}
+
+// labelsToTags turns the labels of a sample into tags, applying the label
+// exclusions and renaming
+func (c *Check) labelsToTags(labels []*dto.LabelPair) []string {
+	tags := make([]string, 0, len(labels))
+	for _, label := range labels {
+		name := label.GetName()
+		if _, excluded := c.conf.excludeLabels[name]; excluded {
+			continue
+		}
+		if renamed, found := c.conf.renameLabels[name]; found {
+			name = renamed
+		}
+		tags = append(tags, name+":"+label.GetValue())
+	}
+	return tags
+}
+
+// formatUpperBound formats the upper bound of a bucket, the +Inf bound is
+// reported as `none` by v1 instances and as `inf` by v2 ones
+func (c *Check) formatUpperBound(bound float64) string {
+	if math.IsInf(bound, 1) {
+		if c.conf.legacy {
+			return "none"
+		}
+		return "inf"
+	}
+	return formatFloat(bound)
+}
+
+// scalarValue returns the value of a gauge, counter or untyped sample, non
+// finite values are skipped
+func scalarValue(metric *dto.Metric) (float64, bool) {
+	var value float64
+	switch {
+	case metric.Gauge != nil:
+		value = metric.GetGauge().GetValue()
+	case metric.Counter != nil:
+		value = metric.GetCounter().GetValue()
+	case metric.Untyped != nil:
+		value = metric.GetUntyped().GetValue()
+	default:
+		return 0, false
+	}
+	return value, isFinite(value)
+}
+
+func isFinite(value float64) bool {
+	return !math.IsNaN(value) && !math.IsInf(value, 0)
+}
+
+func formatFloat(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
+
+// withTag returns a copy of tags with tag appended
+func withTag(tags []string, tag string) []string {
+	return append(tags[:len(tags):len(tags)], tag)
+}
@@ -0,0 +1,11 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+/*
+Package psi implements the psi check, reporting the Linux pressure stall
+information of the host from /proc/pressure: the time during which some (or
+all) non-idle tasks were stalled on the CPU, memory or IO.
+*/
+package psi
@@ -0,0 +1,113 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build linux
+// +build linux
+
+package psi
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator"
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+	"github.com/DataDog/datadog-agent/pkg/collector/check"
+	core "github.com/DataDog/datadog-agent/pkg/collector/corechecks"
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/util/cgroups"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const checkName = "psi"
+
+// pressureResource is a file of /proc/pressure and the prefix of its metrics.
+// The "full" line of /proc/pressure/cpu is undefined at the system level, it
+// isn't reported.
+type pressureResource struct {
+	file         string
+	metricPrefix string
+	full         bool
+}
+
+var pressureResources = []pressureResource{
+	{file: "cpu", metricPrefix: "system.cpu", full: false},
+	{file: "memory", metricPrefix: "system.mem", full: true},
+	{file: "io", metricPrefix: "system.io", full: true},
+}
+
+// Check reports the pressure stall information of the host
+type Check struct {
+	core.CheckBase
+	procfsPath string
+}
+
+// Configure the check
+func (c *Check) Configure(data integration.Data, initConfig integration.Data, source string) error {
+	if err := c.CommonConfigure(data, source); err != nil {
+		return err
+	}
+
+	c.procfsPath = "/proc"
+	if config.Datadog.IsSet("procfs_path") {
+		c.procfsPath = config.Datadog.GetString("procfs_path")
+	}
+	return nil
+}
+
+// Run executes the check
+func (c *Check) Run() error {
+	sender, err := aggregator.GetSender(c.ID())
+	if err != nil {
+		return err
+	}
+
+	found := false
+	for _, resource := range pressureResources {
+		var some, full cgroups.PSIStats
+		fullStats := &full
+		if !resource.full {
+			fullStats = nil
+		}
+
+		path := filepath.Join(c.procfsPath, "pressure", resource.file)
+		if err := cgroups.ParsePSI(path, &some, fullStats); err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				log.Debugf("psi.Check: %s not found, %s pressure metrics will be missing", path, resource.file)
+				continue
+			}
+			return err
+		}
+		found = true
+
+		// The kernel reports the total stall times in microseconds
+		if some.Total != nil {
+			sender.Rate(resource.metricPrefix+".partial_stall", float64(*some.Total)*float64(time.Microsecond), "", nil)
+		}
+		if full.Total != nil {
+			sender.Rate(resource.metricPrefix+".full_stall", float64(*full.Total)*float64(time.Microsecond), "", nil)
+		}
+	}
+
+	if !found {
+		return fmt.Errorf("pressure stall information not available in %s, it requires a Linux 4.20+ kernel with PSI enabled", filepath.Join(c.procfsPath, "pressure"))
+	}
+
+	sender.Commit()
+	return nil
+}
+
+func psiFactory() check.Check {
+	return &Check{
+		CheckBase: core.NewCheckBase(checkName),
+	}
+}
+
+func init() {
+	core.RegisterCheck(checkName, psiFactory)
+}
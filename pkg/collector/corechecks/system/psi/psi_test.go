@@ -0,0 +1,91 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build linux
+// +build linux
+
+package psi
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator/mocksender"
+	"github.com/DataDog/datadog-agent/pkg/config"
+)
+
+func writePressureFiles(t *testing.T, files map[string]string) string {
+	procfsPath := t.TempDir()
+	require.NoError(t, os.Mkdir(filepath.Join(procfsPath, "pressure"), 0755))
+	for name, content := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(procfsPath, "pressure", name), []byte(content), 0644))
+	}
+	return procfsPath
+}
+
+func setupCheck(t *testing.T, procfsPath string) (*Check, *mocksender.MockSender) {
+	mockConfig := config.Mock()
+	mockConfig.Set("procfs_path", procfsPath)
+
+	c := psiFactory().(*Check)
+	require.NoError(t, c.Configure(nil, nil, "test"))
+
+	sender := mocksender.NewMockSender(c.ID())
+	sender.SetupAcceptAll()
+	return c, sender
+}
+
+func TestPSICheck(t *testing.T) {
+	procfsPath := writePressureFiles(t, map[string]string{
+		"cpu": `some avg10=1.05 avg60=0.82 avg300=0.40 total=114289003
+full avg10=0.00 avg60=0.00 avg300=0.00 total=0
+`,
+		"memory": `some avg10=0.00 avg60=0.10 avg300=0.05 total=2500
+full avg10=0.00 avg60=0.02 avg300=0.01 total=1200
+`,
+		"io": `some avg10=3.20 avg60=2.10 avg300=1.30 total=8000000
+full avg10=1.10 avg60=0.90 avg300=0.60 total=4000000
+`,
+	})
+	c, sender := setupCheck(t, procfsPath)
+
+	require.NoError(t, c.Run())
+
+	sender.AssertMetric(t, "Rate", "system.cpu.partial_stall", 114289003000, "", nil)
+	sender.AssertMetric(t, "Rate", "system.mem.partial_stall", 2500000, "", nil)
+	sender.AssertMetric(t, "Rate", "system.mem.full_stall", 1200000, "", nil)
+	sender.AssertMetric(t, "Rate", "system.io.partial_stall", 8000000000, "", nil)
+	sender.AssertMetric(t, "Rate", "system.io.full_stall", 4000000000, "", nil)
+	sender.AssertNumberOfCalls(t, "Rate", 5)
+	sender.AssertNumberOfCalls(t, "Commit", 1)
+}
+
+func TestPSICheckMissingFiles(t *testing.T) {
+	// only the resources exposed by the kernel are reported
+	procfsPath := writePressureFiles(t, map[string]string{
+		"cpu": "some avg10=0.00 avg60=0.00 avg300=0.00 total=42\n",
+	})
+	c, sender := setupCheck(t, procfsPath)
+
+	require.NoError(t, c.Run())
+
+	sender.AssertMetric(t, "Rate", "system.cpu.partial_stall", 42000, "", nil)
+	sender.AssertNumberOfCalls(t, "Rate", 1)
+	sender.AssertNumberOfCalls(t, "Commit", 1)
+}
+
+func TestPSICheckNotAvailable(t *testing.T) {
+	procfsPath := t.TempDir()
+	c, sender := setupCheck(t, procfsPath)
+
+	err := c.Run()
+	assert.EqualError(t, err, "pressure stall information not available in "+filepath.Join(procfsPath, "pressure")+", it requires a Linux 4.20+ kernel with PSI enabled")
+	sender.AssertNumberOfCalls(t, "Rate", 0)
+	sender.AssertNumberOfCalls(t, "Commit", 0)
+}
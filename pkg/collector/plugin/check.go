@@ -0,0 +1,203 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package plugin
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	yaml "gopkg.in/yaml.v2"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator"
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+	core "github.com/DataDog/datadog-agent/pkg/collector/corechecks"
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// cancelGracePeriod is the time given to a plugin to exit once it was asked to
+const cancelGracePeriod = 5 * time.Second
+
+// pluginConfig holds the plugin options of the init_config and instance sections
+type pluginConfig struct {
+	Command    string   `yaml:"plugin_command"`
+	Args       []string `yaml:"plugin_args"`
+	RunTimeout int      `yaml:"plugin_run_timeout"`
+}
+
+// PluginCheck is a check implemented by an executable running out of process
+type PluginCheck struct {
+	core.CheckBase
+	command    string
+	args       []string
+	runTimeout time.Duration
+	instance   integration.Data
+	initConfig integration.Data
+
+	// m protects proc, which is started on the first run and after a crash,
+	// and the version it reported
+	m       sync.Mutex
+	proc    *process
+	version string
+}
+
+func newPluginCheck(name, command string, args []string) *PluginCheck {
+	return &PluginCheck{
+		CheckBase:  core.NewCheckBase(name),
+		command:    command,
+		args:       args,
+		runTimeout: time.Duration(config.Datadog.GetInt("plugin_check_run_timeout")) * time.Second,
+	}
+}
+
+// Configure configures the check, the plugin itself is configured when it's started
+func (c *PluginCheck) Configure(data integration.Data, initConfig integration.Data, source string) error {
+	c.BuildID(data, initConfig)
+	if err := c.CheckBase.Configure(data, initConfig, source); err != nil {
+		return err
+	}
+
+	// the instance overrides the timeout of init_config
+	for _, section := range []integration.Data{initConfig, data} {
+		conf := pluginConfig{}
+		if err := yaml.Unmarshal(section, &conf); err != nil {
+			return err
+		}
+		if conf.RunTimeout > 0 {
+			c.runTimeout = time.Duration(conf.RunTimeout) * time.Second
+		}
+	}
+
+	c.instance = data
+	c.initConfig = initConfig
+	return nil
+}
+
+// Run runs the check in the plugin, starting it if needed, and forwards the
+// submitted metrics, service checks and events to the sender
+func (c *PluginCheck) Run() error {
+	sender, err := aggregator.GetSender(c.ID())
+	if err != nil {
+		return err
+	}
+
+	proc, err := c.getProcess(sender)
+	if err != nil {
+		return err
+	}
+
+	_, err = proc.call(methodRun, nil, c.runTimeout, func(m *message) {
+		c.handleMessage(sender, m)
+	})
+	if errors.Is(err, errTimeout) {
+		// the plugin may be stuck, it's restarted on the next run
+		c.resetProcess(proc)
+	}
+
+	sender.Commit()
+	return err
+}
+
+// getProcess returns the running plugin, it's started and configured if it
+// isn't running
+func (c *PluginCheck) getProcess(sender aggregator.Sender) (*process, error) {
+	c.m.Lock()
+	proc := c.proc
+	c.m.Unlock()
+
+	if proc != nil && proc.isRunning() {
+		return proc, nil
+	}
+	if proc != nil {
+		log.Warnf("plugin %s: restarting the plugin: %v", c.ID(), proc.exitError(nil))
+	}
+
+	proc, err := startProcess(string(c.ID()), c.command, c.args)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := proc.call(methodConfigure, &configParam{
+		ProtocolVersion: protocolVersion,
+		CheckID:         string(c.ID()),
+		Name:            c.String(),
+		InitConfig:      string(c.initConfig),
+		Instance:        string(c.instance),
+		Source:          c.ConfigSource(),
+	}, c.runTimeout, func(m *message) {
+		c.handleMessage(sender, m)
+	})
+	if err != nil {
+		proc.kill()
+		return nil, fmt.Errorf("unable to configure the plugin: %w", err)
+	}
+
+	c.m.Lock()
+	c.proc = proc
+	c.version = result.Version
+	c.m.Unlock()
+
+	return proc, nil
+}
+
+// resetProcess kills proc if it's the running plugin
+func (c *PluginCheck) resetProcess(proc *process) {
+	c.m.Lock()
+	if c.proc == proc {
+		c.proc = nil
+	}
+	c.m.Unlock()
+
+	proc.kill()
+}
+
+func (c *PluginCheck) handleMessage(sender aggregator.Sender, m *message) {
+	if m.Type == messageWarning {
+		c.Warn(m.Message) //nolint:errcheck
+		return
+	}
+	if err := m.submit(sender); err != nil {
+		c.Warnf("plugin %s: %v", c.ID(), err) //nolint:errcheck
+	}
+}
+
+// Stop kills the plugin, interrupting the current run
+func (c *PluginCheck) Stop() {
+	c.m.Lock()
+	proc := c.proc
+	c.m.Unlock()
+
+	if proc != nil {
+		c.resetProcess(proc)
+	}
+}
+
+// Cancel asks the plugin to cancel the check and exit
+func (c *PluginCheck) Cancel() {
+	c.m.Lock()
+	proc := c.proc
+	c.proc = nil
+	c.m.Unlock()
+
+	if proc != nil {
+		proc.shutdown(cancelGracePeriod)
+	}
+	c.CommonCancel()
+}
+
+// LoaderName returns the name of the plugin check loader
+func (c *PluginCheck) LoaderName() string {
+	return "plugin"
+}
+
+// Version returns the version reported by the plugin when it was configured
+func (c *PluginCheck) Version() string {
+	c.m.Lock()
+	defer c.m.Unlock()
+	return c.version
+}
@@ -0,0 +1,261 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package plugin
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator/mocksender"
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/providers/names"
+	"github.com/DataDog/datadog-agent/pkg/collector/check"
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+)
+
+// fakePluginEnv makes the test binary behave as a plugin, its value is the behavior
+const fakePluginEnv = "DD_TEST_FAKE_PLUGIN"
+
+func TestMain(m *testing.M) {
+	if mode := os.Getenv(fakePluginEnv); mode != "" {
+		os.Exit(runFakePlugin(mode))
+	}
+	os.Exit(m.Run())
+}
+
+// runFakePlugin implements the plugin protocol, mode is one of:
+// - ok: submits a gauge, a service check, an event and a warning on each run
+// - hang: never completes a run
+// - crash: exits on the first run
+// - spawn: starts a process sharing its output, reports its pid as a warning and never completes a run
+func runFakePlugin(mode string) int {
+	scanner := bufio.NewScanner(os.Stdin)
+	encoder := json.NewEncoder(os.Stdout)
+	runs := 0
+
+	for scanner.Scan() {
+		req := request{}
+		if err := json.Unmarshal(scanner.Bytes(), &req); err != nil {
+			return 2
+		}
+
+		switch req.Method {
+		case methodConfigure:
+			if req.Params.ProtocolVersion != protocolVersion {
+				encoder.Encode(message{Type: messageResult, ID: req.ID, Error: "unsupported protocol version"}) //nolint:errcheck
+				continue
+			}
+			encoder.Encode(message{Type: messageResult, ID: req.ID, Version: "1.2.3"}) //nolint:errcheck
+		case methodRun:
+			runs++
+			switch mode {
+			case "hang":
+				continue
+			case "spawn":
+				child := exec.Command("sleep", "60")
+				child.Stdout, child.Stderr = os.Stdout, os.Stderr
+				if err := child.Start(); err != nil {
+					return 2
+				}
+				encoder.Encode(message{Type: messageWarning, Message: strconv.Itoa(child.Process.Pid)}) //nolint:errcheck
+				continue
+			case "crash":
+				fmt.Fprintln(os.Stderr, "panic: something went wrong")
+				return 1
+			}
+			encoder.Encode(message{Type: messageMetric, MetricType: "gauge", Name: "fake.runs", Value: float64(runs), Tags: []string{"mode:ok"}})   //nolint:errcheck
+			encoder.Encode(message{Type: messageServiceCheck, Name: "fake.can_connect", Status: int(metrics.ServiceCheckWarning), Message: "slow"}) //nolint:errcheck
+			encoder.Encode(message{Type: messageEvent, Event: &metrics.Event{Title: "fake", Text: "ran", Ts: 42}})                                  //nolint:errcheck
+			encoder.Encode(message{Type: messageWarning, Message: "deprecated option"})                                                             //nolint:errcheck
+			encoder.Encode(message{Type: messageMetric, MetricType: "unknown", Name: "fake.invalid"})                                               //nolint:errcheck
+			encoder.Encode(message{Type: messageResult, ID: req.ID})                                                                                //nolint:errcheck
+		case methodCancel:
+			return 0
+		}
+	}
+	return 0
+}
+
+func enablePlugins(t *testing.T, dir string) {
+	cfg := config.Mock()
+	cfg.Set("plugin_checks_enabled", true)
+	cfg.Set("plugin_checks_dir", dir)
+	t.Cleanup(func() {
+		cfg.Set("plugin_checks_enabled", false)
+		cfg.Set("plugin_checks_dir", "")
+	})
+}
+
+func loadFakePlugin(t *testing.T, mode string, instance string) (*PluginCheck, *mocksender.MockSender) {
+	t.Setenv(fakePluginEnv, mode)
+
+	executable, err := os.Executable()
+	require.NoError(t, err)
+
+	enablePlugins(t, filepath.Dir(executable))
+
+	loader, err := NewCheckLoader()
+	require.NoError(t, err)
+
+	initConfig := integration.Data(fmt.Sprintf("plugin_command: %s\nplugin_run_timeout: 10", filepath.Base(executable)))
+	sender := mocksender.NewMockSender(check.BuildID("fake", integration.Data(instance), initConfig))
+	sender.SetupAcceptAll()
+
+	c, err := loader.Load(integration.Config{Name: "fake", InitConfig: initConfig, Provider: names.File}, integration.Data(instance))
+	require.NoError(t, err)
+
+	pluginCheck := c.(*PluginCheck)
+	t.Cleanup(pluginCheck.Cancel)
+	return pluginCheck, sender
+}
+
+func TestPluginCheckRun(t *testing.T) {
+	c, sender := loadFakePlugin(t, "ok", "host: localhost")
+
+	require.NoError(t, c.Run())
+	assert.Equal(t, "1.2.3", c.Version())
+	sender.AssertMetric(t, "Gauge", "fake.runs", 1, "", []string{"mode:ok"})
+	sender.AssertServiceCheck(t, "fake.can_connect", metrics.ServiceCheckWarning, "", nil, "slow")
+	sender.AssertEvent(t, metrics.Event{Title: "fake", Text: "ran", Ts: 42}, 0)
+	sender.AssertNumberOfCalls(t, "Commit", 1)
+
+	warnings := c.GetWarnings()
+	require.Len(t, warnings, 2)
+	assert.Equal(t, "deprecated option", warnings[0].Error())
+	assert.Contains(t, warnings[1].Error(), `unknown type "unknown" for metric fake.invalid`)
+
+	// the same plugin process is used for the next runs
+	proc := c.proc
+	require.NoError(t, c.Run())
+	assert.Same(t, proc, c.proc)
+	sender.AssertMetric(t, "Gauge", "fake.runs", 2, "", []string{"mode:ok"})
+
+	c.Cancel()
+	assert.Nil(t, c.proc)
+	assert.False(t, proc.isRunning())
+}
+
+func TestPluginCheckTimeout(t *testing.T) {
+	c, sender := loadFakePlugin(t, "hang", "plugin_run_timeout: 1")
+	assert.Equal(t, time.Second, c.runTimeout)
+
+	err := c.Run()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "run request timed out after 1s")
+
+	// the stuck plugin was killed
+	assert.Nil(t, c.proc)
+	sender.AssertNumberOfCalls(t, "Commit", 1)
+}
+
+func TestPluginCheckSpawn(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("process groups are only killed on unix, and procfs is needed to check it")
+	}
+
+	c, _ := loadFakePlugin(t, "spawn", "plugin_run_timeout: 1")
+
+	done := make(chan error)
+	go func() { done <- c.Run() }()
+
+	select {
+	case err := <-done:
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "run request timed out after 1s")
+	case <-time.After(killTimeout):
+		require.FailNow(t, "the plugin wasn't killed")
+	}
+	assert.Nil(t, c.proc)
+
+	// the process spawned by the plugin was killed with it
+	warnings := c.GetWarnings()
+	require.Len(t, warnings, 1)
+	pid := warnings[0].Error()
+	assert.Eventually(t, func() bool {
+		stat, err := os.ReadFile(filepath.Join("/proc", pid, "stat"))
+		// killed processes may not be reaped in containers
+		return err != nil || strings.Contains(string(stat), ") Z ")
+	}, 5*time.Second, 100*time.Millisecond)
+}
+
+func TestPluginCheckCrash(t *testing.T) {
+	c, _ := loadFakePlugin(t, "crash", "")
+
+	err := c.Run()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "plugin exited: exit status 1")
+
+	// the plugin is restarted on the next run
+	crashed := c.proc
+	err = c.Run()
+	require.Error(t, err)
+	assert.NotSame(t, crashed, c.proc)
+}
+
+func TestLoadWithoutCommand(t *testing.T) {
+	enablePlugins(t, t.TempDir())
+
+	loader, err := NewCheckLoader()
+	require.NoError(t, err)
+
+	_, err = loader.Load(integration.Config{Name: "fake", InitConfig: integration.Data("{}"), Provider: names.File}, integration.Data("{}"))
+	assert.EqualError(t, err, "no plugin_command configured for check fake")
+
+	_, err = loader.Load(integration.Config{Name: "fake", InitConfig: integration.Data("plugin_command: does_not_exist"), Provider: names.File}, integration.Data("{}"))
+	assert.Error(t, err)
+}
+
+func TestLoadRestrictions(t *testing.T) {
+	executable, err := os.Executable()
+	require.NoError(t, err)
+	dir, command := filepath.Dir(executable), filepath.Base(executable)
+
+	loader, err := NewCheckLoader()
+	require.NoError(t, err)
+	load := func(provider string, command string) error {
+		initConfig := integration.Data(fmt.Sprintf("plugin_command: %s", command))
+		_, err := loader.Load(integration.Config{Name: "fake", InitConfig: initConfig, Provider: provider}, integration.Data("{}"))
+		return err
+	}
+
+	// disabled by default
+	config.Mock()
+	err = load(names.File, command)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "plugin checks are disabled")
+
+	enablePlugins(t, dir)
+
+	// autodiscovery can't configure plugins
+	err = load(names.Container, command)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "must be configured in a file")
+
+	// commands are resolved in plugin_checks_dir only
+	err = load(names.File, executable)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "must be relative to plugin_checks_dir")
+
+	err = load(names.File, filepath.Join("..", filepath.Base(dir), command))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "must be in plugin_checks_dir")
+
+	err = load(names.File, "sh")
+	require.Error(t, err)
+}
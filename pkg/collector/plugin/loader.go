@@ -0,0 +1,103 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package plugin
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	yaml "gopkg.in/yaml.v2"
+
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/providers/names"
+	"github.com/DataDog/datadog-agent/pkg/collector/check"
+	"github.com/DataDog/datadog-agent/pkg/collector/loaders"
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// CheckLoader is a specific loader for checks implemented by executables
+// configured with `plugin_command` in their init_config. It is disabled unless
+// `plugin_checks_enabled` is set, only loads the configs read from files, and
+// only runs the executables found in `plugin_checks_dir`.
+type CheckLoader struct{}
+
+// NewCheckLoader creates a loader for plugin checks
+func NewCheckLoader() (*CheckLoader, error) {
+	return &CheckLoader{}, nil
+}
+
+// Name returns the plugin loader name
+func (cl *CheckLoader) Name() string {
+	return "plugin"
+}
+
+// Load returns a check running the executable configured in the init_config
+func (cl *CheckLoader) Load(conf integration.Config, instance integration.Data) (check.Check, error) {
+	pluginConf := pluginConfig{}
+	if err := yaml.Unmarshal(conf.InitConfig, &pluginConf); err != nil {
+		return nil, err
+	}
+	if pluginConf.Command == "" {
+		return nil, fmt.Errorf("no plugin_command configured for check %s", conf.Name)
+	}
+
+	if !config.Datadog.GetBool("plugin_checks_enabled") {
+		return nil, fmt.Errorf("plugin checks are disabled, set plugin_checks_enabled to load check %s", conf.Name)
+	}
+	// plugins can run any executable with any arguments, they can't be configured by autodiscovery
+	if conf.Provider != names.File {
+		return nil, fmt.Errorf("plugin check %s must be configured in a file, not by the %q provider", conf.Name, conf.Provider)
+	}
+
+	command, err := resolveCommand(config.Datadog.GetString("plugin_checks_dir"), pluginConf.Command)
+	if err != nil {
+		return nil, fmt.Errorf("could not find the plugin of check %s: %s", conf.Name, err)
+	}
+
+	c := newPluginCheck(conf.Name, command, pluginConf.Args)
+	if err := c.Configure(instance, conf.InitConfig, conf.Source); err != nil {
+		log.Errorf("plugin.loader: could not configure check %s: %s", c, err)
+		return c, fmt.Errorf("Could not configure check %s: %s", c, err)
+	}
+
+	return c, nil
+}
+
+// resolveCommand returns the path of the executable command, relative to dir. Absolute
+// paths and paths going out of dir are rejected.
+func resolveCommand(dir string, command string) (string, error) {
+	if dir == "" {
+		return "", errors.New("plugin_checks_dir is not set")
+	}
+	if filepath.IsAbs(command) {
+		return "", fmt.Errorf("plugin_command %s must be relative to plugin_checks_dir", command)
+	}
+	for _, part := range strings.Split(filepath.ToSlash(command), "/") {
+		if part == ".." {
+			return "", fmt.Errorf("plugin_command %s must be in plugin_checks_dir", command)
+		}
+	}
+
+	// LookPath checks that the file is executable, the path is never searched in $PATH
+	// as it holds a separator
+	return exec.LookPath(filepath.Join(dir, filepath.Clean(command)))
+}
+
+func (cl *CheckLoader) String() string {
+	return "Plugin Check Loader"
+}
+
+func init() {
+	factory := func() (check.Loader, error) {
+		return NewCheckLoader()
+	}
+
+	loaders.RegisterLoader(40, factory)
+}
@@ -0,0 +1,270 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package plugin
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"sync"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// maxMessageSize is the maximum size of a line written by a plugin
+const maxMessageSize = 1024 * 1024
+
+const (
+	// drainTimeout is how long the output of an exited plugin is read, the processes
+	// it spawned can keep the pipes open
+	drainTimeout = time.Second
+	// killTimeout is how long kill waits for the plugin to exit
+	killTimeout = 5 * time.Second
+)
+
+var errTimeout = errors.New("timed out")
+
+// process is a running plugin executable. Requests are written as JSON lines on its
+// standard input and messages are read as JSON lines from its standard output, its
+// standard error is forwarded to the agent logs.
+type process struct {
+	name     string
+	cmd      *exec.Cmd
+	messages chan *message
+
+	// writeLock protects the standard input of the process
+	writeLock sync.Mutex
+	stdin     io.WriteCloser
+	encoder   *json.Encoder
+	nextID    uint64
+
+	// quit is closed to discard the messages not read yet
+	quit     chan struct{}
+	quitOnce sync.Once
+
+	// exited is closed once the process exited, exitErr is set before
+	exited  chan struct{}
+	exitErr error
+}
+
+func startProcess(name string, command string, args []string) (*process, error) {
+	cmd := exec.Command(command, args...)
+	setProcessGroup(cmd)
+
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+
+	// the output pipes are not created with StdoutPipe and StderrPipe, as Wait would
+	// then wait for them to be closed by all the processes holding them
+	stdout, stdoutWriter, err := os.Pipe()
+	if err != nil {
+		return nil, err
+	}
+	stderr, stderrWriter, err := os.Pipe()
+	if err != nil {
+		stdout.Close()
+		stdoutWriter.Close()
+		return nil, err
+	}
+	cmd.Stdout = stdoutWriter
+	cmd.Stderr = stderrWriter
+
+	err = cmd.Start()
+	// the write ends are only used by the plugin
+	stdoutWriter.Close()
+	stderrWriter.Close()
+	if err != nil {
+		stdout.Close()
+		stderr.Close()
+		return nil, fmt.Errorf("unable to start %s: %w", command, err)
+	}
+
+	p := &process{
+		name:     name,
+		cmd:      cmd,
+		stdin:    stdin,
+		encoder:  json.NewEncoder(stdin),
+		messages: make(chan *message),
+		quit:     make(chan struct{}),
+		exited:   make(chan struct{}),
+	}
+
+	var readers sync.WaitGroup
+	readersDone := make(chan struct{})
+	readers.Add(2)
+	go func() {
+		defer readers.Done()
+		p.readMessages(stdout)
+	}()
+	go func() {
+		defer readers.Done()
+		p.forwardLogs(stderr)
+	}()
+	go func() {
+		readers.Wait()
+		close(readersDone)
+	}()
+
+	go func() {
+		p.exitErr = cmd.Wait()
+		close(p.exited)
+
+		// the last messages are read until the pipes are closed, unless processes
+		// spawned by the plugin keep them open
+		select {
+		case <-readersDone:
+		case <-time.After(drainTimeout):
+			log.Debugf("plugin %s exited but its output is still open, closing it", p.name)
+			stdout.Close()
+			stderr.Close()
+			<-readersDone
+		}
+		stdout.Close()
+		stderr.Close()
+		close(p.messages)
+	}()
+
+	return p, nil
+}
+
+func (p *process) readMessages(stdout io.Reader) {
+	scanner := bufio.NewScanner(stdout)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxMessageSize)
+	for scanner.Scan() {
+		m := &message{}
+		if err := json.Unmarshal(scanner.Bytes(), m); err != nil {
+			log.Warnf("plugin %s: ignoring invalid message: %v", p.name, err)
+			continue
+		}
+		select {
+		case p.messages <- m:
+		case <-p.quit:
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		log.Warnf("plugin %s: unable to read the output: %v", p.name, err)
+		// don't block the plugin on a full pipe, it gets killed by the next timeout
+		io.Copy(io.Discard, stdout) //nolint:errcheck
+	}
+}
+
+func (p *process) forwardLogs(stderr io.Reader) {
+	scanner := bufio.NewScanner(stderr)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxMessageSize)
+	for scanner.Scan() {
+		log.Infof("plugin %s | %s", p.name, scanner.Text())
+	}
+	io.Copy(io.Discard, stderr) //nolint:errcheck
+}
+
+// call sends a request to the plugin and waits for its result. The other messages
+// received in the meantime are passed to handle.
+func (p *process) call(method string, params *configParam, timeout time.Duration, handle func(*message)) (*message, error) {
+	id, err := p.send(method, params)
+	if err != nil {
+		return nil, fmt.Errorf("unable to send the %s request: %w", method, p.exitError(err))
+	}
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	for {
+		select {
+		case m, ok := <-p.messages:
+			if !ok {
+				return nil, p.exitError(nil)
+			}
+			if m.Type != messageResult {
+				handle(m)
+				continue
+			}
+			if m.ID != id {
+				log.Debugf("plugin %s: ignoring the result of request %d", p.name, m.ID)
+				continue
+			}
+			if m.Error != "" {
+				return m, errors.New(m.Error)
+			}
+			return m, nil
+		case <-timer.C:
+			return nil, fmt.Errorf("%s request %w after %s", method, errTimeout, timeout)
+		}
+	}
+}
+
+// send writes a request on the standard input of the plugin
+func (p *process) send(method string, params *configParam) (uint64, error) {
+	p.writeLock.Lock()
+	defer p.writeLock.Unlock()
+
+	p.nextID++
+	return p.nextID, p.encoder.Encode(&request{ID: p.nextID, Method: method, Params: params})
+}
+
+// isRunning returns whether the process hasn't exited yet
+func (p *process) isRunning() bool {
+	select {
+	case <-p.exited:
+		return false
+	default:
+		return true
+	}
+}
+
+// exitError returns the reason why the process exited, or err if it's still running
+func (p *process) exitError(err error) error {
+	select {
+	case <-p.exited:
+		if p.exitErr != nil {
+			return fmt.Errorf("plugin exited: %w", p.exitErr)
+		}
+		return errors.New("plugin exited")
+	default:
+		return err
+	}
+}
+
+// kill kills the process and the processes it spawned, and waits for it to exit
+func (p *process) kill() {
+	p.quitOnce.Do(func() { close(p.quit) })
+	if p.isRunning() {
+		if err := killProcessGroup(p.cmd); err != nil {
+			p.cmd.Process.Kill() //nolint:errcheck
+		}
+	}
+
+	select {
+	case <-p.exited:
+	case <-time.After(killTimeout):
+		log.Warnf("plugin %s didn't exit within %s after being killed", p.name, killTimeout)
+	}
+}
+
+// shutdown asks the plugin to cancel the check and closes its standard input,
+// the process is killed if it doesn't exit within grace
+func (p *process) shutdown(grace time.Duration) {
+	p.quitOnce.Do(func() { close(p.quit) })
+	if p.isRunning() {
+		p.send(methodCancel, nil) //nolint:errcheck
+		p.writeLock.Lock()
+		p.stdin.Close()
+		p.writeLock.Unlock()
+	}
+
+	select {
+	case <-p.exited:
+	case <-time.After(grace):
+		log.Warnf("plugin %s didn't exit within %s, killing it", p.name, grace)
+		p.kill()
+	}
+}
@@ -0,0 +1,21 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build windows
+// +build windows
+
+package plugin
+
+import (
+	"os/exec"
+)
+
+// setProcessGroup is a no-op, process groups are not supported on Windows
+func setProcessGroup(cmd *exec.Cmd) {}
+
+// killProcessGroup kills the plugin, the processes it spawned are left running
+func killProcessGroup(cmd *exec.Cmd) error {
+	return cmd.Process.Kill()
+}
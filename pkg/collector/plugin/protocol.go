@@ -0,0 +1,120 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package plugin
+
+import (
+	"fmt"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator"
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+)
+
+// protocolVersion is the version of the protocol spoken with the plugins, sent
+// in the configure request
+const protocolVersion = 1
+
+// Methods of the requests sent by the agent to a plugin
+const (
+	methodConfigure = "configure"
+	methodRun       = "run"
+	methodCancel    = "cancel"
+)
+
+// Types of the messages sent by a plugin to the agent
+const (
+	messageResult       = "result"
+	messageMetric       = "metric"
+	messageServiceCheck = "service_check"
+	messageEvent        = "event"
+	messageWarning      = "warning"
+)
+
+// request is a line written by the agent on the standard input of a plugin
+type request struct {
+	ID     uint64       `json:"id"`
+	Method string       `json:"method"`
+	Params *configParam `json:"params,omitempty"`
+}
+
+// configParam holds the parameters of the configure request
+type configParam struct {
+	ProtocolVersion int    `json:"protocol_version"`
+	CheckID         string `json:"check_id"`
+	Name            string `json:"name"`
+	InitConfig      string `json:"init_config"`
+	Instance        string `json:"instance"`
+	Source          string `json:"source"`
+}
+
+// message is a line written by a plugin on its standard output
+type message struct {
+	Type string `json:"type"`
+
+	// result, the version is only set in the result of the configure request
+	ID      uint64 `json:"id,omitempty"`
+	Error   string `json:"error,omitempty"`
+	Version string `json:"version,omitempty"`
+
+	// metric, service_check and warning
+	Name            string   `json:"name,omitempty"`
+	MetricType      string   `json:"metric_type,omitempty"`
+	Value           float64  `json:"value,omitempty"`
+	FlushFirstValue bool     `json:"flush_first_value,omitempty"`
+	Status          int      `json:"status,omitempty"`
+	Message         string   `json:"message,omitempty"`
+	Hostname        string   `json:"hostname,omitempty"`
+	Tags            []string `json:"tags,omitempty"`
+
+	// event
+	Event *metrics.Event `json:"event,omitempty"`
+}
+
+// submit forwards a metric, service check or event message to the sender
+func (m *message) submit(sender aggregator.Sender) error {
+	switch m.Type {
+	case messageMetric:
+		return m.submitMetric(sender)
+	case messageServiceCheck:
+		if m.Status < int(metrics.ServiceCheckOK) || m.Status > int(metrics.ServiceCheckUnknown) {
+			return fmt.Errorf("invalid status %d for service check %s", m.Status, m.Name)
+		}
+		sender.ServiceCheck(m.Name, metrics.ServiceCheckStatus(m.Status), m.Hostname, m.Tags, m.Message)
+	case messageEvent:
+		if m.Event == nil {
+			return fmt.Errorf("event message without event")
+		}
+		sender.Event(*m.Event)
+	default:
+		return fmt.Errorf("unknown message type %q", m.Type)
+	}
+	return nil
+}
+
+func (m *message) submitMetric(sender aggregator.Sender) error {
+	if m.Name == "" {
+		return fmt.Errorf("metric message without name")
+	}
+
+	switch m.MetricType {
+	case "gauge":
+		sender.Gauge(m.Name, m.Value, m.Hostname, m.Tags)
+	case "rate":
+		sender.Rate(m.Name, m.Value, m.Hostname, m.Tags)
+	case "count":
+		sender.Count(m.Name, m.Value, m.Hostname, m.Tags)
+	case "monotonic_count":
+		sender.MonotonicCountWithFlushFirstValue(m.Name, m.Value, m.Hostname, m.Tags, m.FlushFirstValue)
+	case "counter":
+		sender.Counter(m.Name, m.Value, m.Hostname, m.Tags)
+	case "histogram":
+		sender.Histogram(m.Name, m.Value, m.Hostname, m.Tags)
+	case "historate":
+		sender.Historate(m.Name, m.Value, m.Hostname, m.Tags)
+	default:
+		return fmt.Errorf("unknown type %q for metric %s", m.MetricType, m.Name)
+	}
+	return nil
+}
@@ -44,6 +44,7 @@ type PythonCheck struct {
 	class        *C.rtloader_pyobject_t
 	ModuleName   string
 	interval     time.Duration
+	timeout      time.Duration
 	lastWarnings []error
 	source       string
 	telemetry    bool // whether or not the telemetry is enabled for this check
@@ -118,7 +119,8 @@ func (c *PythonCheck) RunSimple() error {
 	return c.runCheck(false)
 }
 
-// Stop does nothing
+// Stop does nothing, Python checks can't be interrupted: a run that timed out
+// keeps running in the background until it returns
 func (c *PythonCheck) Stop() {}
 
 // Cancel signals to a python check that he can free all internal resources and
@@ -148,6 +150,11 @@ func (c *PythonCheck) Version() string {
 	return c.version
 }
 
+// LoaderName returns the name of the Python check loader
+func (c *PythonCheck) LoaderName() string {
+	return "python"
+}
+
 // IsTelemetryEnabled returns if the telemetry is enabled for this check
 func (c *PythonCheck) IsTelemetryEnabled() bool {
 	return c.telemetry
@@ -227,6 +234,11 @@ func (c *PythonCheck) Configure(data integration.Data, initConfig integration.Da
 		c.interval = time.Duration(commonOptions.MinCollectionInterval) * time.Second
 	}
 
+	// See if a run timeout was specified
+	if commonOptions.CheckTimeout > 0 {
+		c.timeout = time.Duration(commonOptions.CheckTimeout) * time.Second
+	}
+
 	// Disable default hostname if specified
 	if commonOptions.EmptyDefaultHostname {
 		s, err := aggregator.GetSender(c.id)
@@ -311,6 +323,11 @@ func (c *PythonCheck) Interval() time.Duration {
 	return c.interval
 }
 
+// Timeout returns the run timeout of the check, 0 if it isn't set
+func (c *PythonCheck) Timeout() time.Duration {
+	return c.timeout
+}
+
 // ID returns the ID of the check
 func (c *PythonCheck) ID() check.ID {
 	return c.id
@@ -24,6 +24,8 @@ const (
 	runningChecksExpvarKey = "RunningChecks"
 	runsExpvarKey          = "Runs"
 	runningExpvarKey       = "Running"
+	timedOutRunsExpvarKey  = "TimedOutRuns"
+	abandonedRunsExpvarKey = "AbandonedRuns"
 	warningsExpvarKey      = "Warnings"
 )
 
@@ -79,6 +81,8 @@ func Reset() {
 		errorsExpvarKey,
 		runsExpvarKey,
 		runningChecksExpvarKey,
+		timedOutRunsExpvarKey,
+		abandonedRunsExpvarKey,
 		warningsExpvarKey,
 	} {
 		runnerStats.Delete(key)
@@ -221,6 +225,36 @@ func GetRunningCheckCount() int64 {
 	return count.(*expvar.Int).Value()
 }
 
+// AddTimedOutRunsCount is used to increment and decrement the 'TimedOutRuns' expvar,
+// the number of runs that timed out and are still in progress
+func AddTimedOutRunsCount(amount int) {
+	runnerStats.Add(timedOutRunsExpvarKey, int64(amount))
+}
+
+// GetTimedOutRunsCount is used to get the value of 'TimedOutRuns' expvar
+func GetTimedOutRunsCount() int64 {
+	count := runnerStats.Get(timedOutRunsExpvarKey)
+	if count == nil {
+		return 0
+	}
+	return count.(*expvar.Int).Value()
+}
+
+// AddAbandonedRunsCount is used to increment the 'AbandonedRuns' expvar, the total
+// number of runs that timed out and that the workers stopped waiting for
+func AddAbandonedRunsCount(amount int) {
+	runnerStats.Add(abandonedRunsExpvarKey, int64(amount))
+}
+
+// GetAbandonedRunsCount is used to get the value of 'AbandonedRuns' expvar
+func GetAbandonedRunsCount() int64 {
+	count := runnerStats.Get(abandonedRunsExpvarKey)
+	if count == nil {
+		return 0
+	}
+	return count.(*expvar.Int).Value()
+}
+
 // AddRunsCount is used to increment and decrement the 'Runs' expvar
 func AddRunsCount(amount int) {
 	runnerStats.Add(runsExpvarKey, int64(amount))
@@ -239,6 +239,11 @@ func (r *Runner) getScheduler() *scheduler.Scheduler {
 	return r.scheduler
 }
 
+// RunningChecks returns the checks currently running
+func (r *Runner) RunningChecks() map[check.ID]check.Check {
+	return r.checksTracker.RunningChecks()
+}
+
 // ShouldAddCheckStats returns true if check stats should be preserved or not
 func (r *Runner) ShouldAddCheckStats(id check.ID) bool {
 	r.schedulerLock.RLock()
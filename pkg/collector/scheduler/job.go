@@ -7,6 +7,9 @@ package scheduler
 
 import (
 	"fmt"
+	"hash/fnv"
+	"math/rand"
+	"sort"
 	"sync"
 	"time"
 
@@ -67,18 +70,21 @@ type jobQueue struct {
 	currentBucketIdx    uint
 	schedulingBucketIdx uint
 	running             bool
+	hashPlacement       bool          // place the checks in the buckets from a hash of their ID
+	deferred            []check.Check // checks not dispatched at the last tick because of the concurrency limits
 	health              *health.Handle
 	mu                  sync.RWMutex // to protect critical sections in struct's fields
 }
 
 // newJobQueue creates a new jobQueue instance
-func newJobQueue(interval time.Duration) *jobQueue {
+func newJobQueue(interval time.Duration, hashPlacement bool) *jobQueue {
 	jq := &jobQueue{
-		interval:     interval,
-		stop:         make(chan bool),
-		stopped:      make(chan bool),
-		health:       health.RegisterLiveness(fmt.Sprintf("collector-queue-%vs", interval.Seconds())),
-		bucketTicker: time.NewTicker(time.Second),
+		interval:      interval,
+		stop:          make(chan bool),
+		stopped:       make(chan bool),
+		health:        health.RegisterLiveness(fmt.Sprintf("collector-queue-%vs", interval.Seconds())),
+		bucketTicker:  time.NewTicker(time.Second),
+		hashPlacement: hashPlacement,
 	}
 
 	var nb int
@@ -118,6 +124,12 @@ func (jq *jobQueue) addJob(c check.Check) {
 	jq.mu.Lock()
 	defer jq.mu.Unlock()
 
+	// With hash placement, a check is always placed in the same bucket
+	if jq.hashPlacement {
+		jq.buckets[bucketIndex(c.ID(), len(jq.buckets))].addJob(c)
+		return
+	}
+
 	// Checks scheduled to buckets scheduled with sparse round-robin
 	jq.buckets[jq.schedulingBucketIdx].addJob(c)
 	jq.schedulingBucketIdx = (jq.schedulingBucketIdx + jq.sparseStep) % uint(len(jq.buckets))
@@ -142,16 +154,48 @@ func (jq *jobQueue) stats() map[string]interface{} {
 
 	nJobs := 0
 	nBuckets := 0
-	for _, bucket := range jq.buckets {
-		nJobs += bucket.size()
+	bucketSizes := make([]int, 0, len(jq.buckets))
+	placement := make(map[check.ID]int)
+	for i, bucket := range jq.buckets {
+		bucket.mu.RLock()
+		for _, c := range bucket.jobs {
+			placement[c.ID()] = i
+		}
+		size := len(bucket.jobs)
+		bucket.mu.RUnlock()
+
+		bucketSizes = append(bucketSizes, size)
+		nJobs += size
 		nBuckets++
 	}
 
 	return map[string]interface{}{
-		"Interval": jq.interval / time.Second,
-		"Buckets":  nBuckets,
-		"Size":     nJobs,
+		"Interval":      jq.interval / time.Second,
+		"Buckets":       nBuckets,
+		"Size":          nJobs,
+		"HashPlacement": jq.hashPlacement,
+		"BucketSizes":   bucketSizes,
+		"Placement":     placement,
+		"Deferred":      len(jq.deferred),
+	}
+}
+
+// bucketIndex returns the bucket of a check with hash placement, its offset
+// in seconds in the interval
+func bucketIndex(id check.ID, nBuckets int) int {
+	h := fnv.New32a()
+	h.Write([]byte(id)) //nolint:errcheck
+	return int(h.Sum32() % uint32(nBuckets))
+}
+
+// jitterOffsets returns n sorted random offsets within a bucket tick
+func jitterOffsets(n int) []time.Duration {
+	offsets := make([]time.Duration, n)
+	for i := range offsets {
+		offsets[i] = time.Duration(rand.Int63n(int64(time.Second)))
 	}
+	sort.Slice(offsets, func(i, j int) bool { return offsets[i] < offsets[j] })
+	return offsets
 }
 
 // run schedules the checks in the queue by posting them to the
@@ -184,21 +228,56 @@ func (jq *jobQueue) process(s *Scheduler) bool {
 		}
 		jq.lastTick = t
 		bucket := jq.buckets[jq.currentBucketIdx]
+		deferred := jq.deferred
+		jq.deferred = nil
 		jq.mu.Unlock()
 
 		bucket.mu.RLock()
 		// we have to copy to avoid blocking the bucket :(
 		// blocking could interfere with scheduling new jobs
+		// the checks deferred at the previous tick are dispatched first
 		jobs := []check.Check{}
+		jobs = append(jobs, deferred...)
 		jobs = append(jobs, bucket.jobs...)
 		bucket.mu.RUnlock()
 
 		log.Tracef("Jobs in bucket: %v", jobs)
 
-		for _, check := range jobs {
+		var offsets []time.Duration
+		if s.jitter {
+			offsets = jitterOffsets(len(jobs))
+		}
+		round := s.limiter.newRound()
+		seen := make(map[check.ID]struct{}, len(deferred))
+		var deferredNext []check.Check
+
+		for i, check := range jobs {
 			if !s.IsCheckScheduled(check.ID()) {
 				continue
 			}
+			// a check deferred for a whole interval is also in the bucket
+			if len(deferred) > 0 {
+				if _, found := seen[check.ID()]; found {
+					continue
+				}
+				seen[check.ID()] = struct{}{}
+			}
+
+			if offsets != nil {
+				select {
+				case <-time.After(time.Until(t.Add(offsets[i]))):
+				case <-jq.stop:
+					jq.health.Deregister() //nolint:errcheck
+					return false
+				}
+			}
+
+			if loader, ok := round.admit(check); !ok {
+				log.Debugf("Too many checks of the %s loader running, deferring check %s to the next tick", loader, check.ID())
+				schedulerDeferredRuns.Add(loader, 1)
+				deferredNext = append(deferredNext, check)
+				continue
+			}
 
 			select {
 			// blocking, we'll be here as long as it takes
@@ -215,6 +294,7 @@ func (jq *jobQueue) process(s *Scheduler) bool {
 			}
 		}
 		jq.mu.Lock()
+		jq.deferred = deferredNext
 		jq.currentBucketIdx = (jq.currentBucketIdx + 1) % uint(len(jq.buckets))
 		jq.mu.Unlock()
 	case <-jq.health.C:
@@ -6,12 +6,14 @@
 package scheduler
 
 import (
+	"fmt"
 	"runtime"
 	"testing"
 	"time"
 
 	"github.com/DataDog/datadog-agent/pkg/collector/check"
 	"github.com/DataDog/datadog-agent/pkg/util/testutil"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
@@ -65,3 +67,91 @@ func TestBucket_RemoveJob(t *testing.T) {
 	// use the bucket, just to keep it alive during the earlier GC run
 	bucket.addJob(&TestJobCheck{id: "here so the GC doesn't GC the entire bucket"})
 }
+
+type TestLoaderCheck struct {
+	TestJobCheck
+	loader string
+}
+
+func (c *TestLoaderCheck) LoaderName() string { return c.loader }
+
+func TestJobQueueHashPlacement(t *testing.T) {
+	jq := newJobQueue(15*time.Second, true)
+
+	for i := 0; i < 300; i++ {
+		jq.addJob(&TestJobCheck{id: fmt.Sprintf("check:%d", i)})
+	}
+
+	// the checks are spread over all the buckets, in the bucket given by their ID
+	stats := jq.stats()
+	placement := stats["Placement"].(map[check.ID]int)
+	require.Len(t, placement, 300)
+	for id, idx := range placement {
+		assert.Equal(t, bucketIndex(id, 15), idx)
+	}
+	for _, size := range stats["BucketSizes"].([]int) {
+		assert.NotZero(t, size)
+	}
+
+	// a rescheduled check goes back to the same bucket
+	idx := placement["check:42"]
+	require.NoError(t, jq.removeJob("check:42"))
+	jq.addJob(&TestJobCheck{id: "check:42"})
+	assert.Equal(t, idx, jq.stats()["Placement"].(map[check.ID]int)["check:42"])
+}
+
+func TestJitterOffsets(t *testing.T) {
+	offsets := jitterOffsets(50)
+	require.Len(t, offsets, 50)
+	for i, offset := range offsets {
+		assert.True(t, offset >= 0 && offset < time.Second)
+		if i > 0 {
+			assert.True(t, offset >= offsets[i-1])
+		}
+	}
+}
+
+func TestJobQueueConcurrencyLimit(t *testing.T) {
+	pipe := make(chan check.Check)
+	s := NewScheduler(pipe)
+	s.limiter = newConcurrencyLimiter(map[string]interface{}{"python": 2})
+
+	running := map[check.ID]check.Check{"running": &TestLoaderCheck{TestJobCheck{id: "running"}, "python"}}
+	s.SetRunningChecksFunc(func() map[check.ID]check.Check { return running })
+
+	jq := newJobQueue(time.Second, false)
+	ticks := make(chan time.Time)
+	jq.bucketTicker = &time.Ticker{C: ticks}
+	for _, c := range []check.Check{
+		&TestLoaderCheck{TestJobCheck{id: "py1"}, "python"},
+		&TestLoaderCheck{TestJobCheck{id: "py2"}, "python"},
+		&TestLoaderCheck{TestJobCheck{id: "core"}, "core"},
+	} {
+		jq.addJob(c)
+		s.checkToQueue[c.ID()] = jq
+	}
+
+	go func() {
+		for jq.process(s) {
+		}
+	}()
+	defer close(jq.stop)
+
+	// only one python check can be dispatched along the running one
+	ticks <- time.Now()
+	assert.Equal(t, check.ID("py1"), (<-pipe).ID())
+	assert.Equal(t, check.ID("core"), (<-pipe).ID())
+
+	// the deferred check is dispatched first once a python check completed,
+	// and isn't dispatched twice
+	running = map[check.ID]check.Check{}
+	ticks <- time.Now()
+	assert.Equal(t, check.ID("py2"), (<-pipe).ID())
+	assert.Equal(t, check.ID("py1"), (<-pipe).ID())
+	assert.Equal(t, check.ID("core"), (<-pipe).ID())
+
+	ticks <- time.Now()
+	assert.Equal(t, check.ID("py1"), (<-pipe).ID())
+	assert.Equal(t, check.ID("py2"), (<-pipe).ID())
+	assert.Equal(t, check.ID("core"), (<-pipe).ID())
+}
@@ -0,0 +1,104 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package scheduler
+
+import (
+	"fmt"
+	"strconv"
+	"sync"
+
+	"github.com/DataDog/datadog-agent/pkg/collector/check"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// concurrencyLimiter limits the number of checks of a loader running at the same time
+type concurrencyLimiter struct {
+	limits map[string]int
+
+	// runningChecks returns the checks currently running, the limits aren't
+	// enforced until it's set
+	runningChecks func() map[check.ID]check.Check
+	mu            sync.RWMutex
+}
+
+// newConcurrencyLimiter parses the limits, a map of loader names to the maximum
+// number of checks running concurrently
+func newConcurrencyLimiter(limits map[string]interface{}) *concurrencyLimiter {
+	l := &concurrencyLimiter{
+		limits: make(map[string]int, len(limits)),
+	}
+
+	for loader, value := range limits {
+		limit, err := strconv.Atoi(fmt.Sprint(value))
+		if err != nil || limit <= 0 {
+			log.Warnf("Ignoring invalid concurrency limit %v for the %s check loader", value, loader)
+			continue
+		}
+		l.limits[loader] = limit
+	}
+
+	return l
+}
+
+func (l *concurrencyLimiter) setRunningChecksFunc(f func() map[check.ID]check.Check) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.runningChecks = f
+}
+
+// newRound returns the limiterRound used to dispatch the checks of a bucket
+func (l *concurrencyLimiter) newRound() *limiterRound {
+	return &limiterRound{limiter: l}
+}
+
+// loaderLimit returns the loader of c and its limit, 0 when it's not limited
+func (l *concurrencyLimiter) loaderLimit(c check.Check) (string, int) {
+	withLoader, ok := c.(check.WithLoaderName)
+	if !ok {
+		return "", 0
+	}
+	loader := withLoader.LoaderName()
+	return loader, l.limits[loader]
+}
+
+// limiterRound counts the running checks of each loader while the checks of
+// a bucket are dispatched. The running checks are listed once per round, the
+// dispatched checks are added to them as they may not be running yet.
+type limiterRound struct {
+	limiter *concurrencyLimiter
+	counts  map[string]int
+}
+
+// admit returns whether c can be dispatched, and counts it as running if so
+func (r *limiterRound) admit(c check.Check) (string, bool) {
+	loader, limit := r.limiter.loaderLimit(c)
+	if limit == 0 {
+		return loader, true
+	}
+
+	if r.counts == nil {
+		r.limiter.mu.RLock()
+		runningChecks := r.limiter.runningChecks
+		r.limiter.mu.RUnlock()
+		if runningChecks == nil {
+			return loader, true
+		}
+
+		r.counts = make(map[string]int)
+		for _, running := range runningChecks() {
+			if runningLoader, _ := r.limiter.loaderLimit(running); runningLoader != "" {
+				r.counts[runningLoader]++
+			}
+		}
+	}
+
+	if r.counts[loader] >= limit {
+		return loader, false
+	}
+	r.counts[loader]++
+	return loader, true
+}
@@ -0,0 +1,52 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package scheduler
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/DataDog/datadog-agent/pkg/collector/check"
+)
+
+func TestNewConcurrencyLimiter(t *testing.T) {
+	// the values come from YAML or from a JSON environment variable
+	l := newConcurrencyLimiter(map[string]interface{}{
+		"python": 4,
+		"core":   float64(8),
+		"plugin": "2",
+		"jmx":    0,
+		"other":  "many",
+	})
+
+	assert.Equal(t, map[string]int{"python": 4, "core": 8, "plugin": 2}, l.limits)
+}
+
+func TestLimiterRound(t *testing.T) {
+	l := newConcurrencyLimiter(map[string]interface{}{"python": 1})
+	py := &TestLoaderCheck{TestJobCheck{id: "py"}, "python"}
+	other := &TestJobCheck{id: "other"}
+
+	// the limits aren't enforced without the running checks
+	round := l.newRound()
+	_, ok := round.admit(py)
+	assert.True(t, ok)
+	_, ok = round.admit(py)
+	assert.True(t, ok)
+
+	l.setRunningChecksFunc(func() map[check.ID]check.Check { return nil })
+	round = l.newRound()
+	loader, ok := round.admit(py)
+	assert.Equal(t, "python", loader)
+	assert.True(t, ok)
+	_, ok = round.admit(py)
+	assert.False(t, ok)
+
+	// the checks without a loader name aren't limited
+	_, ok = round.admit(other)
+	assert.True(t, ok)
+}
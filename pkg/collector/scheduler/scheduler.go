@@ -12,6 +12,7 @@ import (
 	"sync/atomic"
 	"time"
 
+	"github.com/DataDog/datadog-agent/pkg/config"
 	"github.com/DataDog/datadog-agent/pkg/telemetry"
 	"github.com/DataDog/datadog-agent/pkg/util/log"
 
@@ -23,6 +24,7 @@ var (
 	schedulerExpvars       *expvar.Map
 	schedulerQueuesCount   = expvar.Int{}
 	schedulerChecksEntered = expvar.Int{}
+	schedulerDeferredRuns  = expvar.Map{}
 
 	tlmChecksEntered = telemetry.NewGauge("scheduler", "checks_entered",
 		[]string{"check_name"}, "How many checks are currently tracked by the scheduler")
@@ -34,6 +36,7 @@ func init() {
 	schedulerExpvars = expvar.NewMap("scheduler")
 	schedulerExpvars.Set("QueuesCount", &schedulerQueuesCount)
 	schedulerExpvars.Set("ChecksEntered", &schedulerChecksEntered)
+	schedulerExpvars.Set("DeferredRuns", &schedulerDeferredRuns)
 }
 
 // Scheduler keeps things rolling.
@@ -57,6 +60,10 @@ type Scheduler struct {
 
 	cancelOneTime chan bool      // Used to internally communicate a cancel signal to one-time schedule goroutines
 	wgOneTime     sync.WaitGroup // WaitGroup to track the exit of one-time schedule goroutines
+
+	hashPlacement bool                // Place the checks in the interval from a hash of their ID
+	jitter        bool                // Dispatch the checks of a bucket at random offsets within its tick
+	limiter       *concurrencyLimiter // Limit the number of checks of a loader running concurrently
 }
 
 // NewScheduler create a Scheduler and returns a pointer to it.
@@ -72,9 +79,18 @@ func NewScheduler(checksPipe chan<- check.Check) *Scheduler {
 		running:          0,
 		cancelOneTime:    make(chan bool),
 		wgOneTime:        sync.WaitGroup{},
+		hashPlacement:    config.Datadog.GetBool("check_scheduler_hash_placement"),
+		jitter:           config.Datadog.GetBool("check_scheduler_jitter"),
+		limiter:          newConcurrencyLimiter(config.Datadog.GetStringMap("check_scheduler_max_concurrency")),
 	}
 }
 
+// SetRunningChecksFunc sets the function listing the running checks, which
+// is required to enforce the concurrency limits of the check loaders
+func (s *Scheduler) SetRunningChecksFunc(f func() map[check.ID]check.Check) {
+	s.limiter.setRunningChecksFunc(f)
+}
+
 // Enter schedules a `Check`s for execution accordingly to the `Check.Interval()` value.
 // If the interval is 0, the check is supposed to run only once.
 func (s *Scheduler) Enter(check check.Check) error {
@@ -95,7 +111,7 @@ func (s *Scheduler) Enter(check check.Check) error {
 	defer s.mu.Unlock()
 
 	if _, ok := s.jobQueues[check.Interval()]; !ok {
-		s.jobQueues[check.Interval()] = newJobQueue(check.Interval())
+		s.jobQueues[check.Interval()] = newJobQueue(check.Interval(), s.hashPlacement)
 		s.startQueue(s.jobQueues[check.Interval()])
 		if check.IsTelemetryEnabled() {
 			tlmQueuesCount.Inc()
@@ -14,6 +14,7 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/collector/check"
 	"github.com/DataDog/datadog-agent/pkg/collector/runner/expvars"
 	"github.com/DataDog/datadog-agent/pkg/collector/runner/tracker"
+	"github.com/DataDog/datadog-agent/pkg/config"
 	"github.com/DataDog/datadog-agent/pkg/metrics"
 	"github.com/DataDog/datadog-agent/pkg/util"
 	"github.com/DataDog/datadog-agent/pkg/util/log"
@@ -21,10 +22,15 @@ import (
 
 const (
 	serviceCheckStatusKey = "datadog.agent.check_status"
+	// Message of the service check of the runs that timed out
+	timeoutServiceCheckMessage = check.TimeoutStatus
 
 	// Variables for the utilization expvars
 	windowSize      = 5 * time.Minute
 	pollingInterval = 15 * time.Second
+
+	// Maximum time a check is skipped after consecutive timed out runs
+	maxTimeoutBackoff = 30 * time.Minute
 )
 
 // Worker is an object that encapsulates the logic to manage a loop of processing
@@ -34,6 +40,7 @@ type Worker struct {
 	Name string
 
 	checksTracker           *tracker.RunningChecksTracker
+	defaultCheckTimeout     time.Duration
 	getDefaultSenderFunc    func() (aggregator.Sender, error)
 	pendingChecksChan       chan check.Check
 	runnerID                int
@@ -102,6 +109,7 @@ func newWorkerWithOptions(
 		ID:                      ID,
 		Name:                    workerName,
 		checksTracker:           checksTracker,
+		defaultCheckTimeout:     time.Duration(config.Datadog.GetInt("check_timeout")) * time.Second,
 		pendingChecksChan:       pendingChecksChan,
 		runnerID:                runnerID,
 		shouldAddCheckStatsFunc: shouldAddCheckStatsFunc,
@@ -127,6 +135,11 @@ func (w *Worker) Run() {
 		checkLogger := CheckLogger{Check: check}
 		longRunning := check.Interval() == 0
 
+		if until := timeoutBackoff(check); time.Now().Before(until) {
+			checkLogger.Debug(fmt.Sprintf("Check runs timed out repeatedly, skipping execution until %s...", until.Format(time.RFC3339)))
+			continue
+		}
+
 		// Add check to tracker if it's not already running
 		if !w.checksTracker.AddCheck(check) {
 			checkLogger.Debug("Check is already running, skipping execution...")
@@ -144,13 +157,24 @@ func (w *Worker) Run() {
 
 		// Run the check
 		var checkErr error
-		checkErr = check.Run()
+		timedOut := false
+		if timeout := w.checkTimeout(check); timeout > 0 && !longRunning {
+			timedOut, checkErr = w.runWithTimeout(check, timeout)
+		} else {
+			checkErr = check.Run()
+		}
 
 		w.utilizationTracker.CheckFinished()
 
-		expvars.DeleteRunningStats(check.ID())
+		if !timedOut {
+			expvars.DeleteRunningStats(check.ID())
+		}
 
-		checkWarnings := check.GetWarnings()
+		// The warnings of a timed out check are collected by its next run as it's still running
+		var checkWarnings []error
+		if !timedOut {
+			checkWarnings = check.GetWarnings()
+		}
 
 		// Use the default sender for the service checks
 		sender, err := w.getDefaultSenderFunc()
@@ -167,19 +191,26 @@ func (w *Worker) Run() {
 			serviceCheckStatus = metrics.ServiceCheckWarning
 		}
 
+		serviceCheckMessage := ""
 		if checkErr != nil {
 			checkLogger.Error(checkErr)
 			expvars.AddErrorsCount(1)
 			serviceCheckStatus = metrics.ServiceCheckCritical
+			if timedOut {
+				serviceCheckMessage = timeoutServiceCheckMessage
+			}
 		}
 
 		if sender != nil && !longRunning {
-			sender.ServiceCheck(serviceCheckStatusKey, serviceCheckStatus, hostname, serviceCheckTags, "")
+			sender.ServiceCheck(serviceCheckStatusKey, serviceCheckStatus, hostname, serviceCheckTags, serviceCheckMessage)
 			sender.Commit()
 		}
 
-		// Remove the check from the running list
-		w.checksTracker.DeleteCheck(check.ID())
+		// Remove the check from the running list, a timed out check is removed
+		// once its run completes so that it isn't run concurrently
+		if !timedOut {
+			w.checksTracker.DeleteCheck(check.ID())
+		}
 
 		// Publish statistics about this run
 		expvars.AddRunningCheckCount(-1)
@@ -189,7 +220,7 @@ func (w *Worker) Run() {
 			// If the scheduler isn't assigned (it should), just add stats
 			// otherwise only do so if the check is in the scheduler
 			if w.shouldAddCheckStatsFunc(check.ID()) {
-				sStats, _ := check.GetSenderStats()
+				sStats := senderStats(check, timedOut)
 				expvars.AddCheckStats(check, time.Since(checkStartTime), checkErr, checkWarnings, sStats)
 			}
 		}
@@ -199,3 +230,86 @@ func (w *Worker) Run() {
 
 	log.Debugf("Runner %d, worker %d: Finished processing checks.", w.runnerID, w.ID)
 }
+
+// senderStats returns the sender stats of a check run, they are left empty
+// for a run that timed out as it still submits data
+func senderStats(c check.Check, timedOut bool) check.SenderStats {
+	if timedOut {
+		return check.NewSenderStats()
+	}
+	stats, _ := c.GetSenderStats()
+	return stats
+}
+
+// checkTimeout returns the run timeout of a check, falling back to the
+// `check_timeout` setting of the agent
+func (w *Worker) checkTimeout(c check.Check) time.Duration {
+	if withTimeout, ok := c.(check.WithTimeout); ok && withTimeout.Timeout() > 0 {
+		return withTimeout.Timeout()
+	}
+	return w.defaultCheckTimeout
+}
+
+// runWithTimeout runs a check and returns a `TimeoutError` if the run doesn't
+// complete in time. The run is then interrupted if the check supports it, the check
+// is asked to stop and the worker moves on. The check is kept in the running checks
+// until the run actually completes, so that runs of the same check never overlap.
+// Python checks can't be interrupted, their runs complete in the background.
+func (w *Worker) runWithTimeout(c check.Check, timeout time.Duration) (bool, error) {
+	done := make(chan error, 1)
+	go func() {
+		done <- c.Run()
+	}()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case err := <-done:
+		return false, err
+	case <-timer.C:
+	}
+
+	expvars.AddTimedOutRunsCount(1)
+	expvars.AddAbandonedRunsCount(1)
+	if interruptible, ok := c.(check.Interruptible); ok {
+		interruptible.InterruptRun()
+	}
+	go func() {
+		// Stop may block until the run is interrupted
+		c.Stop()
+	}()
+	go func() {
+		err := <-done
+		log.Infof("Timed out run of check %s completed after %s, error: %v", c.ID(), time.Since(expvars.GetRunningStats(c.ID())), err)
+
+		expvars.DeleteRunningStats(c.ID())
+		expvars.AddTimedOutRunsCount(-1)
+		w.checksTracker.DeleteCheck(c.ID())
+	}()
+
+	return true, &check.TimeoutError{Timeout: timeout}
+}
+
+// timeoutBackoff returns until when a check is skipped after runs that timed out
+// in a row: from the second one, it's skipped for 2^(n-1) intervals after the
+// last run, up to maxTimeoutBackoff.
+func timeoutBackoff(c check.Check) time.Time {
+	stats, found := expvars.CheckStats(c.ID())
+	if !found || c.Interval() == 0 {
+		return time.Time{}
+	}
+
+	consecutiveTimeouts, lastRun := stats.TimeoutState()
+	if consecutiveTimeouts < 2 {
+		return time.Time{}
+	}
+
+	backoff := maxTimeoutBackoff
+	if consecutiveTimeouts < 32 {
+		if b := c.Interval() * time.Duration(1<<(consecutiveTimeouts-1)); b > 0 && b < backoff {
+			backoff = b
+		}
+	}
+	return lastRun.Add(backoff)
+}
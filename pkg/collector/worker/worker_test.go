@@ -31,9 +31,12 @@ type testCheck struct {
 	doWarn      bool
 	id          string
 	longRunning bool
+	interval    time.Duration
+	timeout     time.Duration
 	t           *testing.T
 	runFunc     func(id check.ID)
 	runCount    uint64
+	interrupts  uint64
 }
 
 func (c *testCheck) ID() check.ID   { return check.ID(c.id) }
@@ -44,10 +47,17 @@ func (c *testCheck) Interval() time.Duration {
 	if c.longRunning {
 		return 0
 	}
+	if c.interval != 0 {
+		return c.interval
+	}
 
 	return 123
 }
 
+func (c *testCheck) Timeout() time.Duration { return c.timeout }
+
+func (c *testCheck) InterruptRun() { atomic.AddUint64(&c.interrupts, 1) }
+
 func (c *testCheck) GetWarnings() []error {
 	if c.doWarn {
 		return []error{fmt.Errorf("Warning")}
@@ -617,3 +627,137 @@ func TestWorkerServiceCheckSendingLongRunningTasks(t *testing.T) {
 	mockSender.AssertNumberOfCalls(t, "Commit", 0)
 	mockSender.AssertNumberOfCalls(t, "ServiceCheck", 0)
 }
+
+func TestWorkerCheckTimeout(t *testing.T) {
+	expvars.Reset()
+	config.Datadog.Set("hostname", "myhost")
+
+	checksTracker := tracker.NewRunningChecksTracker()
+	pendingChecksChan := make(chan check.Check, 10)
+	mockShouldAddStatsFunc := func(id check.ID) bool { return true }
+
+	release := make(chan struct{})
+	hangingCheck := newCheck(t, "hanging:123", false, func(check.ID) { <-release })
+	hangingCheck.timeout = 50 * time.Millisecond
+	goodCheck := newCheck(t, "goodcheck:123", false, nil)
+
+	pendingChecksChan <- hangingCheck
+	pendingChecksChan <- goodCheck
+	// the timed out run is still in progress, the check is skipped
+	pendingChecksChan <- hangingCheck
+	close(pendingChecksChan)
+
+	mockSender := mocksender.NewMockSender("")
+	mockSender.On("Commit").Return().Times(2)
+	mockSender.On(
+		"ServiceCheck",
+		serviceCheckStatusKey,
+		metrics.ServiceCheckCritical,
+		"myhost",
+		[]string{"check:hanging"},
+		"check_timeout",
+	).Return().Times(1)
+	mockSender.On(
+		"ServiceCheck",
+		serviceCheckStatusKey,
+		metrics.ServiceCheckOK,
+		"myhost",
+		[]string{"check:goodcheck"},
+		"",
+	).Return().Times(1)
+
+	worker, err := newWorkerWithOptions(
+		100,
+		200,
+		pendingChecksChan,
+		checksTracker,
+		mockShouldAddStatsFunc,
+		func() (aggregator.Sender, error) {
+			return mockSender, nil
+		},
+		windowSize,
+		pollingInterval,
+	)
+	require.Nil(t, err)
+
+	// The worker isn't blocked by the hanging check
+	worker.Run()
+
+	mockSender.AssertExpectations(t)
+	assert.Equal(t, 1, goodCheck.RunCount())
+	assert.Equal(t, 0, hangingCheck.RunCount())
+	assert.Equal(t, 2, int(expvars.GetRunsCount()))
+
+	stats, found := expvars.CheckStats(hangingCheck.ID())
+	require.True(t, found)
+	assert.Equal(t, 1, int(stats.TotalErrors))
+	assert.Equal(t, 1, int(stats.TotalTimeouts))
+	assert.Equal(t, 1, int(stats.ConsecutiveTimeouts))
+	assert.Equal(t, "check_timeout: the check run didn't complete within 50ms", stats.LastError)
+
+	assert.Equal(t, 1, int(expvars.GetTimedOutRunsCount()))
+	assert.Equal(t, 1, int(expvars.GetAbandonedRunsCount()))
+	assert.Equal(t, 1, int(atomic.LoadUint64(&hangingCheck.interrupts)))
+	assert.Equal(t, 0, int(atomic.LoadUint64(&goodCheck.interrupts)))
+	assert.Equal(t, 0, int(expvars.GetRunningCheckCount()))
+	_, running := checksTracker.Check(hangingCheck.ID())
+	assert.True(t, running)
+	assert.False(t, expvars.GetRunningStats(hangingCheck.ID()).IsZero())
+
+	// The run eventually completes
+	close(release)
+	assert.Eventually(t, func() bool {
+		_, running := checksTracker.Check(hangingCheck.ID())
+		return !running && expvars.GetTimedOutRunsCount() == 0
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, 1, hangingCheck.RunCount())
+	assert.True(t, expvars.GetRunningStats(hangingCheck.ID()).IsZero())
+	assert.Equal(t, 1, int(expvars.GetAbandonedRunsCount()))
+}
+
+func TestWorkerDefaultCheckTimeout(t *testing.T) {
+	config.Datadog.Set("check_timeout", 10)
+	defer config.Datadog.Set("check_timeout", 0)
+
+	worker, err := NewWorker(1, 2, make(chan check.Check), tracker.NewRunningChecksTracker(), func(id check.ID) bool { return true })
+	require.Nil(t, err)
+
+	assert.Equal(t, 10*time.Second, worker.checkTimeout(newCheck(t, "default:123", false, nil)))
+
+	withTimeout := newCheck(t, "custom:123", false, nil)
+	withTimeout.timeout = time.Second
+	assert.Equal(t, time.Second, worker.checkTimeout(withTimeout))
+}
+
+func TestTimeoutBackoff(t *testing.T) {
+	expvars.Reset()
+
+	c := newCheck(t, "backoff:123", false, nil)
+	c.interval = time.Minute
+	timeoutErr := &check.TimeoutError{Timeout: time.Second}
+
+	// no stats yet
+	assert.True(t, timeoutBackoff(c).IsZero())
+
+	// a single timeout doesn't delay the next run
+	expvars.AddCheckStats(c, time.Second, timeoutErr, nil, check.SenderStats{})
+	assert.True(t, timeoutBackoff(c).IsZero())
+
+	expvars.AddCheckStats(c, time.Second, timeoutErr, nil, check.SenderStats{})
+	stats, _ := expvars.CheckStats(c.ID())
+	lastRun := time.Unix(stats.UpdateTimestamp, 0)
+	assert.Equal(t, lastRun.Add(2*time.Minute), timeoutBackoff(c))
+
+	expvars.AddCheckStats(c, time.Second, timeoutErr, nil, check.SenderStats{})
+	assert.Equal(t, lastRun.Add(4*time.Minute), timeoutBackoff(c))
+
+	// the backoff is capped
+	for i := 0; i < 40; i++ {
+		expvars.AddCheckStats(c, time.Second, timeoutErr, nil, check.SenderStats{})
+	}
+	assert.Equal(t, lastRun.Add(maxTimeoutBackoff), timeoutBackoff(c))
+
+	// a run that doesn't time out resets the backoff
+	expvars.AddCheckStats(c, time.Second, fmt.Errorf("myerror"), nil, check.SenderStats{})
+	assert.True(t, timeoutBackoff(c).IsZero())
+}
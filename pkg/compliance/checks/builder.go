@@ -12,6 +12,8 @@ import (
 	"errors"
 	"fmt"
 	"io/ioutil"
+	"os"
+	"path/filepath"
 	"strings"
 	"time"
 
@@ -90,6 +92,28 @@ func WithHostRootMount(hostRootMount string) BuilderOption {
 	}
 }
 
+// WithRootFS configures the builder to evaluate checks against an offline root filesystem, like an
+// extracted container image, instead of the live host
+func WithRootFS(rootfs string) BuilderOption {
+	return func(b *builder) error {
+		info, err := os.Stat(rootfs)
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() {
+			return fmt.Errorf("root filesystem %s is not a directory", rootfs)
+		}
+
+		log.Infof("Checks will be evaluated against the root filesystem %s", rootfs)
+		b.rootfs = rootfs
+		b.pathMapper = &pathMapper{
+			hostMountPath: rootfs,
+		}
+		b.etcGroupPath = filepath.Join(rootfs, "/etc/group")
+		return nil
+	}
+}
+
 // WithDocker configures using docker
 func WithDocker() BuilderOption {
 	return func(b *builder) error {
@@ -172,6 +196,19 @@ func WithIsLeader(isLeader func() bool) BuilderOption {
 	}
 }
 
+// WithDriftDetection configures builder to only report the rule results that changed since the
+// previous run, the last result of each rule and resource being persisted in the run path
+func WithDriftDetection() BuilderOption {
+	return withDriftStore(persistentCacheStore{})
+}
+
+func withDriftStore(store driftStore) BuilderOption {
+	return func(b *builder) error {
+		b.driftDetector = newDriftDetector(store)
+		return nil
+	}
+}
+
 // SuiteMatcher checks if a compliance suite is included
 type SuiteMatcher func(*compliance.SuiteMeta) bool
 
@@ -284,6 +321,7 @@ type builder struct {
 	hostname     string
 	pathMapper   *pathMapper
 	etcGroupPath string
+	rootfs       string
 	nodeLabels   map[string]string
 
 	suiteMatcher SuiteMatcher
@@ -298,6 +336,8 @@ type builder struct {
 	regoInputDumpPath string
 
 	status *status
+
+	driftDetector *driftDetector
 }
 
 func (b *builder) Close() error {
@@ -560,6 +600,11 @@ func (b *builder) getRuleResourceReporter(scope compliance.RuleScope, rule compl
 }
 
 func (b *builder) hostMatcher(scope compliance.RuleScope, ruleID string, hostSelector string) (bool, error) {
+	// the scopes describe the live host, all the rules are evaluated against an offline root filesystem
+	if b.rootfs != "" {
+		return true, nil
+	}
+
 	switch scope {
 	case compliance.DockerScope:
 		if b.dockerClient == nil {
@@ -653,9 +698,14 @@ func (b *builder) nodeLabelKeys() []string {
 }
 
 func (b *builder) newCheck(meta *compliance.SuiteMeta, ruleScope compliance.RuleScope, rule *compliance.ConditionFallbackRule, handler resourceReporter) (compliance.Check, error) {
-	checkable, err := newResourceCheckList(b, rule.ID, rule.Resources)
-	if err != nil {
-		return nil, err
+	var checkable checkable
+	if kind := b.offlineUnsupportedKind(resourcesKinds(rule.Resources)); kind != "" {
+		checkable = newNotApplicableCheck(kind)
+	} else {
+		var err error
+		if checkable, err = newResourceCheckList(b, rule.ID, rule.Resources); err != nil {
+			return nil, err
+		}
 	}
 
 	var notify eventNotify
@@ -678,6 +728,8 @@ func (b *builder) newCheck(meta *compliance.SuiteMeta, ruleScope compliance.Rule
 		checkable:       checkable,
 
 		eventNotify: notify,
+
+		driftDetector: b.driftDetector,
 	}, nil
 }
 
@@ -691,6 +743,11 @@ func (b *builder) newRegoCheck(meta *compliance.SuiteMeta, ruleScope compliance.
 		return nil, err
 	}
 
+	var checkable checkable = regoCheck
+	if kind := b.offlineUnsupportedKind(regoInputsKinds(rule.Inputs)); kind != "" {
+		checkable = newNotApplicableCheck(kind)
+	}
+
 	var notify eventNotify
 	if b.status != nil {
 		notify = b.status.updateCheck
@@ -708,9 +765,11 @@ func (b *builder) newRegoCheck(meta *compliance.SuiteMeta, ruleScope compliance.
 
 		resourceHandler: handler,
 		scope:           ruleScope,
-		checkable:       regoCheck,
+		checkable:       checkable,
 
 		eventNotify: notify,
+
+		driftDetector: b.driftDetector,
 	}, nil
 }
 
@@ -776,17 +835,22 @@ func (b *builder) NodeLabels() map[string]string {
 }
 
 func (b *builder) EvaluateFromCache(ev eval.Evaluatable) (interface{}, error) {
-	instance := eval.NewInstance(
-		nil,
-		eval.FunctionMap{
-			builderFuncShell:       b.withValueCache(builderFuncShell, evalCommandShell),
-			builderFuncExec:        b.withValueCache(builderFuncExec, evalCommandExec),
-			builderFuncProcessFlag: b.withValueCache(builderFuncProcessFlag, evalProcessFlag),
-			builderFuncJSON:        b.withValueCache(builderFuncJSON, b.evalValueFromFile(jsonGetter)),
-			builderFuncYAML:        b.withValueCache(builderFuncYAML, b.evalValueFromFile(yamlGetter)),
-		},
-		nil,
-	)
+	functions := eval.FunctionMap{
+		builderFuncShell:       b.withValueCache(builderFuncShell, evalCommandShell),
+		builderFuncExec:        b.withValueCache(builderFuncExec, evalCommandExec),
+		builderFuncProcessFlag: b.withValueCache(builderFuncProcessFlag, evalProcessFlag),
+		builderFuncJSON:        b.withValueCache(builderFuncJSON, b.evalValueFromFile(jsonGetter)),
+		builderFuncYAML:        b.withValueCache(builderFuncYAML, b.evalValueFromFile(yamlGetter)),
+	}
+
+	// commands and processes of the live host don't describe an offline root filesystem
+	if b.rootfs != "" {
+		for _, funcName := range []string{builderFuncShell, builderFuncExec, builderFuncProcessFlag} {
+			functions[funcName] = offlineUnsupportedFunc(funcName)
+		}
+	}
+
+	instance := eval.NewInstance(nil, functions, nil)
 
 	return ev.Evaluate(instance)
 }
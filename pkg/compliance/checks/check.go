@@ -38,6 +38,8 @@ type complianceCheck struct {
 	checkable checkable
 
 	eventNotify eventNotify
+
+	driftDetector *driftDetector
 }
 
 func (c *complianceCheck) Stop() {
@@ -110,6 +112,8 @@ func (c *complianceCheck) Run() error {
 	reports := c.checkable.check(c)
 	resourceQuadIDs := make(map[resourceQuadID]bool)
 
+	var events []*event.Event
+
 	for _, report := range reports {
 		if report.Error != nil {
 			log.Debugf("%s: check run failed: %v", c.ruleID, report.Error)
@@ -150,12 +154,24 @@ func (c *complianceCheck) Run() error {
 			ExpireAt:         c.computeExpireAt(),
 		}
 
-		log.Debugf("%s: reporting [%s] [%s] [%s]", c.ruleID, e.Result, e.ResourceID, e.ResourceType)
-
-		c.Reporter().Report(e)
 		if c.eventNotify != nil {
 			c.eventNotify(c.ruleID, e)
 		}
+		events = append(events, e)
+	}
+
+	if c.driftDetector != nil {
+		events = c.driftDetector.filter(c.suiteMeta.Framework, c.ruleID, events, c.interval)
+	}
+
+	for _, e := range events {
+		if e.Drift != nil && e.Drift.Change == event.DriftRemoved {
+			e.AgentVersion = version.AgentVersion
+		}
+
+		log.Debugf("%s: reporting [%s] [%s] [%s]", c.ruleID, e.Result, e.ResourceID, e.ResourceType)
+
+		c.Reporter().Report(e)
 	}
 
 	return err
@@ -188,6 +204,10 @@ func reportToEventData(report *compliance.Report) (event.Data, string) {
 		data["aggregated"] = true
 	}
 
+	if report.NotApplicable {
+		return data, event.NotApplicable
+	}
+
 	return data, eventResult(passed, report.Error)
 }
 
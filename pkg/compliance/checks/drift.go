@@ -0,0 +1,225 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package checks
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/compliance/event"
+	"github.com/DataDog/datadog-agent/pkg/persistentcache"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// driftStore persists the state of the drift detector between runs
+type driftStore interface {
+	Read(key string) (string, error)
+	Write(key, value string) error
+}
+
+type persistentCacheStore struct{}
+
+func (persistentCacheStore) Read(key string) (string, error) {
+	return persistentcache.Read(key)
+}
+
+func (persistentCacheStore) Write(key, value string) error {
+	return persistentcache.Write(key, value)
+}
+
+// driftResourceState holds the last result reported for a resource
+type driftResourceState struct {
+	Result       string      `json:"result"`
+	ResourceType string      `json:"resource_type"`
+	ResourceID   string      `json:"resource_id"`
+	Data         interface{} `json:"data,omitempty"`
+	Since        time.Time   `json:"since"`
+	ExpireAt     time.Time   `json:"expire_at"`
+}
+
+// driftRuleState holds the last results reported by a rule, indexed by resource
+type driftRuleState map[string]*driftResourceState
+
+// driftDetector keeps the last result of each rule and resource, and only lets
+// through the events describing a transition
+type driftDetector struct {
+	store driftStore
+	now   func() time.Time
+}
+
+func newDriftDetector(store driftStore) *driftDetector {
+	return &driftDetector{
+		store: store,
+		now:   time.Now,
+	}
+}
+
+func driftKey(frameworkID, ruleID string) string {
+	return fmt.Sprintf("compliance_drift:%s_%s", frameworkID, ruleID)
+}
+
+func driftResourceKey(resourceType, resourceID string) string {
+	return resourceType + "/" + resourceID
+}
+
+func (d *driftDetector) load(key string) driftRuleState {
+	state := make(driftRuleState)
+
+	content, err := d.store.Read(key)
+	if err != nil {
+		log.Warnf("Failed to read compliance drift state %s: %v", key, err)
+		return state
+	}
+	if content == "" {
+		return state
+	}
+
+	if err := json.Unmarshal([]byte(content), &state); err != nil {
+		log.Warnf("Ignoring invalid compliance drift state %s: %v", key, err)
+		return make(driftRuleState)
+	}
+	return state
+}
+
+func (d *driftDetector) save(key string, state driftRuleState) {
+	content, err := json.Marshal(state)
+	if err != nil {
+		log.Errorf("Failed to marshal compliance drift state %s: %v", key, err)
+		return
+	}
+
+	if err := d.store.Write(key, string(content)); err != nil {
+		log.Errorf("Failed to write compliance drift state %s: %v", key, err)
+	}
+}
+
+// normalizeData converts event data to its JSON representation so that it can
+// be compared with the data read from the persisted state
+func normalizeData(data interface{}) interface{} {
+	if data == nil {
+		return nil
+	}
+
+	content, err := json.Marshal(data)
+	if err != nil {
+		return data
+	}
+
+	var normalized interface{}
+	if err := json.Unmarshal(content, &normalized); err != nil {
+		return data
+	}
+	return normalized
+}
+
+// diffData returns the attributes that differ between two evaluations
+func diffData(before, after interface{}) map[string]event.DataDiff {
+	beforeMap, beforeIsMap := before.(map[string]interface{})
+	afterMap, afterIsMap := after.(map[string]interface{})
+	if !beforeIsMap || !afterIsMap {
+		if reflect.DeepEqual(before, after) {
+			return nil
+		}
+		return map[string]event.DataDiff{"": {Before: before, After: after}}
+	}
+
+	diff := make(map[string]event.DataDiff)
+	for key, value := range beforeMap {
+		if !reflect.DeepEqual(value, afterMap[key]) {
+			diff[key] = event.DataDiff{Before: value, After: afterMap[key]}
+		}
+	}
+	for key, value := range afterMap {
+		if _, found := beforeMap[key]; !found {
+			diff[key] = event.DataDiff{After: value}
+		}
+	}
+
+	if len(diff) == 0 {
+		return nil
+	}
+	return diff
+}
+
+// filter updates the state of a rule with the events of a run and returns the events
+// describing a transition, including events for the resources that were not reported again.
+// Unchanged results are reported again when their last event would expire before the next
+// run, one interval later, so that stable findings are kept alive in the backend.
+func (d *driftDetector) filter(frameworkID, ruleID string, events []*event.Event, interval time.Duration) []*event.Event {
+	key := driftKey(frameworkID, ruleID)
+	previous := d.load(key)
+	current := make(driftRuleState)
+	now := d.now().UTC().Truncate(time.Second)
+
+	var transitions []*event.Event
+	for _, e := range events {
+		resourceKey := driftResourceKey(e.ResourceType, e.ResourceID)
+		data := normalizeData(e.Data)
+
+		state := &driftResourceState{
+			Result:       e.Result,
+			ResourceType: e.ResourceType,
+			ResourceID:   e.ResourceID,
+			Data:         data,
+			Since:        now,
+			ExpireAt:     e.ExpireAt,
+		}
+		current[resourceKey] = state
+
+		last, found := previous[resourceKey]
+		switch {
+		case !found:
+			e.Drift = &event.Drift{Change: event.DriftNew}
+		case last.Result != e.Result:
+			e.Drift = &event.Drift{
+				Change:         event.DriftChanged,
+				PreviousResult: last.Result,
+				PreviousSince:  last.Since,
+				Diff:           diffData(last.Data, data),
+			}
+		case !last.ExpireAt.After(now.Add(interval)):
+			state.Since = last.Since
+		default:
+			state.Since = last.Since
+			state.ExpireAt = last.ExpireAt
+			continue
+		}
+
+		transitions = append(transitions, e)
+	}
+
+	var removed []string
+	for resourceKey := range previous {
+		if _, found := current[resourceKey]; !found {
+			removed = append(removed, resourceKey)
+		}
+	}
+	sort.Strings(removed)
+
+	for _, resourceKey := range removed {
+		last := previous[resourceKey]
+		transitions = append(transitions, &event.Event{
+			AgentRuleID:      ruleID,
+			AgentFrameworkID: frameworkID,
+			ResourceType:     last.ResourceType,
+			ResourceID:       last.ResourceID,
+			Result:           event.Removed,
+			ExpireAt:         now,
+			Drift: &event.Drift{
+				Change:         event.DriftRemoved,
+				PreviousResult: last.Result,
+				PreviousSince:  last.Since,
+			},
+		})
+	}
+
+	d.save(key, current)
+
+	return transitions
+}
@@ -0,0 +1,138 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package checks
+
+import (
+	"testing"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/compliance/event"
+
+	assert "github.com/stretchr/testify/require"
+)
+
+type memoryDriftStore map[string]string
+
+func (s memoryDriftStore) Read(key string) (string, error) {
+	return s[key], nil
+}
+
+func (s memoryDriftStore) Write(key, value string) error {
+	s[key] = value
+	return nil
+}
+
+func driftEvent(resourceID string, result string, data event.Data) *event.Event {
+	return &event.Event{
+		AgentRuleID:      "rule",
+		AgentFrameworkID: "framework",
+		ResourceType:     "file",
+		ResourceID:       resourceID,
+		Result:           result,
+		Data:             data,
+	}
+}
+
+func TestDriftDetector(t *testing.T) {
+	assert := assert.New(t)
+
+	store := make(memoryDriftStore)
+	detector := newDriftDetector(store)
+
+	const interval = 20 * time.Minute
+	firstRun := time.Date(2021, 11, 2, 10, 0, 0, 0, time.UTC)
+	now := firstRun
+
+	run := func(ruleID string, events ...*event.Event) []*event.Event {
+		detector.now = func() time.Time { return now }
+		for _, e := range events {
+			e.ExpireAt = now.Add(interval * ExpireAtIntervalFactor)
+		}
+		return detector.filter("framework", ruleID, events, interval)
+	}
+
+	// the first run reports all the resources
+	events := run("rule",
+		driftEvent("/etc/a", event.Passed, event.Data{"file.permissions": uint64(0644)}),
+		driftEvent("/etc/b", event.Failed, event.Data{"file.user": "nobody"}),
+		driftEvent("/etc/c", event.Passed, nil),
+	)
+	assert.Len(events, 3)
+	for _, e := range events {
+		assert.Equal(event.DriftNew, e.Drift.Change)
+	}
+
+	// the same results are not reported again
+	now = firstRun.Add(interval)
+	events = run("rule",
+		driftEvent("/etc/a", event.Passed, event.Data{"file.permissions": uint64(0644)}),
+		driftEvent("/etc/b", event.Failed, event.Data{"file.user": "nobody"}),
+		driftEvent("/etc/c", event.Passed, nil),
+	)
+	assert.Empty(events)
+
+	// unless they would expire before the next run
+	now = firstRun.Add(2 * interval)
+	events = run("rule",
+		driftEvent("/etc/a", event.Passed, event.Data{"file.permissions": uint64(0644)}),
+		driftEvent("/etc/b", event.Failed, event.Data{"file.user": "nobody"}),
+		driftEvent("/etc/c", event.Passed, nil),
+	)
+	assert.Len(events, 3)
+	for _, e := range events {
+		assert.Nil(e.Drift)
+	}
+
+	// transitions, new and removed resources are reported
+	now = firstRun.Add(3 * interval)
+	events = run("rule",
+		driftEvent("/etc/a", event.Failed, event.Data{"file.permissions": uint64(0666)}),
+		driftEvent("/etc/b", event.Failed, event.Data{"file.user": "root"}),
+		driftEvent("/etc/d", event.Passed, nil),
+	)
+	assert.Len(events, 3)
+
+	assert.Equal("/etc/a", events[0].ResourceID)
+	assert.Equal(&event.Drift{
+		Change:         event.DriftChanged,
+		PreviousResult: event.Passed,
+		PreviousSince:  firstRun,
+		Diff: map[string]event.DataDiff{
+			"file.permissions": {Before: float64(0644), After: float64(0666)},
+		},
+	}, events[0].Drift)
+
+	assert.Equal("/etc/d", events[1].ResourceID)
+	assert.Equal(event.DriftNew, events[1].Drift.Change)
+
+	// removed resources expire right away
+	assert.Equal("/etc/c", events[2].ResourceID)
+	assert.Equal(event.Removed, events[2].Result)
+	assert.Equal(now, events[2].ExpireAt)
+	assert.Equal(&event.Drift{
+		Change:         event.DriftRemoved,
+		PreviousResult: event.Passed,
+		PreviousSince:  firstRun,
+	}, events[2].Drift)
+
+	// they are not reported again, only the unchanged result about to expire is
+	now = firstRun.Add(4 * interval)
+	events = run("rule",
+		driftEvent("/etc/a", event.Failed, event.Data{"file.permissions": uint64(0666)}),
+		driftEvent("/etc/b", event.Failed, event.Data{"file.user": "root"}),
+		driftEvent("/etc/d", event.Passed, nil),
+	)
+	assert.Len(events, 1)
+	assert.Equal("/etc/b", events[0].ResourceID)
+	assert.Nil(events[0].Drift)
+
+	// state is kept per framework and rule
+	events = run("other-rule",
+		driftEvent("/etc/a", event.Failed, nil),
+	)
+	assert.Len(events, 1)
+	assert.Len(store, 2)
+}
@@ -0,0 +1,421 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package checks
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/DataDog/datadog-agent/pkg/compliance"
+	"github.com/DataDog/datadog-agent/pkg/compliance/checks/env"
+	"github.com/DataDog/datadog-agent/pkg/compliance/eval"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+var packageReportedFields = []string{
+	compliance.PackageFieldName,
+	compliance.PackageFieldVersion,
+	compliance.PackageFieldInstalled,
+	compliance.PackageFieldManager,
+}
+
+// ErrPackageDatabaseNotFound is returned when none of the supported package databases can be found
+var ErrPackageDatabaseNotFound = errors.New("no supported package database found")
+
+// ErrUnsupportedRpmDatabase is returned for the rpm databases in the ndb format, only the
+// Berkeley DB and sqlite formats are supported
+var ErrUnsupportedRpmDatabase = errors.New("unsupported rpm database format")
+
+type packageInfo struct {
+	Name    string
+	Version string
+}
+
+type packageDatabase struct {
+	manager string
+	path    string
+	read    func(path string) ([]packageInfo, error)
+	compare func(a, b string) int
+}
+
+var packageDatabases = []packageDatabase{
+	{manager: "dpkg", path: "/var/lib/dpkg/status", read: readDpkgStatus, compare: compareVersions},
+	{manager: "apk", path: "/lib/apk/db/installed", read: readApkInstalled, compare: compareApkVersions},
+	{manager: "rpm", path: "/var/lib/rpm/rpmdb.sqlite", read: readRpmSqlitePackages, compare: compareVersions},
+	{manager: "rpm", path: "/var/lib/rpm/Packages", read: readRpmPackages, compare: compareVersions},
+	{manager: "rpm", path: "/var/lib/rpm/Packages.db", read: readRpmNdbPackages, compare: compareVersions},
+}
+
+func resolvePackage(_ context.Context, e env.Env, id string, res compliance.ResourceCommon, rego bool) (resolved, error) {
+	if res.Package == nil {
+		return nil, fmt.Errorf("%s: expecting package resource in package check", id)
+	}
+
+	name := res.Package.Name
+
+	for _, db := range packageDatabases {
+		path := e.NormalizeToHostRoot(db.path)
+		if _, err := os.Stat(path); err != nil {
+			continue
+		}
+
+		log.Debugf("%s: looking for package %s in %s database %s", id, name, db.manager, path)
+
+		packages, err := db.read(path)
+		if err != nil {
+			return nil, wrapErrorWithID(id, fmt.Errorf("failed to read %s database %s: %w", db.manager, path, err))
+		}
+
+		var version string
+		installed := false
+		for _, pkg := range packages {
+			if pkg.Name == name {
+				version, installed = pkg.Version, true
+				break
+			}
+		}
+
+		instance := eval.NewInstance(
+			eval.VarMap{
+				compliance.PackageFieldName:      name,
+				compliance.PackageFieldVersion:   version,
+				compliance.PackageFieldInstalled: installed,
+				compliance.PackageFieldManager:   db.manager,
+			},
+			eval.FunctionMap{
+				compliance.PackageFuncVersionAtLeast: packageVersionAtLeast(version, installed, db.compare),
+			},
+			eval.RegoInputMap{
+				"name":      name,
+				"version":   version,
+				"installed": installed,
+				"manager":   db.manager,
+			},
+		)
+
+		return newResolvedInstance(instance, name, "package"), nil
+	}
+
+	return nil, wrapErrorWithID(id, ErrPackageDatabaseNotFound)
+}
+
+func packageVersionAtLeast(version string, installed bool, compare func(a, b string) int) eval.Function {
+	return func(_ eval.Instance, args ...interface{}) (interface{}, error) {
+		if len(args) != 1 {
+			return nil, fmt.Errorf(`invalid number of arguments, expecting 1 got %d`, len(args))
+		}
+		minVersion, ok := args[0].(string)
+		if !ok {
+			return nil, errors.New(`expecting string value for version argument`)
+		}
+		return installed && compare(version, minVersion) >= 0, nil
+	}
+}
+
+// readControlFile parses files made of paragraphs of "key<sep>value" lines, separated by empty lines
+func readControlFile(r io.Reader, sep string, fn func(fields map[string]string)) error {
+	fields := make(map[string]string)
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" {
+			if len(fields) > 0 {
+				fn(fields)
+				fields = make(map[string]string)
+			}
+			continue
+		}
+
+		// continuation lines of multi-line values
+		if line[0] == ' ' || line[0] == '\t' {
+			continue
+		}
+
+		parts := strings.SplitN(line, sep, 2)
+		if len(parts) != 2 {
+			continue
+		}
+		fields[parts[0]] = strings.TrimSpace(parts[1])
+	}
+	if len(fields) > 0 {
+		fn(fields)
+	}
+	return scanner.Err()
+}
+
+// readDpkgStatus returns the installed packages listed in a dpkg status file
+func readDpkgStatus(path string) ([]packageInfo, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var packages []packageInfo
+	err = readControlFile(f, ":", func(fields map[string]string) {
+		// the last word of the status is the package state, e.g. "install ok installed"
+		status := strings.Fields(fields["Status"])
+		if len(status) == 0 || status[len(status)-1] != "installed" {
+			return
+		}
+		packages = append(packages, packageInfo{Name: fields["Package"], Version: fields["Version"]})
+	})
+	return packages, err
+}
+
+// readApkInstalled returns the packages listed in an apk installed database
+func readApkInstalled(path string) ([]packageInfo, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var packages []packageInfo
+	err = readControlFile(f, ":", func(fields map[string]string) {
+		packages = append(packages, packageInfo{Name: fields["P"], Version: fields["V"]})
+	})
+	return packages, err
+}
+
+// compareVersions compares two package versions following the dpkg ordering rules,
+// which also apply to rpm versions in the [epoch:]version[-release] form
+func compareVersions(a, b string) int {
+	epochA, versionA, releaseA := splitVersion(a)
+	epochB, versionB, releaseB := splitVersion(b)
+
+	if epochA != epochB {
+		if epochA < epochB {
+			return -1
+		}
+		return 1
+	}
+
+	if c := compareVersionParts(versionA, versionB); c != 0 {
+		return c
+	}
+	return compareVersionParts(releaseA, releaseB)
+}
+
+func splitVersion(v string) (int, string, string) {
+	epoch := 0
+	if i := strings.IndexByte(v, ':'); i >= 0 {
+		if e, err := strconv.Atoi(v[:i]); err == nil {
+			epoch = e
+			v = v[i+1:]
+		}
+	}
+
+	release := ""
+	if i := strings.LastIndexByte(v, '-'); i >= 0 {
+		v, release = v[:i], v[i+1:]
+	}
+
+	return epoch, v, release
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// versionCharOrder returns the weight of the character at index i: the end of the string and
+// digits sort first, then '~' sorts before everything, then letters before other characters
+func versionCharOrder(s string, i int) int {
+	if i >= len(s) {
+		return 0
+	}
+	c := s[i]
+	switch {
+	case isDigit(c):
+		return 0
+	case (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z'):
+		return int(c)
+	case c == '~':
+		return -1
+	default:
+		return int(c) + 256
+	}
+}
+
+func compareVersionParts(a, b string) int {
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		for (i < len(a) && !isDigit(a[i])) || (j < len(b) && !isDigit(b[j])) {
+			orderA, orderB := versionCharOrder(a, i), versionCharOrder(b, j)
+			if orderA != orderB {
+				if orderA < orderB {
+					return -1
+				}
+				return 1
+			}
+			i++
+			j++
+		}
+
+		for i < len(a) && a[i] == '0' {
+			i++
+		}
+		for j < len(b) && b[j] == '0' {
+			j++
+		}
+
+		firstDiff := 0
+		for i < len(a) && isDigit(a[i]) && j < len(b) && isDigit(b[j]) {
+			if firstDiff == 0 {
+				firstDiff = int(a[i]) - int(b[j])
+			}
+			i++
+			j++
+		}
+
+		if i < len(a) && isDigit(a[i]) {
+			return 1
+		}
+		if j < len(b) && isDigit(b[j]) {
+			return -1
+		}
+		if firstDiff < 0 {
+			return -1
+		}
+		if firstDiff > 0 {
+			return 1
+		}
+	}
+	return 0
+}
+
+// apkSuffixOrder gives the ordering of apk version suffixes relative to the release,
+// which has no suffix: pre-release suffixes sort before it, the others after it
+var apkSuffixOrder = map[string]int{
+	"alpha": -4,
+	"beta":  -3,
+	"pre":   -2,
+	"rc":    -1,
+	"cvs":   1,
+	"svn":   2,
+	"git":   3,
+	"hg":    4,
+	"p":     5,
+}
+
+type apkSuffix struct {
+	order  int
+	number int
+}
+
+type apkVersion struct {
+	numbers  []int
+	letter   byte
+	suffixes []apkSuffix
+	revision int
+}
+
+// parseApkVersion parses versions of the number{.number}[letter]{_suffix[number]}[-rrevision] form
+func parseApkVersion(s string) (*apkVersion, bool) {
+	var v apkVersion
+
+	if i := strings.LastIndex(s, "-r"); i >= 0 {
+		revision, err := strconv.Atoi(s[i+2:])
+		if err != nil {
+			return nil, false
+		}
+		s, v.revision = s[:i], revision
+	}
+
+	parts := strings.Split(s, "_")
+	numbers := strings.Split(parts[0], ".")
+	for i, number := range numbers {
+		// only the last number can be followed by a letter
+		if i == len(numbers)-1 && len(number) > 1 && number[len(number)-1] >= 'a' && number[len(number)-1] <= 'z' {
+			number, v.letter = number[:len(number)-1], number[len(number)-1]
+		}
+		n, err := strconv.Atoi(number)
+		if err != nil || n < 0 {
+			return nil, false
+		}
+		v.numbers = append(v.numbers, n)
+	}
+
+	for _, part := range parts[1:] {
+		end := len(part)
+		for end > 0 && isDigit(part[end-1]) {
+			end--
+		}
+		order, found := apkSuffixOrder[part[:end]]
+		if !found {
+			return nil, false
+		}
+		suffix := apkSuffix{order: order}
+		if end < len(part) {
+			suffix.number, _ = strconv.Atoi(part[end:])
+		}
+		v.suffixes = append(v.suffixes, suffix)
+	}
+
+	return &v, true
+}
+
+func compareInts(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// compareApkVersions compares two package versions following the apk ordering rules, where
+// the _alpha, _beta, _pre and _rc suffixes sort before the release. Versions that can't be
+// parsed are compared with the dpkg rules.
+func compareApkVersions(a, b string) int {
+	versionA, okA := parseApkVersion(a)
+	versionB, okB := parseApkVersion(b)
+	if !okA || !okB {
+		return compareVersions(a, b)
+	}
+
+	for i := 0; i < len(versionA.numbers) || i < len(versionB.numbers); i++ {
+		if i >= len(versionA.numbers) {
+			return -1
+		}
+		if i >= len(versionB.numbers) {
+			return 1
+		}
+		if c := compareInts(versionA.numbers[i], versionB.numbers[i]); c != 0 {
+			return c
+		}
+	}
+
+	if c := compareInts(int(versionA.letter), int(versionB.letter)); c != 0 {
+		return c
+	}
+
+	for i := 0; i < len(versionA.suffixes) || i < len(versionB.suffixes); i++ {
+		var suffixA, suffixB apkSuffix
+		if i < len(versionA.suffixes) {
+			suffixA = versionA.suffixes[i]
+		}
+		if i < len(versionB.suffixes) {
+			suffixB = versionB.suffixes[i]
+		}
+		if c := compareInts(suffixA.order, suffixB.order); c != 0 {
+			return c
+		}
+		if c := compareInts(suffixA.number, suffixB.number); c != 0 {
+			return c
+		}
+	}
+
+	return compareInts(versionA.revision, versionB.revision)
+}
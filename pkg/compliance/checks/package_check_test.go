@@ -0,0 +1,338 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package checks
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/DataDog/datadog-agent/pkg/compliance"
+	"github.com/DataDog/datadog-agent/pkg/compliance/event"
+	"github.com/DataDog/datadog-agent/pkg/compliance/mocks"
+
+	"github.com/stretchr/testify/mock"
+	assert "github.com/stretchr/testify/require"
+)
+
+func mockPackageDatabase(env *mocks.Env, dbPath string, path string) {
+	for _, db := range packageDatabases {
+		if db.path == dbPath {
+			env.On("NormalizeToHostRoot", db.path).Return(path)
+		} else {
+			env.On("NormalizeToHostRoot", db.path).Return("./testdata/package/missing")
+		}
+	}
+}
+
+func TestPackageCheck(t *testing.T) {
+	tests := []struct {
+		name     string
+		dbPath   string
+		database string
+		resource compliance.Resource
+
+		expectReport *compliance.Report
+	}{
+		{
+			name:     "dpkg package installed at minimal version",
+			dbPath:   "/var/lib/dpkg/status",
+			database: "./testdata/package/dpkg/status",
+			resource: compliance.Resource{
+				ResourceCommon: compliance.ResourceCommon{
+					Package: &compliance.Package{
+						Name: "docker-ce",
+					},
+				},
+				Condition: `package.installed && package.versionAtLeast("5:20.10.7")`,
+			},
+			expectReport: &compliance.Report{
+				Passed: true,
+				Data: event.Data{
+					"package.name":      "docker-ce",
+					"package.version":   "5:20.10.10~3-0~ubuntu-focal",
+					"package.installed": true,
+					"package.manager":   "dpkg",
+				},
+				Resource: compliance.ReportResource{
+					ID:   "docker-ce",
+					Type: "package",
+				},
+			},
+		},
+		{
+			name:     "dpkg package removed",
+			dbPath:   "/var/lib/dpkg/status",
+			database: "./testdata/package/dpkg/status",
+			resource: compliance.Resource{
+				ResourceCommon: compliance.ResourceCommon{
+					Package: &compliance.Package{
+						Name: "telnetd",
+					},
+				},
+				Condition: `!package.installed`,
+			},
+			expectReport: &compliance.Report{
+				Passed: true,
+				Data: event.Data{
+					"package.name":      "telnetd",
+					"package.version":   "",
+					"package.installed": false,
+					"package.manager":   "dpkg",
+				},
+				Resource: compliance.ReportResource{
+					ID:   "telnetd",
+					Type: "package",
+				},
+			},
+		},
+		{
+			name:     "apk package too old",
+			dbPath:   "/lib/apk/db/installed",
+			database: "./testdata/package/apk/installed",
+			resource: compliance.Resource{
+				ResourceCommon: compliance.ResourceCommon{
+					Package: &compliance.Package{
+						Name: "openssl",
+					},
+				},
+				Condition: `package.versionAtLeast("1.1.1m")`,
+			},
+			expectReport: &compliance.Report{
+				Passed: false,
+				Data: event.Data{
+					"package.name":      "openssl",
+					"package.version":   "1.1.1l-r0",
+					"package.installed": true,
+					"package.manager":   "apk",
+				},
+				Resource: compliance.ReportResource{
+					ID:   "openssl",
+					Type: "package",
+				},
+			},
+		},
+		{
+			name:     "rpm package installed",
+			dbPath:   "/var/lib/rpm/Packages",
+			database: "./testdata/package/rpm/Packages",
+			resource: compliance.Resource{
+				ResourceCommon: compliance.ResourceCommon{
+					Package: &compliance.Package{
+						Name: "one-epoch",
+					},
+				},
+				Condition: `package.versionAtLeast("0:1.0")`,
+			},
+			expectReport: &compliance.Report{
+				Passed: true,
+				Data: event.Data{
+					"package.name":      "one-epoch",
+					"package.version":   "1:0.1-1",
+					"package.installed": true,
+					"package.manager":   "rpm",
+				},
+				Resource: compliance.ReportResource{
+					ID:   "one-epoch",
+					Type: "package",
+				},
+			},
+		},
+		{
+			name:     "rpm package installed in sqlite database",
+			dbPath:   "/var/lib/rpm/rpmdb.sqlite",
+			database: "./testdata/package/rpm/rpmdb.sqlite",
+			resource: compliance.Resource{
+				ResourceCommon: compliance.ResourceCommon{
+					Package: &compliance.Package{
+						Name: "simple",
+					},
+				},
+				Condition: `package.versionAtLeast("1.0.1-2")`,
+			},
+			expectReport: &compliance.Report{
+				Passed: false,
+				Data: event.Data{
+					"package.name":      "simple",
+					"package.version":   "1.0.1-1",
+					"package.installed": true,
+					"package.manager":   "rpm",
+				},
+				Resource: compliance.ReportResource{
+					ID:   "simple",
+					Type: "package",
+				},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			env := &mocks.Env{}
+			mockPackageDatabase(env, test.dbPath, test.database)
+
+			packageCheck, err := newResourceCheck(env, "rule-id", test.resource)
+			assert.NoError(err)
+
+			reports := packageCheck.check(env)
+			assert.Equal(test.expectReport, reports[0])
+		})
+	}
+}
+
+func TestPackageCheckNoDatabase(t *testing.T) {
+	env := &mocks.Env{}
+	mockPackageDatabase(env, "", "")
+
+	_, err := resolvePackage(context.Background(), env, "rule-id", compliance.ResourceCommon{
+		Package: &compliance.Package{Name: "bash"},
+	}, false)
+	assert.True(t, errors.Is(err, ErrPackageDatabaseNotFound))
+}
+
+func TestPackageCheckUnsupportedRpmDatabase(t *testing.T) {
+	env := &mocks.Env{}
+	mockPackageDatabase(env, "/var/lib/rpm/Packages.db", "./testdata/package/rpm/Packages")
+
+	_, err := resolvePackage(context.Background(), env, "rule-id", compliance.ResourceCommon{
+		Package: &compliance.Package{Name: "simple"},
+	}, false)
+	assert.True(t, errors.Is(err, ErrUnsupportedRpmDatabase))
+}
+
+// The rpm fixtures hold the headers of the simple, one-epoch and zero-epoch packages of the
+// go-rpmutils test data. Packages was written with libdb 5.3 like rpm does, and rpmdb.sqlite
+// uses the schema of the rpm sqlite backend with 1KiB pages so that its b-tree has interior
+// and overflow pages.
+var rpmFixturePackages = []packageInfo{
+	{Name: "one-epoch", Version: "1:0.1-1"},
+	{Name: "simple", Version: "1.0.1-1"},
+	{Name: "zero-epoch", Version: "0:0.1-1"},
+}
+
+func TestReadRpmPackages(t *testing.T) {
+	packages, err := readRpmPackages("./testdata/package/rpm/Packages")
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, rpmFixturePackages, packages)
+}
+
+func TestReadRpmSqlitePackages(t *testing.T) {
+	packages, err := readRpmSqlitePackages("./testdata/package/rpm/rpmdb.sqlite")
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, rpmFixturePackages, packages)
+
+	_, err = readRpmSqlitePackages("./testdata/package/rpm/Packages")
+	assert.True(t, errors.Is(err, errInvalidRpmDatabase))
+}
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b   string
+		expect int
+	}{
+		{"1.0", "1.0", 0},
+		{"1.0", "1.1", -1},
+		{"1.10", "1.9", 1},
+		{"1.0~rc1", "1.0", -1},
+		{"1.0", "1.0+dfsg", -1},
+		{"1.1.1l-r0", "1.1.1m", -1},
+		{"1:1.0", "2.0", 1},
+		{"5:20.10.10~3-0~ubuntu-focal", "5:20.10.7", 1},
+		{"8.0p1-10.el8", "8.0p1-5", 1},
+		{"1.001", "1.1", 0},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.expect, compareVersions(test.a, test.b), "%s <=> %s", test.a, test.b)
+		assert.Equal(t, -test.expect, compareVersions(test.b, test.a), "%s <=> %s", test.b, test.a)
+	}
+}
+
+func TestCompareApkVersions(t *testing.T) {
+	tests := []struct {
+		a, b   string
+		expect int
+	}{
+		{"1.0", "1.0", 0},
+		{"1.0", "1.0.1", -1},
+		{"1.10", "1.9", 1},
+		{"1.1.1l-r0", "1.1.1m", -1},
+		{"1.0_rc1", "1.0", -1},
+		{"1.0_rc1", "1.0_rc2", -1},
+		{"1.0_alpha", "1.0_beta", -1},
+		{"1.0_beta2", "1.0_pre1", -1},
+		{"1.0_pre1", "1.0_rc1", -1},
+		{"1.0", "1.0_p1", -1},
+		{"1.0_p1", "1.0_p2", -1},
+		{"1.0_p1", "1.0.1", -1},
+		{"1.0-r1", "1.0-r2", -1},
+		{"1.0-r10", "1.0-r9", 1},
+		{"1.0_rc1-r5", "1.0-r0", -1},
+		{"1.0_p1-r0", "1.0-r9", 1},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.expect, compareApkVersions(test.a, test.b), "%s <=> %s", test.a, test.b)
+		assert.Equal(t, -test.expect, compareApkVersions(test.b, test.a), "%s <=> %s", test.b, test.a)
+	}
+}
+
+func TestPackageRegoCheck(t *testing.T) {
+	assert := assert.New(t)
+
+	fixture := regoFixture{
+		inputs: []compliance.RegoInput{
+			{
+				ResourceCommon: compliance.ResourceCommon{
+					Package: &compliance.Package{
+						Name: "docker-ce",
+					},
+				},
+				// package is a reserved keyword in rego
+				TagName: "docker",
+			},
+		},
+		module: `
+			package test
+
+			import data.datadog as dd
+
+			findings[f] {
+				input.docker.installed
+				compare_versions(input.docker.version, "5:20.10.7") >= 0
+				f := dd.passed_finding("package", input.docker.name, {"package.version": input.docker.version})
+			}
+		`,
+		findings: "data.test.findings",
+	}
+
+	env := &mocks.Env{}
+	env.On("MaxEventsPerRun").Return(30).Maybe()
+	env.On("ProvidedInput", mock.Anything).Return(nil).Once()
+	env.On("Hostname").Return("hostname_test").Once()
+	env.On("DumpInputPath").Return("").Once()
+	mockPackageDatabase(env, "/var/lib/dpkg/status", "./testdata/package/dpkg/status")
+
+	regoCheck, err := fixture.newRegoCheck()
+	assert.NoError(err)
+
+	reports := regoCheck.check(env)
+	assert.Equal([]*compliance.Report{
+		{
+			Passed: true,
+			Data: event.Data{
+				"package.version": "5:20.10.10~3-0~ubuntu-focal",
+			},
+			Resource: compliance.ReportResource{
+				ID:   "docker-ce",
+				Type: "package",
+			},
+			Evaluator: "rego",
+		},
+	}, reports)
+}
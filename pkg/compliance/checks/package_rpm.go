@@ -0,0 +1,262 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package checks
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+// The rpm Packages database is a Berkeley DB hash database whose values are rpm header blobs.
+// Only the pages holding the values are walked, the hash buckets are ignored.
+const (
+	bdbHashMagic            = 0x061561
+	bdbPageHeaderSize       = 26
+	bdbHashUnsortedPageType = 2
+	bdbOverflowPageType     = 7
+	bdbHashPageType         = 13
+	bdbHashOffPageItemType  = 3
+)
+
+const (
+	rpmTagName    = 1000
+	rpmTagVersion = 1001
+	rpmTagRelease = 1002
+	rpmTagEpoch   = 1003
+
+	rpmTypeInt32  = 4
+	rpmTypeString = 6
+
+	rpmHeaderIndexEntrySize = 16
+	rpmHeaderMaxEntries     = 0xffff
+)
+
+var errInvalidRpmDatabase = errors.New("invalid rpm database")
+
+type bdbReader struct {
+	r        io.ReaderAt
+	order    binary.ByteOrder
+	pageSize uint32
+	lastPage uint32
+}
+
+func newBdbReader(r io.ReaderAt) (*bdbReader, error) {
+	meta := make([]byte, 36)
+	if _, err := r.ReadAt(meta, 0); err != nil {
+		return nil, fmt.Errorf("failed to read metadata page: %w", err)
+	}
+
+	var order binary.ByteOrder
+	switch {
+	case binary.LittleEndian.Uint32(meta[12:16]) == bdbHashMagic:
+		order = binary.LittleEndian
+	case binary.BigEndian.Uint32(meta[12:16]) == bdbHashMagic:
+		order = binary.BigEndian
+	default:
+		return nil, fmt.Errorf("%w: not a Berkeley DB hash database", errInvalidRpmDatabase)
+	}
+
+	reader := &bdbReader{
+		r:        r,
+		order:    order,
+		pageSize: order.Uint32(meta[20:24]),
+		lastPage: order.Uint32(meta[32:36]),
+	}
+	if reader.pageSize < bdbPageHeaderSize || reader.pageSize > 64*1024 {
+		return nil, fmt.Errorf("%w: unexpected page size %d", errInvalidRpmDatabase, reader.pageSize)
+	}
+
+	return reader, nil
+}
+
+func (b *bdbReader) readPage(pgno uint32) ([]byte, error) {
+	page := make([]byte, b.pageSize)
+	if _, err := b.r.ReadAt(page, int64(pgno)*int64(b.pageSize)); err != nil {
+		return nil, fmt.Errorf("failed to read page %d: %w", pgno, err)
+	}
+	return page, nil
+}
+
+// readOverflow reads a value stored in a chain of overflow pages
+func (b *bdbReader) readOverflow(pgno uint32, length uint32) ([]byte, error) {
+	var value []byte
+	for visited := uint32(0); pgno != 0; visited++ {
+		if visited > b.lastPage {
+			return nil, fmt.Errorf("%w: overflow pages loop", errInvalidRpmDatabase)
+		}
+
+		page, err := b.readPage(pgno)
+		if err != nil {
+			return nil, err
+		}
+		if page[25] != bdbOverflowPageType {
+			return nil, fmt.Errorf("%w: page %d is not an overflow page", errInvalidRpmDatabase, pgno)
+		}
+
+		next := b.order.Uint32(page[16:20])
+		end := b.pageSize
+		if next == 0 {
+			// the last page of the chain only holds the remaining bytes
+			end = bdbPageHeaderSize + uint32(b.order.Uint16(page[22:24]))
+			if end > b.pageSize {
+				end = b.pageSize
+			}
+		}
+
+		value = append(value, page[bdbPageHeaderSize:end]...)
+		pgno = next
+	}
+
+	if uint32(len(value)) < length {
+		return nil, fmt.Errorf("%w: truncated value", errInvalidRpmDatabase)
+	}
+	return value[:length], nil
+}
+
+// values returns all the values stored off page in the hash pages of the database
+func (b *bdbReader) values() ([][]byte, error) {
+	var values [][]byte
+	for pgno := uint32(1); pgno <= b.lastPage; pgno++ {
+		page, err := b.readPage(pgno)
+		if err != nil {
+			return nil, err
+		}
+
+		if pageType := page[25]; pageType != bdbHashPageType && pageType != bdbHashUnsortedPageType {
+			continue
+		}
+
+		entries := uint32(b.order.Uint16(page[20:22]))
+		// entries are key and value pairs, only values are read
+		for i := uint32(1); i < entries; i += 2 {
+			indexOffset := bdbPageHeaderSize + 2*i
+			if indexOffset+2 > b.pageSize {
+				break
+			}
+
+			offset := uint32(b.order.Uint16(page[indexOffset : indexOffset+2]))
+			if offset+12 > b.pageSize || page[offset] != bdbHashOffPageItemType {
+				continue
+			}
+
+			value, err := b.readOverflow(b.order.Uint32(page[offset+4:offset+8]), b.order.Uint32(page[offset+8:offset+12]))
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, value)
+		}
+	}
+	return values, nil
+}
+
+// parseRpmHeader extracts the package name and version from an rpm header blob
+func parseRpmHeader(blob []byte) (packageInfo, error) {
+	var info packageInfo
+
+	if len(blob) < 8 {
+		return info, fmt.Errorf("%w: header too short", errInvalidRpmDatabase)
+	}
+
+	entries := binary.BigEndian.Uint32(blob[0:4])
+	dataLength := binary.BigEndian.Uint32(blob[4:8])
+	if entries > rpmHeaderMaxEntries {
+		return info, fmt.Errorf("%w: too many header entries", errInvalidRpmDatabase)
+	}
+
+	storeOffset := 8 + uint64(entries)*rpmHeaderIndexEntrySize
+	if storeOffset+uint64(dataLength) > uint64(len(blob)) {
+		return info, fmt.Errorf("%w: truncated header", errInvalidRpmDatabase)
+	}
+	store := blob[storeOffset : storeOffset+uint64(dataLength)]
+
+	readString := func(offset uint32) string {
+		if offset >= uint32(len(store)) {
+			return ""
+		}
+		value := store[offset:]
+		if end := bytes.IndexByte(value, 0); end >= 0 {
+			value = value[:end]
+		}
+		return string(value)
+	}
+
+	var version, release string
+	var epoch int32
+	hasEpoch := false
+
+	for i := uint32(0); i < entries; i++ {
+		entry := blob[8+i*rpmHeaderIndexEntrySize : 8+(i+1)*rpmHeaderIndexEntrySize]
+		tag := binary.BigEndian.Uint32(entry[0:4])
+		tagType := binary.BigEndian.Uint32(entry[4:8])
+		offset := binary.BigEndian.Uint32(entry[8:12])
+
+		switch {
+		case tag == rpmTagName && tagType == rpmTypeString:
+			info.Name = readString(offset)
+		case tag == rpmTagVersion && tagType == rpmTypeString:
+			version = readString(offset)
+		case tag == rpmTagRelease && tagType == rpmTypeString:
+			release = readString(offset)
+		case tag == rpmTagEpoch && tagType == rpmTypeInt32:
+			if offset+4 <= uint32(len(store)) {
+				epoch = int32(binary.BigEndian.Uint32(store[offset : offset+4]))
+				hasEpoch = true
+			}
+		}
+	}
+
+	if info.Name == "" {
+		return info, fmt.Errorf("%w: header without package name", errInvalidRpmDatabase)
+	}
+
+	info.Version = version
+	if release != "" {
+		info.Version += "-" + release
+	}
+	if hasEpoch {
+		info.Version = fmt.Sprintf("%d:%s", epoch, info.Version)
+	}
+
+	return info, nil
+}
+
+// readRpmNdbPackages reports the rpm databases in the ndb format as unsupported
+func readRpmNdbPackages(path string) ([]packageInfo, error) {
+	return nil, fmt.Errorf("%w: ndb database %s", ErrUnsupportedRpmDatabase, path)
+}
+
+// readRpmPackages returns the packages listed in an rpm Packages database in the Berkeley DB format
+func readRpmPackages(path string) ([]packageInfo, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	reader, err := newBdbReader(f)
+	if err != nil {
+		return nil, err
+	}
+
+	values, err := reader.values()
+	if err != nil {
+		return nil, err
+	}
+
+	packages := make([]packageInfo, 0, len(values))
+	for _, value := range values {
+		info, err := parseRpmHeader(value)
+		if err != nil {
+			return nil, err
+		}
+		packages = append(packages, info)
+	}
+	return packages, nil
+}
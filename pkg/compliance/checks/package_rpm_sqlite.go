@@ -0,0 +1,333 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package checks
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+)
+
+// The rpm sqlite database stores the rpm header blobs in the blob column of its Packages table.
+// Only the table b-trees of the file are walked, changes that are still in the write-ahead log
+// are not read.
+const (
+	sqliteHeaderSize         = 100
+	sqliteMagic              = "SQLite format 3\x00"
+	sqliteInteriorTablePage  = 0x05
+	sqliteLeafTablePage      = 0x0d
+	sqliteSchemaColumnType   = 0
+	sqliteSchemaColumnName   = 1
+	sqliteSchemaColumnRoot   = 3
+	rpmSqlitePackagesTable   = "Packages"
+	rpmSqliteBlobColumnIndex = 1
+)
+
+type sqliteReader struct {
+	r          io.ReaderAt
+	pageSize   uint32
+	usableSize uint32
+	pageCount  uint32
+}
+
+func newSqliteReader(r io.ReaderAt) (*sqliteReader, error) {
+	header := make([]byte, sqliteHeaderSize)
+	if _, err := r.ReadAt(header, 0); err != nil {
+		return nil, fmt.Errorf("failed to read database header: %w", err)
+	}
+	if string(header[:len(sqliteMagic)]) != sqliteMagic {
+		return nil, fmt.Errorf("%w: not a sqlite database", errInvalidRpmDatabase)
+	}
+
+	pageSize := uint32(binary.BigEndian.Uint16(header[16:18]))
+	if pageSize == 1 {
+		pageSize = 64 * 1024
+	}
+	if pageSize < 512 || pageSize&(pageSize-1) != 0 {
+		return nil, fmt.Errorf("%w: unexpected page size %d", errInvalidRpmDatabase, pageSize)
+	}
+
+	return &sqliteReader{
+		r:          r,
+		pageSize:   pageSize,
+		usableSize: pageSize - uint32(header[20]),
+		pageCount:  binary.BigEndian.Uint32(header[28:32]),
+	}, nil
+}
+
+func (s *sqliteReader) readPage(pgno uint32) ([]byte, error) {
+	if pgno == 0 || pgno > s.pageCount {
+		return nil, fmt.Errorf("%w: invalid page number %d", errInvalidRpmDatabase, pgno)
+	}
+	page := make([]byte, s.pageSize)
+	if _, err := s.r.ReadAt(page, int64(pgno-1)*int64(s.pageSize)); err != nil {
+		return nil, fmt.Errorf("failed to read page %d: %w", pgno, err)
+	}
+	return page[:s.usableSize], nil
+}
+
+// readVarint decodes a sqlite variable length integer and returns it with its length
+func readVarint(b []byte) (uint64, int) {
+	var v uint64
+	for i := 0; i < 9 && i < len(b); i++ {
+		if i == 8 {
+			return v<<8 | uint64(b[i]), 9
+		}
+		v = v<<7 | uint64(b[i]&0x7f)
+		if b[i]&0x80 == 0 {
+			return v, i + 1
+		}
+	}
+	return 0, 0
+}
+
+// readPayload returns the payload of a table leaf cell, following its overflow pages
+func (s *sqliteReader) readPayload(cell []byte) ([]byte, error) {
+	payloadSize, n := readVarint(cell)
+	if n == 0 {
+		return nil, fmt.Errorf("%w: invalid cell", errInvalidRpmDatabase)
+	}
+	_, m := readVarint(cell[n:])
+	if m == 0 {
+		return nil, fmt.Errorf("%w: invalid cell", errInvalidRpmDatabase)
+	}
+	cell = cell[n+m:]
+
+	// the payload spills to overflow pages when it doesn't fit in the page, see the
+	// "B-tree Pages" section of the sqlite file format documentation
+	usable := uint64(s.usableSize)
+	maxLocal := usable - 35
+	local := payloadSize
+	if payloadSize > maxLocal {
+		minLocal := (usable-12)*32/255 - 23
+		local = minLocal + (payloadSize-minLocal)%(usable-4)
+		if local > maxLocal {
+			local = minLocal
+		}
+	}
+
+	if uint64(len(cell)) < local {
+		return nil, fmt.Errorf("%w: truncated cell", errInvalidRpmDatabase)
+	}
+	payload := append([]byte{}, cell[:local]...)
+	if local == payloadSize {
+		return payload, nil
+	}
+
+	if uint64(len(cell)) < local+4 {
+		return nil, fmt.Errorf("%w: truncated cell", errInvalidRpmDatabase)
+	}
+	pgno := binary.BigEndian.Uint32(cell[local : local+4])
+	for visited := uint32(0); uint64(len(payload)) < payloadSize; visited++ {
+		if pgno == 0 || visited > s.pageCount {
+			return nil, fmt.Errorf("%w: invalid overflow pages", errInvalidRpmDatabase)
+		}
+
+		page, err := s.readPage(pgno)
+		if err != nil {
+			return nil, err
+		}
+
+		content := page[4:]
+		if remaining := payloadSize - uint64(len(payload)); uint64(len(content)) > remaining {
+			content = content[:remaining]
+		}
+		payload = append(payload, content...)
+		pgno = binary.BigEndian.Uint32(page[0:4])
+	}
+
+	return payload, nil
+}
+
+// walkTable calls fn with the payload of each row of the table b-tree rooted at the given page
+func (s *sqliteReader) walkTable(root uint32, fn func(payload []byte) error) error {
+	pages := []uint32{root}
+	for visited := uint32(0); len(pages) > 0; visited++ {
+		if visited > s.pageCount {
+			return fmt.Errorf("%w: b-tree pages loop", errInvalidRpmDatabase)
+		}
+
+		pgno := pages[len(pages)-1]
+		pages = pages[:len(pages)-1]
+
+		page, err := s.readPage(pgno)
+		if err != nil {
+			return err
+		}
+
+		// the first page starts with the database header
+		headerOffset := uint32(0)
+		if pgno == 1 {
+			headerOffset = sqliteHeaderSize
+		}
+
+		pageType := page[headerOffset]
+		cellCount := uint32(binary.BigEndian.Uint16(page[headerOffset+3 : headerOffset+5]))
+		cellPointers := headerOffset + 8
+		if pageType == sqliteInteriorTablePage {
+			cellPointers = headerOffset + 12
+			pages = append(pages, binary.BigEndian.Uint32(page[headerOffset+8:headerOffset+12]))
+		} else if pageType != sqliteLeafTablePage {
+			return fmt.Errorf("%w: page %d is not a table b-tree page", errInvalidRpmDatabase, pgno)
+		}
+
+		if cellPointers+2*cellCount > s.usableSize {
+			return fmt.Errorf("%w: invalid cell count on page %d", errInvalidRpmDatabase, pgno)
+		}
+
+		for i := uint32(0); i < cellCount; i++ {
+			offset := uint32(binary.BigEndian.Uint16(page[cellPointers+2*i : cellPointers+2*i+2]))
+			if offset >= s.usableSize {
+				return fmt.Errorf("%w: invalid cell offset on page %d", errInvalidRpmDatabase, pgno)
+			}
+
+			if pageType == sqliteInteriorTablePage {
+				if offset+4 > s.usableSize {
+					return fmt.Errorf("%w: invalid cell offset on page %d", errInvalidRpmDatabase, pgno)
+				}
+				pages = append(pages, binary.BigEndian.Uint32(page[offset:offset+4]))
+				continue
+			}
+
+			payload, err := s.readPayload(page[offset:])
+			if err != nil {
+				return err
+			}
+			if err := fn(payload); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// readRecord decodes the columns of a record, integers are returned as int64 and
+// strings and blobs as byte slices
+func readRecord(payload []byte) ([]interface{}, error) {
+	headerSize, n := readVarint(payload)
+	if n == 0 || headerSize > uint64(len(payload)) {
+		return nil, fmt.Errorf("%w: invalid record", errInvalidRpmDatabase)
+	}
+
+	var columns []interface{}
+	header, body := payload[n:headerSize], payload[headerSize:]
+	for len(header) > 0 {
+		serialType, n := readVarint(header)
+		if n == 0 {
+			return nil, fmt.Errorf("%w: invalid record", errInvalidRpmDatabase)
+		}
+		header = header[n:]
+
+		var size uint64
+		switch {
+		case serialType >= 1 && serialType <= 4:
+			size = serialType
+		case serialType == 5:
+			size = 6
+		case serialType == 6 || serialType == 7:
+			size = 8
+		case serialType >= 12:
+			size = (serialType - 12) / 2
+		}
+		if size > uint64(len(body)) {
+			return nil, fmt.Errorf("%w: truncated record", errInvalidRpmDatabase)
+		}
+		value := body[:size]
+		body = body[size:]
+
+		switch {
+		case serialType >= 1 && serialType <= 6:
+			// big-endian two's complement integers
+			v := int64(int8(value[0]))
+			for _, b := range value[1:] {
+				v = v<<8 | int64(b)
+			}
+			columns = append(columns, v)
+		case serialType == 8:
+			columns = append(columns, int64(0))
+		case serialType == 9:
+			columns = append(columns, int64(1))
+		case serialType >= 12:
+			columns = append(columns, value)
+		default:
+			columns = append(columns, nil)
+		}
+	}
+	return columns, nil
+}
+
+// findTable returns the root page of a table from the sqlite_schema table
+func (s *sqliteReader) findTable(name string) (uint32, error) {
+	var root uint32
+	err := s.walkTable(1, func(payload []byte) error {
+		columns, err := readRecord(payload)
+		if err != nil {
+			return err
+		}
+		if len(columns) <= sqliteSchemaColumnRoot {
+			return nil
+		}
+
+		kind, _ := columns[sqliteSchemaColumnType].([]byte)
+		tableName, _ := columns[sqliteSchemaColumnName].([]byte)
+		rootPage, _ := columns[sqliteSchemaColumnRoot].(int64)
+		if string(kind) == "table" && string(tableName) == name && rootPage > 0 {
+			root = uint32(rootPage)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	if root == 0 {
+		return 0, fmt.Errorf("%w: table %s not found", errInvalidRpmDatabase, name)
+	}
+	return root, nil
+}
+
+// readRpmSqlitePackages returns the packages listed in an rpm database in the sqlite format
+func readRpmSqlitePackages(path string) ([]packageInfo, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	reader, err := newSqliteReader(f)
+	if err != nil {
+		return nil, err
+	}
+
+	root, err := reader.findTable(rpmSqlitePackagesTable)
+	if err != nil {
+		return nil, err
+	}
+
+	var packages []packageInfo
+	err = reader.walkTable(root, func(payload []byte) error {
+		columns, err := readRecord(payload)
+		if err != nil {
+			return err
+		}
+		if len(columns) <= rpmSqliteBlobColumnIndex {
+			return fmt.Errorf("%w: missing header blob", errInvalidRpmDatabase)
+		}
+
+		blob, ok := columns[rpmSqliteBlobColumnIndex].([]byte)
+		if !ok {
+			return fmt.Errorf("%w: missing header blob", errInvalidRpmDatabase)
+		}
+
+		info, err := parseRpmHeader(blob)
+		if err != nil {
+			return err
+		}
+		packages = append(packages, info)
+		return nil
+	})
+	return packages, err
+}
@@ -210,6 +210,10 @@ func resourceKindToResolverAndFields(env env.Env, ruleID string, kind compliance
 		return resolveKubeapiserver, kubeResourceReportedFields, nil
 	case compliance.KindConstants:
 		return resolveConstants, nil, nil
+	case compliance.KindPackage:
+		return resolvePackage, packageReportedFields, nil
+	case compliance.KindSystemdUnit:
+		return resolveSystemdUnit, systemdUnitReportedFields, nil
 	default:
 		return nil, nil, ErrResourceKindNotSupported
 	}
@@ -0,0 +1,83 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package checks
+
+import (
+	"fmt"
+
+	"github.com/DataDog/datadog-agent/pkg/compliance"
+	"github.com/DataDog/datadog-agent/pkg/compliance/checks/env"
+	"github.com/DataDog/datadog-agent/pkg/compliance/eval"
+	"github.com/DataDog/datadog-agent/pkg/compliance/event"
+)
+
+// offlineSupportedKinds lists the resource kinds that can be evaluated against an offline root filesystem
+var offlineSupportedKinds = map[compliance.ResourceKind]bool{
+	compliance.KindFile:      true,
+	compliance.KindGroup:     true,
+	compliance.KindConstants: true,
+	compliance.KindPackage:   true,
+}
+
+func resourcesKinds(resources []compliance.Resource) []compliance.ResourceKind {
+	var kinds []compliance.ResourceKind
+	for _, resource := range resources {
+		kinds = append(kinds, resource.Kind())
+		if resource.Fallback != nil {
+			kinds = append(kinds, resourcesKinds([]compliance.Resource{resource.Fallback.Resource})...)
+		}
+	}
+	return kinds
+}
+
+func regoInputsKinds(inputs []compliance.RegoInput) []compliance.ResourceKind {
+	var kinds []compliance.ResourceKind
+	for _, input := range inputs {
+		kinds = append(kinds, input.Kind())
+	}
+	return kinds
+}
+
+// offlineUnsupportedKind returns the first kind that can't be evaluated against the configured
+// offline root filesystem, if any
+func (b *builder) offlineUnsupportedKind(kinds []compliance.ResourceKind) compliance.ResourceKind {
+	if b.rootfs == "" {
+		return ""
+	}
+
+	for _, kind := range kinds {
+		if !offlineSupportedKinds[kind] {
+			return kind
+		}
+	}
+	return ""
+}
+
+func offlineUnsupportedFunc(funcName string) eval.Function {
+	return func(_ eval.Instance, args ...interface{}) (interface{}, error) {
+		return nil, fmt.Errorf(`"%s()" can't be evaluated against an offline root filesystem`, funcName)
+	}
+}
+
+// notApplicableCheck reports rules relying on resources that only exist on a live host
+type notApplicableCheck struct {
+	kind compliance.ResourceKind
+}
+
+func newNotApplicableCheck(kind compliance.ResourceKind) checkable {
+	return &notApplicableCheck{kind: kind}
+}
+
+func (c *notApplicableCheck) check(_ env.Env) []*compliance.Report {
+	return []*compliance.Report{
+		{
+			Data: event.Data{
+				"reason": fmt.Sprintf("%s resources can't be evaluated against an offline root filesystem", c.kind),
+			},
+			NotApplicable: true,
+		},
+	}
+}
@@ -0,0 +1,97 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build !windows
+// +build !windows
+
+package checks
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/DataDog/datadog-agent/pkg/compliance"
+	"github.com/DataDog/datadog-agent/pkg/compliance/event"
+
+	assert "github.com/stretchr/testify/require"
+)
+
+const rootFSSuite = `schema:
+  version: 1.0
+name: Root filesystem suite
+framework: rootfs
+version: 1.0.0
+rules:
+- id: rootfs-file
+  scope:
+    - docker
+  resources:
+    - file:
+        path: /etc/docker/daemon.json
+      condition: file.permissions == 0644
+- id: rootfs-group
+  scope:
+    - docker
+  resources:
+    - group:
+        name: docker
+      condition: '"alice" in group.users'
+- id: rootfs-process
+  scope:
+    - docker
+  resources:
+    - process:
+        name: dockerd
+      condition: process.flag("--icc") == "false"
+`
+
+type rootFSReporter struct {
+	events map[string]*event.Event
+}
+
+func (r *rootFSReporter) Report(e *event.Event) {
+	r.events[e.AgentRuleID] = e
+}
+
+func (r *rootFSReporter) ReportRaw(content []byte, service string, tags ...string) {}
+
+func TestRootFSChecks(t *testing.T) {
+	assert := assert.New(t)
+
+	rootfs := t.TempDir()
+	assert.NoError(os.MkdirAll(filepath.Join(rootfs, "etc", "docker"), 0755))
+	assert.NoError(os.WriteFile(filepath.Join(rootfs, "etc", "docker", "daemon.json"), []byte("{}"), 0644))
+	assert.NoError(os.Chmod(filepath.Join(rootfs, "etc", "docker", "daemon.json"), 0644))
+	assert.NoError(os.WriteFile(filepath.Join(rootfs, "etc", "group"), []byte("root:x:0:\ndocker:x:412:alice,bob\n"), 0644))
+
+	suite := filepath.Join(t.TempDir(), "suite.yaml")
+	assert.NoError(os.WriteFile(suite, []byte(rootFSSuite), 0644))
+
+	reporter := &rootFSReporter{events: make(map[string]*event.Event)}
+	b, err := NewBuilder(reporter, WithHostname("image"), WithRootFS(rootfs))
+	assert.NoError(err)
+
+	err = b.ChecksFromFile(suite, func(rule *compliance.RuleCommon, check compliance.Check, err error) bool {
+		assert.NoError(err)
+		_ = check.Run()
+		return true
+	})
+	assert.NoError(err)
+
+	assert.Len(reporter.events, 3)
+	assert.Equal(event.Passed, reporter.events["rootfs-file"].Result)
+	assert.Equal("/etc/docker/daemon.json", reporter.events["rootfs-file"].Data.(event.Data)["file.path"])
+	assert.Equal(event.Passed, reporter.events["rootfs-group"].Result)
+	assert.Equal(event.NotApplicable, reporter.events["rootfs-process"].Result)
+}
+
+func TestWithRootFSNotDirectory(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "file")
+	assert.NoError(t, os.WriteFile(file, nil, 0644))
+
+	_, err := NewBuilder(&rootFSReporter{}, WithRootFS(file))
+	assert.Error(t, err)
+}
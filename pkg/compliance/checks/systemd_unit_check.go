@@ -0,0 +1,113 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package checks
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/DataDog/datadog-agent/pkg/compliance"
+	"github.com/DataDog/datadog-agent/pkg/compliance/checks/env"
+	"github.com/DataDog/datadog-agent/pkg/compliance/eval"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+var systemdUnitReportedFields = []string{
+	compliance.SystemdUnitFieldName,
+	compliance.SystemdUnitFieldPath,
+	compliance.SystemdUnitFieldLoaded,
+	compliance.SystemdUnitFieldEnabled,
+	compliance.SystemdUnitFieldMasked,
+	compliance.SystemdUnitFieldActive,
+}
+
+// systemdUnitDirs lists the directories unit files are loaded from, by order of precedence
+var systemdUnitDirs = []string{
+	"/etc/systemd/system",
+	"/run/systemd/system",
+	"/usr/local/lib/systemd/system",
+	"/usr/lib/systemd/system",
+	"/lib/systemd/system",
+}
+
+const (
+	systemdConfigDir = "/etc/systemd/system"
+	// systemd keeps a symlink to the invocation ID of each active unit in this directory
+	systemdRuntimeUnitsDir = "/run/systemd/units"
+)
+
+func resolveSystemdUnit(_ context.Context, e env.Env, id string, res compliance.ResourceCommon, rego bool) (resolved, error) {
+	if res.SystemdUnit == nil {
+		return nil, fmt.Errorf("%s: expecting systemd unit resource in systemd unit check", id)
+	}
+
+	name := res.SystemdUnit.Name
+	if filepath.Ext(name) == "" {
+		name += ".service"
+	}
+
+	log.Debugf("%s: looking for systemd unit %s", id, name)
+
+	var unitPath string
+	masked := false
+	for _, dir := range systemdUnitDirs {
+		path := filepath.Join(dir, name)
+		fi, err := os.Lstat(e.NormalizeToHostRoot(path))
+		if err != nil {
+			continue
+		}
+
+		if fi.Mode()&os.ModeSymlink != 0 {
+			// links are not followed, they are relative to the host root
+			if target, err := os.Readlink(e.NormalizeToHostRoot(path)); err == nil && target == os.DevNull {
+				masked = true
+			}
+		}
+
+		unitPath = path
+		break
+	}
+
+	enabled := false
+	for _, dir := range []string{"*.wants", "*.requires"} {
+		matches, err := filepath.Glob(e.NormalizeToHostRoot(filepath.Join(systemdConfigDir, dir, name)))
+		if err == nil && len(matches) > 0 {
+			enabled = true
+			break
+		}
+	}
+
+	active := false
+	if _, err := os.Lstat(e.NormalizeToHostRoot(filepath.Join(systemdRuntimeUnitsDir, "invocation:"+name))); err == nil {
+		active = true
+	}
+
+	loaded := unitPath != "" && !masked
+
+	instance := eval.NewInstance(
+		eval.VarMap{
+			compliance.SystemdUnitFieldName:    name,
+			compliance.SystemdUnitFieldPath:    unitPath,
+			compliance.SystemdUnitFieldLoaded:  loaded,
+			compliance.SystemdUnitFieldEnabled: enabled && !masked,
+			compliance.SystemdUnitFieldMasked:  masked,
+			compliance.SystemdUnitFieldActive:  active,
+		},
+		nil,
+		eval.RegoInputMap{
+			"name":    name,
+			"path":    unitPath,
+			"loaded":  loaded,
+			"enabled": enabled && !masked,
+			"masked":  masked,
+			"active":  active,
+		},
+	)
+
+	return newResolvedInstance(instance, name, "systemd_unit"), nil
+}
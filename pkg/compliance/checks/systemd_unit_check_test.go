@@ -0,0 +1,155 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build !windows
+// +build !windows
+
+package checks
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/DataDog/datadog-agent/pkg/compliance"
+	"github.com/DataDog/datadog-agent/pkg/compliance/event"
+	"github.com/DataDog/datadog-agent/pkg/compliance/mocks"
+
+	"github.com/stretchr/testify/mock"
+	assert "github.com/stretchr/testify/require"
+)
+
+func setupSystemdRoot(t *testing.T) string {
+	root := t.TempDir()
+
+	files := []string{
+		"/lib/systemd/system/docker.service",
+		"/lib/systemd/system/containerd.service",
+		"/lib/systemd/system/telnet.socket",
+		"/run/systemd/units/invocation:docker.service",
+	}
+	for _, file := range files {
+		assert.NoError(t, os.MkdirAll(filepath.Join(root, filepath.Dir(file)), 0755))
+		assert.NoError(t, os.WriteFile(filepath.Join(root, file), []byte("[Unit]\n"), 0644))
+	}
+
+	links := map[string]string{
+		"/etc/systemd/system/multi-user.target.wants/docker.service": "/lib/systemd/system/docker.service",
+		"/etc/systemd/system/telnet.socket":                          "/dev/null",
+	}
+	for link, target := range links {
+		assert.NoError(t, os.MkdirAll(filepath.Join(root, filepath.Dir(link)), 0755))
+		assert.NoError(t, os.Symlink(target, filepath.Join(root, link)))
+	}
+
+	return root
+}
+
+func TestSystemdUnitCheck(t *testing.T) {
+	root := setupSystemdRoot(t)
+
+	tests := []struct {
+		name     string
+		resource compliance.Resource
+
+		expectReport *compliance.Report
+	}{
+		{
+			name: "enabled and running service",
+			resource: compliance.Resource{
+				ResourceCommon: compliance.ResourceCommon{
+					SystemdUnit: &compliance.SystemdUnit{
+						Name: "docker",
+					},
+				},
+				Condition: `unit.enabled && unit.active`,
+			},
+			expectReport: &compliance.Report{
+				Passed: true,
+				Data: event.Data{
+					"unit.name":    "docker.service",
+					"unit.path":    "/lib/systemd/system/docker.service",
+					"unit.loaded":  true,
+					"unit.enabled": true,
+					"unit.masked":  false,
+					"unit.active":  true,
+				},
+				Resource: compliance.ReportResource{
+					ID:   "docker.service",
+					Type: "systemd_unit",
+				},
+			},
+		},
+		{
+			name: "disabled service",
+			resource: compliance.Resource{
+				ResourceCommon: compliance.ResourceCommon{
+					SystemdUnit: &compliance.SystemdUnit{
+						Name: "containerd.service",
+					},
+				},
+				Condition: `unit.enabled`,
+			},
+			expectReport: &compliance.Report{
+				Passed: false,
+				Data: event.Data{
+					"unit.name":    "containerd.service",
+					"unit.path":    "/lib/systemd/system/containerd.service",
+					"unit.loaded":  true,
+					"unit.enabled": false,
+					"unit.masked":  false,
+					"unit.active":  false,
+				},
+				Resource: compliance.ReportResource{
+					ID:   "containerd.service",
+					Type: "systemd_unit",
+				},
+			},
+		},
+		{
+			name: "masked socket",
+			resource: compliance.Resource{
+				ResourceCommon: compliance.ResourceCommon{
+					SystemdUnit: &compliance.SystemdUnit{
+						Name: "telnet.socket",
+					},
+				},
+				Condition: `unit.masked || !unit.loaded`,
+			},
+			expectReport: &compliance.Report{
+				Passed: true,
+				Data: event.Data{
+					"unit.name":    "telnet.socket",
+					"unit.path":    "/etc/systemd/system/telnet.socket",
+					"unit.loaded":  false,
+					"unit.enabled": false,
+					"unit.masked":  true,
+					"unit.active":  false,
+				},
+				Resource: compliance.ReportResource{
+					ID:   "telnet.socket",
+					Type: "systemd_unit",
+				},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			env := &mocks.Env{}
+			env.On("NormalizeToHostRoot", mock.AnythingOfType("string")).Return(func(path string) string {
+				return filepath.Join(root, path)
+			})
+
+			unitCheck, err := newResourceCheck(env, "rule-id", test.resource)
+			assert.NoError(err)
+
+			reports := unitCheck.check(env)
+			assert.Equal(test.expectReport, reports[0])
+		})
+	}
+}
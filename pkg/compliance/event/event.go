@@ -14,6 +14,10 @@ const (
 	Failed = "failed"
 	// Error is used to report result of a rule check that resulted in an error (unable to evaluate condition)
 	Error = "error"
+	// NotApplicable is used to report result of a rule check that can't be evaluated in the current environment
+	NotApplicable = "not_applicable"
+	// Removed is used to report that a resource is no longer evaluated by a rule
+	Removed = "removed"
 )
 
 // Data defines a key value map for storing attributes of a reported rule event
@@ -32,4 +36,28 @@ type Event struct {
 	Data             interface{} `json:"data,omitempty"`
 	ExpireAt         time.Time   `json:"expire_at,omitempty"`
 	Evaluator        string      `json:"evaluator,omitempty"`
+	Drift            *Drift      `json:"drift,omitempty"`
+}
+
+const (
+	// DriftNew is used when a resource is reported for the first time
+	DriftNew = "new"
+	// DriftChanged is used when the result of a rule changed for a resource
+	DriftChanged = "changed"
+	// DriftRemoved is used when a previously reported resource is no longer reported
+	DriftRemoved = "removed"
+)
+
+// DataDiff holds the previous and current value of an evaluated attribute
+type DataDiff struct {
+	Before interface{} `json:"before,omitempty"`
+	After  interface{} `json:"after,omitempty"`
+}
+
+// Drift describes how the result of a rule changed for a resource since the previous run
+type Drift struct {
+	Change         string              `json:"change"`
+	PreviousResult string              `json:"previous_result,omitempty"`
+	PreviousSince  time.Time           `json:"previous_since,omitempty"`
+	Diff           map[string]DataDiff `json:"diff,omitempty"`
 }
@@ -0,0 +1,135 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package output
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/DataDog/datadog-agent/pkg/compliance/event"
+)
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Errors   int              `xml:"errors,attr"`
+	Skipped  int              `xml:"skipped,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Errors    int             `xml:"errors,attr"`
+	Skipped   int             `xml:"skipped,attr"`
+	TestCases []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	ClassName string        `xml:"classname,attr"`
+	Name      string        `xml:"name,attr"`
+	Failure   *junitMessage `xml:"failure,omitempty"`
+	Error     *junitMessage `xml:"error,omitempty"`
+	Skipped   *junitMessage `xml:"skipped,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitMessage struct {
+	Message string `xml:"message,attr,omitempty"`
+	Text    string `xml:",chardata"`
+}
+
+func junitSuiteName(info *RuleInfo) string {
+	if info.Suite.Name == "" {
+		return info.Suite.Framework
+	}
+	if info.Suite.Version == "" {
+		return info.Suite.Name
+	}
+	return fmt.Sprintf("%s %s", info.Suite.Name, info.Suite.Version)
+}
+
+func junitFailureText(e *event.Event, info *RuleInfo) string {
+	var lines []string
+	if info.Rule.Remediation != "" {
+		lines = append(lines, fmt.Sprintf("Remediation: %s", info.Rule.Remediation))
+	}
+	if details := eventDetails(e); details != "" {
+		lines = append(lines, fmt.Sprintf("Data: %s", details))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// WriteJUnit writes the events as a JUnit XML report, with one test suite per framework
+// and one test case per rule and resource
+func WriteJUnit(w io.Writer, events []*event.Event, rules Rules) error {
+	report := junitTestSuites{Name: toolName}
+
+	suiteIndexes := make(map[string]int)
+	for _, e := range sortEvents(events) {
+		info := rules.get(e)
+
+		index, found := suiteIndexes[e.AgentFrameworkID]
+		if !found {
+			index = len(report.Suites)
+			suiteIndexes[e.AgentFrameworkID] = index
+			report.Suites = append(report.Suites, junitTestSuite{Name: junitSuiteName(info)})
+		}
+		suite := &report.Suites[index]
+
+		testCase := junitTestCase{
+			ClassName: e.AgentFrameworkID,
+			Name:      fmt.Sprintf("%s: %s [%s]", e.AgentRuleID, info.description(), resourceName(e)),
+		}
+
+		switch e.Result {
+		case event.Passed:
+		case event.Failed:
+			testCase.Failure = &junitMessage{
+				Message: fmt.Sprintf("rule %s failed on %s", e.AgentRuleID, resourceName(e)),
+				Text:    junitFailureText(e, info),
+			}
+			suite.Failures++
+		case event.NotApplicable:
+			testCase.Skipped = &junitMessage{Message: eventReason(e)}
+			suite.Skipped++
+		default:
+			testCase.Error = &junitMessage{
+				Message: eventError(e),
+				Text:    eventDetails(e),
+			}
+			suite.Errors++
+		}
+
+		suite.Tests++
+		suite.TestCases = append(suite.TestCases, testCase)
+	}
+
+	for _, suite := range report.Suites {
+		report.Tests += suite.Tests
+		report.Failures += suite.Failures
+		report.Errors += suite.Errors
+		report.Skipped += suite.Skipped
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(&report); err != nil {
+		return err
+	}
+
+	_, err := io.WriteString(w, "\n")
+	return err
+}
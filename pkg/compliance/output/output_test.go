@@ -0,0 +1,122 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"testing"
+
+	"github.com/DataDog/datadog-agent/pkg/compliance"
+	"github.com/DataDog/datadog-agent/pkg/compliance/event"
+
+	assert "github.com/stretchr/testify/require"
+)
+
+var testRules = Rules{
+	"cis-docker-1": {
+		Rule: compliance.RuleCommon{
+			ID:          "cis-docker-1",
+			Description: "Ensure docker.service file permissions are set to 644",
+			Remediation: "chmod 644 /usr/lib/systemd/system/docker.service",
+		},
+		Suite: compliance.SuiteMeta{Name: "CIS Docker Generic", Framework: "cis-docker", Version: "1.2.0"},
+	},
+}
+
+var testEvents = []*event.Event{
+	{
+		AgentRuleID:      "cis-docker-2",
+		AgentFrameworkID: "cis-docker",
+		Result:           event.Error,
+		ResourceType:     "docker_daemon",
+		ResourceID:       "host",
+		Data:             event.Data{"error": "no docker client"},
+	},
+	{
+		AgentRuleID:      "cis-docker-1",
+		AgentFrameworkID: "cis-docker",
+		Result:           event.Failed,
+		ResourceType:     "docker_daemon",
+		ResourceID:       "host",
+		Data:             event.Data{"file.permissions": 0600},
+	},
+	{
+		AgentRuleID:      "cis-docker-1",
+		AgentFrameworkID: "cis-docker",
+		Result:           event.Passed,
+		ResourceType:     "docker_daemon",
+		ResourceID:       "container",
+	},
+	{
+		AgentRuleID:      "cis-docker-3",
+		AgentFrameworkID: "cis-docker",
+		Result:           event.NotApplicable,
+		ResourceType:     "docker_daemon",
+		ResourceID:       "host",
+		Data:             event.Data{"reason": "process resources can't be evaluated"},
+	},
+}
+
+func TestWriteSARIF(t *testing.T) {
+	assert := assert.New(t)
+
+	var buf bytes.Buffer
+	assert.NoError(WriteSARIF(&buf, testEvents, testRules))
+
+	var log sarifLog
+	assert.NoError(json.Unmarshal(buf.Bytes(), &log))
+	assert.Equal("2.1.0", log.Version)
+	assert.Len(log.Runs, 1)
+
+	run := log.Runs[0]
+	assert.Len(run.Tool.Driver.Rules, 3)
+	assert.Equal("cis-docker-1", run.Tool.Driver.Rules[0].ID)
+	assert.Equal("Ensure docker.service file permissions are set to 644", run.Tool.Driver.Rules[0].ShortDescription.Text)
+	assert.Equal("chmod 644 /usr/lib/systemd/system/docker.service", run.Tool.Driver.Rules[0].Help.Text)
+	assert.Equal("cis-docker-2", run.Tool.Driver.Rules[1].ShortDescription.Text)
+
+	assert.Len(run.Results, 4)
+
+	kinds := make([]string, 0, len(run.Results))
+	for _, result := range run.Results {
+		kinds = append(kinds, result.Kind+"/"+result.Level)
+	}
+	assert.Equal([]string{"pass/none", "fail/error", "open/none", "notApplicable/none"}, kinds)
+
+	failed := run.Results[1]
+	assert.Equal(0, failed.RuleIndex)
+	assert.Equal("docker_daemon/host", failed.Locations[0].LogicalLocations[0].FullyQualifiedName)
+	assert.Equal("Ensure docker.service file permissions are set to 644: failed on docker_daemon host", failed.Message.Text)
+}
+
+func TestWriteJUnit(t *testing.T) {
+	assert := assert.New(t)
+
+	var buf bytes.Buffer
+	assert.NoError(WriteJUnit(&buf, testEvents, testRules))
+
+	var report junitTestSuites
+	assert.NoError(xml.Unmarshal(buf.Bytes(), &report))
+	assert.Equal(4, report.Tests)
+	assert.Equal(1, report.Failures)
+	assert.Equal(1, report.Errors)
+	assert.Equal(1, report.Skipped)
+
+	assert.Len(report.Suites, 1)
+	suite := report.Suites[0]
+	assert.Equal("CIS Docker Generic 1.2.0", suite.Name)
+	assert.Len(suite.TestCases, 4)
+
+	failed := suite.TestCases[1]
+	assert.Equal("cis-docker-1: Ensure docker.service file permissions are set to 644 [docker_daemon host]", failed.Name)
+	assert.NotNil(failed.Failure)
+	assert.Equal("Remediation: chmod 644 /usr/lib/systemd/system/docker.service\nData: {\"file.permissions\":384}", failed.Failure.Text)
+
+	assert.Equal("no docker client", suite.TestCases[2].Error.Message)
+	assert.Equal("process resources can't be evaluated", suite.TestCases[3].Skipped.Message)
+}
@@ -0,0 +1,108 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+// Package output renders compliance check results in formats consumed by third party tools
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/DataDog/datadog-agent/pkg/compliance"
+	"github.com/DataDog/datadog-agent/pkg/compliance/event"
+)
+
+// RuleInfo holds the metadata of a rule used to render its results
+type RuleInfo struct {
+	Rule  compliance.RuleCommon
+	Suite compliance.SuiteMeta
+}
+
+// Rules maps rule IDs to their metadata
+type Rules map[string]*RuleInfo
+
+// AddSuite adds the rules of a suite
+func (r Rules) AddSuite(suite *compliance.Suite) {
+	for _, rule := range suite.Rules {
+		r[rule.ID] = &RuleInfo{Rule: rule.RuleCommon, Suite: suite.Meta}
+	}
+	for _, rule := range suite.RegoRules {
+		r[rule.ID] = &RuleInfo{Rule: rule.RuleCommon, Suite: suite.Meta}
+	}
+}
+
+// get returns the metadata of a rule, falling back to what the event holds for unknown rules
+func (r Rules) get(e *event.Event) *RuleInfo {
+	if info, found := r[e.AgentRuleID]; found {
+		return info
+	}
+	return &RuleInfo{
+		Rule:  compliance.RuleCommon{ID: e.AgentRuleID},
+		Suite: compliance.SuiteMeta{Framework: e.AgentFrameworkID},
+	}
+}
+
+func (i *RuleInfo) description() string {
+	if i.Rule.Description != "" {
+		return i.Rule.Description
+	}
+	return i.Rule.ID
+}
+
+// sortEvents returns the events ordered by framework, rule and resource
+func sortEvents(events []*event.Event) []*event.Event {
+	sorted := make([]*event.Event, len(events))
+	copy(sorted, events)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		a, b := sorted[i], sorted[j]
+		if a.AgentFrameworkID != b.AgentFrameworkID {
+			return a.AgentFrameworkID < b.AgentFrameworkID
+		}
+		if a.AgentRuleID != b.AgentRuleID {
+			return a.AgentRuleID < b.AgentRuleID
+		}
+		if a.ResourceType != b.ResourceType {
+			return a.ResourceType < b.ResourceType
+		}
+		return a.ResourceID < b.ResourceID
+	})
+	return sorted
+}
+
+func resourceName(e *event.Event) string {
+	return fmt.Sprintf("%s %s", e.ResourceType, e.ResourceID)
+}
+
+// eventError returns the error reported by a check that couldn't be evaluated
+func eventError(e *event.Event) string {
+	if data, ok := e.Data.(event.Data); ok {
+		if err, ok := data["error"].(string); ok {
+			return err
+		}
+	}
+	return ""
+}
+
+// eventReason returns the reason reported by a check that isn't applicable
+func eventReason(e *event.Event) string {
+	if data, ok := e.Data.(event.Data); ok {
+		if reason, ok := data["reason"].(string); ok {
+			return reason
+		}
+	}
+	return ""
+}
+
+func eventDetails(e *event.Event) string {
+	if e.Data == nil {
+		return ""
+	}
+	details, err := json.Marshal(e.Data)
+	if err != nil {
+		return fmt.Sprintf("%v", e.Data)
+	}
+	return string(details)
+}
@@ -0,0 +1,180 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/DataDog/datadog-agent/pkg/compliance/event"
+	"github.com/DataDog/datadog-agent/pkg/version"
+)
+
+const (
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+	sarifVersion = "2.1.0"
+	toolName     = "datadog-security-agent"
+	toolURI      = "https://docs.datadoghq.com/security_platform/cspm/"
+)
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	Version        string      `json:"version,omitempty"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifRule struct {
+	ID               string                 `json:"id"`
+	ShortDescription sarifMessage           `json:"shortDescription"`
+	Help             *sarifMessage          `json:"help,omitempty"`
+	Properties       map[string]interface{} `json:"properties,omitempty"`
+}
+
+type sarifResult struct {
+	RuleID     string                 `json:"ruleId"`
+	RuleIndex  int                    `json:"ruleIndex"`
+	Kind       string                 `json:"kind"`
+	Level      string                 `json:"level"`
+	Message    sarifMessage           `json:"message"`
+	Locations  []sarifLocation        `json:"locations,omitempty"`
+	Properties map[string]interface{} `json:"properties,omitempty"`
+}
+
+type sarifLocation struct {
+	LogicalLocations []sarifLogicalLocation `json:"logicalLocations"`
+}
+
+type sarifLogicalLocation struct {
+	Name               string `json:"name"`
+	FullyQualifiedName string `json:"fullyQualifiedName"`
+	Kind               string `json:"kind,omitempty"`
+}
+
+// sarifKindAndLevel maps a compliance result to a SARIF result kind and level
+func sarifKindAndLevel(result string) (string, string) {
+	switch result {
+	case event.Passed:
+		return "pass", "none"
+	case event.Failed:
+		return "fail", "error"
+	case event.NotApplicable:
+		return "notApplicable", "none"
+	default:
+		// the rule couldn't be evaluated, the result needs to be reviewed
+		return "open", "none"
+	}
+}
+
+func sarifResultMessage(e *event.Event, info *RuleInfo) string {
+	switch e.Result {
+	case event.Error:
+		return fmt.Sprintf("%s: evaluation failed on %s: %s", info.description(), resourceName(e), eventError(e))
+	case event.NotApplicable:
+		return fmt.Sprintf("%s: not applicable to %s: %s", info.description(), resourceName(e), eventReason(e))
+	default:
+		return fmt.Sprintf("%s: %s on %s", info.description(), e.Result, resourceName(e))
+	}
+}
+
+// WriteSARIF writes the events as a SARIF 2.1 log
+func WriteSARIF(w io.Writer, events []*event.Event, rules Rules) error {
+	run := sarifRun{
+		Tool: sarifTool{
+			Driver: sarifDriver{
+				Name:           toolName,
+				Version:        version.AgentVersion,
+				InformationURI: toolURI,
+				Rules:          []sarifRule{},
+			},
+		},
+		Results: []sarifResult{},
+	}
+
+	ruleIndexes := make(map[string]int)
+	for _, e := range sortEvents(events) {
+		info := rules.get(e)
+
+		index, found := ruleIndexes[e.AgentRuleID]
+		if !found {
+			rule := sarifRule{
+				ID:               e.AgentRuleID,
+				ShortDescription: sarifMessage{Text: info.description()},
+				Properties: map[string]interface{}{
+					"framework": info.Suite.Framework,
+				},
+			}
+			if info.Rule.Remediation != "" {
+				rule.Help = &sarifMessage{Text: info.Rule.Remediation}
+			}
+			if info.Suite.Version != "" {
+				rule.Properties["frameworkVersion"] = info.Suite.Version
+			}
+			if len(info.Suite.Tags) > 0 {
+				rule.Properties["tags"] = info.Suite.Tags
+			}
+
+			index = len(run.Tool.Driver.Rules)
+			ruleIndexes[e.AgentRuleID] = index
+			run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, rule)
+		}
+
+		kind, level := sarifKindAndLevel(e.Result)
+		result := sarifResult{
+			RuleID:    e.AgentRuleID,
+			RuleIndex: index,
+			Kind:      kind,
+			Level:     level,
+			Message:   sarifMessage{Text: sarifResultMessage(e, info)},
+			Properties: map[string]interface{}{
+				"result":    e.Result,
+				"framework": e.AgentFrameworkID,
+			},
+		}
+		if e.ResourceID != "" {
+			result.Locations = []sarifLocation{{
+				LogicalLocations: []sarifLogicalLocation{{
+					Name:               e.ResourceID,
+					FullyQualifiedName: fmt.Sprintf("%s/%s", e.ResourceType, e.ResourceID),
+					Kind:               e.ResourceType,
+				}},
+			}}
+		}
+		if e.Data != nil {
+			result.Properties["data"] = e.Data
+		}
+
+		run.Results = append(run.Results, result)
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(&sarifLog{
+		Schema:  sarifSchema,
+		Version: sarifVersion,
+		Runs:    []sarifRun{run},
+	})
+}
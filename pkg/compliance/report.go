@@ -23,6 +23,8 @@ type Report struct {
 	Evaluator string
 	// Error of th check evaluation
 	Error error
+	// NotApplicable defines whether the check can't be evaluated in the current environment
+	NotApplicable bool
 }
 
 // ReportResource holds the id and type of the resource associated with a report
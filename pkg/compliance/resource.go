@@ -34,6 +34,10 @@ const (
 	KindConstants = ResourceKind("constants")
 	// KindCustom is used for a Custom check
 	KindCustom = ResourceKind("custom")
+	// KindPackage is used for a Package resource
+	KindPackage = ResourceKind("package")
+	// KindSystemdUnit is used for a SystemdUnit resource
+	KindSystemdUnit = ResourceKind("systemd_unit")
 )
 
 // ResourceCommon describes the base fields of resource types
@@ -47,6 +51,8 @@ type ResourceCommon struct {
 	KubeApiserver *KubernetesResource `yaml:"kubeApiserver,omitempty"`
 	Constants     *ConstantsResource  `yaml:"constants,omitempty"`
 	Custom        *Custom             `yaml:"custom,omitempty"`
+	Package       *Package            `yaml:"package,omitempty"`
+	SystemdUnit   *SystemdUnit        `yaml:"systemdUnit,omitempty"`
 }
 
 // Resource describes supported resource types observed by a Rule
@@ -96,6 +102,10 @@ func (r *ResourceCommon) Kind() ResourceKind {
 		return KindConstants
 	case r.Custom != nil:
 		return KindCustom
+	case r.Package != nil:
+		return KindPackage
+	case r.SystemdUnit != nil:
+		return KindSystemdUnit
 	default:
 		return KindInvalid
 	}
@@ -301,3 +311,33 @@ type Custom struct {
 	Name      string            `yaml:"name"`
 	Variables map[string]string `yaml:"variables,omitempty"`
 }
+
+// Fields & functions available for Package
+const (
+	PackageFieldName      = "package.name"
+	PackageFieldVersion   = "package.version"
+	PackageFieldInstalled = "package.installed"
+	PackageFieldManager   = "package.manager"
+
+	PackageFuncVersionAtLeast = "package.versionAtLeast"
+)
+
+// Package describes a package installed with the system package manager (dpkg, rpm or apk)
+type Package struct {
+	Name string `yaml:"name"`
+}
+
+// Fields & functions available for SystemdUnit
+const (
+	SystemdUnitFieldName    = "unit.name"
+	SystemdUnitFieldPath    = "unit.path"
+	SystemdUnitFieldLoaded  = "unit.loaded"
+	SystemdUnitFieldEnabled = "unit.enabled"
+	SystemdUnitFieldMasked  = "unit.masked"
+	SystemdUnitFieldActive  = "unit.active"
+)
+
+// SystemdUnit describes a systemd unit, its unit file and its state
+type SystemdUnit struct {
+	Name string `yaml:"name"`
+}
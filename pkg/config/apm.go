@@ -44,6 +44,14 @@ func setupAPM(config Config) {
 	config.SetKnown("apm_config.service_writer.queue_size")
 	config.SetKnown("apm_config.stats_writer.connection_limit")
 	config.SetKnown("apm_config.stats_writer.queue_size")
+	config.SetKnown("apm_config.trace_writer.spool.enabled")
+	config.SetKnown("apm_config.trace_writer.spool.path")
+	config.SetKnown("apm_config.trace_writer.spool.max_size_mb")
+	config.SetKnown("apm_config.trace_writer.spool.max_age_seconds")
+	config.SetKnown("apm_config.stats_writer.spool.enabled")
+	config.SetKnown("apm_config.stats_writer.spool.path")
+	config.SetKnown("apm_config.stats_writer.spool.max_size_mb")
+	config.SetKnown("apm_config.stats_writer.spool.max_age_seconds")
 	config.SetKnown("apm_config.analyzed_rate_by_service.*")
 	config.SetKnown("apm_config.log_throttling")
 	config.SetKnown("apm_config.bucket_size_seconds")
@@ -72,6 +80,9 @@ func setupAPM(config Config) {
 	config.BindEnv("apm_config.errors_per_second", "DD_APM_ERROR_TPS")
 	config.BindEnv("apm_config.disable_rare_sampler", "DD_APM_DISABLE_RARE_SAMPLER")
 	config.BindEnv("apm_config.max_remote_traces_per_second", "DD_APM_MAX_REMOTE_TPS")
+	config.BindEnv("apm_config.sampling_rules", "DD_APM_SAMPLING_RULES")
+	config.BindEnv("apm_config.latency_sampler.traces_per_second", "DD_APM_LATENCY_SAMPLER_TPS")
+	config.BindEnv("apm_config.latency_sampler.percentile", "DD_APM_LATENCY_SAMPLER_PERCENTILE")
 
 	config.BindEnv("apm_config.max_memory", "DD_APM_MAX_MEMORY")
 	config.BindEnv("apm_config.max_cpu_percent", "DD_APM_MAX_CPU_PERCENT")
@@ -89,6 +100,9 @@ func setupAPM(config Config) {
 	config.BindEnv("apm_config.receiver_socket", "DD_APM_RECEIVER_SOCKET")
 	config.BindEnv("apm_config.windows_pipe_name", "DD_APM_WINDOWS_PIPE_NAME")
 	config.BindEnv("apm_config.sync_flushing", "DD_APM_SYNC_FLUSHING")
+	config.BindEnv("apm_config.tap.enabled", "DD_APM_TAP_ENABLED")
+	config.BindEnv("apm_config.capture.enabled", "DD_APM_CAPTURE_ENABLED")
+	config.BindEnv("apm_config.capture.path", "DD_APM_CAPTURE_PATH")
 	config.BindEnv("apm_config.filter_tags.require", "DD_APM_FILTER_TAGS_REQUIRE")
 	config.BindEnv("apm_config.filter_tags.reject", "DD_APM_FILTER_TAGS_REJECT")
 	config.BindEnv("apm_config.internal_profiling.enabled", "DD_APM_INTERNAL_PROFILING_ENABLED")
@@ -125,6 +139,14 @@ func setupAPM(config Config) {
 		return out
 	})
 
+	config.SetEnvKeyTransformer("apm_config.sampling_rules", func(in string) interface{} {
+		var out []map[string]interface{}
+		if err := json.Unmarshal([]byte(in), &out); err != nil {
+			log.Warnf(`"apm_config.sampling_rules" can not be parsed: %v`, err)
+		}
+		return out
+	})
+
 	config.SetEnvKeyTransformer("apm_config.analyzed_spans", func(in string) interface{} {
 		out, err := parseAnalyzedSpans(in)
 		if err != nil {
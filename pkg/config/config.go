@@ -125,19 +125,20 @@ type MetadataProviders struct {
 
 // ConfigurationProviders helps unmarshalling `config_providers` config param
 type ConfigurationProviders struct {
-	Name             string `mapstructure:"name"`
-	Polling          bool   `mapstructure:"polling"`
-	PollInterval     string `mapstructure:"poll_interval"`
-	TemplateURL      string `mapstructure:"template_url"`
-	TemplateDir      string `mapstructure:"template_dir"`
-	Username         string `mapstructure:"username"`
-	Password         string `mapstructure:"password"`
-	CAFile           string `mapstructure:"ca_file"`
-	CAPath           string `mapstructure:"ca_path"`
-	CertFile         string `mapstructure:"cert_file"`
-	KeyFile          string `mapstructure:"key_file"`
-	Token            string `mapstructure:"token"`
-	GraceTimeSeconds int    `mapstructure:"grace_time_seconds"`
+	Name             string            `mapstructure:"name"`
+	Polling          bool              `mapstructure:"polling"`
+	PollInterval     string            `mapstructure:"poll_interval"`
+	TemplateURL      string            `mapstructure:"template_url"`
+	TemplateDir      string            `mapstructure:"template_dir"`
+	Username         string            `mapstructure:"username"`
+	Password         string            `mapstructure:"password"`
+	CAFile           string            `mapstructure:"ca_file"`
+	CAPath           string            `mapstructure:"ca_path"`
+	CertFile         string            `mapstructure:"cert_file"`
+	KeyFile          string            `mapstructure:"key_file"`
+	Token            string            `mapstructure:"token"`
+	Headers          map[string]string `mapstructure:"headers"`
+	GraceTimeSeconds int               `mapstructure:"grace_time_seconds"`
 }
 
 // Listeners helps unmarshalling `listeners` config param
@@ -270,6 +271,13 @@ func InitConfig(config Config) {
 	config.BindEnvAndSetDefault("enable_metadata_collection", true)
 	config.BindEnvAndSetDefault("enable_gohai", true)
 	config.BindEnvAndSetDefault("check_runners", int64(4))
+	config.BindEnvAndSetDefault("check_timeout", 0)
+	config.BindEnvAndSetDefault("plugin_checks_enabled", false)
+	config.BindEnvAndSetDefault("plugin_checks_dir", "")
+	config.BindEnvAndSetDefault("plugin_check_run_timeout", 60)
+	config.BindEnvAndSetDefault("check_scheduler_hash_placement", false)
+	config.BindEnvAndSetDefault("check_scheduler_jitter", false)
+	config.BindEnvAndSetDefault("check_scheduler_max_concurrency", map[string]int{})
 	config.BindEnvAndSetDefault("auth_token_file_path", "")
 	config.BindEnv("bind_host")
 	config.BindEnvAndSetDefault("ipc_address", "localhost")
@@ -558,6 +566,7 @@ func InitConfig(config Config) {
 	config.BindEnvAndSetDefault("container_exclude_stopped_age", DefaultAuditorTTL-1) // in hours
 	config.BindEnvAndSetDefault("ad_config_poll_interval", int64(10))                 // in seconds
 	config.BindEnvAndSetDefault("extra_listeners", []string{})
+	config.BindEnvAndSetDefault("process_listener_polling_interval", 30) // in seconds
 	config.BindEnvAndSetDefault("extra_config_providers", []string{})
 	config.BindEnvAndSetDefault("ignore_autoconf", []string{})
 	config.BindEnvAndSetDefault("autoconfig_from_environment", true)
@@ -615,7 +624,8 @@ func InitConfig(config Config) {
 	config.BindEnvAndSetDefault("prometheus_scrape.service_endpoints", false) // Enables Service Endpoints checks in the prometheus config provider
 	config.BindEnv("prometheus_scrape.checks")                                // Defines any extra prometheus/openmetrics check configurations to be handled by the prometheus config provider
 	config.SetEnvKeyTransformer("prometheus_scrape.checks", prometheusScrapeChecksTransformer)
-	config.BindEnvAndSetDefault("prometheus_scrape.version", 1) // Version of the openmetrics check to be scheduled by the Prometheus auto-discovery
+	config.BindEnvAndSetDefault("prometheus_scrape.version", 1)            // Version of the openmetrics check to be scheduled by the Prometheus auto-discovery
+	config.BindEnvAndSetDefault("prometheus_scrape.use_core_check", false) // Schedules the openmetrics core check instead of the Python one
 
 	// SNMP
 	config.SetKnown("snmp_listener.discovery_interval")
@@ -957,6 +967,7 @@ func InitConfig(config Config) {
 	config.BindEnvAndSetDefault("compliance_config.enabled", false)
 	config.BindEnvAndSetDefault("compliance_config.check_interval", 20*time.Minute)
 	config.BindEnvAndSetDefault("compliance_config.check_max_events_per_run", 100)
+	config.BindEnvAndSetDefault("compliance_config.drift_detection.enabled", false)
 	config.BindEnvAndSetDefault("compliance_config.dir", "/etc/datadog-agent/compliance.d")
 	config.BindEnvAndSetDefault("compliance_config.run_path", defaultRunPath)
 	config.BindEnv("compliance_config.run_commands_as")
@@ -993,6 +1004,18 @@ func InitConfig(config Config) {
 	config.BindEnvAndSetDefault("runtime_security_config.self_test.enabled", true)
 	config.BindEnvAndSetDefault("runtime_security_config.enable_remote_configuration", false)
 	config.BindEnv("runtime_security_config.enable_runtime_compiled_constants")
+	config.BindEnvAndSetDefault("runtime_security_config.actions.kill_allowlist", []string{
+		"/opt/datadog-agent/bin/*",
+		"/opt/datadog-agent/embedded/bin/*",
+		"/sbin/init",
+		"/lib/systemd/systemd",
+		"/usr/lib/systemd/systemd",
+	})
+	config.BindEnvAndSetDefault("runtime_security_config.actions.suppress_cache_size", 4096)
+	config.BindEnvAndSetDefault("runtime_security_config.activity_dump.enabled", false)
+	config.BindEnvAndSetDefault("runtime_security_config.activity_dump.traced_event_types", []string{"exec", "open", "bind"})
+	config.BindEnvAndSetDefault("runtime_security_config.activity_dump.timeout", 600)
+	config.BindEnvAndSetDefault("runtime_security_config.activity_dump.output_directory", "/tmp/activity_dumps")
 
 	// Serverless Agent
 	config.BindEnvAndSetDefault("serverless.logs_enabled", true)
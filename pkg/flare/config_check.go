@@ -9,6 +9,8 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"net/url"
+	"time"
 
 	"github.com/fatih/color"
 
@@ -63,6 +65,17 @@ func GetConfigCheck(w io.Writer, withDebug bool) error {
 		}
 	}
 
+	if len(cr.ProviderErrors) > 0 {
+		fmt.Fprintln(w, fmt.Sprintf("=== Config provider %s ===", color.RedString("errors")))
+		for provider, resources := range cr.ProviderErrors {
+			for resource, errors := range resources {
+				for _, error := range errors {
+					fmt.Fprintln(w, fmt.Sprintf("\n%s %s: %s", color.RedString(provider), resource, error))
+				}
+			}
+		}
+	}
+
 	for _, c := range cr.Configs {
 		PrintConfig(w, c, "")
 	}
@@ -92,6 +105,83 @@ func GetConfigCheck(w io.Writer, withDebug bool) error {
 	return nil
 }
 
+// GetConfigCheckExplain dumps the resolution history of the autodiscovery templates
+// matching the query to the writer
+func GetConfigCheckExplain(w io.Writer, query string) error {
+	if w != color.Output {
+		color.NoColor = true
+	}
+
+	c := util.GetClient(false) // FIX: get certificates right then make this true
+
+	// Set session token
+	err := util.SetAuthToken()
+	if err != nil {
+		return err
+	}
+	ipcAddress, err := config.GetIPCAddress()
+	if err != nil {
+		return err
+	}
+	explainURL := fmt.Sprintf("https://%v:%v/agent/config-check/explain?query=%s", ipcAddress, config.Datadog.GetInt("cmd_port"), url.QueryEscape(query))
+	r, err := util.DoGet(c, explainURL, util.LeaveConnectionOpen)
+	if err != nil {
+		if r != nil && string(r) != "" {
+			return fmt.Errorf("the agent ran into an error while explaining config resolution: %s", string(r))
+		}
+		return fmt.Errorf("failed to query the agent (running?): %s", err)
+	}
+
+	cr := response.ConfigCheckExplainResponse{}
+	err = json.Unmarshal(r, &cr)
+	if err != nil {
+		return err
+	}
+
+	PrintResolutionEvents(w, cr.Query, cr.Events)
+	return nil
+}
+
+// PrintResolutionEvents prints a human-readable representation of template resolutions
+func PrintResolutionEvents(w io.Writer, query string, events []integration.ResolutionEvent) {
+	fmt.Fprintln(w, fmt.Sprintf("=== Resolution history for %s ===", color.BlueString(query)))
+	if len(events) == 0 {
+		fmt.Fprintln(w, "\nNo resolution recorded, check the template name, AD identifier or service ID.")
+		return
+	}
+
+	for _, e := range events {
+		outcome := string(e.Outcome)
+		switch e.Outcome {
+		case integration.ResolutionScheduled:
+			outcome = color.GreenString(outcome)
+		case integration.ResolutionExcluded, integration.ResolutionNoService, integration.ResolutionNoTemplate:
+			outcome = color.YellowString(outcome)
+		default:
+			outcome = color.RedString(outcome)
+		}
+
+		fmt.Fprintln(w, fmt.Sprintf("\n%s %s", e.Timestamp.Format(time.RFC3339), outcome))
+		if e.Template != "" {
+			fmt.Fprintln(w, fmt.Sprintf("%s: %s (%s)", color.BlueString("Template"), e.Template, e.TemplateDigest))
+			if e.TemplateSource != "" {
+				fmt.Fprintln(w, fmt.Sprintf("%s: %s", color.BlueString("Template source"), e.TemplateSource))
+			}
+			fmt.Fprintln(w, fmt.Sprintf("%s: %v", color.BlueString("Template AD identifiers"), e.ADIdentifiers))
+		}
+		if e.ServiceID != "" {
+			fmt.Fprintln(w, fmt.Sprintf("%s: %s", color.BlueString("Service"), e.ServiceID))
+			fmt.Fprintln(w, fmt.Sprintf("%s: %v", color.BlueString("Service AD identifiers"), e.ServiceADIdentifiers))
+		}
+		if e.ConfigDigest != "" {
+			fmt.Fprintln(w, fmt.Sprintf("%s: %s", color.BlueString("Config digest"), e.ConfigDigest))
+		}
+		if e.Reason != "" {
+			fmt.Fprintln(w, fmt.Sprintf("%s: %s", color.BlueString("Reason"), e.Reason))
+		}
+	}
+}
+
 // GetClusterAgentConfigCheck proxies GetConfigCheck overidding the URL
 func GetClusterAgentConfigCheck(w io.Writer, withDebug bool) error {
 	configCheckURL = fmt.Sprintf("https://localhost:%v/config-check", config.Datadog.GetInt("cluster_agent.cmd_port"))
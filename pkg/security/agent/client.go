@@ -59,6 +59,40 @@ func (c *RuntimeSecurityClient) ReloadPolicies() (*api.ReloadPoliciesResultMessa
 	return response, nil
 }
 
+// DumpActivity starts recording the activity of a container, or of the containers of an image
+func (c *RuntimeSecurityClient) DumpActivity(containerID string, image string, timeout string) (*api.ActivityDumpMessage, error) {
+	response, err := c.apiClient.DumpActivity(context.Background(), &api.ActivityDumpParams{
+		ContainerID: containerID,
+		Image:       image,
+		Timeout:     timeout,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// ListActivityDumps returns the running activity dumps
+func (c *RuntimeSecurityClient) ListActivityDumps() (*api.ActivityDumpListMessage, error) {
+	response, err := c.apiClient.ListActivityDumps(context.Background(), &api.ActivityDumpListParams{})
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// StopActivityDump stops an activity dump and returns the file it was written to
+func (c *RuntimeSecurityClient) StopActivityDump(containerID string, image string) (*api.ActivityDumpStopMessage, error) {
+	response, err := c.apiClient.StopActivityDump(context.Background(), &api.ActivityDumpStopParams{
+		ContainerID: containerID,
+		Image:       image,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
 // GetEvents returns a stream of events
 func (c *RuntimeSecurityClient) GetEvents() (api.SecurityModule_GetEventsClient, error) {
 	stream, err := c.apiClient.GetEvents(context.Background(), &api.GetEventParams{})
@@ -92,6 +92,18 @@ type Config struct {
 	EnableRuntimeCompiledConstants bool
 	// RuntimeCompiledConstantsIsSet is set if the runtime compiled constants option is user-set
 	RuntimeCompiledConstantsIsSet bool
+	// KillAllowlist lists the patterns of the executable paths of the processes which can't be killed by rule actions
+	KillAllowlist []string
+	// SuppressCacheSize is the maximum number of distinct events muted by the suppress rule action
+	SuppressCacheSize int
+	// ActivityDumpEnabled defines if activity dumps can be started
+	ActivityDumpEnabled bool
+	// ActivityDumpTracedEventTypes defines the event types recorded in activity dumps
+	ActivityDumpTracedEventTypes []string
+	// ActivityDumpTimeout defines the default duration of an activity dump
+	ActivityDumpTimeout time.Duration
+	// ActivityDumpOutputDirectory defines the directory where activity dumps are written
+	ActivityDumpOutputDirectory string
 }
 
 // IsEnabled returns true if any feature is enabled. Has to be applied in config package too
@@ -134,6 +146,12 @@ func NewConfig(cfg *config.Config) (*Config, error) {
 		EnableRemoteConfig:                 aconfig.Datadog.GetBool("runtime_security_config.enable_remote_configuration"),
 		EnableRuntimeCompiledConstants:     aconfig.Datadog.GetBool("runtime_security_config.enable_runtime_compiled_constants"),
 		RuntimeCompiledConstantsIsSet:      aconfig.Datadog.IsSet("runtime_security_config.enable_runtime_compiled_constants"),
+		KillAllowlist:                      aconfig.Datadog.GetStringSlice("runtime_security_config.actions.kill_allowlist"),
+		SuppressCacheSize:                  aconfig.Datadog.GetInt("runtime_security_config.actions.suppress_cache_size"),
+		ActivityDumpEnabled:                aconfig.Datadog.GetBool("runtime_security_config.activity_dump.enabled"),
+		ActivityDumpTracedEventTypes:       aconfig.Datadog.GetStringSlice("runtime_security_config.activity_dump.traced_event_types"),
+		ActivityDumpTimeout:                time.Duration(aconfig.Datadog.GetInt("runtime_security_config.activity_dump.timeout")) * time.Second,
+		ActivityDumpOutputDirectory:        aconfig.Datadog.GetString("runtime_security_config.activity_dump.output_directory"),
 	}
 
 	// if runtime is enabled then we force fim
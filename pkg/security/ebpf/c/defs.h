@@ -221,6 +221,9 @@ enum event_type
     EVENT_INIT_MODULE,
     EVENT_DELETE_MODULE,
     EVENT_SIGNAL,
+    EVENT_CONNECT,
+    EVENT_BIND,
+    EVENT_DNS,
     EVENT_MAX, // has to be the last one
 
     EVENT_ALL = 0xffffffffffffffff // used as a mask for all the events
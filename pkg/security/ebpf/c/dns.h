@@ -0,0 +1,133 @@
+#ifndef _DNS_H_
+#define _DNS_H_
+
+#include <linux/uio.h>
+#include <net/sock.h>
+
+#include "bpf_endian.h"
+
+#define DNS_PORT 53
+// DNS_MAX_LENGTH must be a power of two, it's used to bound the payload length for the verifier
+#define DNS_MAX_LENGTH 256
+
+struct dns_event_t {
+    struct kevent_t event;
+    struct process_context_t process;
+    struct span_context_t span;
+    struct container_context_t container;
+
+    u64 addr[2];
+    u16 family;
+    u16 port;
+    u16 size;
+    u16 padding;
+    char payload[DNS_MAX_LENGTH];
+};
+
+u64 __attribute__((always_inline)) get_iov_iter_iov_offset(void) {
+    u64 iov_iter_iov_offset;
+    LOAD_CONSTANT("iov_iter_iov_offset", iov_iter_iov_offset);
+    return iov_iter_iov_offset;
+}
+
+u64 __attribute__((always_inline)) get_iter_type_ubuf(void) {
+    u64 iter_type_ubuf;
+    LOAD_CONSTANT("iter_type_ubuf", iter_type_ubuf);
+    return iter_type_ubuf;
+}
+
+// get_msg_buffer returns the user buffer of the first segment of a message. Since Linux 6.0, single buffer
+// messages use ITER_UBUF iterators, where the iovec pointer of the iterator union is the buffer itself. The
+// union moved (and the iovec field was renamed to __iov) in 6.4, its offset is given by the constant fetcher.
+void __attribute__((always_inline)) *get_msg_buffer(struct msghdr *msg) {
+    // the iterator type is the first byte of the iterator, the field was named type before 5.14
+    u8 iter_type = 0;
+    bpf_probe_read(&iter_type, sizeof(iter_type), &msg->msg_iter);
+
+    void *ptr = NULL;
+    bpf_probe_read(&ptr, sizeof(ptr), (void *)&msg->msg_iter + get_iov_iter_iov_offset());
+    if (!ptr || iter_type == get_iter_type_ubuf()) {
+        return ptr;
+    }
+
+    void *base = NULL;
+    bpf_probe_read(&base, sizeof(base), &((struct iovec *)ptr)->iov_base);
+    return base;
+}
+
+// the question is parsed in user space, only the first DNS_MAX_LENGTH bytes of the request are sent
+SEC("kprobe/security_socket_sendmsg")
+int kprobe_security_socket_sendmsg(struct pt_regs *ctx) {
+    struct socket *sock = (struct socket *)PT_REGS_PARM1(ctx);
+    struct msghdr *msg = (struct msghdr *)PT_REGS_PARM2(ctx);
+    int size = (int)PT_REGS_PARM3(ctx);
+
+    short type = 0;
+    bpf_probe_read(&type, sizeof(type), &sock->type);
+    if (type != SOCK_DGRAM || size <= 0) {
+        return 0;
+    }
+
+    struct dns_event_t event = {
+        .size = size,
+    };
+
+    // the destination is either given to sendmsg or set by a previous connect
+    struct sockaddr *address = NULL;
+    bpf_probe_read(&address, sizeof(address), &msg->msg_name);
+    if (address) {
+        if (parse_sockaddr(address, event.addr, &event.family, &event.port) < 0) {
+            return 0;
+        }
+    } else {
+        struct sock *sk = NULL;
+        bpf_probe_read(&sk, sizeof(sk), &sock->sk);
+        if (!sk) {
+            return 0;
+        }
+        bpf_probe_read(&event.family, sizeof(event.family), &sk->__sk_common.skc_family);
+        bpf_probe_read(&event.port, sizeof(event.port), &sk->__sk_common.skc_dport);
+        if (event.family == AF_INET) {
+            bpf_probe_read(&event.addr[0], sizeof(sk->__sk_common.skc_daddr), &sk->__sk_common.skc_daddr);
+        } else if (event.family == AF_INET6) {
+            bpf_probe_read(event.addr, sizeof(u64) * 2, &sk->__sk_common.skc_v6_daddr);
+        } else {
+            return 0;
+        }
+    }
+
+    if (event.port != bpf_htons(DNS_PORT)) {
+        return 0;
+    }
+
+    struct policy_t policy = fetch_policy(EVENT_DNS);
+    if (is_discarded_by_process(policy.mode, EVENT_DNS)) {
+        return 0;
+    }
+
+    void *base = get_msg_buffer(msg);
+    if (!base) {
+        return 0;
+    }
+
+    // the masked length is lower than DNS_MAX_LENGTH, full size payloads are read with a constant length
+    int ret = 0;
+    u32 len = size;
+    if (len >= DNS_MAX_LENGTH) {
+        ret = bpf_probe_read(event.payload, DNS_MAX_LENGTH, base);
+    } else {
+        ret = bpf_probe_read(event.payload, len & (DNS_MAX_LENGTH - 1), base);
+    }
+    if (ret < 0) {
+        return 0;
+    }
+
+    struct proc_cache_t *entry = fill_process_context(&event.process);
+    fill_container_context(entry, &event.container);
+    fill_span_context(&event.span);
+
+    send_event(ctx, EVENT_DNS, event);
+    return 0;
+}
+
+#endif
@@ -0,0 +1,160 @@
+#ifndef _NETWORK_H_
+#define _NETWORK_H_
+
+#include <linux/in.h>
+#include <linux/in6.h>
+#include <linux/net.h>
+#include <linux/socket.h>
+
+struct network_event_t {
+    struct kevent_t event;
+    struct process_context_t process;
+    struct span_context_t span;
+    struct container_context_t container;
+    struct syscall_t syscall;
+
+    u64 addr[2];
+    u16 family;
+    u16 port;
+    u32 padding;
+};
+
+// parse_sockaddr reads the address, the family and the port (network byte order) of an AF_INET or AF_INET6 socket address
+static __attribute__((always_inline)) int parse_sockaddr(struct sockaddr *address, u64 addr[2], u16 *family, u16 *port) {
+    bpf_probe_read(family, sizeof(*family), &address->sa_family);
+    if (*family == AF_INET) {
+        struct sockaddr_in *addr_in = (struct sockaddr_in *)address;
+        bpf_probe_read(port, sizeof(*port), &addr_in->sin_port);
+        bpf_probe_read(&addr[0], sizeof(addr_in->sin_addr.s_addr), &addr_in->sin_addr.s_addr);
+        return 0;
+    } else if (*family == AF_INET6) {
+        struct sockaddr_in6 *addr_in6 = (struct sockaddr_in6 *)address;
+        bpf_probe_read(port, sizeof(*port), &addr_in6->sin6_port);
+        bpf_probe_read(addr, sizeof(u64) * 2, &addr_in6->sin6_addr);
+        return 0;
+    }
+    return -1;
+}
+
+SYSCALL_KPROBE0(connect) {
+    struct policy_t policy = fetch_policy(EVENT_CONNECT);
+    if (is_discarded_by_process(policy.mode, EVENT_CONNECT)) {
+        return 0;
+    }
+
+    struct syscall_cache_t syscall = {
+        .type = EVENT_CONNECT,
+    };
+    cache_syscall(&syscall);
+    return 0;
+}
+
+SEC("kprobe/security_socket_connect")
+int kprobe_security_socket_connect(struct pt_regs *ctx) {
+    struct syscall_cache_t *syscall = peek_syscall(EVENT_CONNECT);
+    if (!syscall) {
+        return 0;
+    }
+
+    struct sockaddr *address = (struct sockaddr *)PT_REGS_PARM2(ctx);
+    parse_sockaddr(address, syscall->connect.addr, &syscall->connect.family, &syscall->connect.port);
+    return 0;
+}
+
+int __attribute__((always_inline)) sys_connect_ret(void *ctx, int retval) {
+    struct syscall_cache_t *syscall = pop_syscall(EVENT_CONNECT);
+    if (!syscall) {
+        return 0;
+    }
+
+    // only AF_INET and AF_INET6 connections are reported
+    if (syscall->connect.family != AF_INET && syscall->connect.family != AF_INET6) {
+        return 0;
+    }
+
+    // EINPROGRESS is expected for non blocking sockets
+    if (IS_UNHANDLED_ERROR(retval) && retval != -EINPROGRESS) {
+        return 0;
+    }
+
+    struct network_event_t event = {
+        .syscall.retval = retval,
+        .addr[0] = syscall->connect.addr[0],
+        .addr[1] = syscall->connect.addr[1],
+        .family = syscall->connect.family,
+        .port = syscall->connect.port,
+    };
+
+    struct proc_cache_t *entry = fill_process_context(&event.process);
+    fill_container_context(entry, &event.container);
+    fill_span_context(&event.span);
+
+    send_event(ctx, EVENT_CONNECT, event);
+    return 0;
+}
+
+SYSCALL_KRETPROBE(connect) {
+    return sys_connect_ret(ctx, (int)PT_REGS_RC(ctx));
+}
+
+SYSCALL_KPROBE0(bind) {
+    struct policy_t policy = fetch_policy(EVENT_BIND);
+    if (is_discarded_by_process(policy.mode, EVENT_BIND)) {
+        return 0;
+    }
+
+    struct syscall_cache_t syscall = {
+        .type = EVENT_BIND,
+    };
+    cache_syscall(&syscall);
+    return 0;
+}
+
+SEC("kprobe/security_socket_bind")
+int kprobe_security_socket_bind(struct pt_regs *ctx) {
+    struct syscall_cache_t *syscall = peek_syscall(EVENT_BIND);
+    if (!syscall) {
+        return 0;
+    }
+
+    struct sockaddr *address = (struct sockaddr *)PT_REGS_PARM2(ctx);
+    parse_sockaddr(address, syscall->bind.addr, &syscall->bind.family, &syscall->bind.port);
+    return 0;
+}
+
+int __attribute__((always_inline)) sys_bind_ret(void *ctx, int retval) {
+    struct syscall_cache_t *syscall = pop_syscall(EVENT_BIND);
+    if (!syscall) {
+        return 0;
+    }
+
+    // only AF_INET and AF_INET6 sockets are reported
+    if (syscall->bind.family != AF_INET && syscall->bind.family != AF_INET6) {
+        return 0;
+    }
+
+    if (IS_UNHANDLED_ERROR(retval)) {
+        return 0;
+    }
+
+    struct network_event_t event = {
+        .syscall.retval = retval,
+        .addr[0] = syscall->bind.addr[0],
+        .addr[1] = syscall->bind.addr[1],
+        .family = syscall->bind.family,
+        .port = syscall->bind.port,
+    };
+
+    struct proc_cache_t *entry = fill_process_context(&event.process);
+    fill_container_context(entry, &event.container);
+    fill_span_context(&event.span);
+
+    send_event(ctx, EVENT_BIND, event);
+    return 0;
+}
+
+SYSCALL_KRETPROBE(bind) {
+    return sys_bind_ret(ctx, (int)PT_REGS_RC(ctx));
+}
+
+#endif
@@ -48,6 +48,8 @@
 #include "raw_syscalls.h"
 #include "module.h"
 #include "signal.h"
+#include "network.h"
+#include "dns.h"
 
 struct invalidate_dentry_event_t {
     struct kevent_t event;
@@ -185,6 +185,18 @@ struct syscall_cache_t {
             u32 pid;
             u32 type;
         } signal;
+
+        struct {
+            u64 addr[2];
+            u16 family;
+            u16 port;
+        } connect;
+
+        struct {
+            u64 addr[2];
+            u16 family;
+            u16 port;
+        } bind;
     };
 };
 
@@ -197,7 +209,46 @@ struct bpf_map_def SEC("maps/syscalls") syscalls = {
     .namespace = "",
 };
 
+struct bpf_map_def SEC("maps/traced_cgroups") traced_cgroups = {
+    .type = BPF_MAP_TYPE_HASH,
+    .key_size = CONTAINER_ID_LEN,
+    .value_size = sizeof(u64),
+    .max_entries = 256,
+    .pinning = 0,
+    .namespace = "",
+};
+
+// is_traced_by_activity_dump returns whether the current process belongs to a container for which an
+// activity dump records the events of the given type
+int __attribute__((always_inline)) is_traced_by_activity_dump(u64 event_type) {
+    u64 activity_dump_enabled;
+    LOAD_CONSTANT("activity_dump_enabled", activity_dump_enabled);
+    if (!activity_dump_enabled) {
+        return 0;
+    }
+
+    u32 tgid = bpf_get_current_pid_tgid() >> 32;
+    struct proc_cache_t *entry = get_proc_cache(tgid);
+    if (!entry) {
+        return 0;
+    }
+
+    char container_id[CONTAINER_ID_LEN] = {};
+    if (!copy_container_id(entry->container.container_id, container_id)) {
+        return 0;
+    }
+
+    u64 *event_mask = bpf_map_lookup_elem(&traced_cgroups, container_id);
+    return event_mask && mask_has_event(*event_mask, event_type);
+}
+
 struct policy_t __attribute__((always_inline)) fetch_policy(u64 event_type) {
+    // the events of the containers recorded by an activity dump are neither approved nor discarded
+    if (is_traced_by_activity_dump(event_type)) {
+        struct policy_t no_filter = { .mode = NO_FILTER };
+        return no_filter;
+    }
+
     struct policy_t *policy = bpf_map_lookup_elem(&filter_policy, &event_type);
     if (policy) {
         return *policy;
@@ -67,6 +67,12 @@ var (
 	Kernel5_14 = kernel.VersionCode(5, 14, 0) //nolint:deadcode,unused
 	// Kernel5_16 is the KernelVersion representation of kernel version 5.16
 	Kernel5_16 = kernel.VersionCode(5, 16, 0) //nolint:deadcode,unused
+	// Kernel6_0 is the KernelVersion representation of kernel version 6.0
+	Kernel6_0 = kernel.VersionCode(6, 0, 0) //nolint:deadcode,unused
+	// Kernel6_4 is the KernelVersion representation of kernel version 6.4
+	Kernel6_4 = kernel.VersionCode(6, 4, 0) //nolint:deadcode,unused
+	// Kernel6_5 is the KernelVersion representation of kernel version 6.5
+	Kernel6_5 = kernel.VersionCode(6, 5, 0) //nolint:deadcode,unused
 )
 
 // Version defines a kernel version helper
@@ -42,6 +42,7 @@ func AllProbes() []*manager.Probe {
 	allProbes = append(allProbes, getMProtectProbes()...)
 	allProbes = append(allProbes, getModuleProbes()...)
 	allProbes = append(allProbes, getSignalProbes()...)
+	allProbes = append(allProbes, getNetworkProbes()...)
 
 	allProbes = append(allProbes,
 		// Syscall monitor
@@ -88,6 +89,7 @@ func AllMaps() []*manager.Map {
 		{Name: "pid_discarders"},
 		{Name: "discarder_revisions"},
 		{Name: "basename_approvers"},
+		{Name: "traced_cgroups"},
 		// Dentry resolver table
 		{Name: "pathnames"},
 		// Snapshot table
@@ -40,6 +40,15 @@ var (
 	// Tags: rule_id
 	MetricRateLimiterAllow = newRuntimeMetric(".rules.rate_limiter.allow")
 
+	// Rule actions metrics
+
+	// MetricRuleActionPerformed is the name of the metric used to count the actions performed on rule matches
+	// Tags: rule_id, action, outcome
+	MetricRuleActionPerformed = newRuntimeMetric(".rules.actions")
+	// MetricRuleActionCustomPrefix is the prefix of the counters incremented by the `emit_metric` action
+	// Tags: rule_id, tags and fields of the action
+	MetricRuleActionCustomPrefix = newRuntimeMetric(".rules.custom.")
+
 	// Syscall monitoring metrics
 
 	// MetricSyscalls is the name of the metric used to count each syscall executed on the host
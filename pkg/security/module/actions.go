@@ -0,0 +1,224 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build linux
+// +build linux
+
+package module
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/DataDog/datadog-go/statsd"
+	"github.com/hashicorp/golang-lru/simplelru"
+
+	"github.com/DataDog/datadog-agent/pkg/security/metrics"
+	sprobe "github.com/DataDog/datadog-agent/pkg/security/probe"
+	"github.com/DataDog/datadog-agent/pkg/security/secl/compiler/eval"
+	"github.com/DataDog/datadog-agent/pkg/security/secl/model"
+	"github.com/DataDog/datadog-agent/pkg/security/secl/rules"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// Action names, as reported in the audit logs and metrics
+const (
+	killActionName       = "kill"
+	emitMetricActionName = "emit_metric"
+	suppressActionName   = "suppress"
+)
+
+// Action outcomes, as reported in the audit logs and metrics
+const (
+	actionPerformed = "performed"
+	actionRefused   = "refused"
+	actionFailed    = "failed"
+)
+
+// ActionExecutor executes the 'kill', 'emit_metric' and 'suppress' actions of the rules.
+// The 'set' actions are executed by the ruleset. Every action taken is audit logged.
+type ActionExecutor struct {
+	probe         *sprobe.Probe
+	statsdClient  *statsd.Client
+	killAllowlist []string
+	kill          func(pid int, sig syscall.Signal) error
+
+	suppressLock sync.Mutex
+	// suppressed holds the expiration time of the muted events, by rule and field values
+	suppressed *simplelru.LRU
+}
+
+// NewActionExecutor returns a new action executor
+func NewActionExecutor(probe *sprobe.Probe, statsdClient *statsd.Client, killAllowlist []string, suppressCacheSize int) (*ActionExecutor, error) {
+	for _, pattern := range killAllowlist {
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid kill allowlist pattern `%s`: %w", pattern, err)
+		}
+	}
+
+	suppressed, err := simplelru.NewLRU(suppressCacheSize, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ActionExecutor{
+		probe:         probe,
+		statsdClient:  statsdClient,
+		killAllowlist: killAllowlist,
+		kill:          syscall.Kill,
+		suppressed:    suppressed,
+	}, nil
+}
+
+// Execute executes the actions of the rule matching the event. It returns false if the
+// event is muted by a 'suppress' action and must not be sent.
+func (a *ActionExecutor) Execute(rule *rules.Rule, event *sprobe.Event) bool {
+	send := true
+	for _, action := range rule.Definition.Actions {
+		switch {
+		case action.Kill != nil:
+			a.executeKill(rule, action.Kill, event)
+		case action.EmitMetric != nil:
+			a.executeEmitMetric(rule, action.EmitMetric, event)
+		case action.Suppress != nil:
+			if a.isSuppressed(rule, action.Suppress, event) {
+				send = false
+			}
+		}
+	}
+	return send
+}
+
+// audit logs an action taken on a rule match and counts it
+func (a *ActionExecutor) audit(rule *rules.Rule, action, outcome, format string, params ...interface{}) {
+	msg := fmt.Sprintf(format, params...)
+	if outcome == actionFailed {
+		log.Errorf("rule `%s`: %s action %s: %s", rule.ID, action, outcome, msg)
+	} else {
+		log.Infof("rule `%s`: %s action %s: %s", rule.ID, action, outcome, msg)
+	}
+
+	if a.statsdClient != nil {
+		tags := []string{"rule_id:" + rule.ID, "action:" + action, "outcome:" + outcome}
+		_ = a.statsdClient.Count(metrics.MetricRuleActionPerformed, 1, tags, 1.0)
+	}
+}
+
+func (a *ActionExecutor) executeKill(rule *rules.Rule, kill *rules.KillDefinition, event *sprobe.Event) {
+	signal := kill.GetSignal()
+	evaluator, ok := model.SECLConstants[signal].(*eval.IntEvaluator)
+	if !ok {
+		a.audit(rule, killActionName, actionFailed, "unknown signal %s", signal)
+		return
+	}
+	sig := syscall.Signal(evaluator.Value)
+
+	var pids []uint32
+	switch kill.Scope {
+	case rules.KillScopeContainer:
+		containerID := event.ProcessContext.ContainerID
+		if containerID == "" {
+			a.audit(rule, killActionName, actionRefused, "process %d isn't running in a container", event.ProcessContext.Pid)
+			return
+		}
+		pids = a.probe.GetResolvers().ProcessResolver.GetContainerPIDs(containerID)
+	default:
+		pids = []uint32{event.ProcessContext.Pid}
+	}
+
+	for _, pid := range pids {
+		entry := a.probe.GetResolvers().ProcessResolver.Get(pid)
+		if entry == nil {
+			a.audit(rule, killActionName, actionFailed, "process %d not found", pid)
+			continue
+		}
+
+		if reason := a.killRefusal(entry); reason != "" {
+			a.audit(rule, killActionName, actionRefused, "%s to process %d (%s): %s", signal, pid, entry.PathnameStr, reason)
+			continue
+		}
+
+		if err := a.kill(int(pid), sig); err != nil {
+			a.audit(rule, killActionName, actionFailed, "%s to process %d (%s): %v", signal, pid, entry.PathnameStr, err)
+			continue
+		}
+
+		a.audit(rule, killActionName, actionPerformed, "%s sent to process %d (%s)", signal, pid, entry.PathnameStr)
+	}
+}
+
+// killRefusal returns why the process can't be killed, if it is protected
+func (a *ActionExecutor) killRefusal(entry *model.ProcessCacheEntry) string {
+	switch {
+	case entry.Pid <= 1:
+		return "init process"
+	case int(entry.Pid) == os.Getpid():
+		return "agent process"
+	case sprobe.IsKThread(entry.PPid, entry.Pid):
+		return "kernel thread"
+	}
+
+	for _, pattern := range a.killAllowlist {
+		if matched, _ := filepath.Match(pattern, entry.PathnameStr); matched {
+			return "allowlisted by " + pattern
+		}
+	}
+
+	return ""
+}
+
+func (a *ActionExecutor) executeEmitMetric(rule *rules.Rule, emitMetric *rules.EmitMetricDefinition, event *sprobe.Event) {
+	tags := append([]string{"rule_id:" + rule.ID}, emitMetric.Tags...)
+	for _, field := range emitMetric.Fields {
+		if value, err := event.GetFieldValue(field); err == nil {
+			tags = append(tags, fmt.Sprintf("%s:%v", field, value))
+		}
+	}
+
+	name := metrics.MetricRuleActionCustomPrefix + emitMetric.Name
+	if a.statsdClient == nil {
+		return
+	}
+	if err := a.statsdClient.Count(name, 1, tags, 1.0); err != nil {
+		a.audit(rule, emitMetricActionName, actionFailed, "%s: %v", name, err)
+		return
+	}
+
+	a.audit(rule, emitMetricActionName, actionPerformed, "%s incremented with tags %s", name, strings.Join(tags, ","))
+}
+
+// isSuppressed returns whether an identical event was sent during the suppress duration. If
+// not, the event starts a new suppression window.
+func (a *ActionExecutor) isSuppressed(rule *rules.Rule, suppress *rules.SuppressDefinition, event *sprobe.Event) bool {
+	fields := suppress.Fields
+	if len(fields) == 0 {
+		fields = rule.GetFields()
+	}
+
+	var key strings.Builder
+	key.WriteString(rule.ID)
+	for _, field := range fields {
+		value, _ := event.GetFieldValue(field)
+		fmt.Fprintf(&key, "|%s=%v", field, value)
+	}
+
+	now := time.Now()
+
+	a.suppressLock.Lock()
+	defer a.suppressLock.Unlock()
+
+	if expiration, found := a.suppressed.Get(key.String()); found && now.Before(expiration.(time.Time)) {
+		a.audit(rule, suppressActionName, actionPerformed, "identical event muted until %s", expiration.(time.Time).Format(time.RFC3339))
+		return true
+	}
+
+	a.suppressed.Add(key.String(), now.Add(suppress.Duration))
+	return false
+}
@@ -59,6 +59,7 @@ type Module struct {
 	grpcServer       *grpc.Server
 	listener         net.Listener
 	rateLimiter      *RateLimiter
+	actionExecutor   *ActionExecutor
 	sigupChan        chan os.Signal
 	ctx              context.Context
 	cancelFnc        context.CancelFunc
@@ -281,12 +282,14 @@ func (m *Module) Reload() error {
 		WithEventTypeEnabled(m.getEventTypeEnabled()).
 		WithReservedRuleIDs(sprobe.AllCustomRuleIDs()).
 		WithLegacyFields(model.SECLLegacyFields).
+		WithSequenceScopes(sprobe.SequenceScopes).
 		WithStateScopes(map[rules.Scope]rules.VariableProviderFactory{
 			"process": func() rules.VariableProvider {
 				return eval.NewScopedVariables(func(ctx *eval.Context) unsafe.Pointer {
 					return unsafe.Pointer(&(*model.Event)(ctx.Object).ProcessContext)
 				}, nil)
 			},
+			"container": sprobe.NewContainerVariables,
 		}).
 		WithLogger(&seclog.PatternLogger{})
 
@@ -297,7 +300,8 @@ func (m *Module) Reload() error {
 	// switch SECLVariables to use the real Event structure and not the mock model.Event one
 	opts.WithVariables(sprobe.SECLVariables)
 	opts.WithStateScopes(map[rules.Scope]rules.VariableProviderFactory{
-		"process": m.probe.GetResolvers().ProcessResolver.NewProcessVariables,
+		"process":   m.probe.GetResolvers().ProcessResolver.NewProcessVariables,
+		"container": sprobe.NewContainerVariables,
 	})
 
 	ruleSet := m.probe.NewRuleSet(&opts)
@@ -339,9 +343,10 @@ func (m *Module) Reload() error {
 		return err
 	}
 
-	// full list of IDs, user rules + custom
+	// full list of IDs, user rules + sequences + custom
 	var ruleIDs []rules.RuleID
 	ruleIDs = append(ruleIDs, ruleSet.ListRuleIDs()...)
+	ruleIDs = append(ruleIDs, ruleSet.ListSequenceIDs()...)
 	ruleIDs = append(ruleIDs, sprobe.AllCustomRuleIDs()...)
 
 	m.apiServer.Apply(ruleIDs)
@@ -432,9 +437,37 @@ func (m *Module) RuleMatch(rule *rules.Rule, event eval.Event) {
 	if m.selfTester != nil {
 		m.selfTester.SendEventIfExpecting(rule, event)
 	}
+
+	if !m.actionExecutor.Execute(rule, event.(*sprobe.Event)) {
+		return
+	}
+
 	m.SendEvent(rule, event, extTagsCb, service)
 }
 
+// SequenceMatch is called by the ruleset when a sequence matches
+func (m *Module) SequenceMatch(sequence *rules.Sequence, match *rules.SequenceMatch, event eval.Event) {
+	// the container and the service are the ones of the event completing the sequence
+	service := event.(*sprobe.Event).GetProcessServiceTag()
+
+	id := event.(*sprobe.Event).ContainerContext.ID
+
+	extTagsCb := func() []string {
+		if service == "" {
+			service = m.probe.GetResolvers().TagsResolver.GetValue(id, "service")
+		}
+
+		if service == "" {
+			service = m.config.HostServiceName
+		}
+
+		return m.probe.GetResolvers().TagsResolver.Resolve(id)
+	}
+
+	rule, customEvent := sprobe.NewSequenceEvent(sequence, match)
+	m.SendEvent(rule, customEvent, extTagsCb, service)
+}
+
 // SendEvent sends an event to the backend after checking that the rate limiter allows it for the provided rule
 func (m *Module) SendEvent(rule *rules.Rule, event Event, extTagsCb func() []string, service string) {
 	if m.rateLimiter.Allow(rule.ID) {
@@ -542,6 +575,11 @@ func NewModule(cfg *sconfig.Config) (module.Module, error) {
 		return nil, err
 	}
 
+	actionExecutor, err := NewActionExecutor(probe, statsdClient, cfg.KillAllowlist, cfg.SuppressCacheSize)
+	if err != nil {
+		return nil, err
+	}
+
 	ctx, cancelFnc := context.WithCancel(context.Background())
 
 	// custom limiters
@@ -553,16 +591,17 @@ func NewModule(cfg *sconfig.Config) (module.Module, error) {
 	}
 
 	m := &Module{
-		config:       cfg,
-		probe:        probe,
-		statsdClient: statsdClient,
-		apiServer:    NewAPIServer(cfg, probe, statsdClient),
-		grpcServer:   grpc.NewServer(),
-		rateLimiter:  NewRateLimiter(statsdClient, LimiterOpts{Limits: limits}),
-		sigupChan:    make(chan os.Signal, 1),
-		ctx:          ctx,
-		cancelFnc:    cancelFnc,
-		selfTester:   selfTester,
+		config:         cfg,
+		probe:          probe,
+		statsdClient:   statsdClient,
+		apiServer:      NewAPIServer(cfg, probe, statsdClient),
+		grpcServer:     grpc.NewServer(),
+		rateLimiter:    NewRateLimiter(statsdClient, LimiterOpts{Limits: limits}),
+		actionExecutor: actionExecutor,
+		sigupChan:      make(chan os.Signal, 1),
+		ctx:            ctx,
+		cancelFnc:      cancelFnc,
+		selfTester:     selfTester,
 	}
 	m.apiServer.module = m
 	m.reloader = debouncer.New(3*time.Second, m.triggerReload)
@@ -113,6 +113,60 @@ func (a *APIServer) DumpProcessCache(ctx context.Context, params *api.DumpProces
 	}, nil
 }
 
+// DumpActivity handles activity dump requests
+func (a *APIServer) DumpActivity(ctx context.Context, params *api.ActivityDumpParams) (*api.ActivityDumpMessage, error) {
+	manager := a.probe.GetActivityDumpManager()
+	if manager == nil {
+		return &api.ActivityDumpMessage{
+			Error: "activity dumps are disabled",
+		}, nil
+	}
+
+	ad, err := manager.StartDump(params)
+	if err != nil {
+		return &api.ActivityDumpMessage{
+			Error: err.Error(),
+		}, nil
+	}
+
+	return ad.ToMessage(), nil
+}
+
+// ListActivityDumps returns the list of running activity dumps
+func (a *APIServer) ListActivityDumps(ctx context.Context, params *api.ActivityDumpListParams) (*api.ActivityDumpListMessage, error) {
+	manager := a.probe.GetActivityDumpManager()
+	if manager == nil {
+		return &api.ActivityDumpListMessage{
+			Error: "activity dumps are disabled",
+		}, nil
+	}
+
+	return &api.ActivityDumpListMessage{
+		Dumps: manager.ListDumps(),
+	}, nil
+}
+
+// StopActivityDump stops an activity dump and returns the file it was written to
+func (a *APIServer) StopActivityDump(ctx context.Context, params *api.ActivityDumpStopParams) (*api.ActivityDumpStopMessage, error) {
+	manager := a.probe.GetActivityDumpManager()
+	if manager == nil {
+		return &api.ActivityDumpStopMessage{
+			Error: "activity dumps are disabled",
+		}, nil
+	}
+
+	filename, err := manager.StopDump(params)
+	if err != nil {
+		return &api.ActivityDumpStopMessage{
+			Error: err.Error(),
+		}, nil
+	}
+
+	return &api.ActivityDumpStopMessage{
+		OutputFile: filename,
+	}, nil
+}
+
 func (a *APIServer) enqueue(msg *pendingMsg) {
 	a.queueLock.Lock()
 	a.queue = append(a.queue, msg)
@@ -0,0 +1,728 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build linux
+// +build linux
+
+package probe
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+
+	"github.com/DataDog/datadog-agent/pkg/security/api"
+	"github.com/DataDog/datadog-agent/pkg/security/config"
+	"github.com/DataDog/datadog-agent/pkg/security/ebpf"
+	"github.com/DataDog/datadog-agent/pkg/security/secl/model"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+	"github.com/DataDog/datadog-agent/pkg/version"
+)
+
+const (
+	// activityDumpCleanupPeriod is the period at which expired activity dumps are persisted
+	activityDumpCleanupPeriod = 5 * time.Second
+	// imageNameTag is the container tag holding the image name
+	imageNameTag = "image_name"
+	// containerIDLen is the size of the keys of the traced_cgroups map, it needs to be aligned with the kernel size
+	containerIDLen = 64
+)
+
+var (
+	// ErrActivityDumpNotFound is returned when no activity dump matches a selector
+	ErrActivityDumpNotFound = errors.New("activity dump not found")
+	// ErrActivityDumpSelector is returned when an activity dump selector is invalid
+	ErrActivityDumpSelector = errors.New("either a container ID or an image is required")
+
+	nonRuleIDChars = regexp.MustCompile(`[^a-zA-Z0-9]+`)
+)
+
+// ActivityDumpMetadata holds the metadata of an activity dump
+type ActivityDumpMetadata struct {
+	AgentVersion     string    `json:"agent_version"`
+	ContainerID      string    `json:"container_id,omitempty"`
+	Image            string    `json:"image,omitempty"`
+	Start            time.Time `json:"start"`
+	End              time.Time `json:"end,omitempty"`
+	Timeout          string    `json:"timeout"`
+	EventsCount      uint64    `json:"events_count"`
+	TracedEventTypes []string  `json:"traced_event_types"`
+}
+
+// FileActivityNode holds a file opened by a process
+type FileActivityNode struct {
+	Path  string `json:"path"`
+	Flags uint32 `json:"flags"`
+	Count uint64 `json:"count"`
+}
+
+// SocketActivityNode holds an address a process bound to
+type SocketActivityNode struct {
+	Family string `json:"family"`
+	IP     string `json:"ip"`
+	Port   uint16 `json:"port"`
+}
+
+// ProcessActivityNode holds the activity of an executable in the process tree of an activity dump
+type ProcessActivityNode struct {
+	FilePath string                       `json:"file_path"`
+	Comm     string                       `json:"comm"`
+	Files    map[string]*FileActivityNode `json:"files,omitempty"`
+	Sockets  []*SocketActivityNode        `json:"sockets,omitempty"`
+	Children []*ProcessActivityNode       `json:"children,omitempty"`
+}
+
+func newProcessActivityNode(entry *model.ProcessCacheEntry) *ProcessActivityNode {
+	return &ProcessActivityNode{
+		FilePath: entry.PathnameStr,
+		Comm:     entry.Comm,
+		Files:    make(map[string]*FileActivityNode),
+	}
+}
+
+func (pan *ProcessActivityNode) insertFile(path string, flags uint32) {
+	node, exists := pan.Files[path]
+	if !exists {
+		node = &FileActivityNode{Path: path}
+		pan.Files[path] = node
+	}
+	node.Flags |= flags
+	node.Count++
+}
+
+func (pan *ProcessActivityNode) insertSocket(socket *SocketActivityNode) {
+	for _, s := range pan.Sockets {
+		if *s == *socket {
+			return
+		}
+	}
+	pan.Sockets = append(pan.Sockets, socket)
+}
+
+// ActivityDump holds the activity recorded for a container, or for all the containers of an image
+type ActivityDump struct {
+	Metadata ActivityDumpMetadata   `json:"metadata"`
+	Tree     []*ProcessActivityNode `json:"tree"`
+
+	timeout time.Duration
+}
+
+// NewActivityDump returns a new activity dump for the given container ID or image
+func NewActivityDump(containerID string, image string, timeout time.Duration, tracedEventTypes []model.EventType) *ActivityDump {
+	ad := &ActivityDump{
+		Metadata: ActivityDumpMetadata{
+			AgentVersion: version.AgentVersion,
+			ContainerID:  containerID,
+			Image:        image,
+			Start:        time.Now(),
+			Timeout:      timeout.String(),
+		},
+		timeout: timeout,
+	}
+	for _, eventType := range tracedEventTypes {
+		ad.Metadata.TracedEventTypes = append(ad.Metadata.TracedEventTypes, eventType.String())
+	}
+	return ad
+}
+
+// IsExpired returns whether the recording window of the dump is over
+func (ad *ActivityDump) IsExpired(now time.Time) bool {
+	return now.After(ad.Metadata.Start.Add(ad.timeout))
+}
+
+// matches returns whether the dump records the activity of the given container
+func (ad *ActivityDump) matches(containerID string, image string) bool {
+	if ad.Metadata.ContainerID != "" {
+		return ad.Metadata.ContainerID == containerID
+	}
+	return image != "" && ad.Metadata.Image == image
+}
+
+// findOrCreateProcessNode returns the node of the process of the entry, inserting its lineage in the
+// container if needed. Consecutive processes executing the same file, like forks, share the same node.
+func (ad *ActivityDump) findOrCreateProcessNode(entry *model.ProcessCacheEntry) *ProcessActivityNode {
+	var lineage []*model.ProcessCacheEntry
+	for ancestor := entry; ancestor != nil && ancestor.ContainerID == entry.ContainerID; ancestor = ancestor.Ancestor {
+		lineage = append(lineage, ancestor)
+	}
+
+	var node *ProcessActivityNode
+	children := &ad.Tree
+
+LOOP:
+	for i := len(lineage) - 1; i >= 0; i-- {
+		current := lineage[i]
+		if node != nil && node.FilePath == current.PathnameStr {
+			continue
+		}
+
+		for _, child := range *children {
+			if child.FilePath == current.PathnameStr {
+				node, children = child, &child.Children
+				continue LOOP
+			}
+		}
+
+		node = newProcessActivityNode(current)
+		*children = append(*children, node)
+		children = &node.Children
+	}
+
+	return node
+}
+
+// insertEvent records the event in the dump
+func (ad *ActivityDump) insertEvent(event *Event) {
+	entry := event.ResolveProcessCacheEntry()
+	if entry == nil || entry.PathnameStr == "" {
+		return
+	}
+
+	node := ad.findOrCreateProcessNode(entry)
+	if node == nil {
+		return
+	}
+	ad.Metadata.EventsCount++
+
+	switch event.GetEventType() {
+	case model.FileOpenEventType:
+		if event.Open.Retval < 0 {
+			return
+		}
+		if path := event.ResolveFilePath(&event.Open.File); path != "" {
+			node.insertFile(path, event.Open.Flags)
+		}
+	case model.BindEventType:
+		if event.Bind.Retval < 0 {
+			return
+		}
+		node.insertSocket(&SocketActivityNode{
+			Family: model.AddressFamily(event.Bind.AddrFamily).String(),
+			IP:     event.Bind.Addr.IPNet.IP.String(),
+			Port:   event.Bind.Addr.Port,
+		})
+	}
+}
+
+// Name returns the name of the dump, used to build its output file name
+func (ad *ActivityDump) Name() string {
+	selector := ad.Metadata.ContainerID
+	if selector == "" {
+		selector = ad.Metadata.Image
+	}
+	return fmt.Sprintf("%s-%d", nonRuleIDChars.ReplaceAllString(selector, "_"), ad.Metadata.Start.Unix())
+}
+
+// ToMessage returns a protobuf message describing the dump
+func (ad *ActivityDump) ToMessage() *api.ActivityDumpMessage {
+	return &api.ActivityDumpMessage{
+		ContainerID: ad.Metadata.ContainerID,
+		Image:       ad.Metadata.Image,
+		Start:       ad.Metadata.Start.Format(time.RFC3339),
+		Timeout:     ad.Metadata.Timeout,
+	}
+}
+
+// Persist writes the dump as JSON in the given directory and returns the path of the file
+func (ad *ActivityDump) Persist(outputDirectory string) (string, error) {
+	if err := os.MkdirAll(outputDirectory, 0750); err != nil {
+		return "", errors.Wrap(err, "couldn't create activity dump output directory")
+	}
+
+	data, err := json.MarshalIndent(ad, "", "  ")
+	if err != nil {
+		return "", errors.Wrap(err, "couldn't encode activity dump")
+	}
+
+	filename := path.Join(outputDirectory, ad.Name()+".json")
+	if err := os.WriteFile(filename, data, 0600); err != nil {
+		return "", errors.Wrap(err, "couldn't write activity dump")
+	}
+
+	return filename, nil
+}
+
+// LoadActivityDump reads an activity dump from a file
+func LoadActivityDump(filename string) (*ActivityDump, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	var ad ActivityDump
+	if err := json.Unmarshal(data, &ad); err != nil {
+		return nil, errors.Wrapf(err, "couldn't decode activity dump `%s`", filename)
+	}
+	return &ad, nil
+}
+
+type generatedRule struct {
+	ID          string `yaml:"id"`
+	Description string `yaml:"description"`
+	Expression  string `yaml:"expression"`
+}
+
+type generatedPolicy struct {
+	Version string           `yaml:"version"`
+	Rules   []*generatedRule `yaml:"rules"`
+}
+
+// processProfile merges the activity of all the nodes sharing the same executable
+type processProfile struct {
+	files map[string]bool
+	ports map[uint16]bool
+}
+
+func collectProcessProfiles(nodes []*ProcessActivityNode, execs map[string]bool, profiles map[string]*processProfile) {
+	for _, node := range nodes {
+		execs[node.FilePath] = true
+
+		profile, exists := profiles[node.FilePath]
+		if !exists {
+			profile = &processProfile{files: make(map[string]bool), ports: make(map[uint16]bool)}
+			profiles[node.FilePath] = profile
+		}
+		for path := range node.Files {
+			profile.files[path] = true
+		}
+		for _, socket := range node.Sockets {
+			profile.ports[socket.Port] = true
+		}
+
+		collectProcessProfiles(node.Children, execs, profiles)
+	}
+}
+
+func quoteStrings(set map[string]bool) string {
+	values := make([]string, 0, len(set))
+	for value := range set {
+		values = append(values, strconv.Quote(value))
+	}
+	sort.Strings(values)
+	return strings.Join(values, ", ")
+}
+
+// GeneratePolicy returns a policy raising an event on any activity missing from the dump: unknown executed
+// files, files opened and ports bound by a process that didn't do it while the dump was recorded
+func (ad *ActivityDump) GeneratePolicy() ([]byte, error) {
+	var selector, prefix string
+	switch {
+	case ad.Metadata.ContainerID != "":
+		selector = fmt.Sprintf(`container.id == %s`, strconv.Quote(ad.Metadata.ContainerID))
+		prefix = ad.Metadata.ContainerID
+		if len(prefix) > 12 {
+			prefix = prefix[:12]
+		}
+	case ad.Metadata.Image != "":
+		selector = fmt.Sprintf(`container.tags == %s`, strconv.Quote(imageNameTag+":"+ad.Metadata.Image))
+		prefix = ad.Metadata.Image
+	default:
+		return nil, ErrActivityDumpSelector
+	}
+	prefix = "activity_dump_" + strings.Trim(nonRuleIDChars.ReplaceAllString(prefix, "_"), "_")
+
+	execs := make(map[string]bool)
+	profiles := make(map[string]*processProfile)
+	collectProcessProfiles(ad.Tree, execs, profiles)
+
+	policy := &generatedPolicy{Version: "1.0.0"}
+	if len(execs) > 0 {
+		policy.Rules = append(policy.Rules, &generatedRule{
+			ID:          prefix + "_exec",
+			Description: "Execution of a file missing from the activity dump",
+			Expression:  fmt.Sprintf(`exec.file.path not in [%s] && %s`, quoteStrings(execs), selector),
+		})
+	}
+
+	paths := make([]string, 0, len(profiles))
+	for path := range profiles {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	ids := make(map[string]int)
+	ruleID := func(kind string, execPath string) string {
+		id := fmt.Sprintf("%s_%s_%s", prefix, kind, strings.Trim(nonRuleIDChars.ReplaceAllString(path.Base(execPath), "_"), "_"))
+		ids[id]++
+		if count := ids[id]; count > 1 {
+			id = fmt.Sprintf("%s_%d", id, count)
+		}
+		return id
+	}
+
+	for _, execPath := range paths {
+		profile := profiles[execPath]
+		process := fmt.Sprintf(`process.file.path == %s`, strconv.Quote(execPath))
+
+		if len(profile.files) > 0 {
+			policy.Rules = append(policy.Rules, &generatedRule{
+				ID:          ruleID("open", execPath),
+				Description: fmt.Sprintf("File missing from the activity dump opened by %s", execPath),
+				Expression:  fmt.Sprintf(`open.file.path not in [%s] && %s && %s`, quoteStrings(profile.files), process, selector),
+			})
+		}
+
+		if len(profile.ports) > 0 {
+			ports := make([]int, 0, len(profile.ports))
+			for port := range profile.ports {
+				ports = append(ports, int(port))
+			}
+			sort.Ints(ports)
+
+			values := make([]string, len(ports))
+			for i, port := range ports {
+				values[i] = strconv.Itoa(port)
+			}
+
+			policy.Rules = append(policy.Rules, &generatedRule{
+				ID:          ruleID("bind", execPath),
+				Description: fmt.Sprintf("Port missing from the activity dump bound by %s", execPath),
+				Expression:  fmt.Sprintf(`bind.addr.port not in [%s] && %s && %s`, strings.Join(values, ", "), process, selector),
+			})
+		}
+	}
+
+	return yaml.Marshal(policy)
+}
+
+// ActivityDumpManager records the activity of the containers for which an activity dump was started
+type ActivityDumpManager struct {
+	sync.Mutex
+	probe            *Probe
+	config           *config.Config
+	tracedEventTypes []model.EventType
+	tracedEventMask  uint64
+	dumps            []*ActivityDump
+	images           map[string]string
+	tracedContainers map[string]bool
+}
+
+// NewActivityDumpManager returns a new activity dump manager
+func NewActivityDumpManager(p *Probe, cfg *config.Config) (*ActivityDumpManager, error) {
+	var tracedEventTypes []model.EventType
+	var tracedEventMask uint64
+	for _, name := range cfg.ActivityDumpTracedEventTypes {
+		eventType := model.ParseEvalEventType(name)
+		if eventType == model.UnknownEventType {
+			return nil, fmt.Errorf("unknown activity dump event type '%s'", name)
+		}
+		tracedEventTypes = append(tracedEventTypes, eventType)
+		tracedEventMask |= 1 << (eventType - 1)
+	}
+
+	return &ActivityDumpManager{
+		probe:            p,
+		config:           cfg,
+		tracedEventTypes: tracedEventTypes,
+		tracedEventMask:  tracedEventMask,
+		images:           make(map[string]string),
+		tracedContainers: make(map[string]bool),
+	}, nil
+}
+
+// IsTracedEventType returns whether the events of the given type are recorded in activity dumps
+func (m *ActivityDumpManager) IsTracedEventType(eventType model.EventType) bool {
+	for _, traced := range m.tracedEventTypes {
+		if traced == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// HasActiveDumps returns whether at least one activity dump is running
+func (m *ActivityDumpManager) HasActiveDumps() bool {
+	m.Lock()
+	defer m.Unlock()
+
+	return len(m.dumps) > 0
+}
+
+// IsTracedContainer returns whether the events of the given type and container are currently recorded by a dump,
+// in which case they are neither approved nor discarded in kernel
+func (m *ActivityDumpManager) IsTracedContainer(containerID string, eventType model.EventType) bool {
+	if containerID == "" || !m.IsTracedEventType(eventType) {
+		return false
+	}
+
+	m.Lock()
+	defer m.Unlock()
+
+	return m.tracedContainers[containerID]
+}
+
+// traceContainer disables the in-kernel filters of the traced event types for the given container
+func (m *ActivityDumpManager) traceContainer(containerID string) {
+	if m.tracedContainers[containerID] {
+		return
+	}
+	m.tracedContainers[containerID] = true
+
+	if m.probe == nil {
+		return
+	}
+
+	table, err := m.probe.Map("traced_cgroups")
+	if err != nil {
+		log.Errorf("failed to trace container %s: %s", containerID, err)
+		return
+	}
+	if err := table.Put(ebpf.NewStringMapItem(containerID, containerIDLen), m.tracedEventMask); err != nil {
+		log.Errorf("failed to trace container %s: %s", containerID, err)
+	}
+}
+
+// untraceStoppedContainers restores the in-kernel filters for the containers that are no longer recorded by a dump
+func (m *ActivityDumpManager) untraceStoppedContainers() {
+	for containerID := range m.tracedContainers {
+		var traced bool
+		for _, ad := range m.dumps {
+			if ad.matches(containerID, m.images[containerID]) {
+				traced = true
+				break
+			}
+		}
+		if traced {
+			continue
+		}
+		delete(m.tracedContainers, containerID)
+
+		if m.probe == nil {
+			continue
+		}
+
+		table, err := m.probe.Map("traced_cgroups")
+		if err != nil {
+			log.Errorf("failed to untrace container %s: %s", containerID, err)
+			continue
+		}
+		if err := table.Delete(ebpf.NewStringMapItem(containerID, containerIDLen)); err != nil {
+			log.Errorf("failed to untrace container %s: %s", containerID, err)
+		}
+	}
+}
+
+// refreshProbes activates the probes of the traced event types while a dump is running, and deactivates them
+// once the last dump is over
+func (m *ActivityDumpManager) refreshProbes() {
+	if m.probe == nil {
+		return
+	}
+
+	if err := m.probe.refreshActivityDumpProbes(); err != nil {
+		log.Errorf("failed to select the probes of the activity dumps: %s", err)
+	}
+}
+
+// Start persists the activity dumps once their recording window is over
+func (m *ActivityDumpManager) Start(ctx context.Context, wg *sync.WaitGroup) {
+	defer wg.Done()
+
+	ticker := time.NewTicker(activityDumpCleanupPeriod)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			expired, last := m.removeExpiredDumps(now)
+			for _, ad := range expired {
+				m.persist(ad)
+			}
+			if last {
+				m.refreshProbes()
+			}
+		}
+	}
+}
+
+// removeExpiredDumps removes the expired dumps and returns them, along with whether they were the last running ones
+func (m *ActivityDumpManager) removeExpiredDumps(now time.Time) ([]*ActivityDump, bool) {
+	m.Lock()
+	defer m.Unlock()
+
+	var expired []*ActivityDump
+	var i int
+	for _, ad := range m.dumps {
+		if ad.IsExpired(now) {
+			expired = append(expired, ad)
+		} else {
+			m.dumps[i] = ad
+			i++
+		}
+	}
+	m.dumps = m.dumps[:i]
+
+	if len(expired) == 0 {
+		return nil, false
+	}
+
+	m.untraceStoppedContainers()
+	if len(m.dumps) == 0 {
+		m.images = make(map[string]string)
+	}
+
+	return expired, len(m.dumps) == 0
+}
+
+func (m *ActivityDumpManager) persist(ad *ActivityDump) (string, error) {
+	ad.Metadata.End = time.Now()
+
+	filename, err := ad.Persist(m.config.ActivityDumpOutputDirectory)
+	if err != nil {
+		log.Errorf("failed to persist activity dump %s: %s", ad.Name(), err)
+		return "", err
+	}
+	log.Infof("activity dump %s written to %s", ad.Name(), filename)
+
+	return filename, nil
+}
+
+// StartDump starts recording the activity of a container, or of the containers of an image
+func (m *ActivityDumpManager) StartDump(params *api.ActivityDumpParams) (*ActivityDump, error) {
+	if (params.ContainerID == "") == (params.Image == "") {
+		return nil, ErrActivityDumpSelector
+	}
+
+	timeout := m.config.ActivityDumpTimeout
+	if params.Timeout != "" {
+		var err error
+		if timeout, err = time.ParseDuration(params.Timeout); err != nil || timeout <= 0 {
+			return nil, fmt.Errorf("invalid activity dump timeout '%s'", params.Timeout)
+		}
+	}
+
+	m.Lock()
+	for _, ad := range m.dumps {
+		if ad.Metadata.ContainerID == params.ContainerID && ad.Metadata.Image == params.Image {
+			m.Unlock()
+			return nil, errors.New("an activity dump is already running for this selector")
+		}
+	}
+
+	ad := NewActivityDump(params.ContainerID, params.Image, timeout, m.tracedEventTypes)
+	m.dumps = append(m.dumps, ad)
+
+	// trace the containers already known to run the image, the others are traced on their first event
+	for containerID, image := range m.images {
+		if ad.matches(containerID, image) {
+			m.traceContainer(containerID)
+		}
+	}
+	if params.ContainerID != "" {
+		m.traceContainer(params.ContainerID)
+	}
+
+	first := len(m.dumps) == 1
+	m.Unlock()
+
+	if first {
+		m.refreshProbes()
+	}
+
+	return ad, nil
+}
+
+// ListDumps returns the running activity dumps
+func (m *ActivityDumpManager) ListDumps() []*api.ActivityDumpMessage {
+	m.Lock()
+	defer m.Unlock()
+
+	msgs := make([]*api.ActivityDumpMessage, 0, len(m.dumps))
+	for _, ad := range m.dumps {
+		msgs = append(msgs, ad.ToMessage())
+	}
+	return msgs
+}
+
+// StopDump stops an activity dump and returns the path of the file it was written to
+func (m *ActivityDumpManager) StopDump(params *api.ActivityDumpStopParams) (string, error) {
+	if (params.ContainerID == "") == (params.Image == "") {
+		return "", ErrActivityDumpSelector
+	}
+
+	m.Lock()
+	var stopped *ActivityDump
+	for i, ad := range m.dumps {
+		if ad.Metadata.ContainerID == params.ContainerID && ad.Metadata.Image == params.Image {
+			stopped = ad
+			m.dumps = append(m.dumps[:i], m.dumps[i+1:]...)
+			break
+		}
+	}
+	if stopped != nil {
+		m.untraceStoppedContainers()
+	}
+	last := stopped != nil && len(m.dumps) == 0
+	m.Unlock()
+
+	if stopped == nil {
+		return "", ErrActivityDumpNotFound
+	}
+
+	if last {
+		m.refreshProbes()
+	}
+
+	return m.persist(stopped)
+}
+
+// resolveImage returns the image of a container, caching it once the tags of the container are known
+func (m *ActivityDumpManager) resolveImage(containerID string) string {
+	if image, exists := m.images[containerID]; exists {
+		return image
+	}
+
+	image := m.probe.resolvers.TagsResolver.GetValue(containerID, imageNameTag)
+	if image != "" {
+		m.images[containerID] = image
+	}
+	return image
+}
+
+// ProcessEvent records the event in the activity dumps of its container
+func (m *ActivityDumpManager) ProcessEvent(event *Event) {
+	m.Lock()
+	defer m.Unlock()
+
+	if len(m.dumps) == 0 {
+		return
+	}
+
+	containerID := event.ResolveContainerID(&event.ContainerContext)
+	if containerID == "" {
+		return
+	}
+
+	var image string
+	for _, ad := range m.dumps {
+		if ad.Metadata.Image != "" && image == "" {
+			image = m.resolveImage(containerID)
+		}
+
+		if !ad.matches(containerID, image) {
+			continue
+		}
+
+		// the containers of an image are only known once they emit their first event
+		m.traceContainer(containerID)
+
+		if m.IsTracedEventType(event.GetEventType()) {
+			ad.insertEvent(event)
+		}
+	}
+}
@@ -0,0 +1,224 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build linux
+// +build linux
+
+package probe
+
+import (
+	"bytes"
+	"net"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/DataDog/datadog-agent/pkg/security/api"
+	"github.com/DataDog/datadog-agent/pkg/security/config"
+	seclog "github.com/DataDog/datadog-agent/pkg/security/log"
+	"github.com/DataDog/datadog-agent/pkg/security/secl/compiler/eval"
+	"github.com/DataDog/datadog-agent/pkg/security/secl/model"
+	"github.com/DataDog/datadog-agent/pkg/security/secl/rules"
+)
+
+func newActivityDumpEntry(path string, containerID string, ancestor *model.ProcessCacheEntry) *model.ProcessCacheEntry {
+	return &model.ProcessCacheEntry{
+		ProcessContext: model.ProcessContext{
+			Process: model.Process{
+				PathnameStr: path,
+				Comm:        path[len(path)-4:],
+				ContainerID: containerID,
+			},
+			Ancestor: ancestor,
+		},
+	}
+}
+
+func newActivityDumpEvent(eventType model.EventType, entry *model.ProcessCacheEntry) *Event {
+	event := &Event{processCacheEntry: entry}
+	event.Type = uint64(eventType)
+	event.ContainerContext.ID = entry.ContainerID
+	return event
+}
+
+func TestActivityDumpTree(t *testing.T) {
+	ad := NewActivityDump("abc", "", time.Minute, []model.EventType{model.ExecEventType, model.FileOpenEventType, model.BindEventType})
+
+	host := newActivityDumpEntry("/usr/bin/containerd-shim", "", nil)
+	shell := newActivityDumpEntry("/usr/bin/bash", "abc", host)
+	fork := newActivityDumpEntry("/usr/bin/bash", "abc", shell)
+	nginx := newActivityDumpEntry("/usr/sbin/nginx", "abc", fork)
+
+	ad.insertEvent(newActivityDumpEvent(model.ExecEventType, shell))
+
+	open := newActivityDumpEvent(model.FileOpenEventType, nginx)
+	open.Open.File.PathnameStr = "/etc/nginx/nginx.conf"
+	open.Open.Flags = syscall.O_RDONLY
+	ad.insertEvent(open)
+	ad.insertEvent(open)
+
+	bind := newActivityDumpEvent(model.BindEventType, nginx)
+	bind.Bind.AddrFamily = syscall.AF_INET
+	bind.Bind.Addr.IPNet = eval.IPNetFromIP(net.ParseIP("0.0.0.0"))
+	bind.Bind.Addr.Port = 80
+	ad.insertEvent(bind)
+	ad.insertEvent(bind)
+
+	failed := newActivityDumpEvent(model.FileOpenEventType, nginx)
+	failed.Open.File.PathnameStr = "/etc/shadow"
+	failed.Open.Retval = -int64(syscall.EACCES)
+	ad.insertEvent(failed)
+
+	assert.Equal(t, uint64(6), ad.Metadata.EventsCount)
+
+	// the host process is out of the container and the fork of bash is merged with its parent
+	if !assert.Len(t, ad.Tree, 1) {
+		return
+	}
+	assert.Equal(t, "/usr/bin/bash", ad.Tree[0].FilePath)
+	if !assert.Len(t, ad.Tree[0].Children, 1) {
+		return
+	}
+
+	node := ad.Tree[0].Children[0]
+	assert.Equal(t, "/usr/sbin/nginx", node.FilePath)
+	assert.Equal(t, map[string]*FileActivityNode{
+		"/etc/nginx/nginx.conf": {Path: "/etc/nginx/nginx.conf", Flags: syscall.O_RDONLY, Count: 2},
+	}, node.Files)
+	assert.Equal(t, []*SocketActivityNode{{Family: "AF_INET", IP: "0.0.0.0", Port: 80}}, node.Sockets)
+}
+
+func TestActivityDumpMatches(t *testing.T) {
+	byContainer := NewActivityDump("abc", "", time.Minute, nil)
+	assert.True(t, byContainer.matches("abc", ""))
+	assert.False(t, byContainer.matches("def", "nginx"))
+
+	byImage := NewActivityDump("", "nginx", time.Minute, nil)
+	assert.True(t, byImage.matches("def", "nginx"))
+	assert.False(t, byImage.matches("def", ""))
+	assert.False(t, byImage.matches("def", "redis"))
+
+	assert.False(t, byImage.IsExpired(time.Now()))
+	assert.True(t, byImage.IsExpired(time.Now().Add(2*time.Minute)))
+}
+
+func TestActivityDumpGeneratePolicy(t *testing.T) {
+	ad := NewActivityDump("", "nginx:1.21", time.Minute, nil)
+	ad.Tree = []*ProcessActivityNode{
+		{
+			FilePath: "/usr/bin/bash",
+			Children: []*ProcessActivityNode{
+				{
+					FilePath: "/usr/sbin/nginx",
+					Files: map[string]*FileActivityNode{
+						"/etc/nginx/nginx.conf": {Path: "/etc/nginx/nginx.conf"},
+						"/var/log/nginx.log":    {Path: "/var/log/nginx.log"},
+					},
+					Sockets: []*SocketActivityNode{{Family: "AF_INET", IP: "0.0.0.0", Port: 80}},
+				},
+			},
+		},
+	}
+
+	data, err := ad.GeneratePolicy()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	policy, err := rules.LoadPolicy(bytes.NewReader(data), "activity_dump.policy")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, ruleDefs, merr := policy.GetValidMacroAndRules()
+	if merr.ErrorOrNil() != nil {
+		t.Fatal(merr)
+	}
+
+	expressions := make(map[rules.RuleID]string)
+	for _, ruleDef := range ruleDefs {
+		expressions[ruleDef.ID] = ruleDef.Expression
+	}
+	assert.Equal(t, map[rules.RuleID]string{
+		"activity_dump_nginx_1_21_exec":       `exec.file.path not in ["/usr/bin/bash", "/usr/sbin/nginx"] && container.tags == "image_name:nginx:1.21"`,
+		"activity_dump_nginx_1_21_open_nginx": `open.file.path not in ["/etc/nginx/nginx.conf", "/var/log/nginx.log"] && process.file.path == "/usr/sbin/nginx" && container.tags == "image_name:nginx:1.21"`,
+		"activity_dump_nginx_1_21_bind_nginx": `bind.addr.port not in [80] && process.file.path == "/usr/sbin/nginx" && container.tags == "image_name:nginx:1.21"`,
+	}, expressions)
+
+	var opts rules.Opts
+	opts.
+		WithConstants(model.SECLConstants).
+		WithEventTypeEnabled(map[eval.EventType]bool{"*": true}).
+		WithLegacyFields(model.SECLLegacyFields).
+		WithLogger(&seclog.PatternLogger{})
+
+	m := &model.Model{}
+	rs := rules.NewRuleSet(m, m.NewEvent, &opts)
+	if err := rs.AddRules(ruleDefs); err.ErrorOrNil() != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestActivityDumpFilters(t *testing.T) {
+	cfg := &config.Config{
+		EnableKernelFilters:          true,
+		EnableApprovers:              true,
+		EnableDiscarders:             true,
+		ActivityDumpEnabled:          true,
+		ActivityDumpTracedEventTypes: []string{"exec", "open", "bind"},
+		ActivityDumpTimeout:          time.Minute,
+		ActivityDumpOutputDirectory:  t.TempDir(),
+	}
+
+	var opts rules.Opts
+	opts.
+		WithConstants(model.SECLConstants).
+		WithEventTypeEnabled(map[eval.EventType]bool{"*": true}).
+		WithLegacyFields(model.SECLLegacyFields).
+		WithLogger(&seclog.PatternLogger{})
+
+	m := &model.Model{}
+	rs := rules.NewRuleSet(m, m.NewEvent, &opts)
+	addRuleExpr(t, rs, `open.file.path == "/etc/passwd"`)
+
+	approvers, err := rs.GetApprovers(GetCapababilities())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// enabling activity dumps doesn't change the rule set filters
+	report, err := NewRuleSetApplier(cfg, nil).Apply(rs, approvers)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, PolicyModeDeny, report.Policies["open"].Mode)
+	assert.Contains(t, report.Policies["open"].Approvers, "open.file.path")
+	assert.Equal(t, PolicyModeDeny, report.Policies["exec"].Mode)
+
+	manager, err := NewActivityDumpManager(nil, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// discarders are only suppressed for the traced events of the containers of a running dump
+	assert.False(t, manager.HasActiveDumps())
+	assert.False(t, manager.IsTracedContainer("abc", model.FileOpenEventType))
+
+	if _, err := manager.StartDump(&api.ActivityDumpParams{ContainerID: "abc"}); err != nil {
+		t.Fatal(err)
+	}
+	assert.True(t, manager.HasActiveDumps())
+	assert.True(t, manager.IsTracedContainer("abc", model.FileOpenEventType))
+	assert.False(t, manager.IsTracedContainer("abc", model.FileChmodEventType))
+	assert.False(t, manager.IsTracedContainer("def", model.FileOpenEventType))
+
+	if _, err := manager.StopDump(&api.ActivityDumpStopParams{ContainerID: "abc"}); err != nil {
+		t.Fatal(err)
+	}
+	assert.False(t, manager.HasActiveDumps())
+	assert.False(t, manager.IsTracedContainer("abc", model.FileOpenEventType))
+}
@@ -22,3 +22,18 @@ func getCheckHelperCallInputType(probe *Probe) uint64 {
 
 	return input
 }
+
+// getIterTypeUBUF returns the value of ITER_UBUF in enum iter_type, or a value that never matches on kernels
+// without single buffer iterators
+func getIterTypeUBUF(probe *Probe) uint64 {
+	iterType := ^uint64(0)
+
+	switch {
+	case probe.kernelVersion.Code != 0 && probe.kernelVersion.Code >= kernel.Kernel6_5:
+		iterType = uint64(0)
+	case probe.kernelVersion.Code != 0 && probe.kernelVersion.Code >= kernel.Kernel6_0:
+		iterType = uint64(6)
+	}
+
+	return iterType
+}
@@ -68,6 +68,8 @@ func (f *FallbackConstantFetcher) appendRequest(id string) {
 		value = getSizeOfUpid(f.kernelVersion)
 	case "dentry_sb_offset":
 		value = getDentrySuperBlockOffset(f.kernelVersion)
+	case "iov_iter_iov_offset":
+		value = getIovIterIovOffset(f.kernelVersion)
 	}
 	f.res[id] = value
 }
@@ -399,3 +401,14 @@ func getDentrySuperBlockOffset(kv *kernel.Version) uint64 {
 
 	return offset
 }
+
+func getIovIterIovOffset(kv *kernel.Version) uint64 {
+	offset := uint64(24)
+
+	// iov_offset and count were moved out of the union in 6.4
+	if kv.Code != 0 && kv.Code >= kernel.Kernel6_4 {
+		offset = 16
+	}
+
+	return offset
+}
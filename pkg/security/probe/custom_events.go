@@ -139,6 +139,31 @@ func NewEventLostWriteEvent(mapName string, perEventPerCPU map[string]uint64) (*
 		})
 }
 
+// SequenceEvent is used to report the events which completed a sequence
+// easyjson:json
+type SequenceEvent struct {
+	Timestamp time.Time                  `json:"date"`
+	Scope     string                     `json:"scope,omitempty"`
+	ScopeKey  string                     `json:"scope_key,omitempty"`
+	Events    []*rules.SequenceStepMatch `json:"events"`
+}
+
+// NewSequenceEvent returns the rule and a populated custom event for a sequence event
+func NewSequenceEvent(sequence *rules.Sequence, match *rules.SequenceMatch) (*rules.Rule, *CustomEvent) {
+	return newRule(&rules.RuleDefinition{
+			ID:          sequence.ID,
+			Version:     sequence.Definition.Version,
+			Description: sequence.Definition.Description,
+			Tags:        sequence.Definition.Tags,
+			Policy:      sequence.Definition.Policy,
+		}), newCustomEvent(model.CustomSequenceEventType, SequenceEvent{
+			Timestamp: time.Now(),
+			Scope:     string(sequence.Definition.Scope),
+			ScopeKey:  match.ScopeKey,
+			Events:    match.Events,
+		})
+}
+
 // RuleIgnored defines a ignored
 // easyjson:json
 type RuleIgnored struct {
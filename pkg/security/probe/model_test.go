@@ -9,6 +9,7 @@
 package probe
 
 import (
+	"net"
 	"reflect"
 	"sort"
 	"testing"
@@ -38,6 +39,11 @@ func TestSetFieldValue(t *testing.T) {
 			if err = event.SetFieldValue(field, true); err != nil {
 				t.Error(err)
 			}
+		case reflect.Struct:
+			ipnet := net.IPNet{IP: net.IPv4(127, 0, 0, 1), Mask: net.CIDRMask(32, 32)}
+			if err = event.SetFieldValue(field, ipnet); err != nil {
+				t.Error(err)
+			}
 		default:
 			t.Errorf("type unknown: %v", kind)
 		}
@@ -77,6 +77,13 @@ type Probe struct {
 	approvers          map[eval.EventType]activeApprovers
 
 	inodeDiscardersCounters map[model.EventType]*int64
+
+	// Activity dumps section
+	activityDumpManager *ActivityDumpManager
+
+	// Probes selection section
+	selectProbesLock sync.Mutex
+	ruleSet          *rules.RuleSet
 }
 
 // GetResolvers returns the resolvers of Probe
@@ -84,6 +91,11 @@ func (p *Probe) GetResolvers() *Resolvers {
 	return p.resolvers
 }
 
+// GetActivityDumpManager returns the activity dump manager, nil if activity dumps are disabled
+func (p *Probe) GetActivityDumpManager() *ActivityDumpManager {
+	return p.activityDumpManager
+}
+
 // Map returns a map by its name
 func (p *Probe) Map(name string) (*lib.Map, error) {
 	if p.manager == nil {
@@ -228,6 +240,11 @@ func (p *Probe) Start() error {
 		return err
 	}
 
+	if p.activityDumpManager != nil {
+		p.wg.Add(1)
+		go p.activityDumpManager.Start(p.ctx, &p.wg)
+	}
+
 	return p.monitor.Start(p.ctx, &p.wg)
 }
 
@@ -247,6 +264,10 @@ func (p *Probe) DispatchEvent(event *Event, size uint64, CPU int, perfMap *manag
 		p.handler.HandleEvent(event)
 	}
 
+	if p.activityDumpManager != nil {
+		p.activityDumpManager.ProcessEvent(event)
+	}
+
 	// Process after evaluation because some monitors need the DentryResolver to have been called first.
 	p.monitor.ProcessEvent(event, size, CPU, perfMap)
 }
@@ -586,6 +607,25 @@ func (p *Probe) handleEvent(CPU uint64, data []byte) {
 			event.Signal.TargetProcessCacheEntry = cacheEntry
 			event.Signal.Target = cacheEntry.ProcessContext
 		}
+	case model.ConnectEventType:
+		if _, err = event.Connect.UnmarshalBinary(data[offset:]); err != nil {
+			log.Errorf("failed to decode connect event: %s (offset %d, len %d)", err, offset, len(data))
+			return
+		}
+	case model.BindEventType:
+		if _, err = event.Bind.UnmarshalBinary(data[offset:]); err != nil {
+			log.Errorf("failed to decode bind event: %s (offset %d, len %d)", err, offset, len(data))
+			return
+		}
+	case model.DNSEventType:
+		if _, err = event.DNS.UnmarshalBinary(data[offset:]); err != nil {
+			if err == model.ErrDNSNamePointerNotSupported {
+				log.Debugf("failed to decode dns event: %s (offset %d, len %d)", err, offset, len(data))
+			} else {
+				log.Errorf("failed to decode dns event: %s (offset %d, len %d)", err, offset, len(data))
+			}
+			return
+		}
 	default:
 		log.Errorf("unsupported event type %d", eventType)
 		return
@@ -636,6 +676,11 @@ func (p *Probe) OnNewDiscarder(rs *rules.RuleSet, event *Event, field eval.Field
 		return nil
 	}
 
+	// events recorded by a running activity dump must not be discarded
+	if p.activityDumpManager != nil && p.activityDumpManager.IsTracedContainer(event.ResolveContainerID(&event.ContainerContext), model.ParseEvalEventType(eventType)) {
+		return nil
+	}
+
 	seclog.Tracef("New discarder of type %s for field %s", eventType, field)
 
 	if handler, ok := allDiscarderHandlers[eventType]; ok {
@@ -702,6 +747,35 @@ func (p *Probe) SetApprovers(eventType eval.EventType, approvers rules.Approvers
 // SelectProbes applies the loaded set of rules and returns a report
 // of the applied approvers for it.
 func (p *Probe) SelectProbes(rs *rules.RuleSet) error {
+	p.selectProbesLock.Lock()
+	defer p.selectProbesLock.Unlock()
+
+	p.ruleSet = rs
+	return p.selectProbes(rs)
+}
+
+// refreshActivityDumpProbes selects the probes again when the first activity dump starts or the last one stops,
+// so that the probes of the event types recorded by the dumps are only activated while a dump is running
+func (p *Probe) refreshActivityDumpProbes() error {
+	p.selectProbesLock.Lock()
+	defer p.selectProbesLock.Unlock()
+
+	// no rule set applied yet, the probes will be selected with it
+	if p.ruleSet == nil {
+		return nil
+	}
+	return p.selectProbes(p.ruleSet)
+}
+
+// activityDumpEventTypes returns the event types recorded by the running activity dumps
+func (p *Probe) activityDumpEventTypes() []model.EventType {
+	if p.activityDumpManager == nil || !p.activityDumpManager.HasActiveDumps() {
+		return nil
+	}
+	return p.activityDumpManager.tracedEventTypes
+}
+
+func (p *Probe) selectProbes(rs *rules.RuleSet) error {
 	var activatedProbes []manager.ProbesSelector
 
 	for eventType, selectors := range probes.SelectorsPerEventType {
@@ -715,6 +789,14 @@ func (p *Probe) SelectProbes(rs *rules.RuleSet) error {
 		activatedProbes = append(activatedProbes, probes.SyscallMonitorSelectors...)
 	}
 
+	// Add the probes of the event types recorded by the running activity dumps
+	activityDumpEventTypes := p.activityDumpEventTypes()
+	for _, eventType := range activityDumpEventTypes {
+		if !rs.HasRulesForEventType(eventType.String()) {
+			activatedProbes = append(activatedProbes, probes.SelectorsPerEventType[eventType.String()]...)
+		}
+	}
+
 	// Print the list of unique probe identification IDs that are registered
 	var selectedIDs []manager.ProbeIdentificationPair
 	for _, selector := range activatedProbes {
@@ -748,6 +830,10 @@ func (p *Probe) SelectProbes(rs *rules.RuleSet) error {
 		}
 	}
 
+	for _, eventType := range activityDumpEventTypes {
+		enabledEvents |= 1 << (eventType - 1)
+	}
+
 	// We might end up missing events during the snapshot. Ultimately we might want to stop the rules evaluation but
 	// not the perf map entirely. For now this will do though :)
 	if err := p.perfMap.Pause(); err != nil {
@@ -1003,6 +1089,10 @@ func NewProbe(config *config.Config, client *statsd.Client) (*Probe, error) {
 			Name:  "check_helper_call_input",
 			Value: getCheckHelperCallInputType(p),
 		},
+		manager.ConstantEditor{
+			Name:  "iter_type_ubuf",
+			Value: getIterTypeUBUF(p),
+		},
 	)
 	p.managerOptions.ConstantEditors = append(p.managerOptions.ConstantEditors, DiscarderConstants...)
 	p.managerOptions.ConstantEditors = append(p.managerOptions.ConstantEditors, getCGroupWriteConstants())
@@ -1019,6 +1109,14 @@ func NewProbe(config *config.Config, client *statsd.Client) (*Probe, error) {
 		)
 	}
 
+	// constants activity dumps
+	if p.config.ActivityDumpEnabled {
+		p.managerOptions.ConstantEditors = append(p.managerOptions.ConstantEditors, manager.ConstantEditor{
+			Name:  "activity_dump_enabled",
+			Value: uint64(1),
+		})
+	}
+
 	// constants syscall monitor
 	if p.config.SyscallMonitor {
 		p.managerOptions.ConstantEditors = append(p.managerOptions.ConstantEditors, manager.ConstantEditor{
@@ -1040,6 +1138,12 @@ func NewProbe(config *config.Config, client *statsd.Client) (*Probe, error) {
 	}
 	p.resolvers = resolvers
 
+	if config.ActivityDumpEnabled {
+		if p.activityDumpManager, err = NewActivityDumpManager(p, config); err != nil {
+			return nil, err
+		}
+	}
+
 	p.reOrderer = NewReOrderer(ctx,
 		p.handleEvent,
 		ExtractEventInfo,
@@ -1100,5 +1204,12 @@ func GetOffsetConstantsFromFetcher(constantFetcher constantfetch.ConstantFetcher
 	constantFetcher.AppendOffsetofRequest("pid_numbers_offset", "struct pid", "numbers", "linux/pid.h")
 	constantFetcher.AppendSizeofRequest("sizeof_upid", "struct upid", "linux/pid.h")
 
+	// iov_iter offsets, the iovec pointer was renamed in 6.4
+	if probe.kernelVersion.Code != 0 && probe.kernelVersion.Code >= kernel.Kernel6_4 {
+		constantFetcher.AppendOffsetofRequest("iov_iter_iov_offset", "struct iov_iter", "__iov", "linux/uio.h")
+	} else {
+		constantFetcher.AppendOffsetofRequest("iov_iter_iov_offset", "struct iov_iter", "iov", "linux/uio.h")
+	}
+
 	return constantFetcher.FinishAndGetResults()
 }
@@ -990,6 +990,20 @@ func (p *ProcessResolver) Dump(withArgs bool) (string, error) {
 	return dump.Name(), err
 }
 
+// GetContainerPIDs returns the pids of the processes of the given container
+func (p *ProcessResolver) GetContainerPIDs(containerID string) []uint32 {
+	p.RLock()
+	defer p.RUnlock()
+
+	var pids []uint32
+	for pid, entry := range p.entryCache {
+		if entry.ContainerID == containerID {
+			pids = append(pids, pid)
+		}
+	}
+	return pids
+}
+
 // GetCacheSize returns the cache size of the process resolver
 func (p *ProcessResolver) GetCacheSize() float64 {
 	p.RLock()
@@ -248,6 +248,45 @@ type SignalEventSerializer struct {
 	Target *ProcessContextSerializer `json:"target,omitempty" jsonschema_description:"process context of the signal target"`
 }
 
+// IPPortSerializer serializes an IP and a port to JSON
+type IPPortSerializer struct {
+	IP   string `json:"ip" jsonschema_description:"IP address"`
+	Port uint16 `json:"port" jsonschema_description:"Port number"`
+}
+
+// IPPortFamilySerializer serializes an IP, a port and an address family to JSON
+type IPPortFamilySerializer struct {
+	Family string `json:"family" jsonschema_description:"Address family"`
+	IP     string `json:"ip" jsonschema_description:"IP address"`
+	Port   uint16 `json:"port" jsonschema_description:"Port number"`
+}
+
+// ConnectEventSerializer serializes a connect event to JSON
+type ConnectEventSerializer struct {
+	Addr IPPortFamilySerializer `json:"addr" jsonschema_description:"Address the connection was initiated to"`
+}
+
+// BindEventSerializer serializes a bind event to JSON
+type BindEventSerializer struct {
+	Addr IPPortFamilySerializer `json:"addr" jsonschema_description:"Address the socket was bound to"`
+}
+
+// DNSQuestionSerializer serializes a DNS question to JSON
+type DNSQuestionSerializer struct {
+	Class string `json:"class" jsonschema_description:"class looked up by the DNS question"`
+	Type  string `json:"type" jsonschema_description:"a two octet code which specifies the DNS question type"`
+	Name  string `json:"name" jsonschema_description:"the queried domain name"`
+	Size  uint16 `json:"size" jsonschema_description:"the total DNS request size in bytes"`
+	Count uint16 `json:"count" jsonschema_description:"the total count of questions in the DNS request"`
+}
+
+// DNSEventSerializer serializes a DNS event to JSON
+type DNSEventSerializer struct {
+	ID       uint16                `json:"id" jsonschema_description:"id is the unique identifier of the DNS request"`
+	Question DNSQuestionSerializer `json:"question,omitempty" jsonschema_description:"question is a DNS question for the current DNS request"`
+	Server   IPPortSerializer      `json:"server" jsonschema_description:"DNS server the request was sent to"`
+}
+
 // DDContextSerializer serializes a span context to JSON
 // easyjson:json
 type DDContextSerializer struct {
@@ -273,6 +312,9 @@ type EventSerializer struct {
 	*PTraceEventSerializer     `json:"ptrace,omitempty"`
 	*ModuleEventSerializer     `json:"module,omitempty"`
 	*SignalEventSerializer     `json:"signal,omitempty"`
+	*ConnectEventSerializer    `json:"connect,omitempty"`
+	*BindEventSerializer       `json:"bind,omitempty"`
+	*DNSEventSerializer        `json:"dns,omitempty"`
 	UserContextSerializer      UserContextSerializer       `json:"usr,omitempty"`
 	ProcessContextSerializer   ProcessContextSerializer    `json:"process,omitempty"`
 	DDContextSerializer        DDContextSerializer         `json:"dd,omitempty"`
@@ -588,6 +630,47 @@ func newSignalEventSerializer(e *Event) *SignalEventSerializer {
 	return ses
 }
 
+func newIPPortSerializer(c *model.IPPortContext) IPPortSerializer {
+	return IPPortSerializer{
+		IP:   c.IPNet.IP.String(),
+		Port: c.Port,
+	}
+}
+
+func newIPPortFamilySerializer(c *model.IPPortContext, family uint16) IPPortFamilySerializer {
+	return IPPortFamilySerializer{
+		Family: model.AddressFamily(family).String(),
+		IP:     c.IPNet.IP.String(),
+		Port:   c.Port,
+	}
+}
+
+func newConnectEventSerializer(e *Event) *ConnectEventSerializer {
+	return &ConnectEventSerializer{
+		Addr: newIPPortFamilySerializer(&e.Connect.Addr, e.Connect.AddrFamily),
+	}
+}
+
+func newBindEventSerializer(e *Event) *BindEventSerializer {
+	return &BindEventSerializer{
+		Addr: newIPPortFamilySerializer(&e.Bind.Addr, e.Bind.AddrFamily),
+	}
+}
+
+func newDNSEventSerializer(e *Event) *DNSEventSerializer {
+	return &DNSEventSerializer{
+		ID: e.DNS.ID,
+		Question: DNSQuestionSerializer{
+			Class: model.DNSQClass(e.DNS.Class).String(),
+			Type:  model.DNSQType(e.DNS.Type).String(),
+			Name:  e.DNS.Name,
+			Size:  e.DNS.Size,
+			Count: e.DNS.Count,
+		},
+		Server: newIPPortSerializer(&e.DNS.Server),
+	}
+}
+
 func serializeSyscallRetval(retval int64) string {
 	switch {
 	case retval < 0:
@@ -810,6 +893,14 @@ func NewEventSerializer(event *Event) *EventSerializer {
 	case model.SignalEventType:
 		s.EventContextSerializer.Outcome = serializeSyscallRetval(event.Signal.Retval)
 		s.SignalEventSerializer = newSignalEventSerializer(event)
+	case model.ConnectEventType:
+		s.EventContextSerializer.Outcome = serializeSyscallRetval(event.Connect.Retval)
+		s.ConnectEventSerializer = newConnectEventSerializer(event)
+	case model.BindEventType:
+		s.EventContextSerializer.Outcome = serializeSyscallRetval(event.Bind.Retval)
+		s.BindEventSerializer = newBindEventSerializer(event)
+	case model.DNSEventType:
+		s.DNSEventSerializer = newDNSEventSerializer(event)
 	}
 
 	return s
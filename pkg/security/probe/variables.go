@@ -9,7 +9,11 @@
 package probe
 
 import (
+	"strconv"
+
 	"github.com/DataDog/datadog-agent/pkg/security/secl/compiler/eval"
+	"github.com/DataDog/datadog-agent/pkg/security/secl/model"
+	"github.com/DataDog/datadog-agent/pkg/security/secl/rules"
 )
 
 var (
@@ -19,4 +23,34 @@ var (
 			return int((*Event)(ctx.Object).ProcessContext.Process.Pid)
 		}, nil),
 	}
+
+	// SequenceScopes set of sequence scopes
+	SequenceScopes = map[rules.Scope]rules.SequenceScopeFnc{
+		"process": func(ctx *eval.Context) []string {
+			return []string{strconv.Itoa(int((*model.Event)(ctx.Object).ProcessContext.Process.Pid))}
+		},
+		"process_tree": func(ctx *eval.Context) []string {
+			pc := &(*model.Event)(ctx.Object).ProcessContext
+			keys := []string{strconv.Itoa(int(pc.Process.Pid))}
+			for ancestor := pc.Ancestor; ancestor != nil; ancestor = ancestor.Ancestor {
+				keys = append(keys, strconv.Itoa(int(ancestor.Pid)))
+			}
+			return keys
+		},
+		"container": func(ctx *eval.Context) []string {
+			if id := (*model.Event)(ctx.Object).ProcessContext.Process.ContainerID; id != "" {
+				return []string{id}
+			}
+			return nil
+		},
+	}
 )
+
+// NewContainerVariables returns a provider for variables attached to a container. As
+// nothing signals the end of a container, its variables are released once they all
+// expired, so container scoped variables should be set with a ttl.
+func NewContainerVariables() rules.VariableProvider {
+	return eval.NewKeyedScopedVariables(func(ctx *eval.Context) string {
+		return (*model.Event)(ctx.Object).ProcessContext.Process.ContainerID
+	})
+}
@@ -18,6 +18,8 @@ import (
 var (
 	seclLexer = lexer.Must(ebnf.New(`
 Comment = ("#" | "//") { "\u0000"…"\uffff"-"\n" } .
+CIDR = IP "/" digit { digit } .
+IP = ipv4 | ipv6 .
 Variable = "${" (alpha | "_") { "_" | alpha | digit | "." } "}" .
 Duration = digit { digit } ("ms" | "s" | "m" | "h" | "d") .
 Regexp = "r\"" { "\u0000"…"\uffff"-"\""-"\\" | "\\" any } "\"" .
@@ -29,6 +31,9 @@ Punct = "!"…"/" | ":"…"@" | "["…` + "\"`\"" + ` | "{"…"~" .
 Whitespace = ( " " | "\t" | "\n" ) { " " | "\t" | "\n" } .
 alpha = "a"…"z" | "A"…"Z" .
 digit = "0"…"9" .
+hex = "a"…"f" | "A"…"F" | "0"…"9" .
+ipv4 = digit { digit } "." digit { digit } "." digit { digit } "." digit { digit } .
+ipv6 = [ hex { hex } ] ":" [ hex { hex } ] ":" [ hex { hex } ] [ ":" | "." ] [ hex { hex } ] [ ":" | "." ] [ hex { hex } ] [ ":" | "." ] [ hex { hex } ] [ ":" | "." ] [ hex { hex } ] [ ":" | "." ] [ hex { hex } ] .
 any = "\u0000"…"\uffff" .
 `))
 )
@@ -187,6 +192,8 @@ type Primary struct {
 	Pattern       *string     `parser:"| @Pattern"`
 	Regexp        *string     `parser:"| @Regexp"`
 	Duration      *int        `parser:"| @Duration"`
+	IP            *string     `parser:"| @IP"`
+	CIDR          *string     `parser:"| @CIDR"`
 	SubExpression *Expression `parser:"| \"(\" @@ \")\""`
 }
 
@@ -205,6 +212,16 @@ type Array struct {
 
 	StringMembers []StringMember `parser:"\"[\" @@ { \",\" @@ } \"]\""`
 	Numbers       []int          `parser:"| \"[\" @Int { \",\" @Int } \"]\""`
+	CIDRMembers   []CIDRMember   `parser:"| \"[\" @@ { \",\" @@ } \"]\""`
+	CIDR          *string        `parser:"| @CIDR"`
 	Variable      *string        `parser:"| @Variable"`
 	Ident         *string        `parser:"| @Ident"`
 }
+
+// CIDRMember describes an IP or CIDR based array member
+type CIDRMember struct {
+	Pos lexer.Position
+
+	IP   *string `parser:"@IP"`
+	CIDR *string `parser:"| @CIDR"`
+}
@@ -222,3 +222,26 @@ func TestNumberVariable(t *testing.T) {
 
 	print(t, rule)
 }
+
+func TestInArrayCIDR(t *testing.T) {
+	rule, err := ParseRule(`connect.addr.ip in [ 10.0.0.0/8, 192.168.1.1, 2001:db8::/32, ::1 ]`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	print(t, rule)
+
+	members := rule.BooleanExpression.Expression.Comparison.ArrayComparison.Array.CIDRMembers
+	if len(members) != 4 || *members[0].CIDR != "10.0.0.0/8" || *members[1].IP != "192.168.1.1" || *members[2].CIDR != "2001:db8::/32" || *members[3].IP != "::1" {
+		t.Errorf("unexpected CIDR members: %+v", members)
+	}
+}
+
+func TestInCIDR(t *testing.T) {
+	rule, err := ParseRule(`connect.addr.ip in 10.0.0.0/8 && connect.addr.port == 53 && bind.addr.ip != fe80::1`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	print(t, rule)
+}
@@ -65,6 +65,14 @@ func NewArrayTypeError(pos lexer.Position, arrayKind reflect.Kind, kind reflect.
 	return NewError(pos, fmt.Sprintf("%s of %s expected", arrayKind, kind))
 }
 
+// NewCIDRTypeError returns a new ErrAstToEval error when an IP address or a CIDR was expected
+func NewCIDRTypeError(pos lexer.Position, isArray bool) *ErrAstToEval {
+	if isArray {
+		return NewError(pos, "array of IP addresses or CIDRs expected")
+	}
+	return NewError(pos, "IP address or CIDR expected")
+}
+
 // NewOpUnknownError returns a new ErrAstToEval error when an unknown operator was used
 func NewOpUnknownError(pos lexer.Position, op string) *ErrAstToEval {
 	return NewError(pos, fmt.Sprintf("operator `%s` unknown", op))
@@ -158,6 +158,18 @@ func arrayToEvaluator(array *ast.Array, opts *Opts, state *State) (interface{},
 			return nil, array.Pos, NewError(array.Pos, err.Error())
 		}
 		return &evaluator, array.Pos, nil
+	} else if len(array.CIDRMembers) != 0 {
+		var evaluator CIDRValuesEvaluator
+		if err := evaluator.AppendMembers(array.CIDRMembers...); err != nil {
+			return nil, array.Pos, NewError(array.Pos, err.Error())
+		}
+		return &evaluator, array.Pos, nil
+	} else if array.CIDR != nil {
+		var evaluator CIDRValuesEvaluator
+		if err := evaluator.Value.AppendCIDR(*array.CIDR); err != nil {
+			return nil, array.Pos, NewError(array.Pos, err.Error())
+		}
+		return &evaluator, array.Pos, nil
 	} else if array.Ident != nil {
 		if state.macros != nil {
 			if macro, ok := state.macros[*array.Ident]; ok {
@@ -542,6 +554,39 @@ func nodeToEvaluator(obj interface{}, opts *Opts, state *State) (interface{}, le
 				default:
 					return nil, pos, NewArrayTypeError(pos, reflect.Array, reflect.Int)
 				}
+			case *CIDREvaluator:
+				switch nextCIDR := next.(type) {
+				case *CIDRValuesEvaluator:
+					boolEvaluator, err = CIDRValuesContains(unary, nextCIDR, opts, state)
+					if err != nil {
+						return nil, pos, err
+					}
+				case *CIDRArrayEvaluator:
+					boolEvaluator, err = CIDRArrayContains(unary, nextCIDR, opts, state)
+					if err != nil {
+						return nil, pos, err
+					}
+				default:
+					return nil, pos, NewCIDRTypeError(pos, true)
+				}
+				if *obj.ArrayComparison.Op == "notin" {
+					return Not(boolEvaluator, opts, state), obj.Pos, nil
+				}
+				return boolEvaluator, obj.Pos, nil
+			case *CIDRArrayEvaluator:
+				switch nextCIDR := next.(type) {
+				case *CIDRValuesEvaluator:
+					boolEvaluator, err = CIDRArrayMatches(unary, nextCIDR, opts, state)
+					if err != nil {
+						return nil, pos, err
+					}
+					if *obj.ArrayComparison.Op == "notin" {
+						return Not(boolEvaluator, opts, state), obj.Pos, nil
+					}
+					return boolEvaluator, obj.Pos, nil
+				default:
+					return nil, pos, NewCIDRTypeError(pos, true)
+				}
 			default:
 				return nil, pos, NewTypeError(pos, reflect.Array)
 			}
@@ -874,6 +919,44 @@ func nodeToEvaluator(obj interface{}, opts *Opts, state *State) (interface{}, le
 					return boolEvaluator, obj.Pos, nil
 				}
 				return nil, pos, NewOpUnknownError(obj.Pos, *obj.ScalarComparison.Op)
+			case *CIDREvaluator:
+				switch nextCIDR := next.(type) {
+				case *CIDREvaluator:
+					boolEvaluator, err = CIDREquals(unary, nextCIDR, opts, state)
+				case *CIDRArrayEvaluator:
+					boolEvaluator, err = CIDRArrayContains(unary, nextCIDR, opts, state)
+				default:
+					return nil, pos, NewCIDRTypeError(pos, false)
+				}
+				if err != nil {
+					return nil, obj.Pos, err
+				}
+
+				switch *obj.ScalarComparison.Op {
+				case "!=":
+					return Not(boolEvaluator, opts, state), obj.Pos, nil
+				case "==":
+					return boolEvaluator, obj.Pos, nil
+				}
+				return nil, pos, NewOpUnknownError(obj.Pos, *obj.ScalarComparison.Op)
+			case *CIDRArrayEvaluator:
+				nextCIDR, ok := next.(*CIDREvaluator)
+				if !ok {
+					return nil, pos, NewCIDRTypeError(pos, false)
+				}
+
+				boolEvaluator, err = CIDRArrayContains(nextCIDR, unary, opts, state)
+				if err != nil {
+					return nil, obj.Pos, err
+				}
+
+				switch *obj.ScalarComparison.Op {
+				case "!=":
+					return Not(boolEvaluator, opts, state), obj.Pos, nil
+				case "==":
+					return boolEvaluator, obj.Pos, nil
+				}
+				return nil, pos, NewOpUnknownError(obj.Pos, *obj.ScalarComparison.Op)
 			}
 		} else {
 			return unary, pos, nil
@@ -969,6 +1052,21 @@ func nodeToEvaluator(obj interface{}, opts *Opts, state *State) (interface{}, le
 				return nil, obj.Pos, NewError(obj.Pos, err.Error())
 			}
 			return evaluator, obj.Pos, nil
+		case obj.IP != nil, obj.CIDR != nil:
+			value := obj.IP
+			if obj.CIDR != nil {
+				value = obj.CIDR
+			}
+
+			ipnet, err := ParseCIDR(*value)
+			if err != nil {
+				return nil, obj.Pos, NewError(obj.Pos, err.Error())
+			}
+
+			return &CIDREvaluator{
+				Value:     ipnet,
+				ValueType: IPNetValueType,
+			}, obj.Pos, nil
 		case obj.SubExpression != nil:
 			return nodeToEvaluator(obj.SubExpression, opts, state)
 		default:
@@ -8,6 +8,7 @@ package eval
 import (
 	"container/list"
 	"fmt"
+	"net"
 	"os"
 	"runtime"
 	"strings"
@@ -142,6 +143,66 @@ func TestSimpleString(t *testing.T) {
 	}
 }
 
+func TestCIDR(t *testing.T) {
+	mustParseCIDR := func(value string) net.IPNet {
+		ipnet, err := ParseCIDR(value)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return ipnet
+	}
+
+	event := &testEvent{
+		network: testNetwork{
+			ip:  mustParseCIDR("10.1.2.3"),
+			ips: []net.IPNet{mustParseCIDR("192.168.1.1"), mustParseCIDR("2001:db8::1")},
+		},
+	}
+
+	tests := []struct {
+		Expr     string
+		Expected bool
+	}{
+		{Expr: `network.ip == 10.1.2.3`, Expected: true},
+		{Expr: `network.ip == 10.1.2.4`, Expected: false},
+		{Expr: `network.ip != 10.1.2.4`, Expected: true},
+		{Expr: `network.ip == 10.0.0.0/8`, Expected: true},
+		{Expr: `network.ip == 192.168.0.0/16`, Expected: false},
+		{Expr: `network.ip in 10.0.0.0/8`, Expected: true},
+		{Expr: `network.ip not in 10.0.0.0/8`, Expected: false},
+		{Expr: `network.ip in [ 192.168.0.0/16, 10.1.0.0/16 ]`, Expected: true},
+		{Expr: `network.ip in [ 192.168.0.0/16, 10.1.2.4 ]`, Expected: false},
+		{Expr: `network.ip not in [ 192.168.0.0/16, 172.16.0.0/12 ]`, Expected: true},
+		{Expr: `network.ip == ::1`, Expected: false},
+		{Expr: `network.ips in [ 2001:db8::/32 ]`, Expected: true},
+		{Expr: `network.ips in [ 10.0.0.0/8, fe80::/10 ]`, Expected: false},
+		{Expr: `network.ips == 192.168.1.0/24`, Expected: true},
+		{Expr: `network.ips != 192.168.1.0/24`, Expected: false},
+		{Expr: `10.0.0.1 in [ 10.0.0.0/8 ]`, Expected: true},
+	}
+
+	for _, test := range tests {
+		result, _, err := eval(t, event, test.Expr)
+		if err != nil {
+			t.Fatalf("error while evaluating `%s`: %s", test.Expr, err)
+		}
+
+		if result != test.Expected {
+			t.Errorf("expected result `%t` not found, got `%t`\n%s", test.Expected, result, test.Expr)
+		}
+	}
+
+	for _, expr := range []string{
+		`network.ip == "10.0.0.1"`,
+		`network.ip in [ 1, 2 ]`,
+		`network.ip == 10.0.0.0/99`,
+	} {
+		if _, _, err := eval(t, event, expr); err == nil {
+			t.Errorf("expected an error for `%s`", expr)
+		}
+	}
+}
+
 func TestSimpleInt(t *testing.T) {
 	event := &testEvent{
 		process: testProcess{
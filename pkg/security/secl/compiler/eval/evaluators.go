@@ -7,6 +7,7 @@ package eval
 
 import (
 	"fmt"
+	"net"
 
 	"github.com/DataDog/datadog-agent/pkg/security/secl/compiler/ast"
 )
@@ -319,3 +320,114 @@ func (b *BoolArrayEvaluator) GetField() string {
 func (b *BoolArrayEvaluator) IsScalar() bool {
 	return b.EvalFnc == nil
 }
+
+// CIDREvaluator returns a network as result of the evaluation
+type CIDREvaluator struct {
+	EvalFnc     func(ctx *Context) net.IPNet
+	Field       Field
+	Value       net.IPNet
+	Weight      int
+	OpOverrides *OpOverrides
+	ValueType   FieldValueType
+
+	// used during compilation
+	isPartial bool
+}
+
+// Eval returns the result of the evaluation
+func (c *CIDREvaluator) Eval(ctx *Context) interface{} {
+	return c.EvalFnc(ctx)
+}
+
+// IsPartial returns whether the evaluator is partial
+func (c *CIDREvaluator) IsPartial() bool {
+	return c.isPartial
+}
+
+// GetField returns field name used by this evaluator
+func (c *CIDREvaluator) GetField() string {
+	return c.Field
+}
+
+// IsScalar returns whether the evaluator is a scalar
+func (c *CIDREvaluator) IsScalar() bool {
+	return c.EvalFnc == nil
+}
+
+// CIDRArrayEvaluator returns an array of networks
+type CIDRArrayEvaluator struct {
+	EvalFnc     func(ctx *Context) []net.IPNet
+	Field       Field
+	Value       []net.IPNet
+	Weight      int
+	OpOverrides *OpOverrides
+	ValueType   FieldValueType
+
+	// used during compilation
+	isPartial bool
+}
+
+// Eval returns the result of the evaluation
+func (c *CIDRArrayEvaluator) Eval(ctx *Context) interface{} {
+	return c.EvalFnc(ctx)
+}
+
+// IsPartial returns whether the evaluator is partial
+func (c *CIDRArrayEvaluator) IsPartial() bool {
+	return c.isPartial
+}
+
+// GetField returns field name used by this evaluator
+func (c *CIDRArrayEvaluator) GetField() string {
+	return c.Field
+}
+
+// IsScalar returns whether the evaluator is a scalar
+func (c *CIDRArrayEvaluator) IsScalar() bool {
+	return c.EvalFnc == nil
+}
+
+// CIDRValuesEvaluator returns a set of networks
+type CIDRValuesEvaluator struct {
+	EvalFnc func(ctx *Context) *CIDRValues
+	Value   CIDRValues
+	Weight  int
+
+	// used during compilation
+	isPartial bool
+}
+
+// Eval returns the result of the evaluation
+func (c *CIDRValuesEvaluator) Eval(ctx *Context) interface{} {
+	return c.EvalFnc(ctx)
+}
+
+// IsPartial returns whether the evaluator is partial
+func (c *CIDRValuesEvaluator) IsPartial() bool {
+	return c.isPartial
+}
+
+// GetField returns field name used by this evaluator
+func (c *CIDRValuesEvaluator) GetField() string {
+	return ""
+}
+
+// IsScalar returns whether the evaluator is a scalar
+func (c *CIDRValuesEvaluator) IsScalar() bool {
+	return c.EvalFnc == nil
+}
+
+// AppendMembers add members to the evaluator
+func (c *CIDRValuesEvaluator) AppendMembers(members ...ast.CIDRMember) error {
+	for _, member := range members {
+		value := member.IP
+		if member.CIDR != nil {
+			value = member.CIDR
+		}
+		if err := c.Value.AppendCIDR(*value); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -22,6 +22,7 @@ const (
 	RegexpValueType   FieldValueType = 1 << 2
 	BitmaskValueType  FieldValueType = 1 << 3
 	VariableValueType FieldValueType = 1 << 4
+	IPNetValueType    FieldValueType = 1 << 5
 )
 
 // FieldValue describes a field value with its type
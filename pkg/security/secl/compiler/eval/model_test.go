@@ -7,6 +7,7 @@ package eval
 
 import (
 	"container/list"
+	"net"
 	"reflect"
 	"syscall"
 	"unsafe"
@@ -100,6 +101,11 @@ type testMkdir struct {
 	mode     int
 }
 
+type testNetwork struct {
+	ip  net.IPNet
+	ips []net.IPNet
+}
+
 type testEvent struct {
 	id   string
 	kind string
@@ -107,6 +113,7 @@ type testEvent struct {
 	process testProcess
 	open    testOpen
 	mkdir   testMkdir
+	network testNetwork
 
 	listEvaluated bool
 	uidEvaluated  bool
@@ -165,6 +172,20 @@ func (m *testModel) GetIterator(field Field) (Iterator, error) {
 func (m *testModel) GetEvaluator(field Field, regID RegisterID) (Evaluator, error) {
 	switch field {
 
+	case "network.ip":
+
+		return &CIDREvaluator{
+			EvalFnc: func(ctx *Context) net.IPNet { return (*testEvent)(ctx.Object).network.ip },
+			Field:   field,
+		}, nil
+
+	case "network.ips":
+
+		return &CIDRArrayEvaluator{
+			EvalFnc: func(ctx *Context) []net.IPNet { return (*testEvent)(ctx.Object).network.ips },
+			Field:   field,
+		}, nil
+
 	case "process.name":
 
 		return &StringEvaluator{
@@ -441,6 +462,14 @@ func (m *testModel) GetEvaluator(field Field, regID RegisterID) (Evaluator, erro
 func (e *testEvent) GetFieldValue(field Field) (interface{}, error) {
 	switch field {
 
+	case "network.ip":
+
+		return e.network.ip, nil
+
+	case "network.ips":
+
+		return e.network.ips, nil
+
 	case "process.name":
 
 		return e.process.name, nil
@@ -493,6 +522,14 @@ func (e *testEvent) GetFieldValue(field Field) (interface{}, error) {
 func (e *testEvent) GetFieldEventType(field Field) (string, error) {
 	switch field {
 
+	case "network.ip":
+
+		return "network", nil
+
+	case "network.ips":
+
+		return "network", nil
+
 	case "process.name":
 
 		return "*", nil
@@ -577,6 +614,16 @@ func (e *testEvent) GetFieldEventType(field Field) (string, error) {
 func (e *testEvent) SetFieldValue(field Field, value interface{}) error {
 	switch field {
 
+	case "network.ip":
+
+		e.network.ip = value.(net.IPNet)
+		return nil
+
+	case "network.ips":
+
+		e.network.ips = append(e.network.ips, value.(net.IPNet))
+		return nil
+
 	case "process.name":
 
 		e.process.name = value.(string)
@@ -640,6 +687,14 @@ func (e *testEvent) SetFieldValue(field Field, value interface{}) error {
 func (e *testEvent) GetFieldType(field Field) (reflect.Kind, error) {
 	switch field {
 
+	case "network.ip":
+
+		return reflect.Struct, nil
+
+	case "network.ips":
+
+		return reflect.Struct, nil
+
 	case "process.name":
 
 		return reflect.String, nil
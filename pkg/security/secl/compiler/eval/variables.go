@@ -9,6 +9,7 @@ import (
 	"fmt"
 	"reflect"
 	"regexp"
+	"time"
 	"unsafe"
 
 	"github.com/pkg/errors"
@@ -389,11 +390,48 @@ func NewMutableIntArrayVariable() *MutableIntArrayVariable {
 // Scoper maps a variable to the entity its scoped to
 type Scoper func(ctx *Context) unsafe.Pointer
 
+// KeyScoper maps a variable to the key of the entity its scoped to. An empty key means
+// that the event isn't related to any entity of the scope.
+type KeyScoper func(ctx *Context) string
+
+// VariableOpts holds the options of a variable
+type VariableOpts struct {
+	// TTL is the duration after which a value expires, counted from the time it was set.
+	// For arrays, each value expires on its own. Zero means that the values never expire.
+	TTL time.Duration
+	// Size is the maximum number of values of an array, the oldest values being evicted
+	// first. Arrays with a size behave as sets. Zero means that the array is unbounded.
+	Size int
+}
+
+// IsZero returns whether the options have their default values
+func (o VariableOpts) IsZero() bool {
+	return o.TTL == 0 && o.Size == 0
+}
+
+// now returns the current time, overridden by the tests
+var now = time.Now
+
+// globalScope is the key of the unique scope of the global variables having options
+type globalScope struct{}
+
 // GlobalVariables holds a set of global variables
-type GlobalVariables struct{}
+type GlobalVariables struct {
+	bounded *ScopedVariables
+}
 
 // GetVariable returns new variable of the type of the specified value
-func (v *GlobalVariables) GetVariable(name string, value interface{}) (VariableValue, error) {
+func (v *GlobalVariables) GetVariable(name string, value interface{}, opts VariableOpts) (VariableValue, error) {
+	if !opts.IsZero() {
+		// expirations and evictions are handled by a single scope holding all the variables
+		if v.bounded == nil {
+			v.bounded = newScopedVariables(func(ctx *Context) interface{} {
+				return globalScope{}
+			}, nil)
+		}
+		return v.bounded.GetVariable(name, value, opts)
+	}
+
 	switch value := value.(type) {
 	case bool:
 		return NewMutableBoolVariable(), nil
@@ -410,53 +448,105 @@ func (v *GlobalVariables) GetVariable(name string, value interface{}) (VariableV
 	}
 }
 
+// variableEntries holds the expiration and eviction state of a variable having options
+type variableEntries struct {
+	opts VariableOpts
+	// expiration is the expiration time of a scalar value
+	expiration time.Time
+	// added holds the time each value of an array was added at, in the order of the values
+	added []time.Time
+}
+
 // Variables holds a set of variables
 type Variables struct {
-	vars map[string]interface{}
+	vars    map[string]interface{}
+	entries map[string]*variableEntries
+}
+
+// get returns the value of the specified variable, dropping the expired values
+func (v *Variables) get(name string) (interface{}, bool) {
+	value, found := v.vars[name]
+	if !found {
+		return nil, false
+	}
+
+	entries := v.entries[name]
+	if entries == nil || entries.opts.TTL == 0 {
+		return value, true
+	}
+
+	current := now()
+	if entries.added == nil {
+		if !current.Before(entries.expiration) {
+			delete(v.vars, name)
+			delete(v.entries, name)
+			return nil, false
+		}
+		return value, true
+	}
+
+	// values are ordered by the time they were added at, the expired ones come first
+	expired := 0
+	for expired < len(entries.added) && !current.Before(entries.added[expired].Add(entries.opts.TTL)) {
+		expired++
+	}
+	if expired > 0 {
+		value = reflect.ValueOf(value).Slice(expired, len(entries.added)).Interface()
+		entries.added = entries.added[expired:]
+		v.vars[name] = value
+	}
+	return value, true
 }
 
 // GetBool returns the boolean value of the specified variable
 func (v *Variables) GetBool(name string) bool {
-	if _, found := v.vars[name]; !found {
-		return false
+	if value, found := v.get(name); found {
+		return value.(bool)
 	}
-	return v.vars[name].(bool)
+	return false
 }
 
 // GetInt returns the integer value of the specified variable
 func (v *Variables) GetInt(name string) int {
-	if _, found := v.vars[name]; !found {
-		return 0
+	if value, found := v.get(name); found {
+		return value.(int)
 	}
-	return v.vars[name].(int)
+	return 0
 }
 
 // GetString returns the string value of the specified variable
 func (v *Variables) GetString(name string) string {
-	if _, found := v.vars[name]; !found {
-		return ""
+	if value, found := v.get(name); found {
+		return value.(string)
 	}
-	return v.vars[name].(string)
+	return ""
 }
 
 // GetStringArray returns the string array value of the specified variable
 func (v *Variables) GetStringArray(name string) []string {
-	if _, found := v.vars[name]; !found {
-		return nil
+	if value, found := v.get(name); found {
+		return value.([]string)
 	}
-	return v.vars[name].([]string)
+	return nil
 }
 
 // GetIntArray returns the integer array value of the specified variable
 func (v *Variables) GetIntArray(name string) []int {
-	if _, found := v.vars[name]; !found {
-		return nil
+	if value, found := v.get(name); found {
+		return value.([]int)
 	}
-	return v.vars[name].([]int)
+	return nil
 }
 
 // Set the value of the specified variable
 func (v *Variables) Set(name string, value interface{}) bool {
+	return v.SetWithOpts(name, value, VariableOpts{})
+}
+
+// SetWithOpts sets the value of the specified variable, applying the expiration and
+// eviction options. Arrays are expected to be either a new value or the current value
+// with new values appended, in which case the current values keep their age.
+func (v *Variables) SetWithOpts(name string, value interface{}, opts VariableOpts) bool {
 	existed := false
 	if v.vars == nil {
 		v.vars = make(map[string]interface{})
@@ -464,34 +554,137 @@ func (v *Variables) Set(name string, value interface{}) bool {
 		_, existed = v.vars[name]
 	}
 
+	if opts.IsZero() {
+		delete(v.entries, name)
+		v.vars[name] = value
+		return !existed
+	}
+
+	if v.entries == nil {
+		v.entries = make(map[string]*variableEntries)
+	}
+
+	current := now()
+	entries := &variableEntries{opts: opts}
+
+	switch value.(type) {
+	case []string, []int:
+		value, entries.added = boundValues(v.vars[name], v.entries[name], value, opts, current)
+	default:
+		entries.expiration = current.Add(opts.TTL)
+	}
+
 	v.vars[name] = value
+	v.entries[name] = entries
 	return !existed
 }
 
+// expired returns whether all the variables have expired
+func (v *Variables) expired() bool {
+	for name := range v.vars {
+		if entries := v.entries[name]; entries == nil || entries.opts.TTL == 0 {
+			return false
+		}
+		if value, found := v.get(name); found && (v.entries[name].added == nil || reflect.ValueOf(value).Len() > 0) {
+			return false
+		}
+	}
+	return true
+}
+
+// boundValues deduplicates the values of an array and evicts the oldest ones above the
+// size limit. It returns the resulting values with the time each of them was added at.
+func boundValues(previous interface{}, previousEntries *variableEntries, value interface{}, opts VariableOpts, current time.Time) (interface{}, []time.Time) {
+	values := reflect.ValueOf(value)
+
+	added := make([]time.Time, values.Len())
+	for i := range added {
+		added[i] = current
+	}
+
+	// values already present keep their age when new values are appended
+	if previous != nil && previousEntries != nil && previousEntries.added != nil {
+		old := reflect.ValueOf(previous)
+		if old.Type() == values.Type() && old.Len() <= values.Len() && old.Len() == len(previousEntries.added) &&
+			reflect.DeepEqual(old.Interface(), values.Slice(0, old.Len()).Interface()) {
+			copy(added, previousEntries.added)
+		}
+	}
+
+	// only the last occurrence of a value is kept, a value added again is refreshed
+	seen := make(map[interface{}]bool, values.Len())
+	keep := make([]bool, values.Len())
+	kept := 0
+	for i := values.Len() - 1; i >= 0; i-- {
+		item := values.Index(i).Interface()
+		if !seen[item] {
+			seen[item] = true
+			keep[i] = true
+			kept++
+		}
+	}
+
+	// the oldest values come first and are the first to be evicted
+	evict := 0
+	if opts.Size > 0 && kept > opts.Size {
+		evict = kept - opts.Size
+	}
+
+	result := reflect.MakeSlice(values.Type(), 0, kept-evict)
+	resultAdded := make([]time.Time, 0, kept-evict)
+	for i := 0; i < values.Len(); i++ {
+		if !keep[i] {
+			continue
+		}
+		if evict > 0 {
+			evict--
+			continue
+		}
+		result = reflect.Append(result, values.Index(i))
+		resultAdded = append(resultAdded, added[i])
+	}
+
+	return result.Interface(), resultAdded
+}
+
+// scopesPurgeInterval is the minimum interval between two purges of the expired scopes
+const scopesPurgeInterval = time.Minute
+
 // ScopedVariables holds a set of scoped variables
 type ScopedVariables struct {
-	scoper         Scoper
-	onNewVariables func(_ unsafe.Pointer)
-	vars           map[unsafe.Pointer]*Variables
+	scoper         func(ctx *Context) interface{}
+	onNewVariables func(key interface{})
+	vars           map[interface{}]*Variables
+	lastPurge      time.Time
 }
 
 // GetVariable returns new variable of the type of the specified value
-func (v *ScopedVariables) GetVariable(name string, value interface{}) (VariableValue, error) {
+func (v *ScopedVariables) GetVariable(name string, value interface{}, opts VariableOpts) (VariableValue, error) {
 	getVariables := func(ctx *Context) *Variables {
-		return v.vars[v.scoper(ctx)]
+		if key := v.scoper(ctx); key != nil {
+			return v.vars[key]
+		}
+		return nil
 	}
 
 	setVariable := func(ctx *Context, value interface{}) error {
 		key := v.scoper(ctx)
+		if key == nil {
+			// the event isn't related to any entity of the scope
+			return nil
+		}
+
 		vars := v.vars[key]
 		if vars == nil {
+			v.purgeExpired()
+
 			vars = &Variables{}
 			v.vars[key] = vars
 			if v.onNewVariables != nil {
 				v.onNewVariables(key)
 			}
 		}
-		vars.Set(name, value)
+		vars.SetWithOpts(name, value, opts)
 		return nil
 	}
 
@@ -537,16 +730,69 @@ func (v *ScopedVariables) GetVariable(name string, value interface{}) (VariableV
 	}
 }
 
+// purgeExpired releases the scopes whose variables all expired. Scopes without release
+// callback, such as the ones keyed by a string, would otherwise never be released.
+func (v *ScopedVariables) purgeExpired() {
+	current := now()
+	if current.Sub(v.lastPurge) < scopesPurgeInterval {
+		return
+	}
+	v.lastPurge = current
+
+	for key, vars := range v.vars {
+		if vars.expired() {
+			delete(v.vars, key)
+		}
+	}
+}
+
 // ReleaseVariable releases a scoped variable
 func (v *ScopedVariables) ReleaseVariable(key unsafe.Pointer) {
 	delete(v.vars, key)
 }
 
-// NewScopedVariables returns a new set of scope variables
-func NewScopedVariables(scoper Scoper, onNewVariables func(unsafe.Pointer)) *ScopedVariables {
+// ReleaseScope releases the variables of the scope identified by key
+func (v *ScopedVariables) ReleaseScope(key string) {
+	delete(v.vars, key)
+}
+
+// ScopesCount returns the number of scopes holding variables
+func (v *ScopedVariables) ScopesCount() int {
+	return len(v.vars)
+}
+
+func newScopedVariables(scoper func(ctx *Context) interface{}, onNewVariables func(key interface{})) *ScopedVariables {
 	return &ScopedVariables{
 		scoper:         scoper,
 		onNewVariables: onNewVariables,
-		vars:           make(map[unsafe.Pointer]*Variables),
+		vars:           make(map[interface{}]*Variables),
+	}
+}
+
+// NewScopedVariables returns a new set of scope variables
+func NewScopedVariables(scoper Scoper, onNewVariables func(unsafe.Pointer)) *ScopedVariables {
+	var onNew func(key interface{})
+	if onNewVariables != nil {
+		onNew = func(key interface{}) {
+			onNewVariables(key.(unsafe.Pointer))
+		}
 	}
+
+	return newScopedVariables(func(ctx *Context) interface{} {
+		if key := scoper(ctx); key != nil {
+			return key
+		}
+		return nil
+	}, onNew)
+}
+
+// NewKeyedScopedVariables returns a new set of variables scoped by the key returned by
+// scoper, such as a container ID. Events without key can't set these variables.
+func NewKeyedScopedVariables(scoper KeyScoper) *ScopedVariables {
+	return newScopedVariables(func(ctx *Context) interface{} {
+		if key := scoper(ctx); key != "" {
+			return key
+		}
+		return nil
+	}, nil)
 }
@@ -0,0 +1,179 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package eval
+
+import (
+	"reflect"
+	"testing"
+	"time"
+	"unsafe"
+)
+
+// setNow sets the clock used by the variables and returns a function moving it forward
+func setNow(t *testing.T) func(d time.Duration) {
+	current := time.Now()
+	now = func() time.Time { return current }
+	t.Cleanup(func() { now = time.Now })
+
+	return func(d time.Duration) {
+		current = current.Add(d)
+	}
+}
+
+func TestVariablesTTL(t *testing.T) {
+	forward := setNow(t)
+
+	var vars Variables
+	vars.SetWithOpts("str", "value", VariableOpts{TTL: time.Minute})
+	vars.Set("int", 123)
+
+	forward(30 * time.Second)
+	if value := vars.GetString("str"); value != "value" {
+		t.Errorf("expected `value`, got `%s`", value)
+	}
+
+	forward(30 * time.Second)
+	if value := vars.GetString("str"); value != "" {
+		t.Errorf("expected an expired value, got `%s`", value)
+	}
+	if value := vars.GetInt("int"); value != 123 {
+		t.Errorf("expected 123, got %d", value)
+	}
+}
+
+func TestVariablesArrayTTL(t *testing.T) {
+	forward := setNow(t)
+
+	opts := VariableOpts{TTL: time.Minute}
+
+	var vars Variables
+	vars.SetWithOpts("array", []string{"a"}, opts)
+
+	forward(30 * time.Second)
+	vars.SetWithOpts("array", append(vars.GetStringArray("array"), "b"), opts)
+
+	forward(30 * time.Second)
+	if values := vars.GetStringArray("array"); !reflect.DeepEqual(values, []string{"b"}) {
+		t.Errorf("expected [b], got %v", values)
+	}
+
+	// adding a value again refreshes it
+	vars.SetWithOpts("array", append(vars.GetStringArray("array"), "c", "b"), opts)
+
+	forward(59 * time.Second)
+	if values := vars.GetStringArray("array"); !reflect.DeepEqual(values, []string{"c", "b"}) {
+		t.Errorf("expected [c b], got %v", values)
+	}
+
+	forward(time.Second)
+	if values := vars.GetStringArray("array"); len(values) != 0 {
+		t.Errorf("expected an empty array, got %v", values)
+	}
+	if !vars.expired() {
+		t.Error("expected the variables to be expired")
+	}
+}
+
+func TestVariablesArraySize(t *testing.T) {
+	setNow(t)
+
+	opts := VariableOpts{Size: 3}
+
+	var vars Variables
+	vars.SetWithOpts("array", []int{1, 2, 3}, opts)
+	vars.SetWithOpts("array", append(vars.GetIntArray("array"), 1, 4), opts)
+
+	if values := vars.GetIntArray("array"); !reflect.DeepEqual(values, []int{3, 1, 4}) {
+		t.Errorf("expected [3 1 4], got %v", values)
+	}
+}
+
+func TestGlobalVariablesOpts(t *testing.T) {
+	forward := setNow(t)
+
+	var globals GlobalVariables
+
+	variable, err := globals.GetVariable("var", []string{}, VariableOpts{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := variable.(*MutableStringArrayVariable); !ok {
+		t.Errorf("expected a mutable string array variable, got %s", reflect.TypeOf(variable))
+	}
+
+	variable, err = globals.GetVariable("bounded", []string{}, VariableOpts{TTL: time.Minute, Size: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := NewContext(unsafe.Pointer(&testEvent{}))
+	mutable := variable.(MutableVariable)
+	for _, value := range []string{"a", "b", "c"} {
+		if err := mutable.Append(ctx, []string{value}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	evaluator := variable.GetEvaluator().(*StringArrayEvaluator)
+	if values := evaluator.EvalFnc(ctx); !reflect.DeepEqual(values, []string{"b", "c"}) {
+		t.Errorf("expected [b c], got %v", values)
+	}
+
+	forward(time.Minute)
+	if values := evaluator.EvalFnc(ctx); len(values) != 0 {
+		t.Errorf("expected an empty array, got %v", values)
+	}
+}
+
+func TestKeyedScopedVariables(t *testing.T) {
+	forward := setNow(t)
+
+	scoped := NewKeyedScopedVariables(func(ctx *Context) string {
+		return (*testEvent)(ctx.Object).process.name
+	})
+
+	variable, err := scoped.GetVariable("var", 0, VariableOpts{TTL: time.Minute})
+	if err != nil {
+		t.Fatal(err)
+	}
+	mutable := variable.(MutableVariable)
+	evaluator := variable.GetEvaluator().(*IntEvaluator)
+
+	ctx1 := NewContext(unsafe.Pointer(&testEvent{process: testProcess{name: "abc"}}))
+	ctx2 := NewContext(unsafe.Pointer(&testEvent{process: testProcess{name: "def"}}))
+	noScope := NewContext(unsafe.Pointer(&testEvent{}))
+
+	if err := mutable.Set(ctx1, 1); err != nil {
+		t.Fatal(err)
+	}
+	if err := mutable.Set(noScope, 2); err != nil {
+		t.Fatal(err)
+	}
+
+	if value := evaluator.EvalFnc(ctx1); value != 1 {
+		t.Errorf("expected 1, got %d", value)
+	}
+	if value := evaluator.EvalFnc(ctx2); value != 0 {
+		t.Errorf("expected 0, got %d", value)
+	}
+	if value := evaluator.EvalFnc(noScope); value != 0 {
+		t.Errorf("expected 0, got %d", value)
+	}
+
+	// the expired scopes are purged when a new scope is created
+	forward(scopesPurgeInterval)
+	if err := mutable.Set(ctx2, 3); err != nil {
+		t.Fatal(err)
+	}
+	if count := scoped.ScopesCount(); count != 1 {
+		t.Errorf("expected 1 scope, got %d", count)
+	}
+
+	scoped.ReleaseScope("def")
+	if count := scoped.ScopesCount(); count != 0 {
+		t.Errorf("expected no scope, got %d", count)
+	}
+}
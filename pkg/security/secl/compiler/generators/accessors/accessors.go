@@ -85,7 +85,7 @@ func handleField(astFile *ast.File, name, alias, prefix, aliasPrefix, pkgName st
 	fmt.Printf("handleField fieldName %s, alias %s, prefix %s, aliasPrefix %s, pkgName %s, fieldType, %s\n", name, alias, prefix, aliasPrefix, pkgName, fieldType)
 
 	switch fieldType.Name {
-	case "string", "bool", "int", "int8", "int16", "int32", "int64", "uint8", "uint16", "uint32", "uint64":
+	case "string", "bool", "int", "int8", "int16", "int32", "int64", "uint8", "uint16", "uint32", "uint64", "net.IPNet":
 		if prefix != "" {
 			name = prefix + "." + name
 			alias = aliasPrefix + "." + alias
@@ -116,9 +116,25 @@ func handleField(astFile *ast.File, name, alias, prefix, aliasPrefix, pkgName st
 	return nil
 }
 
+// selectorIdent returns the qualified identifier of the types of other packages supported
+// by SECL, such as net.IPNet
+func selectorIdent(expr ast.Expr) *ast.Ident {
+	if selector, ok := expr.(*ast.SelectorExpr); ok {
+		if pkg, ok := selector.X.(*ast.Ident); ok {
+			switch name := pkg.Name + "." + selector.Sel.Name; name {
+			case "net.IPNet":
+				return &ast.Ident{NamePos: selector.Pos(), Name: name}
+			}
+		}
+	}
+	return nil
+}
+
 func getFieldIdent(field *ast.Field) (ident *ast.Ident, isPointer, isArray bool) {
 	if fieldType, ok := field.Type.(*ast.Ident); ok {
 		return fieldType, false, false
+	} else if ident := selectorIdent(field.Type); ident != nil {
+		return ident, false, false
 	} else if fieldType, ok := field.Type.(*ast.StarExpr); ok {
 		if ident, ok := fieldType.X.(*ast.Ident); ok {
 			return ident, true, false
@@ -127,6 +143,9 @@ func getFieldIdent(field *ast.Field) (ident *ast.Ident, isPointer, isArray bool)
 		if ident, ok := ft.Elt.(*ast.Ident); ok {
 			return ident, false, true
 		}
+		if ident := selectorIdent(ft.Elt); ident != nil {
+			return ident, false, true
+		}
 	}
 	return nil, false, false
 }
@@ -414,6 +433,7 @@ func main() {
 package {{.Name}}
 
 import (
+	"net"
 	"reflect"
 	"unsafe"
 
@@ -424,6 +444,7 @@ import (
 // suppress unused package warning
 var (
 	_ *unsafe.Pointer
+	_ *net.IPNet
 )
 
 func (m *Model) GetIterator(field eval.Field) (eval.Iterator, error) {
@@ -465,6 +486,11 @@ func (m *Model) GetEvaluator(field eval.Field, regID eval.RegisterID) (eval.Eval
 		{{if or $Field.Iterator $Field.IsArray}}
 			{{$EvaluatorType = "eval.BoolArrayEvaluator"}}
 		{{end}}
+	{{else if eq $Field.ReturnType "net.IPNet"}}
+		{{$EvaluatorType = "eval.CIDREvaluator"}}
+		{{if or $Field.Iterator $Field.IsArray}}
+			{{$EvaluatorType = "eval.CIDRArrayEvaluator"}}
+		{{end}}
 	{{end}}
 
 	case "{{$Name}}":
@@ -671,6 +697,8 @@ func (e *Event) GetFieldValue(field eval.Field) (interface{}, error) {
 				{{end -}}
 			{{else if eq $Field.ReturnType "bool"}}
 				return {{$Return}}, nil
+			{{else if eq $Field.ReturnType "net.IPNet"}}
+				return {{$Return}}, nil
 			{{end}}
 		{{end}}
 		{{end}}
@@ -701,6 +729,8 @@ func (e *Event) GetFieldType(field eval.Field) (reflect.Kind, error) {
 			return reflect.Int, nil
 		{{else if eq $Field.ReturnType "bool"}}
 			return reflect.Bool, nil
+		{{else if eq $Field.ReturnType "net.IPNet"}}
+			return reflect.Struct, nil
 		{{end}}
 		{{end}}
 		}
@@ -748,6 +778,17 @@ func (e *Event) SetFieldValue(field eval.Field, value interface{}) error {
 				return &eval.ErrValueTypeMismatch{Field: "{{$Field.Name}}"}
 			}
 			return nil
+		{{else if eq $Field.BasicType "net.IPNet"}}
+			v, ok := value.(net.IPNet)
+			if !ok {
+				return &eval.ErrValueTypeMismatch{Field: "{{$Field.Name}}"}
+			}
+			{{- if $Field.IsArray}}
+				{{$FieldName}} = append({{$FieldName}}, v)
+			{{else}}
+				{{$FieldName}} = v
+			{{end}}
+			return nil
 		{{end}}
 		{{end}}
 		}
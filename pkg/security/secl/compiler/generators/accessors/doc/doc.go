@@ -54,9 +54,14 @@ func GenerateDocJSON(module *common.Module, outputPath string) error {
 	kinds := make(map[string][]eventTypeProperty)
 
 	for name, field := range module.Fields {
+		fieldType := field.ReturnType
+		if fieldType == "net.IPNet" {
+			fieldType = "IP/CIDR"
+		}
+
 		kinds[field.Event] = append(kinds[field.Event], eventTypeProperty{
 			Name: name,
-			Type: field.ReturnType,
+			Type: fieldType,
 			Doc:  strings.TrimSpace(field.CommentText),
 		})
 	}
@@ -18,6 +18,8 @@ const (
 	ProcessCategory EventCategory = "Process Activity"
 	// KernelCategory Kernel events
 	KernelCategory EventCategory = "Kernel Activity"
+	// NetworkCategory network events
+	NetworkCategory EventCategory = "Network Activity"
 )
 
 // GetAllCategories returns all categories
@@ -26,6 +28,7 @@ func GetAllCategories() []EventCategory {
 		FIMCategory,
 		ProcessCategory,
 		KernelCategory,
+		NetworkCategory,
 	}
 }
 
@@ -36,6 +39,8 @@ func GetEventTypeCategory(eventType eval.EventType) EventCategory {
 		return ProcessCategory
 	case "bpf", "selinux", "mmap", "mprotect", "ptrace", "load_module", "unload_module":
 		return KernelCategory
+	case "connect", "bind", "dns":
+		return NetworkCategory
 	}
 
 	return FIMCategory
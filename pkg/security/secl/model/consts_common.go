@@ -69,6 +69,40 @@ var (
 		"VM_MERGEABLE":    0x80000000, /* KSM may merge identical pages */
 	}
 
+	// addressFamilyConstants is the list of supported address families
+	addressFamilyConstants = map[string]uint16{
+		"AF_INET":  2,
+		"AF_INET6": 10,
+	}
+
+	// DNSQTypeConstants see https://www.iana.org/assignments/dns-parameters/dns-parameters.xhtml
+	DNSQTypeConstants = map[string]DNSQType{
+		"A":     1,
+		"NS":    2,
+		"CNAME": 5,
+		"SOA":   6,
+		"PTR":   12,
+		"MX":    15,
+		"TXT":   16,
+		"AAAA":  28,
+		"SRV":   33,
+		"NAPTR": 35,
+		"DS":    43,
+		"SVCB":  64,
+		"HTTPS": 65,
+		"ANY":   255,
+	}
+
+	// DNSQClassConstants see https://www.iana.org/assignments/dns-parameters/dns-parameters.xhtml
+	DNSQClassConstants = map[string]DNSQClass{
+		"CLASS_INET":   1,
+		"CLASS_CSNET":  2,
+		"CLASS_CHAOS":  3,
+		"CLASS_HESIOD": 4,
+		"CLASS_NONE":   254,
+		"CLASS_ANY":    255,
+	}
+
 	// BPFCmdConstants is the list of BPF commands
 	BPFCmdConstants = map[string]BPFCmd{
 		"BPF_MAP_CREATE":                  BpfMapCreateCmd,
@@ -415,6 +449,9 @@ var (
 	protStrings               = map[int]string{}
 	mmapFlagStrings           = map[int]string{}
 	signalStrings             = map[int]string{}
+	addressFamilyStrings      = map[uint16]string{}
+	dnsQTypeStrings           = map[uint32]string{}
+	dnsQClassStrings          = map[uint32]string{}
 )
 
 // File flags
@@ -555,6 +592,25 @@ func initSignalConstants() {
 	}
 }
 
+func initAddressFamilyConstants() {
+	for k, v := range addressFamilyConstants {
+		SECLConstants[k] = &eval.IntEvaluator{Value: int(v)}
+		addressFamilyStrings[v] = k
+	}
+}
+
+func initDNSConstants() {
+	for k, v := range DNSQTypeConstants {
+		SECLConstants[k] = &eval.IntEvaluator{Value: int(v)}
+		dnsQTypeStrings[uint32(v)] = k
+	}
+
+	for k, v := range DNSQClassConstants {
+		SECLConstants[k] = &eval.IntEvaluator{Value: int(v)}
+		dnsQClassStrings[uint32(v)] = k
+	}
+}
+
 func initConstants() {
 	initErrorConstants()
 	initOpenConstants()
@@ -571,6 +627,8 @@ func initConstants() {
 	initProtConstansts()
 	initMMapFlagsConstants()
 	initSignalConstants()
+	initAddressFamilyConstants()
+	initDNSConstants()
 }
 
 func bitmaskToStringArray(bitmask int, intToStrMap map[int]string) []string {
@@ -1393,3 +1451,33 @@ type Signal int
 func (sig Signal) String() string {
 	return signalStrings[int(sig)]
 }
+
+// AddressFamily represents a family address (AF_INET, AF_INET6, etc)
+type AddressFamily uint16
+
+func (af AddressFamily) String() string {
+	if str, ok := addressFamilyStrings[uint16(af)]; ok {
+		return str
+	}
+	return fmt.Sprintf("%d", af)
+}
+
+// DNSQType represents a DNS question type
+type DNSQType uint16
+
+func (t DNSQType) String() string {
+	if str, ok := dnsQTypeStrings[uint32(t)]; ok {
+		return str
+	}
+	return fmt.Sprintf("%d", t)
+}
+
+// DNSQClass represents a DNS question class
+type DNSQClass uint16
+
+func (c DNSQClass) String() string {
+	if str, ok := dnsQClassStrings[uint32(c)]; ok {
+		return str
+	}
+	return fmt.Sprintf("%d", c)
+}
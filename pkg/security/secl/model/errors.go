@@ -18,4 +18,10 @@ var (
 
 	// ErrNonPrintable returned when a string contains non printable char
 	ErrNonPrintable = errors.New("non printable")
+
+	// ErrDNSNamePointerNotSupported returned when a DNS name uses message compression
+	ErrDNSNamePointerNotSupported = errors.New("dns name pointer compression is not supported")
+
+	// ErrDNSNameOutOfBounds returned when a DNS name exceeds the captured payload
+	ErrDNSNameOutOfBounds = errors.New("dns name out of bound")
 )
@@ -73,6 +73,12 @@ const (
 	UnloadModuleEventType
 	// SignalEventType Signal event
 	SignalEventType
+	// ConnectEventType Connect event
+	ConnectEventType
+	// BindEventType Bind event
+	BindEventType
+	// DNSEventType DNS event
+	DNSEventType
 	// MaxEventType is used internally to get the maximum number of kernel events.
 	MaxEventType
 
@@ -94,6 +100,8 @@ const (
 	CustomForkBombEventType
 	// CustomTruncatedParentsEventType is the custom event used to report that the parents of a path were truncated
 	CustomTruncatedParentsEventType
+	// CustomSequenceEventType is the custom event used to report that a sequence matched
+	CustomSequenceEventType
 )
 
 func (t EventType) String() string {
@@ -158,6 +166,12 @@ func (t EventType) String() string {
 		return "unload_module"
 	case SignalEventType:
 		return "signal"
+	case ConnectEventType:
+		return "connect"
+	case BindEventType:
+		return "bind"
+	case DNSEventType:
+		return "dns"
 
 	case CustomLostReadEventType:
 		return "lost_events_read"
@@ -171,6 +185,8 @@ func (t EventType) String() string {
 		return "fork_bomb"
 	case CustomTruncatedParentsEventType:
 		return "truncated_parents"
+	case CustomSequenceEventType:
+		return "sequence"
 	default:
 		return "unknown"
 	}
@@ -11,6 +11,7 @@ package model
 
 import (
 	"fmt"
+	"net"
 	"path"
 	"path/filepath"
 	"regexp"
@@ -157,6 +158,10 @@ type Event struct {
 	LoadModule   LoadModuleEvent   `field:"load_module" event:"load_module"`     // [7.35] [Kernel] A new kernel module was loaded
 	UnloadModule UnloadModuleEvent `field:"unload_module" event:"unload_module"` // [7.35] [Kernel] A kernel module was deleted
 
+	Connect ConnectEvent `field:"connect" event:"connect"` // [7.36] [Network] A connection was initiated
+	Bind    BindEvent    `field:"bind" event:"bind"`       // [7.36] [Network] A socket was bound to an address
+	DNS     DNSEvent     `field:"dns" event:"dns"`         // [7.36] [Network] A DNS request was sent
+
 	Mount            MountEvent            `field:"-"`
 	Umount           UmountEvent           `field:"-"`
 	InvalidateDentry InvalidateDentryEvent `field:"-"`
@@ -676,6 +681,39 @@ type UnloadModuleEvent struct {
 	Name string `field:"name"` // Name of the kernel module that was deleted
 }
 
+// IPPortContext is used to hold an IP and a port
+type IPPortContext struct {
+	IPNet net.IPNet `field:"ip"`   // IP address
+	Port  uint16    `field:"port"` // Port number
+}
+
+// ConnectEvent represents a connect event
+type ConnectEvent struct {
+	SyscallEvent
+
+	Addr       IPPortContext `field:"addr"`        // Address the connection was initiated to
+	AddrFamily uint16        `field:"addr.family"` // Address family
+}
+
+// BindEvent represents a bind event
+type BindEvent struct {
+	SyscallEvent
+
+	Addr       IPPortContext `field:"addr"`        // Address the socket was bound to
+	AddrFamily uint16        `field:"addr.family"` // Address family
+}
+
+// DNSEvent represents a DNS request event
+type DNSEvent struct {
+	ID     uint16        `field:"id"`              // DNS request ID
+	Name   string        `field:"question.name"`   // The queried domain name
+	Type   uint16        `field:"question.type"`   // A two octet code which specifies the DNS question type
+	Class  uint16        `field:"question.class"`  // The class looked up by the DNS question
+	Size   uint16        `field:"question.length"` // The total DNS request size in bytes
+	Count  uint16        `field:"question.count"`  // Indicates the total number of questions in the DNS request
+	Server IPPortContext `field:"server"`          // DNS server the request was sent to
+}
+
 // SignalEvent represents a signal event
 type SignalEvent struct {
 	SyscallEvent
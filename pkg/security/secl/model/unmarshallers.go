@@ -6,10 +6,14 @@
 package model
 
 import (
+	"encoding/binary"
 	"fmt"
+	"net"
 	"strings"
 	"time"
 	"unsafe"
+
+	"github.com/DataDog/datadog-agent/pkg/security/secl/compiler/eval"
 )
 
 // BinaryUnmarshaler interface implemented by every event type
@@ -718,3 +722,145 @@ func (e *SignalEvent) UnmarshalBinary(data []byte) (int, error) {
 	e.Type = ByteOrder.Uint32(data[read+4 : read+8])
 	return read + 8, nil
 }
+
+// UnmarshalBinary unmarshalls a binary representation of itself, the family is read right after the address
+func (e *IPPortContext) UnmarshalBinary(data []byte) (int, error) {
+	if len(data) < 20 {
+		return 0, ErrNotEnoughData
+	}
+
+	// copy the address, the event buffer is reused
+	family := ByteOrder.Uint16(data[16:18])
+	switch family {
+	case 2: // AF_INET
+		e.IPNet = eval.IPNetFromIP(append(net.IP{}, data[0:4]...))
+	case 10: // AF_INET6
+		e.IPNet = eval.IPNetFromIP(append(net.IP{}, data[0:16]...))
+	default:
+		e.IPNet = net.IPNet{}
+	}
+	// the port is stored in network byte order
+	e.Port = binary.BigEndian.Uint16(data[18:20])
+
+	return 20, nil
+}
+
+func unmarshalAddr(data []byte, addr *IPPortContext, family *uint16) (int, error) {
+	if len(data) < 24 {
+		return 0, ErrNotEnoughData
+	}
+
+	if _, err := addr.UnmarshalBinary(data); err != nil {
+		return 0, err
+	}
+	*family = ByteOrder.Uint16(data[16:18])
+
+	// 4 bytes of padding
+	return 24, nil
+}
+
+// UnmarshalBinary unmarshalls a binary representation of itself
+func (e *ConnectEvent) UnmarshalBinary(data []byte) (int, error) {
+	read, err := UnmarshalBinary(data, &e.SyscallEvent)
+	if err != nil {
+		return 0, err
+	}
+
+	n, err := unmarshalAddr(data[read:], &e.Addr, &e.AddrFamily)
+	if err != nil {
+		return 0, err
+	}
+	return read + n, nil
+}
+
+// UnmarshalBinary unmarshalls a binary representation of itself
+func (e *BindEvent) UnmarshalBinary(data []byte) (int, error) {
+	read, err := UnmarshalBinary(data, &e.SyscallEvent)
+	if err != nil {
+		return 0, err
+	}
+
+	n, err := unmarshalAddr(data[read:], &e.Addr, &e.AddrFamily)
+	if err != nil {
+		return 0, err
+	}
+	return read + n, nil
+}
+
+// decodeDNSName decodes the labels of a DNS name, message compression is not supported
+// as a question is always the first name of a request
+func decodeDNSName(data []byte) (string, int, error) {
+	var (
+		labels []string
+		i      int
+	)
+
+	for {
+		if i >= len(data) {
+			return "", 0, ErrDNSNameOutOfBounds
+		}
+
+		length := int(data[i])
+		i++
+		if length == 0 {
+			break
+		}
+		if length&0xc0 != 0 {
+			return "", 0, ErrDNSNamePointerNotSupported
+		}
+		if i+length > len(data) {
+			return "", 0, ErrDNSNameOutOfBounds
+		}
+
+		label := string(data[i : i+length])
+		if !IsPrintableASCII(label) {
+			return "", 0, ErrNonPrintable
+		}
+		labels = append(labels, label)
+		i += length
+	}
+
+	return strings.Join(labels, "."), i, nil
+}
+
+// UnmarshalBinary unmarshalls a binary representation of itself
+func (e *DNSEvent) UnmarshalBinary(data []byte) (int, error) {
+	read, err := e.Server.UnmarshalBinary(data)
+	if err != nil {
+		return 0, err
+	}
+
+	if len(data) < 24 {
+		return 0, ErrNotEnoughData
+	}
+	e.Size = ByteOrder.Uint16(data[20:22])
+	// 2 bytes of padding
+	read = 24
+
+	// the DNS payload is in network byte order: a 12 bytes header followed by the first question
+	payload := data[read:]
+	if int(e.Size) < len(payload) {
+		payload = payload[:e.Size]
+	}
+	if len(payload) < 12 {
+		return 0, ErrNotEnoughData
+	}
+
+	e.ID = binary.BigEndian.Uint16(payload[0:2])
+	e.Count = binary.BigEndian.Uint16(payload[4:6])
+
+	name, n, err := decodeDNSName(payload[12:])
+	if err != nil {
+		return 0, err
+	}
+	e.Name = name
+
+	payload = payload[12+n:]
+	if len(payload) < 4 {
+		return 0, ErrNotEnoughData
+	}
+	e.Type = binary.BigEndian.Uint16(payload[0:2])
+	e.Class = binary.BigEndian.Uint16(payload[2:4])
+
+	return len(data), nil
+}
@@ -0,0 +1,56 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package model
+
+import (
+	"encoding/binary"
+	"testing"
+)
+
+func TestDNSEventUnmarshalBinary(t *testing.T) {
+	payload := []byte{
+		0x12, 0x34, // id
+		0x01, 0x00, // flags
+		0x00, 0x01, // question count
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+		0x03, 'w', 'w', 'w', 0x07, 'e', 'x', 'a', 'm', 'p', 'l', 'e', 0x03, 'c', 'o', 'm', 0x00,
+		0x00, 0x1c, // AAAA
+		0x00, 0x01, // CLASS_INET
+	}
+
+	data := make([]byte, 24, 24+len(payload)+16)
+	copy(data[0:4], []byte{8, 8, 8, 8})
+	ByteOrder.PutUint16(data[16:18], 2)
+	binary.BigEndian.PutUint16(data[18:20], 53)
+	ByteOrder.PutUint16(data[20:22], uint16(len(payload)))
+	data = append(data, payload...)
+	// trailing bytes of the kernel buffer are ignored
+	data = append(data, make([]byte, 16)...)
+
+	var e DNSEvent
+	if _, err := e.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+
+	if e.ID != 0x1234 || e.Count != 1 {
+		t.Errorf("wrong header: id %x, count %d", e.ID, e.Count)
+	}
+	if e.Name != "www.example.com" {
+		t.Errorf("expected www.example.com, got %s", e.Name)
+	}
+	if DNSQType(e.Type).String() != "AAAA" || DNSQClass(e.Class).String() != "CLASS_INET" {
+		t.Errorf("wrong question: type %d, class %d", e.Type, e.Class)
+	}
+	if e.Server.IPNet.String() != "8.8.8.8/32" || e.Server.Port != 53 {
+		t.Errorf("wrong server: %s:%d", e.Server.IPNet.String(), e.Server.Port)
+	}
+
+	// compressed names are not supported in questions
+	data[24+12] = 0xc0
+	if _, err := e.UnmarshalBinary(data[:24+len(payload)]); err == nil {
+		t.Error("expected an error")
+	}
+}
@@ -107,3 +107,13 @@ type ErrRuleLoad struct {
 func (e ErrRuleLoad) Error() string {
 	return fmt.Sprintf("rule `%s` definition error: %s", e.Definition.ID, e.Err)
 }
+
+// ErrSequenceLoad is on sequence definition error
+type ErrSequenceLoad struct {
+	Definition *SequenceDefinition
+	Err        error
+}
+
+func (e ErrSequenceLoad) Error() string {
+	return fmt.Sprintf("sequence `%s` definition error: %s", e.Definition.ID, e.Err)
+}
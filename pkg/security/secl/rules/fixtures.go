@@ -0,0 +1,358 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package rules
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+
+	"github.com/DataDog/datadog-agent/pkg/security/secl/compiler/eval"
+)
+
+// EventFixture describes an event by the values of its fields, so that rules can be
+// evaluated against it without a live probe
+type EventFixture struct {
+	Name string `yaml:"name" json:"name"`
+	// Type is the event type. When empty, it is inferred from the fields.
+	Type eval.EventType `yaml:"type" json:"type"`
+	// Fields maps SECL fields to their values. Values of array fields are lists,
+	// and integer fields accept constants such as "O_CREAT|O_WRONLY".
+	Fields map[eval.Field]interface{} `yaml:"fields" json:"fields"`
+	// ExpectedRules lists the IDs of the rules expected to match the event. When nil,
+	// the matches are reported but not checked.
+	ExpectedRules []RuleID `yaml:"expected_rules" json:"expected_rules"`
+}
+
+// FixtureResult holds the outcome of the evaluation of an event fixture
+type FixtureResult struct {
+	Name string `json:"name"`
+	// Error reports why the fixture couldn't be mapped onto an event
+	Error string `json:"error,omitempty"`
+	// Rules holds the evaluation report of each rule of the event type, by rule ID
+	Rules []*RuleReport `json:"rules"`
+	// Missing and Unexpected list the rules which didn't match the expectations
+	Missing    []RuleID `json:"missing,omitempty"`
+	Unexpected []RuleID `json:"unexpected,omitempty"`
+}
+
+// RuleReport describes why a rule did or did not match an event
+type RuleReport struct {
+	ID      RuleID `json:"id"`
+	Matched bool   `json:"matched"`
+	// Macros holds the value of the boolean macros used by the rule
+	Macros map[MacroID]bool `json:"macros,omitempty"`
+	// Fields holds the value of the fields used by the rule
+	Fields map[eval.Field]interface{} `json:"fields,omitempty"`
+}
+
+// Passed returns whether the fixture could be evaluated and matched its expectations
+func (r *FixtureResult) Passed() bool {
+	return r.Error == "" && len(r.Missing) == 0 && len(r.Unexpected) == 0
+}
+
+// MatchedRules returns the IDs of the rules which matched the event
+func (r *FixtureResult) MatchedRules() []RuleID {
+	var ids []RuleID
+	for _, report := range r.Rules {
+		if report.Matched {
+			ids = append(ids, report.ID)
+		}
+	}
+	return ids
+}
+
+// LoadEventFixtures loads the event fixtures of a YAML or JSON file holding a list of
+// fixtures. If path is a directory, the fixtures of all its .yaml, .yml and .json
+// files are loaded, in lexical order.
+func LoadEventFixtures(path string) ([]*EventFixture, error) {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	files := []string{path}
+	if fi.IsDir() {
+		entries, err := os.ReadDir(path)
+		if err != nil {
+			return nil, err
+		}
+
+		files = files[:0]
+		for _, entry := range entries {
+			switch filepath.Ext(entry.Name()) {
+			case ".yaml", ".yml", ".json":
+				files = append(files, filepath.Join(path, entry.Name()))
+			}
+		}
+		sort.Strings(files)
+	}
+
+	var fixtures []*EventFixture
+	for _, file := range files {
+		f, err := os.Open(file)
+		if err != nil {
+			return nil, err
+		}
+
+		var fileFixtures []*EventFixture
+		err = yaml.NewDecoder(f).Decode(&fileFixtures)
+		f.Close()
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to parse fixtures `%s`", file)
+		}
+
+		for i, fixture := range fileFixtures {
+			if fixture.Name == "" {
+				fixture.Name = fmt.Sprintf("%s#%d", filepath.Base(file), i)
+			}
+		}
+		fixtures = append(fixtures, fileFixtures...)
+	}
+
+	return fixtures, nil
+}
+
+// EvaluateFixtures evaluates the event fixtures, in order, against the rules of the
+// ruleset. newEvent returns an empty event of the given type. The actions of the
+// rules matching a fixture are executed, so that variables set by a fixture are seen
+// by the following ones, and sequences progress from one fixture to the next. Listeners
+// are not notified.
+func (rs *RuleSet) EvaluateFixtures(fixtures []*EventFixture, newEvent func(eventType eval.EventType) (eval.Event, error)) []*FixtureResult {
+	results := make([]*FixtureResult, 0, len(fixtures))
+	for _, fixture := range fixtures {
+		results = append(results, rs.evaluateFixture(fixture, newEvent))
+	}
+	return results
+}
+
+func (rs *RuleSet) evaluateFixture(fixture *EventFixture, newEvent func(eventType eval.EventType) (eval.Event, error)) *FixtureResult {
+	result := &FixtureResult{Name: fixture.Name}
+
+	event, err := rs.fixtureEvent(fixture, newEvent)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+
+	ctx := rs.pool.Get(event.GetPointer())
+	defer rs.pool.Put(ctx)
+
+	if bucket, exists := rs.eventRuleBuckets[event.GetType()]; exists {
+		var steps []*Rule
+		for _, rule := range bucket.rules {
+			if rule.sequence != nil {
+				if rule.GetEvaluator().Eval(ctx) {
+					steps = append(steps, rule)
+				}
+				continue
+			}
+
+			report := &RuleReport{
+				ID:      rule.ID,
+				Matched: rule.GetEvaluator().Eval(ctx),
+				Macros:  rs.ruleMacroValues(ctx, rule),
+				Fields:  make(map[eval.Field]interface{}),
+			}
+			for _, field := range rule.GetFields() {
+				if value, err := event.GetFieldValue(field); err == nil {
+					report.Fields[field] = value
+				}
+			}
+			result.Rules = append(result.Rules, report)
+
+			if report.Matched {
+				if err := rs.runRuleActions(ctx, rule); err != nil {
+					rs.logger.Errorf("Error while executing rule actions: %s", err)
+				}
+			}
+		}
+
+		// sequences are reported as matching the event completing them
+		for i := len(steps) - 1; i >= 0; i-- {
+			if match := steps[i].sequence.advance(ctx, event, steps[i]); match != nil {
+				last := match.Events[len(match.Events)-1]
+				result.Rules = append(result.Rules, &RuleReport{ID: steps[i].sequence.ID, Matched: true, Fields: last.Fields})
+			}
+		}
+	}
+	sort.Slice(result.Rules, func(i, j int) bool { return result.Rules[i].ID < result.Rules[j].ID })
+
+	if fixture.ExpectedRules != nil {
+		matched := make(map[RuleID]bool)
+		for _, id := range result.MatchedRules() {
+			matched[id] = true
+		}
+		expected := make(map[RuleID]bool)
+		for _, id := range fixture.ExpectedRules {
+			expected[id] = true
+			if !matched[id] {
+				result.Missing = append(result.Missing, id)
+			}
+		}
+		for _, id := range result.MatchedRules() {
+			if !expected[id] {
+				result.Unexpected = append(result.Unexpected, id)
+			}
+		}
+	}
+
+	return result
+}
+
+// fixtureEvent returns the event described by the fixture
+func (rs *RuleSet) fixtureEvent(fixture *EventFixture, newEvent func(eventType eval.EventType) (eval.Event, error)) (eval.Event, error) {
+	fields := make([]eval.Field, 0, len(fixture.Fields))
+	for field := range fixture.Fields {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	eventType := fixture.Type
+	if eventType == "" {
+		for _, field := range fields {
+			fieldEventType, err := rs.eventCtor().GetFieldEventType(field)
+			if err != nil {
+				return nil, err
+			}
+			if fieldEventType != "" && fieldEventType != "*" {
+				eventType = fieldEventType
+				break
+			}
+		}
+		if eventType == "" {
+			return nil, errors.New("no event type specified and none could be inferred from the fields")
+		}
+	}
+
+	event, err := newEvent(eventType)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, field := range fields {
+		fieldEventType, err := event.GetFieldEventType(field)
+		if err != nil {
+			return nil, err
+		}
+		if fieldEventType != "" && fieldEventType != "*" && fieldEventType != eventType {
+			return nil, fmt.Errorf("field `%s` is not available for `%s` events", field, eventType)
+		}
+
+		kind, err := event.GetFieldType(field)
+		if err != nil {
+			return nil, err
+		}
+
+		values, ok := fixture.Fields[field].([]interface{})
+		if !ok {
+			values = []interface{}{fixture.Fields[field]}
+		}
+		for _, value := range values {
+			if value, err = rs.fixtureFieldValue(kind, value); err != nil {
+				return nil, errors.Wrapf(err, "invalid value for field `%s`", field)
+			}
+			if err := event.SetFieldValue(field, value); err != nil {
+				return nil, errors.Wrapf(err, "failed to set field `%s`", field)
+			}
+		}
+	}
+
+	return event, nil
+}
+
+// fixtureFieldValue converts a value decoded from a fixture file to the type of its field
+func (rs *RuleSet) fixtureFieldValue(kind reflect.Kind, value interface{}) (interface{}, error) {
+	switch kind {
+	case reflect.Int:
+		switch v := value.(type) {
+		case int:
+			return v, nil
+		case float64:
+			return int(v), nil
+		case string:
+			// constants such as "O_CREAT|O_WRONLY"
+			var result int
+			for _, name := range strings.Split(v, "|") {
+				name = strings.TrimSpace(name)
+				constant, ok := rs.opts.Constants[name].(*eval.IntEvaluator)
+				if !ok {
+					return nil, fmt.Errorf("unknown constant `%s`", name)
+				}
+				result |= constant.Value
+			}
+			return result, nil
+		}
+	case reflect.String:
+		if v, ok := value.(string); ok {
+			return v, nil
+		}
+	case reflect.Bool:
+		if v, ok := value.(bool); ok {
+			return v, nil
+		}
+	}
+	return nil, fmt.Errorf("expected a value of type %s, got %v", kind, value)
+}
+
+// ruleMacroValues returns the value of the boolean macros used by the rule
+func (rs *RuleSet) ruleMacroValues(ctx *eval.Context, rule *Rule) map[MacroID]bool {
+	idents := make(map[string]bool)
+	collectIdents(reflect.ValueOf(rule.GetAst()), idents)
+
+	var values map[MacroID]bool
+	for ident := range idents {
+		macro, exists := rs.opts.Macros[ident]
+		if !exists {
+			continue
+		}
+		if evaluator, ok := macro.GetEvaluator().Value.(*eval.BoolEvaluator); ok {
+			if values == nil {
+				values = make(map[MacroID]bool)
+			}
+			if evaluator.EvalFnc != nil {
+				values[ident] = evaluator.EvalFnc(ctx)
+			} else {
+				values[ident] = evaluator.Value
+			}
+		}
+	}
+	return values
+}
+
+// collectIdents collects the identifiers of the AST node v
+func collectIdents(v reflect.Value, idents map[string]bool) {
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if !v.IsNil() {
+			collectIdents(v.Elem(), idents)
+		}
+	case reflect.Slice:
+		for i := 0; i < v.Len(); i++ {
+			collectIdents(v.Index(i), idents)
+		}
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			if !v.Type().Field(i).IsExported() {
+				continue
+			}
+			field := v.Field(i)
+			if v.Type().Field(i).Name == "Ident" {
+				if ident, ok := field.Interface().(*string); ok && ident != nil {
+					idents[*ident] = true
+					continue
+				}
+			}
+			collectIdents(field, idents)
+		}
+	}
+}
@@ -0,0 +1,92 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package rules
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/DataDog/datadog-agent/pkg/security/secl/compiler/eval"
+)
+
+const testFixtures = `
+- name: passwd-write
+  fields:
+    open.filename: /etc/passwd
+    open.flags: O_CREAT|O_RDWR
+    process.uid: 1000
+  expected_rules: [ID0]
+- name: root-write
+  type: open
+  fields:
+    open.filename: /etc/passwd
+    process.uid: 0
+  expected_rules: []
+- name: sbin-mkdir
+  fields:
+    mkdir.filename: /sbin/test
+    process.uid: 1000
+  expected_rules: [ID0]
+- fields:
+    open.flags: O_UNKNOWN
+`
+
+func TestEvaluateFixtures(t *testing.T) {
+	rs := newRuleSet()
+	if _, err := rs.AddMacro(&MacroDefinition{ID: "sensitive_files", Expression: `["/etc/passwd", "/etc/shadow"]`}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := rs.AddMacro(&MacroDefinition{ID: "is_user", Expression: `process.uid != 0`}); err != nil {
+		t.Fatal(err)
+	}
+	addRuleExpr(t, rs,
+		`open.filename in sensitive_files && is_user`,
+		`open.flags & O_TRUNC > 0`,
+	)
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "fixtures.yaml")
+	if err := os.WriteFile(path, []byte(testFixtures), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	fixtures, err := LoadEventFixtures(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !assert.Len(t, fixtures, 4) {
+		return
+	}
+	assert.Equal(t, "fixtures.yaml#3", fixtures[3].Name)
+
+	results := rs.EvaluateFixtures(fixtures, func(eventType eval.EventType) (eval.Event, error) {
+		return &testEvent{kind: eventType}, nil
+	})
+
+	passwd := results[0]
+	assert.True(t, passwd.Passed())
+	assert.Equal(t, []RuleID{"ID0"}, passwd.MatchedRules())
+	assert.Equal(t, map[MacroID]bool{"is_user": true}, passwd.Rules[0].Macros)
+	assert.Equal(t, "/etc/passwd", passwd.Rules[0].Fields["open.filename"])
+	assert.Equal(t, 1000, passwd.Rules[0].Fields["process.uid"])
+
+	root := results[1]
+	assert.True(t, root.Passed())
+	assert.Empty(t, root.MatchedRules())
+	assert.Equal(t, map[MacroID]bool{"is_user": false}, root.Rules[0].Macros)
+
+	mkdir := results[2]
+	assert.False(t, mkdir.Passed())
+	assert.Equal(t, []RuleID{"ID0"}, mkdir.Missing)
+	assert.Empty(t, mkdir.Rules)
+
+	unknown := results[3]
+	assert.False(t, unknown.Passed())
+	assert.Contains(t, unknown.Error, "O_UNKNOWN")
+}
@@ -354,6 +354,10 @@ var testConstants = map[string]interface{}{
 	"O_EXCL":   &eval.IntEvaluator{Value: syscall.O_EXCL},
 	"O_SYNC":   &eval.IntEvaluator{Value: syscall.O_SYNC},
 	"O_TRUNC":  &eval.IntEvaluator{Value: syscall.O_TRUNC},
+
+	// signals
+	"SIGKILL": &eval.IntEvaluator{Value: int(syscall.SIGKILL)},
+	"SIGTERM": &eval.IntEvaluator{Value: int(syscall.SIGTERM)},
 }
 
 var testSupportedDiscarders = map[eval.Field]bool{
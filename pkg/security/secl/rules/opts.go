@@ -11,7 +11,7 @@ import (
 
 // VariableProvider is the interface implemented by SECL variable providers
 type VariableProvider interface {
-	GetVariable(name string, value interface{}) (eval.VariableValue, error)
+	GetVariable(name string, value interface{}, opts eval.VariableOpts) (eval.VariableValue, error)
 }
 
 // VariableProviderFactory describes a function called to instantiate a variable provider
@@ -24,6 +24,7 @@ type Opts struct {
 	ReservedRuleIDs     []RuleID
 	EventTypeEnabled    map[eval.EventType]bool
 	StateScopes         map[Scope]VariableProviderFactory
+	SequenceScopes      map[Scope]SequenceScopeFnc
 	Logger              Logger
 }
 
@@ -86,3 +87,9 @@ func (o *Opts) WithStateScopes(stateScopes map[Scope]VariableProviderFactory) *O
 	o.StateScopes = stateScopes
 	return o
 }
+
+// WithSequenceScopes set sequence scopes
+func (o *Opts) WithSequenceScopes(sequenceScopes map[Scope]SequenceScopeFnc) *Opts {
+	o.SequenceScopes = sequenceScopes
+	return o
+}
@@ -25,10 +25,11 @@ const defaultPolicy = "default.policy"
 
 // Policy represents a policy file which is composed of a list of rules and macros
 type Policy struct {
-	Name    string
-	Version string             `yaml:"version"`
-	Rules   []*RuleDefinition  `yaml:"rules"`
-	Macros  []*MacroDefinition `yaml:"macros"`
+	Name      string
+	Version   string                `yaml:"version"`
+	Rules     []*RuleDefinition     `yaml:"rules"`
+	Macros    []*MacroDefinition    `yaml:"macros"`
+	Sequences []*SequenceDefinition `yaml:"sequences"`
 }
 
 var ruleIDPattern = `^([a-zA-Z0-9]*_*)*$`
@@ -76,12 +77,65 @@ func (p *Policy) GetValidMacroAndRules() ([]*MacroDefinition, []*RuleDefinition,
 			continue
 		}
 
+		if err := checkActions(ruleDef.Actions); err != nil {
+			result = multierror.Append(result, &ErrRuleLoad{Definition: ruleDef, Err: err})
+			continue
+		}
+
 		rules = append(rules, ruleDef)
 	}
 
 	return macros, rules, result
 }
 
+// GetValidSequences returns valid sequence definitions
+func (p *Policy) GetValidSequences() ([]*SequenceDefinition, *multierror.Error) {
+	var (
+		result    *multierror.Error
+		sequences []*SequenceDefinition
+	)
+
+	for _, seqDef := range p.Sequences {
+		seqDef.Policy = p
+
+		if seqDef.ID == "" {
+			result = multierror.Append(result, &ErrSequenceLoad{Definition: seqDef, Err: errors.New("no ID defined for sequence")})
+			continue
+		}
+		if !checkRuleID(seqDef.ID) {
+			result = multierror.Append(result, &ErrSequenceLoad{Definition: seqDef, Err: fmt.Errorf("ID does not match pattern `%s`", ruleIDPattern)})
+			continue
+		}
+
+		if len(seqDef.Steps) == 0 && !seqDef.Disabled {
+			result = multierror.Append(result, &ErrSequenceLoad{Definition: seqDef, Err: errors.New("no steps defined")})
+			continue
+		}
+
+		sequences = append(sequences, seqDef)
+	}
+
+	return sequences, result
+}
+
+// checkActions returns an error if one of the actions is invalid
+func checkActions(actions []ActionDefinition) error {
+	suppress := false
+	for _, action := range actions {
+		if err := action.Check(); err != nil {
+			return fmt.Errorf("invalid action: %w", err)
+		}
+
+		if action.Suppress != nil {
+			if suppress {
+				return errors.New("invalid action: only one 'suppress' action can be defined")
+			}
+			suppress = true
+		}
+	}
+	return nil
+}
+
 // LoadPolicy loads a YAML file and returns a new policy
 func LoadPolicy(r io.Reader, name string) (*Policy, error) {
 	policy := &Policy{Name: name}
@@ -97,11 +151,13 @@ func LoadPolicy(r io.Reader, name string) (*Policy, error) {
 // LoadPolicies loads the policies listed in the configuration and apply them to the given ruleset
 func LoadPolicies(policiesDir string, ruleSet *RuleSet) *multierror.Error {
 	var (
-		result     *multierror.Error
-		allRules   []*RuleDefinition
-		allMacros  []*MacroDefinition
-		macroIndex = make(map[string]*MacroDefinition)
-		ruleIndex  = make(map[string]*RuleDefinition)
+		result        *multierror.Error
+		allRules      []*RuleDefinition
+		allMacros     []*MacroDefinition
+		allSequences  []*SequenceDefinition
+		macroIndex    = make(map[string]*MacroDefinition)
+		ruleIndex     = make(map[string]*RuleDefinition)
+		sequenceIndex = make(map[string]*SequenceDefinition)
 	)
 
 	policyFiles, err := os.ReadDir(policiesDir)
@@ -178,6 +234,22 @@ func LoadPolicies(policiesDir string, ruleSet *RuleSet) *multierror.Error {
 				}
 			}
 		}
+
+		sequences, sErr := policy.GetValidSequences()
+		if sErr.ErrorOrNil() != nil {
+			result = multierror.Append(result, sErr)
+		}
+
+		for _, sequence := range sequences {
+			if existingSequence := sequenceIndex[sequence.ID]; existingSequence != nil {
+				if err := existingSequence.MergeWith(sequence); err != nil {
+					result = multierror.Append(result, err)
+				}
+			} else {
+				sequenceIndex[sequence.ID] = sequence
+				allSequences = append(allSequences, sequence)
+			}
+		}
 	}
 
 	// Add the macros to the ruleset and generate macros evaluators
@@ -185,10 +257,20 @@ func LoadPolicies(policiesDir string, ruleSet *RuleSet) *multierror.Error {
 		result = multierror.Append(result, err)
 	}
 
+	variablesOpts := make(map[string]eval.VariableOpts)
+
 	for _, rule := range allRules {
 		for _, action := range rule.Actions {
-			if err := action.Check(); err != nil {
-				result = multierror.Append(result, fmt.Errorf("invalid action: %w", err))
+			for _, field := range action.Fields() {
+				if _, err := ruleSet.eventCtor().GetFieldType(field); err != nil {
+					result = multierror.Append(result, &ErrRuleLoad{Definition: rule, Err: fmt.Errorf("invalid action: unknown field '%s'", field)})
+				}
+			}
+
+			if action.Kill != nil {
+				if _, found := ruleSet.opts.Constants[action.Kill.GetSignal()]; !found {
+					result = multierror.Append(result, &ErrRuleLoad{Definition: rule, Err: fmt.Errorf("invalid action: unknown signal '%s'", action.Kill.GetSignal())})
+				}
 			}
 
 			if action.Set != nil {
@@ -253,6 +335,22 @@ func LoadPolicies(policiesDir string, ruleSet *RuleSet) *multierror.Error {
 					}
 				}
 
+				variableOpts := action.Set.VariableOpts()
+				if existingOpts, found := variablesOpts[varName]; found && existingOpts != variableOpts {
+					result = multierror.Append(result, fmt.Errorf("conflicting ttl or size for variable '%s'", varName))
+					continue
+				}
+				variablesOpts[varName] = variableOpts
+
+				if variableOpts.Size > 0 {
+					switch variableValue.(type) {
+					case []string, []int:
+					default:
+						result = multierror.Append(result, fmt.Errorf("size is only supported by array variables, '%s' isn't an array", varName))
+						continue
+					}
+				}
+
 				var variable eval.VariableValue
 				var variableProvider VariableProvider
 
@@ -272,7 +370,7 @@ func LoadPolicies(policiesDir string, ruleSet *RuleSet) *multierror.Error {
 					variableProvider = &ruleSet.globalVariables
 				}
 
-				variable, err = variableProvider.GetVariable(action.Set.Name, variableValue)
+				variable, err = variableProvider.GetVariable(action.Set.Name, variableValue, variableOpts)
 				if err != nil {
 					result = multierror.Append(result, fmt.Errorf("invalid type '%s' for variable '%s': %w", reflect.TypeOf(action.Set.Value), action.Set.Name, err))
 					continue
@@ -293,5 +391,10 @@ func LoadPolicies(policiesDir string, ruleSet *RuleSet) *multierror.Error {
 		result = multierror.Append(result, err)
 	}
 
+	// Add sequences to the ruleset and generate the evaluators of their steps
+	if err := ruleSet.AddSequences(allSequences); err.ErrorOrNil() != nil {
+		result = multierror.Append(result, err)
+	}
+
 	return result
 }
@@ -12,6 +12,7 @@ import (
 	"path/filepath"
 	"syscall"
 	"testing"
+	"time"
 
 	"gopkg.in/yaml.v3"
 
@@ -163,7 +164,7 @@ type testVariableProvider struct {
 	vars map[string]map[string]interface{}
 }
 
-func (t *testVariableProvider) GetVariable(name string, value interface{}) (eval.VariableValue, error) {
+func (t *testVariableProvider) GetVariable(name string, value interface{}, opts eval.VariableOpts) (eval.VariableValue, error) {
 	switch value.(type) {
 	case []int:
 		intVar := eval.NewIntArrayVariable(func(ctx *eval.Context) []int {
@@ -616,3 +617,106 @@ func TestActionSetVariableInvalid(t *testing.T) {
 		}
 	})
 }
+
+func TestLoadSequences(t *testing.T) {
+	rs := newRuleSet()
+
+	tmpDir := t.TempDir()
+	policy := `
+version: 1.0.0
+rules:
+  - id: shell_then_cron
+    expression: open.filename == "/etc/passwd"
+sequences:
+  - id: mkdir_then_open
+    ttl: 30s
+    max_pending: 10
+    steps:
+      - expression: mkdir.filename == "/tmp/test"
+      - expression: open.filename == "/tmp/test/file"
+  - id: shell_then_cron
+    steps:
+      - expression: mkdir.filename == "/tmp/test"
+      - expression: open.filename == "/tmp/test/file"
+  - id: no_steps
+`
+	if err := ioutil.WriteFile(filepath.Join(tmpDir, "test.policy"), []byte(policy), 0700); err != nil {
+		t.Fatal(err)
+	}
+
+	err := LoadPolicies(tmpDir, rs)
+	if err == nil || len(err.Errors) != 2 {
+		t.Fatalf("expected errors for the sequences without steps and conflicting with a rule, got %v", err)
+	}
+
+	sequence := rs.GetSequences()["mkdir_then_open"]
+	if sequence == nil {
+		t.Fatal("failed to find mkdir_then_open in ruleset")
+	}
+	if sequence.ttl != 30*time.Second || sequence.maxPending != 10 || len(sequence.GetSteps()) != 2 {
+		t.Errorf("unexpected sequence: %+v", sequence)
+	}
+
+	if !rs.HasRulesForEventType("mkdir") {
+		t.Error("expected the steps to be added to the mkdir bucket")
+	}
+}
+
+func TestActionsInvalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		actions []ActionDefinition
+	}{
+		{"empty", []ActionDefinition{{}}},
+		{"multiple", []ActionDefinition{{Kill: &KillDefinition{}, Suppress: &SuppressDefinition{Duration: time.Second}}}},
+		{"kill-signal", []ActionDefinition{{Kill: &KillDefinition{Signal: "kill"}}}},
+		{"kill-unknown-signal", []ActionDefinition{{Kill: &KillDefinition{Signal: "SIGUNKNOWN"}}}},
+		{"kill-scope", []ActionDefinition{{Kill: &KillDefinition{Scope: "host"}}}},
+		{"metric-name", []ActionDefinition{{EmitMetric: &EmitMetricDefinition{Name: "bad name"}}}},
+		{"metric-field", []ActionDefinition{{EmitMetric: &EmitMetricDefinition{Name: "matches", Fields: []string{"open.unknown"}}}}},
+		{"suppress-duration", []ActionDefinition{{Suppress: &SuppressDefinition{}}}},
+		{"suppress-twice", []ActionDefinition{{Suppress: &SuppressDefinition{Duration: time.Second}}, {Suppress: &SuppressDefinition{Duration: time.Minute}}}},
+		{"set-ttl", []ActionDefinition{{Set: &SetDefinition{Name: "var", Value: true, TTL: -time.Second}}}},
+		{"set-size-scalar", []ActionDefinition{{Set: &SetDefinition{Name: "var", Value: true, Size: 10}}}},
+		{"set-conflicting-ttl", []ActionDefinition{{Set: &SetDefinition{Name: "var", Field: "open.filename", Append: true, TTL: time.Minute}}, {Set: &SetDefinition{Name: "var", Field: "open.filename", Append: true, TTL: time.Hour}}}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			testPolicy := &Policy{
+				Name: "test-policy",
+				Rules: []*RuleDefinition{{
+					ID:         "test_rule",
+					Expression: `open.filename == "/tmp/test"`,
+					Actions:    test.actions,
+				}},
+			}
+
+			if err := loadPolicy(t, testPolicy); err == nil {
+				t.Error("expected policy to fail to load")
+			} else {
+				t.Log(err)
+			}
+		})
+	}
+}
+
+func TestActionsValid(t *testing.T) {
+	testPolicy := &Policy{
+		Name: "test-policy",
+		Rules: []*RuleDefinition{{
+			ID:         "test_rule",
+			Expression: `open.filename == "/tmp/test"`,
+			Actions: []ActionDefinition{
+				{Kill: &KillDefinition{Signal: "SIGTERM", Scope: KillScopeContainer}},
+				{EmitMetric: &EmitMetricDefinition{Name: "tmp_test.opens", Tags: []string{"team:security"}, Fields: []string{"process.name"}}},
+				{Suppress: &SuppressDefinition{Duration: time.Minute}},
+				{Set: &SetDefinition{Name: "filenames", Field: "open.filename", Append: true, TTL: time.Hour, Size: 100}},
+			},
+		}},
+	}
+
+	if err := loadPolicy(t, testPolicy); err != nil {
+		t.Error(err)
+	}
+}
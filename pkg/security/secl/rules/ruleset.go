@@ -8,7 +8,9 @@ package rules
 import (
 	"fmt"
 	"reflect"
+	"regexp"
 	"strings"
+	"time"
 
 	"github.com/hashicorp/go-multierror"
 	"github.com/pkg/errors"
@@ -102,23 +104,52 @@ func (rd *RuleDefinition) MergeWith(rd2 *RuleDefinition) error {
 
 // ActionDefinition describes a rule action section
 type ActionDefinition struct {
-	Set *SetDefinition `yaml:"set"`
+	Set        *SetDefinition        `yaml:"set"`
+	Kill       *KillDefinition       `yaml:"kill"`
+	EmitMetric *EmitMetricDefinition `yaml:"emit_metric"`
+	Suppress   *SuppressDefinition   `yaml:"suppress"`
 }
 
+var (
+	signalPattern     = regexp.MustCompile(`^SIG[A-Z0-9]+$`)
+	metricNamePattern = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9_.]*$`)
+)
+
 // Check returns an error if the action in invalid
 func (a *ActionDefinition) Check() error {
-	if a.Set == nil {
-		return errors.New("missing 'set' section in action")
+	count := 0
+	for _, defined := range []bool{a.Set != nil, a.Kill != nil, a.EmitMetric != nil, a.Suppress != nil} {
+		if defined {
+			count++
+		}
 	}
 
-	if a.Set.Name == "" {
-		return errors.New("action name is empty")
+	switch {
+	case count == 0:
+		return errors.New("missing 'set', 'kill', 'emit_metric' or 'suppress' section in action")
+	case count > 1:
+		return errors.New("only one of 'set', 'kill', 'emit_metric' and 'suppress' can be defined in an action")
+	case a.Set != nil:
+		return a.Set.Check()
+	case a.Kill != nil:
+		return a.Kill.Check()
+	case a.EmitMetric != nil:
+		return a.EmitMetric.Check()
+	default:
+		return a.Suppress.Check()
 	}
+}
 
-	if (a.Set.Value == nil && a.Set.Field == "") || (a.Set.Value != nil && a.Set.Field != "") {
-		return errors.New("either 'value' or 'field' must be specified")
+// Fields returns the event fields used by the action
+func (a *ActionDefinition) Fields() []eval.Field {
+	switch {
+	case a.Set != nil && a.Set.Field != "":
+		return []eval.Field{a.Set.Field}
+	case a.EmitMetric != nil:
+		return a.EmitMetric.Fields
+	case a.Suppress != nil:
+		return a.Suppress.Fields
 	}
-
 	return nil
 }
 
@@ -132,12 +163,130 @@ type SetDefinition struct {
 	Field  string      `yaml:"field"`
 	Append bool        `yaml:"append"`
 	Scope  Scope       `yaml:"scope"`
+	// TTL is the duration after which a value set by the action expires
+	TTL time.Duration `yaml:"ttl"`
+	// Size is the maximum number of values of an array variable, the oldest being evicted first
+	Size int `yaml:"size"`
+}
+
+// Check returns an error if the 'set' section is invalid
+func (s *SetDefinition) Check() error {
+	if s.Name == "" {
+		return errors.New("action name is empty")
+	}
+
+	if (s.Value == nil && s.Field == "") || (s.Value != nil && s.Field != "") {
+		return errors.New("either 'value' or 'field' must be specified")
+	}
+
+	if s.TTL < 0 {
+		return errors.New("variable ttl can't be negative")
+	}
+
+	if s.Size < 0 {
+		return errors.New("variable size can't be negative")
+	}
+
+	return nil
+}
+
+// VariableOpts returns the expiration and eviction options of the variable
+func (s *SetDefinition) VariableOpts() eval.VariableOpts {
+	return eval.VariableOpts{TTL: s.TTL, Size: s.Size}
+}
+
+// Kill scopes
+const (
+	// KillScopeProcess kills the process which triggered the rule
+	KillScopeProcess = "process"
+	// KillScopeContainer kills all the processes of the container of the process which triggered the rule
+	KillScopeContainer = "container"
+)
+
+// KillDefinition describes the 'kill' section of a rule action
+type KillDefinition struct {
+	// Signal is the name of the signal to send, SIGKILL by default
+	Signal string `yaml:"signal"`
+	// Scope is either 'process', the default, or 'container'
+	Scope string `yaml:"scope"`
+}
+
+// Check returns an error if the 'kill' section is invalid
+func (k *KillDefinition) Check() error {
+	if k.Signal != "" && !signalPattern.MatchString(k.Signal) {
+		return fmt.Errorf("invalid signal '%s'", k.Signal)
+	}
+
+	switch k.Scope {
+	case "", KillScopeProcess, KillScopeContainer:
+	default:
+		return fmt.Errorf("invalid kill scope '%s'", k.Scope)
+	}
+
+	return nil
+}
+
+// GetSignal returns the name of the signal to send
+func (k *KillDefinition) GetSignal() string {
+	if k.Signal == "" {
+		return "SIGKILL"
+	}
+	return k.Signal
+}
+
+// EmitMetricDefinition describes the 'emit_metric' section of a rule action. A counter is
+// incremented for each match of the rule.
+type EmitMetricDefinition struct {
+	// Name is the name of the counter, appended to the prefix of the rule metrics
+	Name string `yaml:"name"`
+	// Tags holds static tags, as key:value strings
+	Tags []string `yaml:"tags"`
+	// Fields lists the event fields whose values are added as tags
+	Fields []eval.Field `yaml:"fields"`
+}
+
+// Check returns an error if the 'emit_metric' section is invalid
+func (m *EmitMetricDefinition) Check() error {
+	if !metricNamePattern.MatchString(m.Name) {
+		return fmt.Errorf("invalid metric name '%s'", m.Name)
+	}
+
+	for _, tag := range m.Tags {
+		if tag == "" {
+			return errors.New("empty metric tag")
+		}
+	}
+
+	return nil
+}
+
+// SuppressDefinition describes the 'suppress' section of a rule action. Once an event is
+// sent, the identical events, that is the ones having the same values for the given
+// fields, are not sent for the given duration.
+type SuppressDefinition struct {
+	Duration time.Duration `yaml:"duration"`
+	// Fields lists the event fields identifying identical events. When empty, the fields of
+	// the rule expression are used.
+	Fields []eval.Field `yaml:"fields"`
+}
+
+// Check returns an error if the 'suppress' section is invalid
+func (s *SuppressDefinition) Check() error {
+	if s.Duration <= 0 {
+		return errors.New("suppress duration must be positive")
+	}
+
+	return nil
 }
 
 // Rule describes a rule of a ruleset
 type Rule struct {
 	*eval.Rule
 	Definition *RuleDefinition
+
+	// sequence is set when the rule is a step of a sequence
+	sequence *Sequence
+	step     int
 }
 
 // RuleSetListener describes the methods implemented by an object used to be
@@ -154,6 +303,7 @@ type RuleSet struct {
 	loadedPolicies   map[string]string
 	eventRuleBuckets map[eval.EventType]*RuleBucket
 	rules            map[eval.RuleID]*Rule
+	sequences        map[SequenceID]*Sequence
 	fieldEvaluators  map[string]eval.Evaluator
 	model            eval.Model
 	eventCtor        func() eval.Event
@@ -506,8 +656,14 @@ func (rs *RuleSet) Evaluate(event eval.Event) bool {
 	}
 	rs.logger.Tracef("Evaluating event of type `%s` against set of %d rules", eventType, len(bucket.rules))
 
+	var steps []*Rule
 	for _, rule := range bucket.rules {
 		if rule.GetEvaluator().Eval(ctx) {
+			if rule.sequence != nil {
+				steps = append(steps, rule)
+				continue
+			}
+
 			rs.logger.Tracef("Rule `%s` matches with event `%s`\n", rule.ID, event)
 
 			rs.NotifyRuleMatch(rule, event)
@@ -519,6 +675,15 @@ func (rs *RuleSet) Evaluate(event eval.Event) bool {
 		}
 	}
 
+	if len(steps) > 0 {
+		if rs.evaluateSequenceSteps(ctx, event, steps) {
+			result = true
+		}
+
+		// an event matching a step is not a discarder
+		return result
+	}
+
 	if !result {
 		rs.logger.Tracef("Looking for discarders for event of type `%s`", eventType)
 
@@ -604,6 +769,7 @@ func NewRuleSet(model eval.Model, eventCtor func() eval.Event, opts *Opts) *Rule
 		opts:             opts,
 		eventRuleBuckets: make(map[eval.EventType]*RuleBucket),
 		rules:            make(map[eval.RuleID]*Rule),
+		sequences:        make(map[SequenceID]*Sequence),
 		loadedPolicies:   make(map[string]string),
 		logger:           logger,
 		pool:             eval.NewContextPool(),
@@ -0,0 +1,418 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package rules
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/go-multierror"
+	"github.com/pkg/errors"
+
+	"github.com/DataDog/datadog-agent/pkg/security/secl/compiler/eval"
+)
+
+const (
+	// defaultSequenceTTL is the window used when a sequence doesn't specify one
+	defaultSequenceTTL = time.Minute
+	// defaultSequenceMaxPending is the number of pending sequences kept when a sequence doesn't specify it
+	defaultSequenceMaxPending = 1000
+)
+
+// SequenceID represents the ID of a sequence
+type SequenceID = string
+
+// SequenceDefinition holds the definition of a sequence. A sequence matches when events
+// matching each of its steps occur in order, in the same scope, within the TTL window
+// starting at the event matching the first step.
+type SequenceDefinition struct {
+	ID          SequenceID                `yaml:"id"`
+	Version     string                    `yaml:"version"`
+	Description string                    `yaml:"description"`
+	Tags        map[string]string         `yaml:"tags"`
+	Disabled    bool                      `yaml:"disabled"`
+	Combine     CombinePolicy             `yaml:"combine"`
+	Scope       Scope                     `yaml:"scope"`
+	TTL         time.Duration             `yaml:"ttl"`
+	MaxPending  int                       `yaml:"max_pending"`
+	Steps       []*SequenceStepDefinition `yaml:"steps"`
+	Policy      *Policy
+}
+
+// SequenceStepDefinition holds the definition of a step of a sequence
+type SequenceStepDefinition struct {
+	Expression string `yaml:"expression"`
+}
+
+// GetTags returns the tags associated to a sequence
+func (sd *SequenceDefinition) GetTags() []string {
+	tags := []string{}
+	for k, v := range sd.Tags {
+		tags = append(
+			tags,
+			fmt.Sprintf("%s:%s", k, v))
+	}
+	return tags
+}
+
+// MergeWith merges sequence sd2 into sd
+func (sd *SequenceDefinition) MergeWith(sd2 *SequenceDefinition) error {
+	switch sd2.Combine {
+	case OverridePolicy:
+		sd.Steps = sd2.Steps
+	default:
+		if !sd2.Disabled {
+			return &ErrSequenceLoad{Definition: sd2, Err: ErrInternalIDConflict}
+		}
+	}
+	sd.Disabled = sd2.Disabled
+	return nil
+}
+
+// Check returns an error if the sequence definition is invalid
+func (sd *SequenceDefinition) Check() error {
+	if len(sd.Steps) < 2 {
+		return errors.New("a sequence requires at least 2 steps")
+	}
+	for i, step := range sd.Steps {
+		if step == nil || step.Expression == "" {
+			return fmt.Errorf("no expression defined for step %d", i+1)
+		}
+	}
+	if sd.TTL < 0 {
+		return errors.New("ttl can't be negative")
+	}
+	if sd.MaxPending < 0 {
+		return errors.New("max_pending can't be negative")
+	}
+	return nil
+}
+
+// SequenceScopeFnc returns the keys of the scope instances an event belongs to. The first
+// key is the instance a sequence started by the event is bound to, the following ones, like
+// the ancestors of a process, are the instances whose pending sequences the event can
+// continue. An event without keys can't take part in sequences.
+type SequenceScopeFnc func(ctx *eval.Context) []string
+
+// SequenceStepMatch describes an event which matched a step of a sequence
+type SequenceStepMatch struct {
+	Step      int                        `json:"step"`
+	EventType eval.EventType             `json:"event_type"`
+	Time      time.Time                  `json:"time"`
+	Fields    map[eval.Field]interface{} `json:"fields"`
+}
+
+// SequenceMatch describes the events which completed a sequence, in the order of the steps
+type SequenceMatch struct {
+	ScopeKey string               `json:"scope_key,omitempty"`
+	Events   []*SequenceStepMatch `json:"events"`
+}
+
+// SequenceListener describes the methods implemented by a ruleset listener which is also
+// notified of the sequence matches.
+type SequenceListener interface {
+	SequenceMatch(sequence *Sequence, match *SequenceMatch, event eval.Event)
+}
+
+// Sequence describes a sequence of a ruleset. It holds the pending sequences, that is the
+// scope instances in which some of the steps matched, up to a maximum.
+type Sequence struct {
+	ID         SequenceID
+	Definition *SequenceDefinition
+	Tags       []string
+
+	steps      []*Rule
+	scope      SequenceScopeFnc
+	ttl        time.Duration
+	maxPending int
+	now        func() time.Time
+
+	lock    sync.Mutex
+	pending map[string]*pendingSequence
+}
+
+type pendingSequence struct {
+	start   time.Time
+	matches []*SequenceStepMatch
+}
+
+// GetSteps returns the rules of the steps of the sequence
+func (s *Sequence) GetSteps() []*Rule {
+	return s.steps
+}
+
+// PendingCount returns the number of pending sequences
+func (s *Sequence) PendingCount() int {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return len(s.pending)
+}
+
+// scopeKeys returns the scope keys of the event being evaluated
+func (s *Sequence) scopeKeys(ctx *eval.Context) []string {
+	if s.scope == nil {
+		return []string{""}
+	}
+	return s.scope(ctx)
+}
+
+// advance records that the event matched the given step and returns the sequence match
+// if this step completed the sequence
+func (s *Sequence) advance(ctx *eval.Context, event eval.Event, step *Rule) *SequenceMatch {
+	keys := s.scopeKeys(ctx)
+	if len(keys) == 0 {
+		return nil
+	}
+
+	now := s.now()
+	stepMatch := &SequenceStepMatch{
+		Step:      step.step + 1,
+		EventType: event.GetType(),
+		Time:      now,
+		Fields:    make(map[eval.Field]interface{}),
+	}
+	for _, field := range step.GetFields() {
+		if value, err := event.GetFieldValue(field); err == nil {
+			stepMatch.Fields[field] = value
+		}
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if step.step == 0 {
+		key := keys[0]
+		if pending, exists := s.pending[key]; exists && !s.expired(pending, now) {
+			// the window starts at the first event of the sequence
+			return nil
+		}
+
+		if len(s.pending) >= s.maxPending {
+			s.expire(now)
+		}
+		if len(s.pending) >= s.maxPending {
+			s.evictOldest()
+		}
+		s.pending[key] = &pendingSequence{start: now, matches: []*SequenceStepMatch{stepMatch}}
+		return nil
+	}
+
+	for _, key := range keys {
+		pending, exists := s.pending[key]
+		if !exists || len(pending.matches) != step.step {
+			continue
+		}
+		if s.expired(pending, now) {
+			delete(s.pending, key)
+			continue
+		}
+
+		pending.matches = append(pending.matches, stepMatch)
+		if len(pending.matches) < len(s.steps) {
+			return nil
+		}
+
+		delete(s.pending, key)
+		return &SequenceMatch{ScopeKey: key, Events: pending.matches}
+	}
+
+	return nil
+}
+
+func (s *Sequence) expired(pending *pendingSequence, now time.Time) bool {
+	return now.Sub(pending.start) > s.ttl
+}
+
+// expire removes the pending sequences whose window is over. s.lock must be held.
+func (s *Sequence) expire(now time.Time) {
+	for key, pending := range s.pending {
+		if s.expired(pending, now) {
+			delete(s.pending, key)
+		}
+	}
+}
+
+// evictOldest removes the oldest pending sequence. s.lock must be held.
+func (s *Sequence) evictOldest() {
+	var (
+		oldestKey string
+		oldest    *pendingSequence
+	)
+	for key, pending := range s.pending {
+		if oldest == nil || pending.start.Before(oldest.start) {
+			oldestKey, oldest = key, pending
+		}
+	}
+	if oldest != nil {
+		delete(s.pending, oldestKey)
+	}
+}
+
+// ListSequenceIDs returns the list of SequenceIDs from the ruleset
+func (rs *RuleSet) ListSequenceIDs() []SequenceID {
+	var ids []string
+	for id := range rs.sequences {
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+// GetSequences returns the active sequences
+func (rs *RuleSet) GetSequences() map[SequenceID]*Sequence {
+	return rs.sequences
+}
+
+// AddSequences adds sequences to the ruleset and generate the partials of their steps
+func (rs *RuleSet) AddSequences(sequences []*SequenceDefinition) *multierror.Error {
+	var result *multierror.Error
+
+	for _, seqDef := range sequences {
+		if _, err := rs.AddSequence(seqDef); err != nil {
+			result = multierror.Append(result, err)
+		}
+	}
+
+	if err := rs.generatePartials(); err != nil {
+		result = multierror.Append(result, errors.Wrapf(err, "couldn't generate partials for sequence"))
+	}
+
+	return result
+}
+
+// AddSequence creates the evaluators of the steps of the sequence and adds them to the
+// buckets of their events
+func (rs *RuleSet) AddSequence(seqDef *SequenceDefinition) (*Sequence, error) {
+	if seqDef.Disabled {
+		return nil, nil
+	}
+
+	if err := seqDef.Check(); err != nil {
+		return nil, &ErrSequenceLoad{Definition: seqDef, Err: err}
+	}
+
+	for _, id := range rs.opts.ReservedRuleIDs {
+		if id == seqDef.ID {
+			return nil, &ErrSequenceLoad{Definition: seqDef, Err: ErrInternalIDConflict}
+		}
+	}
+
+	if _, exists := rs.sequences[seqDef.ID]; exists {
+		return nil, &ErrSequenceLoad{Definition: seqDef, Err: ErrDefinitionIDConflict}
+	}
+	if _, exists := rs.rules[seqDef.ID]; exists {
+		return nil, &ErrSequenceLoad{Definition: seqDef, Err: ErrDefinitionIDConflict}
+	}
+
+	sequence := &Sequence{
+		ID:         seqDef.ID,
+		Definition: seqDef,
+		Tags:       seqDef.GetTags(),
+		ttl:        seqDef.TTL,
+		maxPending: seqDef.MaxPending,
+		now:        time.Now,
+		pending:    make(map[string]*pendingSequence),
+	}
+	if sequence.ttl == 0 {
+		sequence.ttl = defaultSequenceTTL
+	}
+	if sequence.maxPending == 0 {
+		sequence.maxPending = defaultSequenceMaxPending
+	}
+
+	if seqDef.Scope != "" {
+		if sequence.scope = rs.opts.SequenceScopes[seqDef.Scope]; sequence.scope == nil {
+			return nil, &ErrSequenceLoad{Definition: seqDef, Err: fmt.Errorf("invalid scope '%s'", seqDef.Scope)}
+		}
+	}
+
+	for i, stepDef := range seqDef.Steps {
+		rule := &Rule{
+			Rule: &eval.Rule{
+				ID:         fmt.Sprintf("%s_step_%d", seqDef.ID, i+1),
+				Expression: stepDef.Expression,
+				Tags:       sequence.Tags,
+			},
+			Definition: &RuleDefinition{
+				ID:         fmt.Sprintf("%s_step_%d", seqDef.ID, i+1),
+				Expression: stepDef.Expression,
+				Tags:       seqDef.Tags,
+				Policy:     seqDef.Policy,
+			},
+			sequence: sequence,
+			step:     i,
+		}
+
+		if err := rule.Parse(); err != nil {
+			return nil, &ErrSequenceLoad{Definition: seqDef, Err: errors.Wrapf(err, "syntax error in step %d", i+1)}
+		}
+
+		if err := rule.GenEvaluator(rs.model, &rs.opts.Opts); err != nil {
+			return nil, &ErrSequenceLoad{Definition: seqDef, Err: errors.Wrapf(err, "step %d", i+1)}
+		}
+
+		eventType, err := GetRuleEventType(rule.Rule)
+		if err != nil {
+			return nil, &ErrSequenceLoad{Definition: seqDef, Err: errors.Wrapf(err, "step %d", i+1)}
+		}
+
+		// ignore event types not supported
+		if _, exists := rs.opts.EventTypeEnabled["*"]; !exists {
+			if _, exists := rs.opts.EventTypeEnabled[eventType]; !exists {
+				return nil, &ErrSequenceLoad{Definition: seqDef, Err: ErrEventTypeNotEnabled}
+			}
+		}
+
+		sequence.steps = append(sequence.steps, rule)
+	}
+
+	for _, rule := range sequence.steps {
+		for _, event := range rule.GetEvaluator().EventTypes {
+			bucket, exists := rs.eventRuleBuckets[event]
+			if !exists {
+				bucket = &RuleBucket{}
+				rs.eventRuleBuckets[event] = bucket
+			}
+
+			if err := bucket.AddRule(rule); err != nil {
+				return nil, err
+			}
+		}
+
+		rs.AddFields(rule.GetEvaluator().GetFields())
+	}
+
+	rs.sequences[seqDef.ID] = sequence
+
+	return sequence, nil
+}
+
+// NotifySequenceMatch notifies the ruleset listeners implementing SequenceListener that a
+// sequence matched
+func (rs *RuleSet) NotifySequenceMatch(sequence *Sequence, match *SequenceMatch, event eval.Event) {
+	for _, listener := range rs.listeners {
+		if l, ok := listener.(SequenceListener); ok {
+			l.SequenceMatch(sequence, match, event)
+		}
+	}
+}
+
+// evaluateSequenceSteps advances the sequences of the steps matched by the event, from
+// the last steps to the first ones so that an event doesn't match consecutive steps of a
+// sequence. It returns whether a sequence was completed.
+func (rs *RuleSet) evaluateSequenceSteps(ctx *eval.Context, event eval.Event, steps []*Rule) bool {
+	result := false
+	for i := len(steps) - 1; i >= 0; i-- {
+		step := steps[i]
+		if match := step.sequence.advance(ctx, event, step); match != nil {
+			rs.logger.Tracef("Sequence `%s` matches with event `%s`\n", step.sequence.ID, event)
+
+			rs.NotifySequenceMatch(step.sequence, match, event)
+			result = true
+		}
+	}
+	return result
+}
@@ -0,0 +1,166 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package rules
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/DataDog/datadog-agent/pkg/security/secl/compiler/eval"
+)
+
+type testSequenceHandler struct {
+	testHandler
+	matches []*SequenceMatch
+}
+
+func (h *testSequenceHandler) SequenceMatch(sequence *Sequence, match *SequenceMatch, event eval.Event) {
+	h.matches = append(h.matches, match)
+}
+
+func newSequenceRuleSet(t *testing.T, seqDef *SequenceDefinition) (*RuleSet, *Sequence, *testSequenceHandler) {
+	rs := newRuleSet()
+	rs.opts.WithSequenceScopes(map[Scope]SequenceScopeFnc{
+		// the uid plays the role of the pid, the gid the one of the parent pid
+		"process_tree": func(ctx *eval.Context) []string {
+			process := (*testEvent)(ctx.Object).process
+			return []string{strconv.Itoa(process.uid), strconv.Itoa(process.gid)}
+		},
+	})
+
+	handler := &testSequenceHandler{testHandler: testHandler{model: &testModel{}, filters: make(map[string]testFieldValues)}}
+	rs.AddListener(handler)
+
+	if err := rs.AddSequences([]*SequenceDefinition{seqDef}); err.ErrorOrNil() != nil {
+		t.Fatal(err)
+	}
+
+	return rs, rs.GetSequences()[seqDef.ID], handler
+}
+
+func TestSequence(t *testing.T) {
+	rs, sequence, handler := newSequenceRuleSet(t, &SequenceDefinition{
+		ID:    "shell_then_cron",
+		Scope: "process_tree",
+		TTL:   time.Minute,
+		Steps: []*SequenceStepDefinition{
+			{Expression: `mkdir.filename == "/tmp/shell"`},
+			{Expression: `open.filename =~ "/etc/cron.d/*"`},
+		},
+	})
+
+	now := time.Now()
+	sequence.now = func() time.Time { return now }
+
+	// the second step alone doesn't match
+	rs.Evaluate(&testEvent{kind: "open", open: testOpen{filename: "/etc/cron.d/job"}, process: testProcess{uid: 100}})
+	assert.Empty(t, handler.matches)
+	assert.Equal(t, 0, sequence.PendingCount())
+
+	// the event of the second step isn't a discarder for the first step
+	assert.Empty(t, handler.filters)
+
+	rs.Evaluate(&testEvent{kind: "mkdir", mkdir: testMkdir{filename: "/tmp/shell"}, process: testProcess{uid: 100}})
+	assert.Equal(t, 1, sequence.PendingCount())
+
+	// another process tree
+	rs.Evaluate(&testEvent{kind: "open", open: testOpen{filename: "/etc/cron.d/job"}, process: testProcess{uid: 200, gid: 300}})
+	assert.Empty(t, handler.matches)
+
+	// a child of the process which started the sequence
+	now = now.Add(30 * time.Second)
+	assert.True(t, rs.Evaluate(&testEvent{kind: "open", open: testOpen{filename: "/etc/cron.d/job"}, process: testProcess{uid: 101, gid: 100}}))
+	if assert.Len(t, handler.matches, 1) {
+		match := handler.matches[0]
+		assert.Equal(t, "100", match.ScopeKey)
+		if assert.Len(t, match.Events, 2) {
+			assert.Equal(t, "mkdir", match.Events[0].EventType)
+			assert.Equal(t, "/tmp/shell", match.Events[0].Fields["mkdir.filename"])
+			assert.Equal(t, 2, match.Events[1].Step)
+			assert.Equal(t, "/etc/cron.d/job", match.Events[1].Fields["open.filename"])
+		}
+	}
+	assert.Equal(t, 0, sequence.PendingCount())
+
+	// the window expired
+	rs.Evaluate(&testEvent{kind: "mkdir", mkdir: testMkdir{filename: "/tmp/shell"}, process: testProcess{uid: 100}})
+	now = now.Add(2 * time.Minute)
+	rs.Evaluate(&testEvent{kind: "open", open: testOpen{filename: "/etc/cron.d/job"}, process: testProcess{uid: 100}})
+	assert.Len(t, handler.matches, 1)
+}
+
+func TestSequenceSameEventType(t *testing.T) {
+	rs, _, handler := newSequenceRuleSet(t, &SequenceDefinition{
+		ID: "two_opens",
+		Steps: []*SequenceStepDefinition{
+			{Expression: `open.filename =~ "/etc/*"`},
+			{Expression: `open.filename =~ "/etc/*"`},
+		},
+	})
+
+	// a single event can't match two steps
+	rs.Evaluate(&testEvent{kind: "open", open: testOpen{filename: "/etc/passwd"}})
+	assert.Empty(t, handler.matches)
+
+	rs.Evaluate(&testEvent{kind: "open", open: testOpen{filename: "/etc/shadow"}})
+	if assert.Len(t, handler.matches, 1) {
+		assert.Equal(t, "/etc/passwd", handler.matches[0].Events[0].Fields["open.filename"])
+		assert.Equal(t, "/etc/shadow", handler.matches[0].Events[1].Fields["open.filename"])
+	}
+}
+
+func TestSequenceMaxPending(t *testing.T) {
+	rs, sequence, handler := newSequenceRuleSet(t, &SequenceDefinition{
+		ID:         "bounded",
+		Scope:      "process_tree",
+		MaxPending: 2,
+		Steps: []*SequenceStepDefinition{
+			{Expression: `mkdir.filename == "/tmp/shell"`},
+			{Expression: `open.filename == "/etc/passwd"`},
+		},
+	})
+
+	now := time.Now()
+	sequence.now = func() time.Time { return now }
+
+	for uid := 1; uid <= 3; uid++ {
+		now = now.Add(time.Second)
+		rs.Evaluate(&testEvent{kind: "mkdir", mkdir: testMkdir{filename: "/tmp/shell"}, process: testProcess{uid: uid}})
+	}
+	assert.Equal(t, 2, sequence.PendingCount())
+
+	// the oldest pending sequence was evicted
+	rs.Evaluate(&testEvent{kind: "open", open: testOpen{filename: "/etc/passwd"}, process: testProcess{uid: 1}})
+	assert.Empty(t, handler.matches)
+
+	rs.Evaluate(&testEvent{kind: "open", open: testOpen{filename: "/etc/passwd"}, process: testProcess{uid: 3}})
+	assert.Len(t, handler.matches, 1)
+}
+
+func TestSequenceDefinitionErrors(t *testing.T) {
+	rs := newRuleSet()
+
+	tests := []struct {
+		def *SequenceDefinition
+		err string
+	}{
+		{&SequenceDefinition{ID: "one_step", Steps: []*SequenceStepDefinition{{Expression: `open.filename == "/etc/passwd"`}}}, "at least 2 steps"},
+		{&SequenceDefinition{ID: "bad_scope", Scope: "unknown", Steps: []*SequenceStepDefinition{{Expression: `open.filename == "/a"`}, {Expression: `open.filename == "/b"`}}}, "invalid scope"},
+		{&SequenceDefinition{ID: "bad_step", Steps: []*SequenceStepDefinition{{Expression: `open.filename == "/a"`}, {Expression: `open.filename ==`}}}, "step 2"},
+		{&SequenceDefinition{ID: "multiple_events", Steps: []*SequenceStepDefinition{{Expression: `open.filename == "/a"`}, {Expression: `open.filename == "/a" && mkdir.filename == "/b"`}}}, "step 2"},
+	}
+
+	for _, test := range tests {
+		_, err := rs.AddSequence(test.def)
+		if assert.Error(t, err, test.def.ID) {
+			assert.True(t, strings.Contains(err.Error(), test.err), err.Error())
+		}
+	}
+}
@@ -0,0 +1,126 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build functionaltests
+// +build functionaltests
+
+package tests
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/dns/dnsmessage"
+	"golang.org/x/sys/unix"
+
+	sprobe "github.com/DataDog/datadog-agent/pkg/security/probe"
+	"github.com/DataDog/datadog-agent/pkg/security/secl/rules"
+)
+
+func TestNetworkEvents(t *testing.T) {
+	ruleDefs := []*rules.RuleDefinition{
+		{
+			ID:         "test_bind_af_inet",
+			Expression: `bind.addr.family == AF_INET && bind.addr.ip == 127.0.0.1 && bind.addr.port == 4242 && process.file.name == "testsuite"`,
+		},
+		{
+			ID:         "test_connect_af_inet",
+			Expression: `connect.addr.family == AF_INET && connect.addr.ip in [ 127.0.0.0/8 ] && connect.addr.port == 4242 && process.file.name == "testsuite"`,
+		},
+		{
+			ID:         "test_dns",
+			Expression: `dns.question.type == A && dns.question.name == "testsuite.example.com" && process.file.name == "testsuite"`,
+		},
+	}
+
+	test, err := newTestModule(t, nil, ruleDefs, testOpts{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer test.Close()
+
+	var listener net.Listener
+	defer func() {
+		if listener != nil {
+			listener.Close()
+		}
+	}()
+
+	t.Run("bind", func(t *testing.T) {
+		test.WaitSignal(t, func() error {
+			listener, err = net.Listen("tcp4", "127.0.0.1:4242")
+			return err
+		}, func(event *sprobe.Event, r *rules.Rule) {
+			assert.Equal(t, "bind", event.GetType(), "wrong event type")
+			assert.Equal(t, uint16(unix.AF_INET), event.Bind.AddrFamily, "wrong address family")
+			assert.Equal(t, "127.0.0.1", event.Bind.Addr.IPNet.IP.String(), "wrong address")
+			assert.Equal(t, uint16(4242), event.Bind.Addr.Port, "wrong port")
+			assert.Equal(t, int64(0), event.Bind.Retval, "wrong retval")
+
+			if !validateBindSchema(t, event) {
+				t.Error(event.String())
+			}
+		})
+	})
+
+	t.Run("connect", func(t *testing.T) {
+		test.WaitSignal(t, func() error {
+			conn, err := net.Dial("tcp4", "127.0.0.1:4242")
+			if err != nil {
+				return err
+			}
+			return conn.Close()
+		}, func(event *sprobe.Event, r *rules.Rule) {
+			assert.Equal(t, "connect", event.GetType(), "wrong event type")
+			assert.Equal(t, uint16(unix.AF_INET), event.Connect.AddrFamily, "wrong address family")
+			assert.Equal(t, "127.0.0.1", event.Connect.Addr.IPNet.IP.String(), "wrong address")
+			assert.Equal(t, uint16(4242), event.Connect.Addr.Port, "wrong port")
+
+			if !validateConnectSchema(t, event) {
+				t.Error(event.String())
+			}
+		})
+	})
+
+	t.Run("dns", func(t *testing.T) {
+		test.WaitSignal(t, func() error {
+			builder := dnsmessage.NewBuilder(nil, dnsmessage.Header{ID: 4242, RecursionDesired: true})
+			if err := builder.StartQuestions(); err != nil {
+				return err
+			}
+			if err := builder.Question(dnsmessage.Question{
+				Name:  dnsmessage.MustNewName("testsuite.example.com."),
+				Type:  dnsmessage.TypeA,
+				Class: dnsmessage.ClassINET,
+			}); err != nil {
+				return err
+			}
+			msg, err := builder.Finish()
+			if err != nil {
+				return err
+			}
+
+			// the request doesn't need to be answered
+			conn, err := net.Dial("udp4", "127.0.0.1:53")
+			if err != nil {
+				return err
+			}
+			defer conn.Close()
+
+			_, err = conn.Write(msg)
+			return err
+		}, func(event *sprobe.Event, r *rules.Rule) {
+			assert.Equal(t, "dns", event.GetType(), "wrong event type")
+			assert.Equal(t, uint16(4242), event.DNS.ID, "wrong request id")
+			assert.Equal(t, uint16(1), event.DNS.Count, "wrong question count")
+			assert.Equal(t, "127.0.0.1", event.DNS.Server.IPNet.IP.String(), "wrong server")
+
+			if !validateDNSSchema(t, event) {
+				t.Error(event.String())
+			}
+		})
+	})
+}
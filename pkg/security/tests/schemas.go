@@ -132,3 +132,15 @@ func validateUnloadModuleSchema(t *testing.T, event *sprobe.Event) bool {
 func validateSignalSchema(t *testing.T, event *sprobe.Event) bool {
 	return validateSchema(t, event, "file:///schemas/signal.schema.json")
 }
+
+func validateConnectSchema(t *testing.T, event *sprobe.Event) bool {
+	return validateSchema(t, event, "file:///schemas/connect.schema.json")
+}
+
+func validateBindSchema(t *testing.T, event *sprobe.Event) bool {
+	return validateSchema(t, event, "file:///schemas/bind.schema.json")
+}
+
+func validateDNSSchema(t *testing.T, event *sprobe.Event) bool {
+	return validateSchema(t, event, "file:///schemas/dns.schema.json")
+}
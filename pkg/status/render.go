@@ -86,10 +86,9 @@ func FormatStatus(data []byte) (string, error) {
 		}
 	}
 	autodiscoveryFunc := func() {
-		if config.IsContainerized() {
-			renderAutodiscoveryStats(b, stats["adEnabledFeatures"], stats["adConfigErrors"],
-				stats["filterErrors"])
-		}
+		// config providers errors are also reported outside of containers
+		renderAutodiscoveryStats(b, stats["adEnabledFeatures"], stats["adConfigErrors"],
+			stats["filterErrors"])
 	}
 
 	var renderFuncs []func()
@@ -87,11 +87,11 @@ func GetStatus() (map[string]interface{}, error) {
 		httputils.NoProxyMapMutex.Unlock()
 	}
 
+	if common.AC != nil {
+		stats["adConfigErrors"] = common.AC.GetAutodiscoveryErrors()
+	}
 	if config.IsContainerized() {
 		stats["adEnabledFeatures"] = config.GetDetectedFeatures()
-		if common.AC != nil {
-			stats["adConfigErrors"] = common.AC.GetAutodiscoveryErrors()
-		}
 		stats["filterErrors"] = containers.GetFilterErrors()
 	}
 
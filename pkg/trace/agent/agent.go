@@ -46,6 +46,7 @@ type Agent struct {
 	PrioritySampler       *sampler.PrioritySampler
 	ErrorsSampler         *sampler.ErrorsSampler
 	RareSampler           *sampler.RareSampler
+	LatencySampler        *sampler.LatencySampler
 	NoPrioritySampler     *sampler.NoPrioritySampler
 	EventProcessor        *event.Processor
 	TraceWriter           *writer.TraceWriter
@@ -88,6 +89,7 @@ func NewAgent(ctx context.Context, conf *config.AgentConfig) *Agent {
 		PrioritySampler:       sampler.NewPrioritySampler(conf, dynConf),
 		ErrorsSampler:         sampler.NewErrorsSampler(conf),
 		RareSampler:           sampler.NewRareSampler(),
+		LatencySampler:        sampler.NewLatencySampler(conf),
 		NoPrioritySampler:     sampler.NewNoPrioritySampler(conf),
 		EventProcessor:        newEventProcessor(conf),
 		TraceWriter:           writer.NewTraceWriter(conf),
@@ -176,6 +178,7 @@ func (a *Agent) loop() {
 				a.ErrorsSampler,
 				a.NoPrioritySampler,
 				a.RareSampler,
+				a.LatencySampler,
 				a.EventProcessor,
 				a.OTLPReceiver,
 				a.obfuscator,
@@ -324,6 +327,7 @@ func (a *Agent) Process(p *api.Payload) {
 			ss.TracerPayload = p.TracerPayload.Cut(i)
 			i = 0
 			ss.TracerPayload.Chunks = newChunksArray(ss.TracerPayload.Chunks)
+			a.Receiver.Tap.Publish(ss.TracerPayload)
 			a.TraceWriter.In <- ss
 			ss = new(writer.SampledChunks)
 		}
@@ -331,6 +335,7 @@ func (a *Agent) Process(p *api.Payload) {
 	ss.TracerPayload = p.TracerPayload
 	ss.TracerPayload.Chunks = newChunksArray(p.TracerPayload.Chunks)
 	if ss.Size > 0 {
+		a.Receiver.Tap.Publish(ss.TracerPayload)
 		a.TraceWriter.In <- ss
 	}
 	if len(statsInput.Traces) > 0 {
@@ -444,10 +449,19 @@ func (a *Agent) runSamplers(now time.Time, pt traceutil.ProcessedTrace, hasPrior
 }
 
 // samplePriorityTrace samples traces with priority set on them. PrioritySampler and
-// ErrorSampler are run in parallel. The RareSampler catches traces with rare top-level
-// or measured spans that are not caught by PrioritySampler and ErrorSampler.
+// ErrorSampler are run in parallel. The LatencySampler catches traces with slow top-level
+// or measured spans that are not caught by PrioritySampler. The RareSampler catches traces
+// with rare top-level or measured spans that are not caught by the other samplers.
 func (a *Agent) samplePriorityTrace(now time.Time, pt traceutil.ProcessedTrace) bool {
-	if a.PrioritySampler.Sample(now, pt.TraceChunk, pt.Root, pt.TracerEnv, pt.ClientDroppedP0sWeight) {
+	sampled, ruleMatched := a.PrioritySampler.SampleWithRules(now, pt.TraceChunk, pt.Root, pt.TracerEnv, pt.ClientDroppedP0sWeight)
+	// the latency sampler sees all traces so that it tracks unbiased latency distributions,
+	// but it can't keep the traces dropped by an agent sampling rule
+	if ruleMatched {
+		a.LatencySampler.Record(now, pt.TraceChunk, pt.TracerEnv)
+	} else if a.LatencySampler.Sample(now, pt.TraceChunk, pt.TracerEnv) {
+		return true
+	}
+	if sampled {
 		return true
 	}
 	if traceContainsError(pt.TraceChunk.Spans) {
@@ -850,6 +850,7 @@ func TestSampling(t *testing.T) {
 				ErrorsSampler:     sampler.NewErrorsSampler(cfg),
 				PrioritySampler:   sampler.NewPrioritySampler(cfg, &sampler.DynamicConfig{}),
 				RareSampler:       sampler.NewRareSampler(),
+				LatencySampler:    sampler.NewLatencySampler(cfg),
 				conf:              cfg,
 			}
 			if tt.errorsSampled {
@@ -884,6 +885,57 @@ func TestSampling(t *testing.T) {
 	}
 }
 
+func TestSamplingAgentRuleLatency(t *testing.T) {
+	zero := 0.0
+	cfg := &config.AgentConfig{
+		DisableRareSampler: true,
+		LatencyTPS:         10,
+		LatencyPercentile:  0.9,
+		SamplingRules:      []*config.SamplingRule{{Resource: "GET /health", SampleRate: &zero}},
+	}
+	a := &Agent{
+		NoPrioritySampler: sampler.NewNoPrioritySampler(cfg),
+		ErrorsSampler:     sampler.NewErrorsSampler(cfg),
+		PrioritySampler:   sampler.NewPrioritySampler(cfg, &sampler.DynamicConfig{}),
+		RareSampler:       sampler.NewRareSampler(),
+		LatencySampler:    sampler.NewLatencySampler(cfg),
+		conf:              cfg,
+	}
+	defer a.LatencySampler.Stop()
+
+	newTrace := func(resource string, d time.Duration) traceutil.ProcessedTrace {
+		root := &pb.Span{
+			TraceID:  1,
+			Service:  "serv1",
+			Name:     "http.request",
+			Resource: resource,
+			Duration: d.Nanoseconds(),
+			Metrics:  map[string]float64{"_top_level": 1},
+		}
+		chunk := testutil.TraceChunkWithSpan(root)
+		chunk.Priority = int32(sampler.PriorityAutoDrop)
+		return traceutil.ProcessedTrace{TraceChunk: chunk, Root: root}
+	}
+
+	// fill a latency window with latencies between 1 and 100ms
+	now := time.Now()
+	for i := 0; i < 200; i++ {
+		d := time.Duration(1+i%100) * time.Millisecond
+		a.runSamplers(now, newTrace("GET /", d), true)
+		a.runSamplers(now, newTrace("GET /health", d), true)
+	}
+	now = now.Add(time.Minute)
+
+	// slow traces are kept by the latency sampler
+	assert.True(t, a.runSamplers(now, newTrace("GET /", time.Second), true))
+
+	// unless they are dropped by an agent sampling rule
+	pt := newTrace("GET /health", time.Second)
+	assert.False(t, a.runSamplers(now, pt, true))
+	assert.EqualValues(t, sampler.PriorityAutoDrop, pt.TraceChunk.Priority)
+	assert.NotContains(t, pt.TraceChunk.Tags, "_dd.p.dm")
+}
+
 func TestPartialSamplingFree(t *testing.T) {
 	cfg := &config.AgentConfig{DisableRareSampler: true, BucketInterval: 10 * time.Second}
 	statsChan := make(chan pb.StatsPayload, 100)
@@ -899,6 +951,7 @@ func TestPartialSamplingFree(t *testing.T) {
 		PrioritySampler:   sampler.NewPrioritySampler(cfg, &sampler.DynamicConfig{}),
 		EventProcessor:    newEventProcessor(cfg),
 		RareSampler:       sampler.NewRareSampler(),
+		LatencySampler:    sampler.NewLatencySampler(cfg),
 		TraceWriter:       &writer.TraceWriter{In: writerChan},
 		conf:              cfg,
 	}
@@ -0,0 +1,117 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package agent
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/trace/api"
+	"github.com/DataDog/datadog-agent/pkg/trace/config"
+)
+
+// replayStats holds the outcome of a replay.
+type replayStats struct {
+	sent, failed int
+}
+
+// runReplay implements the "replay" command, which sends the payloads of a capture
+// file recorded through the /debug/capture endpoint to a running agent.
+func runReplay(ctx context.Context, cfg *config.AgentConfig, args []string) error {
+	fs := flag.NewFlagSet("replay", flag.ContinueOnError)
+	speed := fs.Float64("speed", 1, "Replay speed multiplier relative to the original pace; 0 sends payloads as fast as possible")
+	addr := fs.String("addr", fmt.Sprintf("%s:%d", cfg.ReceiverHost, cfg.ReceiverPort), "Address of the trace-agent receiving the payloads")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: trace-agent replay [-speed N] [-addr host:port] <capture file>")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return fmt.Errorf("expected a capture file")
+	}
+	if *speed < 0 {
+		return fmt.Errorf("speed must be positive")
+	}
+	rd, err := api.OpenCapture(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	defer rd.Close()
+	stats, err := replay(ctx, rd, "http://"+*addr, *speed, &http.Client{Timeout: 10 * time.Second})
+	fmt.Printf("Replayed %d payloads, %d failed.\n", stats.sent, stats.failed)
+	return err
+}
+
+// replay sends the payloads read from rd to the agent at baseURL, preserving the
+// original interval between payloads divided by speed. A zero speed sends them
+// without waiting.
+func replay(ctx context.Context, rd *api.CaptureReader, baseURL string, speed float64, client *http.Client) (replayStats, error) {
+	var (
+		stats replayStats
+		first time.Time // time of the first payload of the capture
+		start = time.Now()
+	)
+	for {
+		rec, err := rd.Next()
+		if err == io.EOF {
+			return stats, nil
+		}
+		if err != nil {
+			return stats, err
+		}
+		if first.IsZero() {
+			first = rec.Time
+		}
+		if speed > 0 {
+			at := start.Add(time.Duration(float64(rec.Time.Sub(first)) / speed))
+			select {
+			case <-time.After(time.Until(at)):
+			case <-ctx.Done():
+				return stats, ctx.Err()
+			}
+		}
+		if err := sendRecord(ctx, client, baseURL, rec); err != nil {
+			stats.failed++
+			fmt.Printf("Error replaying payload to %s: %v\n", rec.Path, err)
+			continue
+		}
+		stats.sent++
+	}
+}
+
+// sendRecord sends the payload recorded in rec to the agent at baseURL.
+func sendRecord(ctx context.Context, client *http.Client, baseURL string, rec *api.CaptureRecord) error {
+	req, err := http.NewRequest(http.MethodPost, baseURL+rec.Path, bytes.NewReader(rec.Body))
+	if err != nil {
+		return err
+	}
+	req = req.WithContext(ctx)
+	for k, v := range rec.Header {
+		req.Header[k] = v
+	}
+	// the body is sent as recorded
+	req.Header.Del("Content-Length")
+	req.Header.Del("Transfer-Encoding")
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(ioutil.Discard, resp.Body)
+	if resp.StatusCode >= 400 {
+		return fmt.Errorf("server responded with %q", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package agent
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/trace/api"
+)
+
+func TestReplay(t *testing.T) {
+	// write a capture file with payloads received 20ms apart
+	path := filepath.Join(t.TempDir(), "capture.jsonl.gz")
+	f, err := os.Create(path)
+	require.NoError(t, err)
+	gz := gzip.NewWriter(f)
+	enc := json.NewEncoder(gz)
+	now := time.Now()
+	for i, body := range []string{"payload-1", "payload-2", "fail"} {
+		require.NoError(t, enc.Encode(&api.CaptureRecord{
+			Time:        now.Add(time.Duration(i) * 20 * time.Millisecond),
+			Path:        "/v0.4/traces",
+			Version:     "v0.4",
+			ContentType: "application/msgpack",
+			Header:      http.Header{"Content-Type": []string{"application/msgpack"}},
+			Body:        []byte(body),
+		}))
+	}
+	require.NoError(t, gz.Close())
+	require.NoError(t, f.Close())
+
+	var (
+		mu       sync.Mutex
+		received []string
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, _ := ioutil.ReadAll(req.Body)
+		mu.Lock()
+		received = append(received, req.URL.Path+" "+req.Header.Get("Content-Type")+" "+string(body))
+		mu.Unlock()
+		if string(body) == "fail" {
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	defer server.Close()
+
+	for _, tt := range []struct {
+		speed   float64
+		minTime time.Duration
+	}{
+		{speed: 1, minTime: 40 * time.Millisecond},
+		{speed: 0},
+	} {
+		received = nil
+		rd, err := api.OpenCapture(path)
+		require.NoError(t, err)
+		start := time.Now()
+		stats, err := replay(context.Background(), rd, server.URL, tt.speed, server.Client())
+		rd.Close()
+		require.NoError(t, err)
+		assert.True(t, time.Since(start) >= tt.minTime)
+		assert.Equal(t, replayStats{sent: 2, failed: 1}, stats)
+		assert.Equal(t, []string{
+			"/v0.4/traces application/msgpack payload-1",
+			"/v0.4/traces application/msgpack payload-2",
+			"/v0.4/traces application/msgpack fail",
+		}, received)
+	}
+}
@@ -7,11 +7,15 @@ package agent
 
 import (
 	"context"
+	"flag"
 	"fmt"
+	"io"
 	"math/rand"
+	"net"
 	"os"
 	"runtime"
 	"runtime/pprof"
+	"strings"
 	"time"
 
 	"github.com/DataDog/datadog-agent/cmd/manager"
@@ -27,6 +31,7 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/trace/metrics"
 	"github.com/DataDog/datadog-agent/pkg/trace/metrics/timing"
 	"github.com/DataDog/datadog-agent/pkg/trace/osutil"
+	"github.com/DataDog/datadog-agent/pkg/trace/tap"
 	"github.com/DataDog/datadog-agent/pkg/trace/watchdog"
 	"github.com/DataDog/datadog-agent/pkg/util"
 	"github.com/DataDog/datadog-agent/pkg/util/log"
@@ -77,6 +82,20 @@ func Run(ctx context.Context) {
 		return
 	}
 
+	if flag.Arg(0) == "replay" {
+		if err := runReplay(ctx, cfg, flag.Args()[1:]); err != nil {
+			osutil.Exitf("Failed to replay capture: %s", err)
+		}
+		return
+	}
+
+	if flags.Tap {
+		if err := runTap(ctx, cfg); err != nil {
+			osutil.Exitf("Failed to tap traces: %s", err)
+		}
+		return
+	}
+
 	if err := coreconfig.SetupLogger(
 		coreconfig.LoggerName("TRACE"),
 		cfg.LogLevel,
@@ -199,3 +218,32 @@ func Run(ctx context.Context) {
 		f.Close()
 	}
 }
+
+// runTap streams the chunks sent to the intake by the running agent to the
+// destination given by flags.TapOut, until ctx is cancelled.
+func runTap(ctx context.Context, cfg *config.AgentConfig) error {
+	var out io.Writer = os.Stdout
+	switch {
+	case strings.HasPrefix(flags.TapOut, "unix://"):
+		conn, err := net.Dial("unix", strings.TrimPrefix(flags.TapOut, "unix://"))
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+		out = conn
+	case flags.TapOut != "":
+		f, err := os.OpenFile(flags.TapOut, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		out = f
+	}
+	filter := tap.Filter{
+		Service:  flags.TapService,
+		Resource: flags.TapResource,
+		TraceID:  flags.TapTraceID,
+	}
+	addr := fmt.Sprintf("http://%s:%d/debug/tap", cfg.ReceiverHost, cfg.ReceiverPort)
+	return tap.Stream(ctx, addr, filter, flags.TapBuffer, out)
+}
@@ -47,6 +47,7 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/trace/osutil"
 	"github.com/DataDog/datadog-agent/pkg/trace/pb"
 	"github.com/DataDog/datadog-agent/pkg/trace/sampler"
+	"github.com/DataDog/datadog-agent/pkg/trace/tap"
 	"github.com/DataDog/datadog-agent/pkg/trace/watchdog"
 	"github.com/DataDog/datadog-agent/pkg/util/grpc"
 	"github.com/DataDog/datadog-agent/pkg/util/log"
@@ -73,6 +74,10 @@ func putBuffer(buffer *bytes.Buffer) {
 type HTTPReceiver struct {
 	Stats       *info.ReceiverStats
 	RateLimiter *rateLimiter
+	// Tap receives the chunks sent to the intake and streams them to /debug/tap subscribers.
+	Tap *tap.Tap
+	// Capture records the received payloads to files when requested through /debug/capture.
+	Capture *Capture
 
 	out             chan *Payload
 	conf            *config.AgentConfig
@@ -115,6 +120,8 @@ func NewHTTPReceiver(conf *config.AgentConfig, dynConf *sampler.DynamicConfig, o
 	return &HTTPReceiver{
 		Stats:       info.NewReceiverStats(),
 		RateLimiter: newRateLimiter(),
+		Tap:         tap.New(),
+		Capture:     NewCapture(conf.CapturePath, conf.MaxRequestBytes),
 
 		out:             out,
 		statsProcessor:  statsProcessor,
@@ -301,6 +308,8 @@ func (r *HTTPReceiver) Stop() error {
 	<-r.exit
 
 	r.RateLimiter.Stop()
+	r.Tap.Stop()
+	r.Capture.Stop()
 
 	expiry := time.Now().Add(5 * time.Second) // give it 5 seconds
 	ctx, cancel := context.WithDeadline(context.Background(), expiry)
@@ -321,6 +330,8 @@ func (r *HTTPReceiver) handleWithVersion(v Version, f func(Version, http.Respons
 			return
 		}
 
+		r.Capture.record(v, req)
+
 		// TODO(x): replace with http.MaxBytesReader?
 		req.Body = apiutil.NewLimitedReader(req.Body, r.conf.MaxRequestBytes)
 
@@ -502,6 +513,7 @@ func (r *HTTPReceiver) handleStats(w http.ResponseWriter, req *http.Request) {
 	defer timing.Since("datadog.trace_agent.receiver.stats_process_ms", time.Now())
 
 	ts := r.tagStats(v07, req.Header)
+	r.Capture.record("v0.6", req)
 	rd := apiutil.NewLimitedReader(req.Body, r.conf.MaxRequestBytes)
 	req.Header.Set("Accept", "application/msgpack")
 	var in pb.ClientStatsPayload
@@ -0,0 +1,263 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package api
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/trace/metrics"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const (
+	// defaultCaptureDuration is the duration of a capture when none is specified.
+	defaultCaptureDuration = time.Minute
+	// maxCaptureDuration is the maximum duration of a capture.
+	maxCaptureDuration = time.Hour
+	// captureFileTemplate is the name of capture files, formatted with the start time.
+	captureFileTemplate = "trace-capture-%d.jsonl.gz"
+)
+
+// ErrCaptureInProgress is returned when starting a capture while another one is running.
+var ErrCaptureInProgress = errors.New("a capture is already in progress")
+
+// CaptureRecord is a payload received by the agent, as recorded in a capture file.
+type CaptureRecord struct {
+	// Time is the time at which the payload was received.
+	Time time.Time `json:"time"`
+	// Path is the path of the endpoint which received the payload, e.g. /v0.4/traces.
+	Path string `json:"path"`
+	// Version is the API version of the endpoint.
+	Version Version `json:"version"`
+	// ContentType is the content type of the payload.
+	ContentType string `json:"content_type"`
+	// Header holds the headers of the request.
+	Header http.Header `json:"header"`
+	// Body holds the raw payload.
+	Body []byte `json:"body"`
+}
+
+// Capture records the payloads received by the agent to files, so that they can later
+// be replayed against another agent. Only one capture may run at a time. It is safe for
+// concurrent use.
+type Capture struct {
+	dir     string
+	maxSize int64 // maximum size of a recorded payload
+
+	active int32 // atomic; 1 when a capture is running
+
+	mu      sync.Mutex
+	path    string // path of the current capture file
+	f       *os.File
+	gz      *gzip.Writer
+	w       *bufio.Writer
+	timer   *time.Timer
+	records int64
+}
+
+// NewCapture returns a new Capture writing files to dir. Payloads larger than maxSize
+// bytes are not recorded.
+func NewCapture(dir string, maxSize int64) *Capture {
+	return &Capture{dir: dir, maxSize: maxSize}
+}
+
+// Active reports whether a capture is running.
+func (c *Capture) Active() bool {
+	return atomic.LoadInt32(&c.active) == 1
+}
+
+// Start starts a capture which stops after d, or when Stop is called. It returns the
+// path of the capture file.
+func (c *Capture) Start(d time.Duration) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.f != nil {
+		return "", ErrCaptureInProgress
+	}
+	if err := os.MkdirAll(c.dir, 0700); err != nil {
+		return "", fmt.Errorf("error creating capture directory: %v", err)
+	}
+	path := filepath.Join(c.dir, fmt.Sprintf(captureFileTemplate, time.Now().UnixNano()))
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+	if err != nil {
+		return "", fmt.Errorf("error creating capture file: %v", err)
+	}
+	c.path = path
+	c.f = f
+	c.gz = gzip.NewWriter(f)
+	c.w = bufio.NewWriter(c.gz)
+	c.records = 0
+	c.timer = time.AfterFunc(d, func() { c.Stop() })
+	atomic.StoreInt32(&c.active, 1)
+	log.Infof("Started capturing payloads to %s for %s", path, d)
+	return path, nil
+}
+
+// Stop stops the running capture, if any, and returns the path of its file along
+// with the number of payloads recorded.
+func (c *Capture) Stop() (path string, records int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.f == nil {
+		return "", 0
+	}
+	atomic.StoreInt32(&c.active, 0)
+	c.timer.Stop()
+	if err := c.w.Flush(); err != nil {
+		log.Errorf("Error writing capture file: %v", err)
+	}
+	if err := c.gz.Close(); err != nil {
+		log.Errorf("Error writing capture file: %v", err)
+	}
+	if err := c.f.Close(); err != nil {
+		log.Errorf("Error closing capture file: %v", err)
+	}
+	path, records = c.path, c.records
+	c.f, c.gz, c.w, c.timer, c.path = nil, nil, nil, nil, ""
+	log.Infof("Stopped capturing payloads: %d payloads written to %s", records, path)
+	return path, records
+}
+
+// record records the payload of req, received by the endpoint of version v, if a
+// capture is running. The body of req is replaced so that it can still be read.
+func (c *Capture) record(v Version, req *http.Request) {
+	if !c.Active() {
+		return
+	}
+	body, err := ioutil.ReadAll(io.LimitReader(req.Body, c.maxSize+1))
+	req.Body = &readCloser{Reader: io.MultiReader(bytes.NewReader(body), req.Body), Closer: req.Body}
+	if err != nil || int64(len(body)) > c.maxSize {
+		// this payload will be rejected anyway
+		return
+	}
+	line, err := json.Marshal(&CaptureRecord{
+		Time:        time.Now(),
+		Path:        req.URL.Path,
+		Version:     v,
+		ContentType: req.Header.Get("Content-Type"),
+		Header:      req.Header,
+		Body:        body,
+	})
+	if err != nil {
+		log.Errorf("Error encoding captured payload: %v", err)
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.w == nil {
+		// the capture stopped in the meantime
+		return
+	}
+	c.w.Write(line)
+	if err := c.w.WriteByte('\n'); err != nil {
+		log.Errorf("Error writing capture file, stopping capture: %v", err)
+		go c.Stop()
+		return
+	}
+	c.records++
+	metrics.Count("datadog.trace_agent.receiver.captured_payloads", 1, nil, 1)
+}
+
+// readCloser combines a Reader and a Closer.
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// Handler returns the handler of the /debug/capture endpoint. A POST request starts a
+// capture for the duration given by the "duration" query parameter, a DELETE request
+// stops it. Both reply with the path of the capture file.
+func (c *Capture) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		var resp struct {
+			Path     string `json:"path"`
+			Duration string `json:"duration,omitempty"`
+			Records  int64  `json:"records"`
+		}
+		switch req.Method {
+		case http.MethodPost:
+			d := defaultCaptureDuration
+			if v := req.URL.Query().Get("duration"); v != "" {
+				var err error
+				if d, err = time.ParseDuration(v); err != nil || d <= 0 || d > maxCaptureDuration {
+					http.Error(w, fmt.Sprintf("duration must be a positive duration up to %s", maxCaptureDuration), http.StatusBadRequest)
+					return
+				}
+			}
+			path, err := c.Start(d)
+			if err == ErrCaptureInProgress {
+				http.Error(w, err.Error(), http.StatusConflict)
+				return
+			}
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			resp.Path, resp.Duration = path, d.String()
+		case http.MethodDelete:
+			resp.Path, resp.Records = c.Stop()
+		default:
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(resp)
+	})
+}
+
+// CaptureReader reads the payloads recorded in a capture file.
+type CaptureReader struct {
+	f   *os.File
+	gz  *gzip.Reader
+	dec *json.Decoder
+}
+
+// OpenCapture opens the capture file at path.
+func OpenCapture(path string) (*CaptureReader, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	gz, err := gzip.NewReader(bufio.NewReader(f))
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("invalid capture file: %v", err)
+	}
+	return &CaptureReader{f: f, gz: gz, dec: json.NewDecoder(gz)}, nil
+}
+
+// Next returns the next payload of the capture. It returns io.EOF when all payloads
+// have been read.
+func (r *CaptureReader) Next() (*CaptureRecord, error) {
+	var rec CaptureRecord
+	if err := r.dec.Decode(&rec); err != nil {
+		if err == io.ErrUnexpectedEOF {
+			// the capture was interrupted while being written
+			return nil, io.EOF
+		}
+		return nil, err
+	}
+	return &rec, nil
+}
+
+// Close closes the capture file.
+func (r *CaptureReader) Close() error {
+	r.gz.Close()
+	return r.f.Close()
+}
@@ -0,0 +1,104 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package api
+
+import (
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCapture(t *testing.T) {
+	c := NewCapture(t.TempDir(), 10)
+	newRequest := func(body string) *http.Request {
+		req := httptest.NewRequest("POST", "/v0.4/traces", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/msgpack")
+		req.Header.Set(headerLang, "go")
+		return req
+	}
+
+	// nothing is recorded while no capture runs
+	req := newRequest("ignored")
+	c.record(v04, req)
+	assert.False(t, c.Active())
+
+	path, err := c.Start(time.Minute)
+	require.NoError(t, err)
+	assert.True(t, c.Active())
+	_, err = c.Start(time.Minute)
+	assert.Equal(t, ErrCaptureInProgress, err)
+
+	for _, body := range []string{"first", "too large payload", "second"} {
+		req := newRequest(body)
+		c.record(v04, req)
+		// the body can still be read by the handler
+		data, err := ioutil.ReadAll(req.Body)
+		require.NoError(t, err)
+		assert.Equal(t, body, string(data))
+	}
+	p, n := c.Stop()
+	assert.Equal(t, path, p)
+	assert.EqualValues(t, 2, n)
+	assert.False(t, c.Active())
+
+	rd, err := OpenCapture(path)
+	require.NoError(t, err)
+	defer rd.Close()
+	for _, body := range []string{"first", "second"} {
+		rec, err := rd.Next()
+		require.NoError(t, err)
+		assert.Equal(t, body, string(rec.Body))
+		assert.Equal(t, "/v0.4/traces", rec.Path)
+		assert.Equal(t, v04, rec.Version)
+		assert.Equal(t, "application/msgpack", rec.ContentType)
+		assert.Equal(t, "go", rec.Header.Get(headerLang))
+	}
+	_, err = rd.Next()
+	assert.Equal(t, io.EOF, err)
+}
+
+func TestCaptureExpires(t *testing.T) {
+	c := NewCapture(t.TempDir(), 10)
+	_, err := c.Start(10 * time.Millisecond)
+	require.NoError(t, err)
+	assert.Eventually(t, func() bool { return !c.Active() }, time.Second, 5*time.Millisecond)
+}
+
+func TestCaptureHandler(t *testing.T) {
+	c := NewCapture(t.TempDir(), 10)
+	defer c.Stop()
+	h := c.Handler()
+	do := func(method, url string) (int, map[string]interface{}) {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(method, url, nil))
+		var resp map[string]interface{}
+		json.Unmarshal(rec.Body.Bytes(), &resp)
+		return rec.Code, resp
+	}
+
+	code, _ := do("POST", "/debug/capture?duration=2h")
+	assert.Equal(t, http.StatusBadRequest, code)
+	code, resp := do("POST", "/debug/capture?duration=1m")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "1m0s", resp["duration"])
+	path := resp["path"]
+	assert.NotEmpty(t, path)
+	code, _ = do("POST", "/debug/capture")
+	assert.Equal(t, http.StatusConflict, code)
+	code, resp = do("DELETE", "/debug/capture")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, path, resp["path"])
+	code, _ = do("GET", "/debug/capture")
+	assert.Equal(t, http.StatusMethodNotAllowed, code)
+}
@@ -117,4 +117,16 @@ var endpoints = []endpoint{
 		Handler:   func(r *HTTPReceiver) http.Handler { return http.HandlerFunc(r.handleGetConfig) },
 		IsEnabled: func(_ *config.AgentConfig) bool { return features.Has("config_endpoint") },
 	},
+	{
+		Pattern:   "/debug/tap",
+		Handler:   func(r *HTTPReceiver) http.Handler { return r.Tap.Handler() },
+		Hidden:    true,
+		IsEnabled: func(cfg *config.AgentConfig) bool { return cfg.TapEnabled },
+	},
+	{
+		Pattern:   "/debug/capture",
+		Handler:   func(r *HTTPReceiver) http.Handler { return r.Capture.Handler() },
+		Hidden:    true,
+		IsEnabled: func(cfg *config.AgentConfig) bool { return cfg.CaptureEnabled },
+	},
 }
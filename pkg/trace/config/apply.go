@@ -10,6 +10,7 @@ import (
 	"errors"
 	"fmt"
 	"net/url"
+	"path/filepath"
 	"regexp"
 	"strconv"
 	"strings"
@@ -206,6 +207,52 @@ type WriterConfig struct {
 	// FlushPeriodSeconds specifies the frequency at which the writer's buffer
 	// will be flushed to the sender, in seconds. Fractions are permitted.
 	FlushPeriodSeconds float64 `mapstructure:"flush_period_seconds"`
+
+	// Spool specifies the configuration for persisting payloads to disk when
+	// they can not be sent.
+	Spool SpoolConfig `mapstructure:"spool"`
+}
+
+// SpoolConfig specifies the configuration for persisting a writer's payloads to disk
+// instead of dropping them when the sender's queue is full or when the agent stops.
+// Spooled payloads are replayed once the intake is reachable again, including after
+// a restart.
+type SpoolConfig struct {
+	// Enabled reports whether payloads should be spooled to disk.
+	Enabled bool `mapstructure:"enabled"`
+
+	// Path specifies the directory in which payloads are stored. It defaults to a
+	// sub-directory of the agent's run_path.
+	Path string `mapstructure:"path"`
+
+	// MaxSizeMB specifies the maximum size of the spool, in megabytes. The oldest
+	// payloads are dropped when it is exceeded.
+	MaxSizeMB int `mapstructure:"max_size_mb"`
+
+	// MaxAgeSeconds specifies the maximum age of a spooled payload, in seconds. Older
+	// payloads are dropped instead of being replayed.
+	MaxAgeSeconds int `mapstructure:"max_age_seconds"`
+}
+
+const (
+	// defaultSpoolMaxSizeMB specifies the default maximum size of a writer's spool.
+	defaultSpoolMaxSizeMB = 100
+	// defaultSpoolMaxAgeSeconds specifies the default maximum age of a spooled payload.
+	defaultSpoolMaxAgeSeconds = 6 * 60 * 60
+)
+
+// applySpoolDefaults fills in the unset values of sc, using the given spool
+// sub-directory name.
+func applySpoolDefaults(sc *SpoolConfig, name string) {
+	if sc.Path == "" {
+		sc.Path = filepath.Join(config.Datadog.GetString("run_path"), "apm-spool", name)
+	}
+	if sc.MaxSizeMB == 0 {
+		sc.MaxSizeMB = defaultSpoolMaxSizeMB
+	}
+	if sc.MaxAgeSeconds == 0 {
+		sc.MaxAgeSeconds = defaultSpoolMaxAgeSeconds
+	}
 }
 
 // appendEndpoints appends any endpoint configuration found at the given cfgKey.
@@ -320,10 +367,33 @@ func (c *AgentConfig) applyDatadogConfig() error {
 	if config.Datadog.IsSet("apm_config.disable_rare_sampler") {
 		c.DisableRareSampler = config.Datadog.GetBool("apm_config.disable_rare_sampler")
 	}
+	if config.Datadog.IsSet("apm_config.latency_sampler.traces_per_second") {
+		c.LatencyTPS = config.Datadog.GetFloat64("apm_config.latency_sampler.traces_per_second")
+	}
+	if k := "apm_config.latency_sampler.percentile"; config.Datadog.IsSet(k) {
+		if p := config.Datadog.GetFloat64(k); p > 0 && p < 1 {
+			c.LatencyPercentile = p
+		} else {
+			log.Errorf("Invalid value for %q, it should be between 0 and 1 exclusive; using default %v", k, c.LatencyPercentile)
+		}
+	}
 
 	if config.Datadog.IsSet("apm_config.max_remote_traces_per_second") {
 		c.MaxRemoteTPS = config.Datadog.GetFloat64("apm_config.max_remote_traces_per_second")
 	}
+	if k := "apm_config.sampling_rules"; config.Datadog.IsSet(k) {
+		var rules []*SamplingRule
+		if err := config.Datadog.UnmarshalKey(k, &rules); err != nil {
+			log.Errorf("Bad format for %q, it should be a list of rules of the form {\"service\": \"web\", \"resource\": \"GET /*\", \"sample_rate\": 0.5}: %v", k, err)
+		}
+		for i, rule := range rules {
+			if err := rule.validate(); err != nil {
+				log.Errorf("Ignoring sampling rule #%d: %v", i, err)
+				continue
+			}
+			c.SamplingRules = append(c.SamplingRules, rule)
+		}
+	}
 
 	if k := "apm_config.ignore_resources"; config.Datadog.IsSet(k) {
 		c.Ignore["resource"] = config.Datadog.GetStringSlice(k)
@@ -431,12 +501,24 @@ func (c *AgentConfig) applyDatadogConfig() error {
 			log.Errorf("Error reading writer config %q: %v", key, err)
 		}
 	}
+	applySpoolDefaults(&c.TraceWriter.Spool, "traces")
+	applySpoolDefaults(&c.StatsWriter.Spool, "stats")
 	if config.Datadog.IsSet("apm_config.connection_reset_interval") {
 		c.ConnectionResetInterval = getDuration(config.Datadog.GetInt("apm_config.connection_reset_interval"))
 	}
 	if config.Datadog.IsSet("apm_config.sync_flushing") {
 		c.SynchronousFlushing = config.Datadog.GetBool("apm_config.sync_flushing")
 	}
+	if config.Datadog.IsSet("apm_config.tap.enabled") {
+		c.TapEnabled = config.Datadog.GetBool("apm_config.tap.enabled")
+	}
+	if config.Datadog.IsSet("apm_config.capture.enabled") {
+		c.CaptureEnabled = config.Datadog.GetBool("apm_config.capture.enabled")
+	}
+	c.CapturePath = filepath.Join(config.Datadog.GetString("run_path"), "apm-capture")
+	if config.Datadog.IsSet("apm_config.capture.path") {
+		c.CapturePath = config.Datadog.GetString("apm_config.capture.path")
+	}
 
 	// undocumented deprecated
 	if config.Datadog.IsSet("apm_config.analyzed_rate_by_service") {
@@ -75,6 +75,16 @@ type AgentConfig struct {
 	MaxEPS             float64
 	MaxRemoteTPS       float64
 
+	// LatencyTPS specifies the number of traces per second kept by the latency sampler
+	// because they contain spans slower than LatencyPercentile of their resource.
+	// Zero disables the latency sampler.
+	LatencyTPS        float64
+	LatencyPercentile float64
+
+	// SamplingRules specifies the sampling rules enforced by the agent on the traces
+	// they match, in order of precedence.
+	SamplingRules []*SamplingRule
+
 	// Receiver
 	ReceiverHost    string
 	ReceiverPort    int
@@ -141,6 +151,15 @@ type AgentConfig struct {
 
 	// Telemetry settings
 	TelemetryConfig *TelemetryConfig
+
+	// TapEnabled reports whether the /debug/tap endpoint, which streams the chunks
+	// sent to the intake, is enabled.
+	TapEnabled bool
+
+	// CaptureEnabled reports whether the /debug/capture endpoint, which records the
+	// payloads received by the agent to a file in CapturePath, is enabled.
+	CaptureEnabled bool
+	CapturePath    string
 }
 
 // Tag represents a key/value pair.
@@ -148,6 +167,48 @@ type Tag struct {
 	K, V string
 }
 
+// SamplingRule specifies a sampling decision enforced by the agent on the traces whose
+// root span matches all of its non-empty patterns. Patterns are globs where "*" matches
+// any sequence of characters and "?" matches a single character.
+type SamplingRule struct {
+	// Service specifies the pattern matching the service of the root span.
+	Service string `mapstructure:"service" json:"service"`
+	// Env specifies the pattern matching the env of the trace.
+	Env string `mapstructure:"env" json:"env"`
+	// Name specifies the pattern matching the operation name of the root span.
+	Name string `mapstructure:"name" json:"name"`
+	// Resource specifies the pattern matching the resource of the root span.
+	Resource string `mapstructure:"resource" json:"resource"`
+	// SampleRate specifies the rate at which matching traces are kept. When nil, the
+	// rate is 1.
+	SampleRate *float64 `mapstructure:"sample_rate" json:"sample_rate"`
+	// MaxPerSecond specifies the maximum number of matching traces kept per second.
+	// Zero means no limit.
+	MaxPerSecond float64 `mapstructure:"max_per_second" json:"max_per_second"`
+}
+
+// Rate returns the sampling rate of the rule.
+func (r *SamplingRule) Rate() float64 {
+	if r.SampleRate == nil {
+		return 1
+	}
+	return *r.SampleRate
+}
+
+// validate returns an error if the rule is not usable.
+func (r *SamplingRule) validate() error {
+	if r.SampleRate == nil && r.MaxPerSecond == 0 {
+		return errors.New("one of sample_rate or max_per_second must be set")
+	}
+	if rate := r.Rate(); rate < 0 || rate > 1 {
+		return fmt.Errorf("sample_rate must be between 0 and 1, got %f", rate)
+	}
+	if r.MaxPerSecond < 0 {
+		return fmt.Errorf("max_per_second must be positive, got %f", r.MaxPerSecond)
+	}
+	return nil
+}
+
 // New returns a configuration with the default values.
 func New() *AgentConfig {
 	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
@@ -170,6 +231,8 @@ func New() *AgentConfig {
 		MaxEPS:          200,
 		MaxRemoteTPS:    100,
 
+		LatencyPercentile: 0.99,
+
 		ReceiverHost:    "localhost",
 		ReceiverPort:    8126,
 		MaxRequestBytes: 50 * 1024 * 1024, // 50MB
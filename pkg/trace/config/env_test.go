@@ -367,6 +367,36 @@ func TestLoadEnv(t *testing.T) {
 		assert.Equal(337.41, cfg.MaxRemoteTPS)
 	})
 
+	env = "DD_APM_LATENCY_SAMPLER_TPS"
+	t.Run(env, func(t *testing.T) {
+		defer cleanConfig()()
+		assert := assert.New(t)
+		assert.NoError(os.Setenv(env, "3"))
+		defer os.Unsetenv(env)
+		assert.NoError(os.Setenv("DD_APM_LATENCY_SAMPLER_PERCENTILE", "0.95"))
+		defer os.Unsetenv("DD_APM_LATENCY_SAMPLER_PERCENTILE")
+		cfg, err := Load("./testdata/full.yaml")
+		assert.NoError(err)
+		assert.Equal(3., cfg.LatencyTPS)
+		assert.Equal(0.95, cfg.LatencyPercentile)
+	})
+
+	env = "DD_APM_SAMPLING_RULES"
+	t.Run(env, func(t *testing.T) {
+		defer cleanConfig()()
+		assert := assert.New(t)
+		err := os.Setenv(env, `[{"service": "web", "resource": "GET /health*", "sample_rate": 0}, {"env": "prod", "max_per_second": 50}, {"service": "invalid"}]`)
+		assert.NoError(err)
+		defer os.Unsetenv(env)
+		cfg, err := Load("./testdata/full.yaml")
+		assert.NoError(err)
+		zero := 0.
+		assert.Equal([]*SamplingRule{
+			{Service: "web", Resource: "GET /health*", SampleRate: &zero},
+			{Env: "prod", MaxPerSecond: 50},
+		}, cfg.SamplingRules)
+	})
+
 	env = "DD_APM_ADDITIONAL_ENDPOINTS"
 	t.Run(env, func(t *testing.T) {
 		defer cleanConfig()()
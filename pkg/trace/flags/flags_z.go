@@ -30,6 +30,23 @@ var (
 	// MemProfile specifies the path to output memory profiling information to.
 	// When empty, memory profiling is disabled.
 	MemProfile string
+
+	// Tap will stream the chunks sent to the intake by a running agent and exit
+	// when interrupted.
+	Tap bool
+
+	// TapOut specifies where to write the tapped chunks: a file path or a unix
+	// socket address of the form unix:///path. When empty, stdout is used.
+	TapOut string
+
+	// TapService, TapResource and TapTraceID filter the tapped chunks.
+	TapService  string
+	TapResource string
+	TapTraceID  uint64
+
+	// TapBuffer specifies the number of chunks buffered by the agent for the tap
+	// before dropping them.
+	TapBuffer int
 )
 
 // Win holds a set of flags which will be populated only during the Windows build.
@@ -51,5 +68,13 @@ func init() {
 	flag.StringVar(&CPUProfile, "cpuprofile", "", "Write cpu profile to file")
 	flag.StringVar(&MemProfile, "memprofile", "", "Write memory profile to `file`")
 
+	// tap
+	flag.BoolVar(&Tap, "tap", false, "Stream the traces sent by the running trace agent as JSON lines and exit when interrupted")
+	flag.StringVar(&TapOut, "tap-out", "", "Write tapped traces to `file` or unix:///socket instead of stdout")
+	flag.StringVar(&TapService, "tap-service", "", "Only tap traces containing spans of this service")
+	flag.StringVar(&TapResource, "tap-resource", "", "Only tap traces containing spans with this resource")
+	flag.Uint64Var(&TapTraceID, "tap-trace-id", 0, "Only tap the trace with this ID")
+	flag.IntVar(&TapBuffer, "tap-buffer", 0, "Number of traces buffered by the agent for the tap before dropping them")
+
 	registerOSSpecificFlags()
 }
@@ -33,6 +33,7 @@ var (
 
 	traceWriterInfo TraceWriterInfo
 	statsWriterInfo StatsWriterInfo
+	spoolInfo       map[string]SpoolInfo
 
 	watchdogInfo     watchdog.Info
 	rateByService    map[string]float64
@@ -84,6 +85,9 @@ const (
   {{if gt .Status.TraceWriter.Errors 0}}WARNING: Traces API errors (1 min): {{.Status.TraceWriter.Errors}}{{end}}
   Stats: {{.Status.StatsWriter.Payloads}} payloads, {{.Status.StatsWriter.StatsBuckets}} stats buckets, {{.Status.StatsWriter.Bytes}} bytes
   {{if gt .Status.StatsWriter.Errors 0}}WARNING: Stats API errors (1 min): {{.Status.StatsWriter.Errors}}{{end}}
+  {{- range $name, $s := .Status.Spool }}
+  Spool ({{ $name }}): {{ $s.Payloads }} payloads on disk ({{ $s.Bytes }} bytes), {{ $s.Replayed }} replayed, {{ $s.Dropped }} dropped
+  {{- end }}
 `
 
 	notRunningTmplSrc = `{{.Banner}}
@@ -216,6 +220,7 @@ func InitInfo(conf *config.AgentConfig) error {
 		expvar.Publish("receiver", expvar.Func(publishReceiverStats))
 		expvar.Publish("trace_writer", expvar.Func(publishTraceWriterInfo))
 		expvar.Publish("stats_writer", expvar.Func(publishStatsWriterInfo))
+		expvar.Publish("spool", expvar.Func(publishSpoolInfo))
 		expvar.Publish("ratebyservice", expvar.Func(publishRateByService))
 		expvar.Publish("watchdog", expvar.Func(publishWatchdogInfo))
 		expvar.Publish("ratelimiter", expvar.Func(publishRateLimiterStats))
@@ -269,14 +274,15 @@ type StatusInfo struct {
 	MemStats struct {
 		Alloc uint64
 	} `json:"memstats"`
-	Version       infoVersion        `json:"version"`
-	Receiver      []TagStats         `json:"receiver"`
-	RateByService map[string]float64 `json:"ratebyservice"`
-	TraceWriter   TraceWriterInfo    `json:"trace_writer"`
-	StatsWriter   StatsWriterInfo    `json:"stats_writer"`
-	Watchdog      watchdog.Info      `json:"watchdog"`
-	RateLimiter   RateLimiterStats   `json:"ratelimiter"`
-	Config        config.AgentConfig `json:"config"`
+	Version       infoVersion          `json:"version"`
+	Receiver      []TagStats           `json:"receiver"`
+	RateByService map[string]float64   `json:"ratebyservice"`
+	TraceWriter   TraceWriterInfo      `json:"trace_writer"`
+	StatsWriter   StatsWriterInfo      `json:"stats_writer"`
+	Spool         map[string]SpoolInfo `json:"spool"`
+	Watchdog      watchdog.Info        `json:"watchdog"`
+	RateLimiter   RateLimiterStats     `json:"ratelimiter"`
+	Config        config.AgentConfig   `json:"config"`
 }
 
 func getProgramBanner(version string) (string, string) {
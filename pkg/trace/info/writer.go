@@ -31,6 +31,35 @@ type StatsWriterInfo struct {
 	Bytes          int64
 }
 
+// SpoolInfo represents statistics from the on-disk spool of a writer.
+type SpoolInfo struct {
+	Payloads int64 // payloads currently on disk
+	Bytes    int64 // size of the payloads currently on disk
+	Spooled  int64 // payloads written to disk since start
+	Replayed int64 // payloads read back from disk since start
+	Dropped  int64 // payloads evicted from disk because of size or age limits
+}
+
+// UpdateSpoolInfo updates the spool stats of the writer with the given name.
+func UpdateSpoolInfo(writer string, si SpoolInfo) {
+	infoMu.Lock()
+	defer infoMu.Unlock()
+	if spoolInfo == nil {
+		spoolInfo = make(map[string]SpoolInfo)
+	}
+	spoolInfo[writer] = si
+}
+
+func publishSpoolInfo() interface{} {
+	infoMu.RLock()
+	defer infoMu.RUnlock()
+	m := make(map[string]SpoolInfo, len(spoolInfo))
+	for k, v := range spoolInfo {
+		m[k] = v
+	}
+	return m
+}
+
 // UpdateTraceWriterInfo updates internal trace writer stats
 func UpdateTraceWriterInfo(tws TraceWriterInfo) {
 	infoMu.Lock()
@@ -6,6 +6,8 @@ package pb
 //     2: dynamically calculated remote rate
 //     6: remote rate defined by user
 //     7: remote rate defined by Datadog
+//     8: agent sampling rule
+//     9: agent latency sampler
 // This list is not exhaustive.
 type SamplingMechanism uint32
 
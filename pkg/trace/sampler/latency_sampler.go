@@ -0,0 +1,258 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package sampler
+
+import (
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/quantile"
+	"github.com/DataDog/datadog-agent/pkg/trace/config"
+	"github.com/DataDog/datadog-agent/pkg/trace/metrics"
+	"github.com/DataDog/datadog-agent/pkg/trace/pb"
+	"github.com/DataDog/datadog-agent/pkg/trace/traceutil"
+	"golang.org/x/time/rate"
+)
+
+const (
+	// latencyKey is set on the spans which caused a trace to be kept by the latency sampler.
+	// Its value is the latency threshold, in nanoseconds, which the span exceeded.
+	latencyKey = "_dd.latency_threshold"
+	// decisionMakerKey is the trace tag holding the mechanism which took the sampling decision.
+	decisionMakerKey = "_dd.p.dm"
+
+	// mechanismLatency is the sampling mechanism of traces kept by the latency sampler.
+	mechanismLatency pb.SamplingMechanism = 9
+
+	// latencyWindow is the duration over which latencies are aggregated before the
+	// threshold of a signature is updated.
+	latencyWindow = 30 * time.Second
+	// latencyMinCount is the number of latencies needed in a window to compute a threshold.
+	// Windows with fewer latencies are merged into the next one.
+	latencyMinCount = 100
+	// latencyCardinalityLimit is the maximum number of signatures tracked by the sampler.
+	latencyCardinalityLimit = 5000
+	// latencyBufferSize is the number of latencies buffered before being inserted in a sketch.
+	latencyBufferSize = 64
+	// latencySamplerBurst sizes the token store used by the rate limiter.
+	latencySamplerBurst = 50
+)
+
+// latencySketchConfig is the configuration of the sketches used to track latencies.
+var latencySketchConfig = quantile.Default()
+
+// LatencySampler catches traces containing spans which are slower than a configured
+// percentile of the latencies of their (env, service, name, resource). Latencies are
+// tracked in streaming sketches, and the traces are kept within a TPS budget. Traces
+// kept by the sampler are tagged with a dedicated sampling mechanism.
+type LatencySampler struct {
+	// Variables access through the 'atomic' package must be 64bits aligned.
+	hits    int64
+	misses  int64
+	dropped int64
+
+	percentile float64
+	disabled   bool
+	limiter    *rate.Limiter
+	stop       chan struct{}
+	stopOnce   sync.Once
+
+	mu   sync.RWMutex
+	sigs map[Signature]*latencyStats
+}
+
+// NewLatencySampler returns a LatencySampler configured by conf. It is disabled when
+// conf.LatencyTPS is zero.
+func NewLatencySampler(conf *config.AgentConfig) *LatencySampler {
+	s := &LatencySampler{
+		percentile: conf.LatencyPercentile,
+		disabled:   conf.LatencyTPS <= 0,
+		limiter:    rate.NewLimiter(rate.Limit(conf.LatencyTPS), latencySamplerBurst),
+		sigs:       make(map[Signature]*latencyStats),
+		stop:       make(chan struct{}),
+	}
+	if !s.disabled {
+		go s.reportLoop()
+	}
+	return s
+}
+
+// reportLoop reports stats every 10 seconds until the sampler is stopped.
+func (s *LatencySampler) reportLoop() {
+	tick := time.NewTicker(10 * time.Second)
+	defer tick.Stop()
+	for {
+		select {
+		case <-tick.C:
+			s.report()
+		case <-s.stop:
+			return
+		}
+	}
+}
+
+// Sample records the latencies of the top-level and measured spans of t and returns
+// true if the trace should be kept because one of them is slower than the configured
+// percentile. Traces which are already sampled by priority are only recorded, as
+// are traces explicitly dropped by the user (negative priority), which are never kept.
+func (s *LatencySampler) Sample(now time.Time, t *pb.TraceChunk, env string) bool {
+	priority, ok := GetSamplingPriority(t)
+	// only try sampling if the trace isn't already kept (priority > 0) nor
+	// explicitly dropped by the user (priority < 0)
+	return s.sample(now, t, env, ok && priority != PriorityAutoDrop)
+}
+
+// Record records the latencies of the top-level and measured spans of t without ever
+// keeping it. It is used for traces whose decision was taken by an agent sampling rule.
+func (s *LatencySampler) Record(now time.Time, t *pb.TraceChunk, env string) {
+	s.sample(now, t, env, true)
+}
+
+// sample records the latencies of t and returns whether it should be kept, which is never
+// the case when its sampling decision was already taken.
+func (s *LatencySampler) sample(now time.Time, t *pb.TraceChunk, env string, decided bool) bool {
+	if s.disabled {
+		return false
+	}
+	keep := false
+	for _, span := range t.Spans {
+		if !traceutil.HasTopLevel(span) && !traceutil.IsMeasured(span) {
+			continue
+		}
+		threshold := s.observe(now, env, span)
+		if decided || keep || threshold <= 0 || float64(span.Duration) <= threshold {
+			continue
+		}
+		if !s.limiter.AllowN(now, 1) {
+			atomic.AddInt64(&s.misses, 1)
+			continue
+		}
+		keep = true
+		atomic.AddInt64(&s.hits, 1)
+		traceutil.SetMetric(span, latencyKey, threshold)
+	}
+	if keep {
+		if t.Tags == nil {
+			t.Tags = make(map[string]string, 1)
+		}
+		t.Tags[decisionMakerKey] = "-" + strconv.Itoa(int(mechanismLatency))
+	}
+	return keep
+}
+
+// observe records the latency of span and returns the current latency threshold of its
+// signature, or 0 if it is not known yet.
+func (s *LatencySampler) observe(now time.Time, env string, span *pb.Span) float64 {
+	sig := latencySignature(env, span)
+	s.mu.RLock()
+	ls, ok := s.sigs[sig]
+	s.mu.RUnlock()
+	if !ok {
+		s.mu.Lock()
+		if ls, ok = s.sigs[sig]; !ok {
+			if len(s.sigs) >= latencyCardinalityLimit {
+				s.expireLocked(now)
+			}
+			if len(s.sigs) >= latencyCardinalityLimit {
+				s.mu.Unlock()
+				atomic.AddInt64(&s.dropped, 1)
+				return 0
+			}
+			ls = &latencyStats{start: now}
+			s.sigs[sig] = ls
+		}
+		s.mu.Unlock()
+	}
+	return ls.add(now, float64(span.Duration), s.percentile)
+}
+
+// expireLocked removes the signatures which were not seen during the last two windows.
+// s.mu must be held.
+func (s *LatencySampler) expireLocked(now time.Time) {
+	for sig, ls := range s.sigs {
+		if ls.idle(now) {
+			delete(s.sigs, sig)
+		}
+	}
+}
+
+// Stop stops reporting stats.
+func (s *LatencySampler) Stop() {
+	s.stopOnce.Do(func() { close(s.stop) })
+}
+
+func (s *LatencySampler) report() {
+	s.mu.RLock()
+	size := len(s.sigs)
+	s.mu.RUnlock()
+	metrics.Count("datadog.trace_agent.sampler.latency.hits", atomic.SwapInt64(&s.hits, 0), nil, 1)
+	metrics.Count("datadog.trace_agent.sampler.latency.misses", atomic.SwapInt64(&s.misses, 0), nil, 1)
+	metrics.Count("datadog.trace_agent.sampler.latency.dropped", atomic.SwapInt64(&s.dropped, 0), nil, 1)
+	metrics.Gauge("datadog.trace_agent.sampler.latency.size", float64(size), nil, 1)
+}
+
+// latencySignature returns the signature under which the latency of span is tracked.
+func latencySignature(env string, span *pb.Span) Signature {
+	h := new32a()
+	h.Write([]byte(env))
+	h.WriteChar(',')
+	h.Write([]byte(span.Service))
+	h.WriteChar(',')
+	h.Write([]byte(span.Name))
+	h.WriteChar(',')
+	h.Write([]byte(span.Resource))
+	return Signature(h.Sum32())
+}
+
+// latencyStats tracks the latency distribution of a signature. Latencies are aggregated
+// over windows; at the end of each window, the threshold is updated to the configured
+// percentile of the window.
+type latencyStats struct {
+	mu        sync.Mutex
+	start     time.Time // start of the current window
+	last      time.Time // last time a latency was added
+	sketch    quantile.Sketch
+	buf       []float64
+	threshold float64
+}
+
+// add records latency v and returns the current threshold.
+func (ls *latencyStats) add(now time.Time, v float64, percentile float64) float64 {
+	ls.mu.Lock()
+	defer ls.mu.Unlock()
+	ls.last = now
+	if now.Sub(ls.start) >= latencyWindow {
+		ls.flush()
+		if ls.sketch.Basic.Cnt >= latencyMinCount {
+			ls.threshold = ls.sketch.Quantile(latencySketchConfig, percentile)
+			ls.sketch.Reset()
+		}
+		ls.start = now
+	}
+	ls.buf = append(ls.buf, v)
+	if len(ls.buf) >= latencyBufferSize {
+		ls.flush()
+	}
+	return ls.threshold
+}
+
+// flush inserts the buffered latencies in the sketch. ls.mu must be held.
+func (ls *latencyStats) flush() {
+	if len(ls.buf) == 0 {
+		return
+	}
+	ls.sketch.InsertMany(latencySketchConfig, ls.buf)
+	ls.buf = ls.buf[:0]
+}
+
+// idle reports whether ls was not updated during the last two windows.
+func (ls *latencyStats) idle(now time.Time) bool {
+	ls.mu.Lock()
+	defer ls.mu.Unlock()
+	return now.Sub(ls.last) > 2*latencyWindow
+}
@@ -0,0 +1,108 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package sampler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/DataDog/datadog-agent/pkg/trace/config"
+	"github.com/DataDog/datadog-agent/pkg/trace/pb"
+)
+
+func latencyTestChunk(resource string, duration time.Duration, priority SamplingPriority) *pb.TraceChunk {
+	return &pb.TraceChunk{
+		Priority: int32(priority),
+		Spans: []*pb.Span{{
+			Service:  "web",
+			Name:     "http.request",
+			Resource: resource,
+			Duration: duration.Nanoseconds(),
+			Metrics:  map[string]float64{"_top_level": 1},
+		}},
+	}
+}
+
+func TestLatencySampler(t *testing.T) {
+	s := NewLatencySampler(&config.AgentConfig{LatencyTPS: 1, LatencyPercentile: 0.9})
+	defer s.Stop()
+	now := time.Now()
+
+	// fill a window with latencies between 1 and 100ms
+	for i := 0; i < 2*latencyMinCount; i++ {
+		d := time.Duration(1+i%100) * time.Millisecond
+		assert.False(t, s.Sample(now, latencyTestChunk("GET /", d, PriorityAutoDrop), "prod"), "no threshold yet")
+	}
+	now = now.Add(latencyWindow)
+
+	// fast traces are not kept
+	assert.False(t, s.Sample(now, latencyTestChunk("GET /", 10*time.Millisecond, PriorityAutoDrop), "prod"))
+
+	// slow traces are kept and tagged
+	chunk := latencyTestChunk("GET /", time.Second, PriorityAutoDrop)
+	assert.True(t, s.Sample(now, chunk, "prod"))
+	assert.Equal(t, "-9", chunk.Tags[decisionMakerKey])
+	assert.InDelta(t, 90*time.Millisecond, chunk.Spans[0].Metrics[latencyKey], float64(5*time.Millisecond))
+
+	// the threshold is tracked per resource and env
+	assert.False(t, s.Sample(now, latencyTestChunk("GET /other", time.Second, PriorityAutoDrop), "prod"))
+	assert.False(t, s.Sample(now, latencyTestChunk("GET /", time.Second, PriorityAutoDrop), "staging"))
+
+	// traces already kept by priority are only counted
+	chunk = latencyTestChunk("GET /", time.Second, PriorityAutoKeep)
+	assert.False(t, s.Sample(now, chunk, "prod"))
+	assert.Empty(t, chunk.Tags)
+
+	// traces explicitly dropped by the user are never kept
+	chunk = latencyTestChunk("GET /", time.Second, PriorityUserDrop)
+	assert.False(t, s.Sample(now, chunk, "prod"))
+	assert.Empty(t, chunk.Tags)
+	assert.NotContains(t, chunk.Spans[0].Metrics, latencyKey)
+
+	// the TPS budget is enforced
+	var kept int
+	for i := 0; i < 2*latencySamplerBurst; i++ {
+		if s.Sample(now, latencyTestChunk("GET /", time.Second, PriorityAutoDrop), "prod") {
+			kept++
+		}
+	}
+	assert.Equal(t, latencySamplerBurst-1, kept)
+}
+
+func TestLatencySamplerDisabled(t *testing.T) {
+	s := NewLatencySampler(&config.AgentConfig{LatencyPercentile: 0.99})
+	defer s.Stop()
+	assert.False(t, s.Sample(time.Now(), latencyTestChunk("GET /", time.Second, PriorityAutoDrop), "prod"))
+	assert.Empty(t, s.sigs)
+}
+
+func TestLatencySamplerStop(t *testing.T) {
+	s := NewLatencySampler(&config.AgentConfig{LatencyTPS: 1, LatencyPercentile: 0.99})
+	s.Stop()
+	s.Stop()
+	select {
+	case <-s.stop:
+	default:
+		t.Fatal("stop channel should be closed")
+	}
+}
+
+func TestLatencySamplerCardinality(t *testing.T) {
+	s := NewLatencySampler(&config.AgentConfig{LatencyTPS: 1, LatencyPercentile: 0.99})
+	defer s.Stop()
+	now := time.Now()
+	for i := 0; i < latencyCardinalityLimit+10; i++ {
+		s.Sample(now, latencyTestChunk(string(rune(i)), time.Millisecond, PriorityAutoDrop), "prod")
+	}
+	assert.Len(t, s.sigs, latencyCardinalityLimit)
+	assert.EqualValues(t, 10, s.dropped)
+
+	// idle signatures are expired to make room for new ones
+	s.Sample(now.Add(3*latencyWindow), latencyTestChunk("new", time.Millisecond, PriorityAutoDrop), "prod")
+	assert.Len(t, s.sigs, 1)
+}
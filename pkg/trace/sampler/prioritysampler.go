@@ -46,6 +46,9 @@ type PrioritySampler struct {
 	// remoteRates can be nil if the remote feature is not enabled in the trace-agent with feature flag "remote_rates"
 	// or in the core-agent remote client.
 	remoteRates *RemoteRates
+	// rules holds the sampling rules set in the agent configuration. They take precedence
+	// over the rates computed by the sampler. rules is nil when none are configured.
+	rules *ruleSampler
 
 	// rateByService contains the sampling rates in % to communicate with trace-agent clients.
 	// This struct is shared with the agent API which sends the rates in http responses to spans post requests
@@ -60,6 +63,7 @@ func NewPrioritySampler(conf *config.AgentConfig, dynConf *DynamicConfig) *Prior
 		agentEnv:      conf.DefaultEnv,
 		localRates:    newSampler(conf.ExtraSampleRate, conf.TargetTPS, []string{"sampler:priority"}),
 		remoteRates:   newRemoteRates(conf.MaxRemoteTPS),
+		rules:         newRuleSampler(conf.SamplingRules),
 		rateByService: &dynConf.RateByService,
 		catalog:       newServiceLookup(),
 		exit:          make(chan struct{}),
@@ -92,6 +96,9 @@ func (s *PrioritySampler) reportStats() {
 	if s.remoteRates != nil {
 		s.remoteRates.report()
 	}
+	if s.rules != nil {
+		s.rules.report()
+	}
 }
 
 // update sampling rates
@@ -109,25 +116,32 @@ func (s *PrioritySampler) Stop() {
 
 // Sample counts an incoming trace and returns the trace sampling decision and the applied sampling rate
 func (s *PrioritySampler) Sample(now time.Time, trace *pb.TraceChunk, root *pb.Span, tracerEnv string, clientDroppedP0sWeight float64) bool {
+	sampled, _ := s.SampleWithRules(now, trace, root, tracerEnv, clientDroppedP0sWeight)
+	return sampled
+}
+
+// SampleWithRules is like Sample and also returns whether the decision was taken by an agent
+// sampling rule, in which case it must not be overridden by the latency sampler.
+func (s *PrioritySampler) SampleWithRules(now time.Time, trace *pb.TraceChunk, root *pb.Span, tracerEnv string, clientDroppedP0sWeight float64) (sampled, ruleMatched bool) {
 	// Extra safety, just in case one trace is empty
 	if len(trace.Spans) == 0 {
-		return false
+		return false, false
 	}
 
 	samplingPriority, _ := GetSamplingPriority(trace)
 	// Regardless of rates, sampling here is based on the metadata set
 	// by the client library. Which, is turn, is based on agent hints,
 	// but the rule of thumb is: respect client choice.
-	sampled := samplingPriority > 0
+	sampled = samplingPriority > 0
 
 	// Short-circuit and return without counting the trace in the sampling rate logic
 	// if its value has not been set automaticallt by the client lib.
 	// The feedback loop should be scoped to the values it can act upon.
 	if samplingPriority < 0 {
-		return sampled
+		return sampled, false
 	}
 	if samplingPriority > 1 {
-		return sampled
+		return sampled, false
 	}
 
 	signature := s.catalog.register(ServiceSignature{Name: root.Service, Env: toSamplerEnv(tracerEnv, s.agentEnv)})
@@ -135,11 +149,23 @@ func (s *PrioritySampler) Sample(now time.Time, trace *pb.TraceChunk, root *pb.S
 	// Update sampler state by counting this trace
 	s.countSignature(now, root, signature, clientDroppedP0sWeight)
 
+	if s.rules != nil {
+		if r := s.rules.match(root, toSamplerEnv(tracerEnv, s.agentEnv)); r != nil {
+			// agent sampling rules override the decision taken by the tracer
+			weight := weightRoot(root) + float32(clientDroppedP0sWeight)
+			sampled = s.rules.sample(now, trace, root, r, weight)
+			if sampled {
+				s.countSampled(now, root, signature, r.currentRate())
+			}
+			return sampled, true
+		}
+	}
+
 	if sampled {
 		rate := s.applyRate(sampled, root, signature)
 		s.countSampled(now, root, signature, rate)
 	}
-	return sampled
+	return sampled, false
 }
 
 // countSignature counts all chunks received with local chunk root signature.
@@ -207,5 +233,9 @@ func (s *PrioritySampler) ratesByService() map[ServiceSignature]rm {
 		remoteRates = s.remoteRates.getAllSignatureSampleRates()
 	}
 	localRates, defaultRate := s.localRates.getAllSignatureSampleRates()
-	return s.catalog.ratesByService(s.agentEnv, localRates, remoteRates, defaultRate)
+	rates := s.catalog.ratesByService(s.agentEnv, localRates, remoteRates, defaultRate)
+	if s.rules != nil {
+		s.rules.applyServiceRates(rates, s.agentEnv)
+	}
+	return rates
 }
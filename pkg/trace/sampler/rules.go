@@ -0,0 +1,177 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package sampler
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/trace/config"
+	"github.com/DataDog/datadog-agent/pkg/trace/pb"
+)
+
+const (
+	// agentRuleRateKey is set on the root span of traces sampled by an agent sampling rule.
+	agentRuleRateKey = "_dd.agent_rule_psr"
+
+	// mechanismAgentRule is the sampling mechanism reported to tracers for rates coming
+	// from the agent's sampling rules.
+	mechanismAgentRule pb.SamplingMechanism = 8
+)
+
+// ruleSampler enforces the sampling rules defined in the agent configuration. Rules
+// are evaluated in order and the first one matching the root span of a trace decides
+// whether it is kept.
+type ruleSampler struct {
+	rules []*samplingRule
+}
+
+// samplingRule is the compiled form of a config.SamplingRule.
+type samplingRule struct {
+	service, env, name, resource *regexp.Regexp // nil matches anything
+
+	// serviceName is set when the rule applies to a whole service, in which case its
+	// rate is fed back to tracers through the rates by service.
+	serviceName string
+	envName     string
+
+	rate float64
+	// limiter adjusts the rate so that at most maxPerSecond traces are kept per
+	// second. It is nil when the rule has no limit.
+	limiter *Sampler
+}
+
+// ruleSignature is the signature under which a rule's limiter counts traces.
+const ruleSignature Signature = 0
+
+// newRuleSampler returns a ruleSampler enforcing the given rules, or nil if there are none.
+func newRuleSampler(rules []*config.SamplingRule) *ruleSampler {
+	if len(rules) == 0 {
+		return nil
+	}
+	rs := &ruleSampler{rules: make([]*samplingRule, 0, len(rules))}
+	for i, r := range rules {
+		sr := &samplingRule{
+			service:  globRegexp(r.Service),
+			env:      globRegexp(r.Env),
+			name:     globRegexp(r.Name),
+			resource: globRegexp(r.Resource),
+			rate:     r.Rate(),
+		}
+		if r.Name == "" && r.Resource == "" && isLiteral(r.Service) && (r.Env == "" || isLiteral(r.Env)) {
+			sr.serviceName, sr.envName = r.Service, r.Env
+		}
+		if r.MaxPerSecond > 0 {
+			sr.limiter = newSampler(1, r.MaxPerSecond, []string{"sampler:rule", "rule:" + strconv.Itoa(i)})
+		}
+		rs.rules = append(rs.rules, sr)
+	}
+	return rs
+}
+
+// globRegexp compiles the glob pattern p into a regular expression, returning nil
+// for an empty pattern.
+func globRegexp(p string) *regexp.Regexp {
+	if p == "" {
+		return nil
+	}
+	expr := regexp.QuoteMeta(p)
+	expr = strings.ReplaceAll(expr, `\*`, ".*")
+	expr = strings.ReplaceAll(expr, `\?`, ".")
+	return regexp.MustCompile("^" + expr + "$")
+}
+
+// isLiteral reports whether the glob pattern p matches a single, non-empty value.
+func isLiteral(p string) bool {
+	return p != "" && !strings.ContainsAny(p, "*?")
+}
+
+func (r *samplingRule) matches(root *pb.Span, env string) bool {
+	return (r.service == nil || r.service.MatchString(root.Service)) &&
+		(r.env == nil || r.env.MatchString(env)) &&
+		(r.name == nil || r.name.MatchString(root.Name)) &&
+		(r.resource == nil || r.resource.MatchString(root.Resource))
+}
+
+// currentRate returns the rate applied by the rule, including its limiter's.
+func (r *samplingRule) currentRate() float64 {
+	if r.limiter == nil {
+		return r.rate
+	}
+	return r.rate * r.limiter.getSignatureSampleRate(ruleSignature)
+}
+
+// match returns the first rule matching the given root span and env, or nil.
+func (rs *ruleSampler) match(root *pb.Span, env string) *samplingRule {
+	for _, r := range rs.rules {
+		if r.matches(root, env) {
+			return r
+		}
+	}
+	return nil
+}
+
+// sample applies the rule r to the chunk, overriding its priority. It returns the
+// sampling decision.
+func (rs *ruleSampler) sample(now time.Time, chunk *pb.TraceChunk, root *pb.Span, r *samplingRule, weight float32) bool {
+	if r.limiter != nil {
+		// count the traces passing the fixed rate, so that the limiter's rate brings
+		// them down to the target TPS.
+		r.limiter.countWeightedSig(now, ruleSignature, weight*float32(r.rate))
+	}
+	rate := r.currentRate()
+	sampled := SampleByRate(root.TraceID, rate)
+	if sampled {
+		chunk.Priority = int32(PriorityAutoKeep)
+		if r.limiter != nil {
+			r.limiter.countSample()
+		}
+	} else {
+		chunk.Priority = int32(PriorityAutoDrop)
+	}
+	if root.ParentID == 0 {
+		setMetric(root, agentRuleRateKey, rate)
+		setMetric(root, deprecatedRateKey, rate)
+	}
+	return sampled
+}
+
+// applyServiceRates overrides the rates in rbs for the services which are fully covered
+// by a rule, so that tracers apply the rule's rate themselves.
+func (rs *ruleSampler) applyServiceRates(rbs map[ServiceSignature]rm, agentEnv string) {
+	// iterate backwards so that rules with a higher precedence win
+	for i := len(rs.rules) - 1; i >= 0; i-- {
+		r := rs.rules[i]
+		if r.serviceName == "" {
+			continue
+		}
+		v := rm{r: r.currentRate(), m: mechanismAgentRule}
+		if r.envName != "" {
+			rbs[ServiceSignature{Name: r.serviceName, Env: r.envName}] = v
+			if rateWithEmptyEnv(r.envName, agentEnv) {
+				rbs[ServiceSignature{Name: r.serviceName}] = v
+			}
+			continue
+		}
+		for sig := range rbs {
+			if sig.Name == r.serviceName {
+				rbs[sig] = v
+			}
+		}
+		rbs[ServiceSignature{Name: r.serviceName}] = v
+	}
+}
+
+// report reports the stats of the rules' limiters.
+func (rs *ruleSampler) report() {
+	for _, r := range rs.rules {
+		if r.limiter != nil {
+			r.limiter.report()
+		}
+	}
+}
@@ -0,0 +1,102 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package sampler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/DataDog/datadog-agent/pkg/trace/config"
+	"github.com/DataDog/datadog-agent/pkg/trace/pb"
+)
+
+func ruleRate(r float64) *float64 { return &r }
+
+func TestRuleSamplerMatch(t *testing.T) {
+	rs := newRuleSampler([]*config.SamplingRule{
+		{Service: "web-*", Resource: "GET /health*", SampleRate: ruleRate(0)},
+		{Service: "web-?", Env: "prod"},
+		{Name: "http.request", SampleRate: ruleRate(0.5)},
+	})
+	for _, tt := range []struct {
+		service, name, resource, env string
+		rule                         int // -1 for no match
+	}{
+		{"web-a", "http.request", "GET /health", "prod", 0},
+		{"web-a", "http.request", "GET /healthz", "staging", 0},
+		{"web-a", "http.request", "GET /users", "prod", 1},
+		{"web-ab", "http.request", "GET /users", "prod", 2},
+		{"web-a", "grpc.server", "GET /users", "staging", -1},
+		{"api", "grpc.server", "GET /health", "prod", -1},
+	} {
+		root := &pb.Span{Service: tt.service, Name: tt.name, Resource: tt.resource}
+		r := rs.match(root, tt.env)
+		if tt.rule < 0 {
+			assert.Nil(t, r, "%+v", tt)
+			continue
+		}
+		assert.Same(t, rs.rules[tt.rule], r, "%+v", tt)
+	}
+	assert.Nil(t, newRuleSampler(nil))
+}
+
+func TestPrioritySamplerRules(t *testing.T) {
+	s := NewPrioritySampler(&config.AgentConfig{
+		ExtraSampleRate: 1.0,
+		DefaultEnv:      defaultEnv,
+		SamplingRules: []*config.SamplingRule{
+			{Service: "dropped", SampleRate: ruleRate(0)},
+			{Service: "kept", Resource: "GET /*"},
+		},
+	}, &DynamicConfig{})
+
+	chunk, root := getTestTraceWithService(t, "dropped", s)
+	chunk.Priority = int32(PriorityAutoKeep)
+	assert.False(t, s.Sample(time.Now(), chunk, root, defaultEnv, 0))
+	assert.EqualValues(t, PriorityAutoDrop, chunk.Priority)
+	assert.Equal(t, 0., root.Metrics[agentRuleRateKey])
+
+	chunk, root = getTestTraceWithService(t, "kept", s)
+	root.Resource = "GET /users"
+	chunk.Priority = int32(PriorityAutoDrop)
+	assert.True(t, s.Sample(time.Now(), chunk, root, defaultEnv, 0))
+	assert.EqualValues(t, PriorityAutoKeep, chunk.Priority)
+
+	// user decisions are never overridden
+	chunk, root = getTestTraceWithService(t, "dropped", s)
+	chunk.Priority = int32(PriorityUserKeep)
+	assert.True(t, s.Sample(time.Now(), chunk, root, defaultEnv, 0))
+
+	// rules covering a whole service are sent back to tracers once the
+	// services have been registered by the feedback loop
+	chunk, root = getTestTraceWithService(t, "dropped", s)
+	s.Sample(time.Now().Add(2*bucketDuration), chunk, root, defaultEnv, 0)
+	rates := s.ratesByService()
+	assert.Equal(t, rm{r: 0, m: mechanismAgentRule}, rates[ServiceSignature{Name: "dropped", Env: defaultEnv}])
+	assert.Equal(t, rm{r: 0, m: mechanismAgentRule}, rates[ServiceSignature{Name: "dropped"}])
+	assert.NotEqual(t, mechanismAgentRule, rates[ServiceSignature{Name: "kept", Env: defaultEnv}].m)
+}
+
+func TestRuleSamplerLimit(t *testing.T) {
+	rs := newRuleSampler([]*config.SamplingRule{{Service: "web", MaxPerSecond: 10}})
+	r := rs.rules[0]
+	now := time.Now()
+	var kept int
+	// send 100 traces per second for a while, so that the limiter's rate settles
+	for sec := 0; sec < 30; sec++ {
+		for i := 0; i < 100; i++ {
+			root := &pb.Span{Service: "web", TraceID: randomTraceID()}
+			if rs.sample(now, &pb.TraceChunk{Spans: []*pb.Span{root}}, root, r, 1) && sec >= 20 {
+				kept++
+			}
+		}
+		now = now.Add(time.Second)
+	}
+	assert.InDelta(t, 0.1, r.currentRate(), 0.05)
+	assert.InDelta(t, 100, kept, 50)
+}
@@ -0,0 +1,166 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package tap
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/trace/metrics"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// flushInterval specifies how often buffered output is flushed to the client.
+const flushInterval = time.Second
+
+// FilterFromQuery returns the Filter and buffer size described by the query string q.
+// Recognized keys are "service", "resource", "trace_id" and "buffer".
+func FilterFromQuery(q url.Values) (f Filter, size int, err error) {
+	f.Service = q.Get("service")
+	f.Resource = q.Get("resource")
+	if v := q.Get("trace_id"); v != "" {
+		if f.TraceID, err = strconv.ParseUint(v, 10, 64); err != nil {
+			return f, 0, fmt.Errorf("invalid trace_id %q: %v", v, err)
+		}
+	}
+	if v := q.Get("buffer"); v != "" {
+		if size, err = strconv.Atoi(v); err != nil {
+			return f, 0, fmt.Errorf("invalid buffer %q: %v", v, err)
+		}
+	}
+	return f, size, nil
+}
+
+// Query returns the query string encoding f and size, as understood by FilterFromQuery.
+func (f Filter) Query(size int) url.Values {
+	q := url.Values{}
+	if f.Service != "" {
+		q.Set("service", f.Service)
+	}
+	if f.Resource != "" {
+		q.Set("resource", f.Resource)
+	}
+	if f.TraceID != 0 {
+		q.Set("trace_id", strconv.FormatUint(f.TraceID, 10))
+	}
+	if size > 0 {
+		q.Set("buffer", strconv.Itoa(size))
+	}
+	return q
+}
+
+// Handler returns an http.Handler which streams matching records to the client as JSON
+// lines until the client disconnects or the tap is stopped.
+//
+// The connection is hijacked so that the stream is not cut short by the write timeout
+// of the receiver's HTTP server.
+func (t *Tap) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		f, size, err := FilterFromQuery(req.URL.Query())
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		hj, ok := w.(http.Hijacker)
+		if !ok {
+			http.Error(w, "streaming is not supported on this connection", http.StatusInternalServerError)
+			return
+		}
+		conn, bufrw, err := hj.Hijack()
+		if err != nil {
+			log.Errorf("Tap: error hijacking connection: %v", err)
+			return
+		}
+		defer conn.Close()
+		conn.SetDeadline(time.Time{}) //nolint:errcheck
+
+		s := t.Subscribe(f, size)
+		defer t.Unsubscribe(s)
+		metrics.Count("datadog.trace_agent.tap.subscriptions", 1, nil, 1)
+		log.Infof("Tap: new subscriber %s (service=%q resource=%q trace_id=%d)", conn.RemoteAddr(), f.Service, f.Resource, f.TraceID)
+
+		fmt.Fprint(bufrw, "HTTP/1.1 200 OK\r\nContent-Type: application/x-ndjson\r\nConnection: close\r\n\r\n")
+		if err := bufrw.Flush(); err != nil {
+			return
+		}
+		closed := make(chan struct{})
+		go func() {
+			// the client is not expected to send anything; a read returning
+			// means that it went away.
+			io.Copy(io.Discard, bufrw) //nolint:errcheck
+			close(closed)
+		}()
+		err = stream(s, bufrw.Writer, closed)
+		if dropped := s.Dropped(); dropped > 0 {
+			metrics.Count("datadog.trace_agent.tap.dropped", dropped, nil, 1)
+			log.Warnf("Tap: subscriber %s dropped %d chunks", conn.RemoteAddr(), dropped)
+		}
+		if err != nil {
+			log.Debugf("Tap: subscriber %s went away: %v", conn.RemoteAddr(), err)
+		}
+	})
+}
+
+// stream encodes the records received by s into w until the subscription ends,
+// done is closed or writing fails.
+func stream(s *Subscription, w *bufio.Writer, done <-chan struct{}) error {
+	enc := json.NewEncoder(w)
+	tick := time.NewTicker(flushInterval)
+	defer tick.Stop()
+	for {
+		select {
+		case rec, ok := <-s.C:
+			if !ok {
+				return w.Flush()
+			}
+			if err := enc.Encode(rec); err != nil {
+				return err
+			}
+		case <-tick.C:
+			if err := w.Flush(); err != nil {
+				return err
+			}
+		case <-done:
+			return nil
+		}
+	}
+}
+
+// Stream connects to the tap endpoint at addr (e.g. "http://localhost:8126/debug/tap")
+// and copies the records matching f to out until ctx is cancelled or the agent closes
+// the stream.
+func Stream(ctx context.Context, addr string, f Filter, size int, out io.Writer) error {
+	u, err := url.Parse(addr)
+	if err != nil {
+		return err
+	}
+	u.RawQuery = f.Query(size).Encode()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("tap endpoint returned %s: %s", resp.Status, msg)
+	}
+	_, err = io.Copy(out, resp.Body)
+	if ctx.Err() != nil {
+		return nil
+	}
+	return err
+}
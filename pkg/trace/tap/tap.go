@@ -0,0 +1,187 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+// Package tap allows inspecting the trace chunks which the agent sends to the
+// intake, after sampling, normalization and obfuscation have been applied. It
+// is meant as a debugging aid: subscribers never slow down or alter the
+// agent's output and chunks are dropped when a subscriber can not keep up.
+package tap
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"github.com/DataDog/datadog-agent/pkg/trace/pb"
+)
+
+const (
+	// DefaultBufferSize specifies the default number of records buffered for a subscriber.
+	DefaultBufferSize = 100
+
+	// MaxBufferSize specifies the maximum number of records which may be buffered for
+	// a subscriber.
+	MaxBufferSize = 10000
+)
+
+// Filter specifies which chunks a subscriber is interested in. A chunk matches
+// when it contains at least one span matching all of the non-empty fields.
+type Filter struct {
+	// Service, when not empty, requires the span to have this service.
+	Service string
+	// Resource, when not empty, requires the span to have this resource.
+	Resource string
+	// TraceID, when not zero, requires the span to have this trace ID.
+	TraceID uint64
+}
+
+// Matches reports whether the given chunk matches the filter.
+func (f Filter) Matches(chunk *pb.TraceChunk) bool {
+	if f.Service == "" && f.Resource == "" && f.TraceID == 0 {
+		return true
+	}
+	for _, span := range chunk.Spans {
+		if f.Service != "" && span.Service != f.Service {
+			continue
+		}
+		if f.Resource != "" && span.Resource != f.Resource {
+			continue
+		}
+		if f.TraceID != 0 && span.TraceID != f.TraceID {
+			continue
+		}
+		return true
+	}
+	return false
+}
+
+// Record holds a single trace chunk along with the payload-level metadata
+// that is sent alongside it to the intake.
+type Record struct {
+	Hostname      string     `json:"hostname"`
+	Env           string     `json:"env"`
+	ContainerID   string     `json:"container_id,omitempty"`
+	LanguageName  string     `json:"language_name,omitempty"`
+	TracerVersion string     `json:"tracer_version,omitempty"`
+	AppVersion    string     `json:"app_version,omitempty"`
+	Priority      int32      `json:"priority"`
+	Origin        string     `json:"origin,omitempty"`
+	DroppedTrace  bool       `json:"dropped_trace"`
+	Spans         []*pb.Span `json:"spans"`
+}
+
+// Subscription receives the records published to a Tap which match its filter.
+type Subscription struct {
+	// C receives matching records. It is closed when the subscription ends.
+	C <-chan *Record
+
+	c       chan *Record
+	filter  Filter
+	dropped int64 // atomic
+}
+
+// Dropped returns the number of records which were dropped because the
+// subscriber was not consuming them fast enough.
+func (s *Subscription) Dropped() int64 {
+	return atomic.LoadInt64(&s.dropped)
+}
+
+// Tap fans out the chunks sent by the agent to any number of subscribers.
+type Tap struct {
+	mu   sync.RWMutex
+	subs map[*Subscription]struct{}
+	n    int32 // atomic; number of subscribers, used as a lock-free fast path
+}
+
+// New returns a new Tap without any subscribers.
+func New() *Tap {
+	return &Tap{subs: make(map[*Subscription]struct{})}
+}
+
+// Active reports whether the tap has any subscribers.
+func (t *Tap) Active() bool {
+	return atomic.LoadInt32(&t.n) > 0
+}
+
+// Subscribe registers a new subscription receiving records which match f, buffering
+// at most size records. A non-positive size uses DefaultBufferSize.
+func (t *Tap) Subscribe(f Filter, size int) *Subscription {
+	if size <= 0 {
+		size = DefaultBufferSize
+	}
+	if size > MaxBufferSize {
+		size = MaxBufferSize
+	}
+	c := make(chan *Record, size)
+	s := &Subscription{C: c, c: c, filter: f}
+	t.mu.Lock()
+	t.subs[s] = struct{}{}
+	atomic.StoreInt32(&t.n, int32(len(t.subs)))
+	t.mu.Unlock()
+	return s
+}
+
+// Unsubscribe ends the subscription s and closes its channel.
+func (t *Tap) Unsubscribe(s *Subscription) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if _, ok := t.subs[s]; !ok {
+		return
+	}
+	delete(t.subs, s)
+	atomic.StoreInt32(&t.n, int32(len(t.subs)))
+	close(s.c)
+}
+
+// Publish sends the chunks in p to all matching subscribers. It never blocks:
+// records are dropped for subscribers whose buffer is full.
+func (t *Tap) Publish(p *pb.TracerPayload) {
+	if p == nil || !t.Active() {
+		return
+	}
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	for _, chunk := range p.Chunks {
+		var rec *Record
+		for s := range t.subs {
+			if !s.filter.Matches(chunk) {
+				continue
+			}
+			if rec == nil {
+				rec = newRecord(p, chunk)
+			}
+			select {
+			case s.c <- rec:
+			default:
+				atomic.AddInt64(&s.dropped, 1)
+			}
+		}
+	}
+}
+
+// Stop ends all subscriptions.
+func (t *Tap) Stop() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for s := range t.subs {
+		delete(t.subs, s)
+		close(s.c)
+	}
+	atomic.StoreInt32(&t.n, 0)
+}
+
+func newRecord(p *pb.TracerPayload, chunk *pb.TraceChunk) *Record {
+	return &Record{
+		Hostname:      p.Hostname,
+		Env:           p.Env,
+		ContainerID:   p.ContainerID,
+		LanguageName:  p.LanguageName,
+		TracerVersion: p.TracerVersion,
+		AppVersion:    p.AppVersion,
+		Priority:      chunk.Priority,
+		Origin:        chunk.Origin,
+		DroppedTrace:  chunk.DroppedTrace,
+		Spans:         chunk.Spans,
+	}
+}
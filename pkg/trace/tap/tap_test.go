@@ -0,0 +1,149 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package tap
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/trace/pb"
+)
+
+func testPayload() *pb.TracerPayload {
+	return &pb.TracerPayload{
+		Hostname: "host",
+		Env:      "prod",
+		Chunks: []*pb.TraceChunk{
+			{Priority: 1, Spans: []*pb.Span{
+				{Service: "web", Resource: "GET /", TraceID: 1, SpanID: 1},
+				{Service: "db", Resource: "SELECT ?", TraceID: 1, SpanID: 2, ParentID: 1},
+			}},
+			{Priority: 2, Spans: []*pb.Span{
+				{Service: "payments", Resource: "POST /charge", TraceID: 2, SpanID: 3},
+			}},
+		},
+	}
+}
+
+func TestFilterMatches(t *testing.T) {
+	p := testPayload()
+	for _, tt := range []struct {
+		filter Filter
+		match  []bool
+	}{
+		{Filter{}, []bool{true, true}},
+		{Filter{Service: "db"}, []bool{true, false}},
+		{Filter{Service: "web", Resource: "SELECT ?"}, []bool{false, false}},
+		{Filter{Service: "payments", Resource: "POST /charge"}, []bool{false, true}},
+		{Filter{TraceID: 1}, []bool{true, false}},
+		{Filter{TraceID: 3}, []bool{false, false}},
+	} {
+		for i, chunk := range p.Chunks {
+			assert.Equal(t, tt.match[i], tt.filter.Matches(chunk), "%+v on chunk %d", tt.filter, i)
+		}
+	}
+}
+
+func TestFilterQuery(t *testing.T) {
+	f := Filter{Service: "web", Resource: "GET /users", TraceID: 42}
+	got, size, err := FilterFromQuery(f.Query(5))
+	require.NoError(t, err)
+	assert.Equal(t, f, got)
+	assert.Equal(t, 5, size)
+
+	_, _, err = FilterFromQuery(url.Values{"trace_id": []string{"abc"}})
+	assert.Error(t, err)
+}
+
+func TestTap(t *testing.T) {
+	t.Run("inactive", func(t *testing.T) {
+		tp := New()
+		assert.False(t, tp.Active())
+		tp.Publish(testPayload()) // must not block or panic
+	})
+
+	t.Run("filter", func(t *testing.T) {
+		tp := New()
+		s := tp.Subscribe(Filter{Service: "payments"}, 10)
+		assert.True(t, tp.Active())
+		tp.Publish(testPayload())
+		tp.Unsubscribe(s)
+		assert.False(t, tp.Active())
+
+		var recs []*Record
+		for rec := range s.C {
+			recs = append(recs, rec)
+		}
+		require.Len(t, recs, 1)
+		assert.Equal(t, "prod", recs[0].Env)
+		assert.Equal(t, "host", recs[0].Hostname)
+		assert.EqualValues(t, 2, recs[0].Priority)
+		assert.Equal(t, "POST /charge", recs[0].Spans[0].Resource)
+	})
+
+	t.Run("drops", func(t *testing.T) {
+		tp := New()
+		s := tp.Subscribe(Filter{}, 1)
+		tp.Publish(testPayload())
+		tp.Publish(testPayload())
+		assert.Len(t, s.C, 1)
+		assert.EqualValues(t, 3, s.Dropped())
+		tp.Stop()
+		tp.Unsubscribe(s) // no double close
+		assert.False(t, tp.Active())
+	})
+}
+
+func TestHandler(t *testing.T) {
+	tp := New()
+	srv := httptest.NewServer(tp.Handler())
+	defer srv.Close()
+
+	pr, pw := io.Pipe()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	errc := make(chan error, 1)
+	go func() {
+		errc <- Stream(ctx, srv.URL, Filter{TraceID: 1}, 0, pw)
+		pw.Close()
+	}()
+
+	// wait for the subscriber to be registered
+	deadline := time.Now().Add(5 * time.Second)
+	for !tp.Active() {
+		if time.Now().After(deadline) {
+			t.Fatal("subscriber never registered")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	tp.Publish(testPayload())
+
+	line, err := bufio.NewReader(pr).ReadBytes('\n')
+	require.NoError(t, err)
+	var rec Record
+	require.NoError(t, json.NewDecoder(bytes.NewReader(line)).Decode(&rec))
+	assert.Len(t, rec.Spans, 2)
+	assert.EqualValues(t, 1, rec.Spans[0].TraceID)
+
+	tp.Stop()
+	go io.Copy(io.Discard, pr) //nolint:errcheck
+	select {
+	case err := <-errc:
+		assert.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("stream did not end after the tap was stopped")
+	}
+}
@@ -15,6 +15,7 @@ import (
 	"math/rand"
 	"net/http"
 	"net/url"
+	"path/filepath"
 	"strconv"
 	"sync"
 	"sync/atomic"
@@ -28,8 +29,10 @@ import (
 )
 
 // newSenders returns a list of senders based on the given agent configuration, using climit
-// as the maximum number of concurrent outgoing connections, writing to path.
-func newSenders(cfg *config.AgentConfig, r eventRecorder, path string, climit, qsize int) []*sender {
+// as the maximum number of concurrent outgoing connections, writing to path. When spooling
+// is enabled in scfg, each sender persists the payloads it can not send in a sub-directory
+// of the spool path.
+func newSenders(cfg *config.AgentConfig, r eventRecorder, path string, climit, qsize int, scfg *config.SpoolConfig) []*sender {
 	if e := cfg.Endpoints; len(e) == 0 || e[0].Host == "" || e[0].APIKey == "" {
 		panic(errors.New("config was not properly validated"))
 	}
@@ -42,6 +45,15 @@ func newSenders(cfg *config.AgentConfig, r eventRecorder, path string, climit, q
 		if err != nil {
 			osutil.Exitf("Invalid host endpoint: %q", endpoint.Host)
 		}
+		var sp *spool
+		if scfg != nil && scfg.Enabled {
+			dir := filepath.Join(scfg.Path, strconv.Itoa(i)+"_"+url.Hostname())
+			sp, err = newSpool(dir, int64(scfg.MaxSizeMB)*1024*1024, time.Duration(scfg.MaxAgeSeconds)*time.Second)
+			if err != nil {
+				log.Errorf("Payload spooling disabled for %s: %v", url.Hostname(), err)
+				sp = nil
+			}
+		}
 		senders[i] = newSender(&senderConfig{
 			client:    client,
 			maxConns:  int(maxConns),
@@ -49,6 +61,7 @@ func newSenders(cfg *config.AgentConfig, r eventRecorder, path string, climit, q
 			url:       url,
 			apiKey:    endpoint.APIKey,
 			recorder:  r,
+			spool:     sp,
 		})
 	}
 	return senders
@@ -75,6 +88,9 @@ const (
 	// eventTypeDropped specifies that a payload had to be dropped to make room
 	// in the queue.
 	eventTypeDropped
+	// eventTypeSpooled specifies that a payload was written to disk instead of
+	// being dropped, to be sent at a later time.
+	eventTypeSpooled
 )
 
 var eventTypeStrings = map[eventType]string{
@@ -82,6 +98,7 @@ var eventTypeStrings = map[eventType]string{
 	eventTypeSent:     "eventTypeSent",
 	eventTypeRejected: "eventTypeRejected",
 	eventTypeDropped:  "eventTypeDropped",
+	eventTypeSpooled:  "eventTypeSpooled",
 }
 
 // String implements fmt.Stringer.
@@ -126,6 +143,9 @@ type senderConfig struct {
 	// recorder specifies the eventRecorder to use when reporting events occurring
 	// in the sender.
 	recorder eventRecorder
+	// spool, when non-nil, persists payloads which would otherwise be dropped, to
+	// be replayed once the queue has room again, or after a restart.
+	spool *spool
 }
 
 // sender is responsible for sending payloads to a given URL. It uses a size-limited
@@ -140,6 +160,8 @@ type sender struct {
 
 	mu     sync.RWMutex // guards closed
 	closed bool         // closed reports if the loop is stopped
+
+	done chan struct{} // closed when the sender is stopped
 }
 
 // newSender returns a new sender based on the given config cfg.
@@ -148,8 +170,12 @@ func newSender(cfg *senderConfig) *sender {
 		cfg:    cfg,
 		queue:  make(chan *payload, cfg.maxQueued),
 		climit: make(chan struct{}, cfg.maxConns),
+		done:   make(chan struct{}),
 	}
 	go s.loop()
+	if cfg.spool != nil {
+		go s.replay()
+	}
 	return &s
 }
 
@@ -165,6 +191,38 @@ func (s *sender) loop() {
 	}
 }
 
+// replay periodically moves spooled payloads back onto the queue, for as long as
+// the destination is healthy and the queue is no more than half full.
+func (s *sender) replay() {
+	t := time.NewTicker(spoolReplayInterval)
+	defer t.Stop()
+	for {
+		select {
+		case <-s.done:
+			return
+		case <-t.C:
+			for s.replayOne() {
+			}
+		}
+	}
+}
+
+// replayOne pushes the oldest spooled payload onto the queue, reporting whether
+// it did so.
+func (s *sender) replayOne() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.closed || atomic.LoadInt32(&s.attempt) > 0 || len(s.queue) >= cap(s.queue)/2 {
+		return false
+	}
+	p := s.cfg.spool.load()
+	if p == nil {
+		return false
+	}
+	s.Push(p)
+	return true
+}
+
 // backoff triggers a sleep period proportional to the retry attempt, if any.
 func (s *sender) backoff() {
 	attempt := atomic.LoadInt32(&s.attempt)
@@ -182,6 +240,19 @@ func (s *sender) Stop() {
 	s.mu.Lock()
 	s.closed = true
 	s.mu.Unlock()
+	close(s.done)
+	if s.cfg.spool != nil {
+		// persist whatever is left in the queue so that it is sent after a restart
+	drain:
+		for {
+			select {
+			case p := <-s.queue:
+				s.dropPayload(p, &eventData{bytes: p.body.Len(), count: 1})
+			default:
+				break drain
+			}
+		}
+	}
 	close(s.queue)
 }
 
@@ -215,7 +286,7 @@ func (s *sender) Push(p *payload) {
 			// drop the oldest item in the queue to make room
 			select {
 			case p := <-s.queue:
-				s.releasePayload(p, eventTypeDropped, &eventData{
+				s.dropPayload(p, &eventData{
 					bytes: p.body.Len(),
 					count: 1,
 				})
@@ -250,6 +321,9 @@ func (s *sender) sendPayload(p *payload) {
 		defer s.mu.RUnlock()
 		if s.closed {
 			// sender is stopped
+			if s.cfg.spool != nil {
+				s.dropPayload(p, stats)
+			}
 			return
 		}
 		atomic.AddInt32(&s.attempt, 1)
@@ -266,7 +340,7 @@ func (s *sender) sendPayload(p *payload) {
 			return
 		default:
 			// queue is full; since this is the oldest payload, we drop it
-			s.releasePayload(p, eventTypeDropped, stats)
+			s.dropPayload(p, stats)
 		}
 	case nil:
 		// request was successful; the retry queue may have grown large - we should
@@ -306,6 +380,20 @@ func (s *sender) releasePayload(p *payload, t eventType, data *eventData) {
 	atomic.AddInt32(&s.inflight, -1)
 }
 
+// dropPayload releases the payload p, which could not be kept in the queue. When
+// spooling is enabled, it is first written to disk.
+func (s *sender) dropPayload(p *payload, data *eventData) {
+	if sp := s.cfg.spool; sp != nil {
+		err := sp.store(p)
+		if err == nil {
+			s.releasePayload(p, eventTypeSpooled, data)
+			return
+		}
+		log.Errorf("Error spooling payload to disk: %v", err)
+	}
+	s.releasePayload(p, eventTypeDropped, data)
+}
+
 // recordEvent records the occurrence of the given event type t. It additionally
 // passes on the data and augments it with additional information.
 func (s *sender) recordEvent(t eventType, data *eventData) {
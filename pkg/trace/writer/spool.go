@@ -0,0 +1,238 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package writer
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/trace/info"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// spoolFileExt is the extension of files holding spooled payloads.
+const spoolFileExt = ".payload"
+
+// spoolReplayInterval specifies how often a sender attempts to replay spooled payloads;
+// replaced in tests.
+var spoolReplayInterval = time.Second
+
+// spool persists payloads which could not be sent to a directory on disk, so that they
+// survive intake outages and agent restarts. Files are stored oldest first and evicted
+// when they exceed the configured size or age. It is safe for concurrent use.
+type spool struct {
+	dir     string
+	maxSize int64
+	maxAge  time.Duration
+
+	mu    sync.Mutex
+	files []spoolFile // spooled files, oldest first
+	size  int64       // total size of files
+	seq   uint64      // used to disambiguate file names created at the same time
+
+	spooled  int64 // atomic; payloads written to disk
+	replayed int64 // atomic; payloads read back from disk
+	dropped  int64 // atomic; payloads evicted or unreadable
+}
+
+// spoolFile holds information about a payload stored on disk.
+type spoolFile struct {
+	name    string
+	size    int64
+	created time.Time
+}
+
+// newSpool returns a new spool storing payloads in dir, which is created if needed.
+// Payloads left over by a previous run are picked up so that they may be replayed.
+// A zero maxSize or maxAge disables the corresponding limit.
+func newSpool(dir string, maxSize int64, maxAge time.Duration) (*spool, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("error creating spool directory: %v", err)
+	}
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("error reading spool directory: %v", err)
+	}
+	s := &spool{dir: dir, maxSize: maxSize, maxAge: maxAge}
+	for _, fi := range entries {
+		if fi.IsDir() {
+			continue
+		}
+		if !strings.HasSuffix(fi.Name(), spoolFileExt) {
+			if strings.Contains(fi.Name(), spoolFileExt+".tmp") {
+				// partially written by a previous run
+				os.Remove(filepath.Join(dir, fi.Name()))
+			}
+			continue
+		}
+		s.files = append(s.files, spoolFile{name: fi.Name(), size: fi.Size(), created: fi.ModTime()})
+		s.size += fi.Size()
+	}
+	// file names start with a zero-padded timestamp, so they sort chronologically
+	sort.Slice(s.files, func(i, j int) bool { return s.files[i].name < s.files[j].name })
+	if len(s.files) > 0 {
+		log.Infof("Found %d payloads (%d bytes) to replay in %s", len(s.files), s.size, dir)
+	}
+	s.mu.Lock()
+	s.evictLocked(time.Now())
+	s.mu.Unlock()
+	return s, nil
+}
+
+// store writes p to disk. It does not take ownership of p.
+func (s *spool) store(p *payload) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	s.seq++
+	name := fmt.Sprintf("%020d-%06d%s", now.UnixNano(), s.seq%1e6, spoolFileExt)
+	tmp, err := ioutil.TempFile(s.dir, name+".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	w := bufio.NewWriter(tmp)
+	if err := json.NewEncoder(w).Encode(p.headers); err != nil {
+		tmp.Close()
+		return err
+	}
+	if _, err := w.Write(p.body.Bytes()); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := w.Flush(); err != nil {
+		tmp.Close()
+		return err
+	}
+	fi, err := tmp.Stat()
+	if err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), filepath.Join(s.dir, name)); err != nil {
+		return err
+	}
+	s.files = append(s.files, spoolFile{name: name, size: fi.Size(), created: now})
+	s.size += fi.Size()
+	atomic.AddInt64(&s.spooled, 1)
+	s.evictLocked(now)
+	return nil
+}
+
+// load removes the oldest payload from disk and returns it. It returns nil when
+// the spool is empty.
+func (s *spool) load() *payload {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.evictLocked(time.Now())
+	for len(s.files) > 0 {
+		f := s.files[0]
+		s.files = s.files[1:]
+		s.size -= f.size
+		p, err := s.readFile(f.name)
+		os.Remove(filepath.Join(s.dir, f.name))
+		if err != nil {
+			log.Errorf("Dropping unreadable spooled payload %s: %v", f.name, err)
+			atomic.AddInt64(&s.dropped, 1)
+			continue
+		}
+		atomic.AddInt64(&s.replayed, 1)
+		return p
+	}
+	return nil
+}
+
+// readFile reads the payload stored in the spool file with the given name.
+func (s *spool) readFile(name string) (*payload, error) {
+	data, err := ioutil.ReadFile(filepath.Join(s.dir, name))
+	if err != nil {
+		return nil, err
+	}
+	i := bytes.IndexByte(data, '\n')
+	if i < 0 {
+		return nil, fmt.Errorf("missing headers")
+	}
+	var headers map[string]string
+	if err := json.Unmarshal(data[:i], &headers); err != nil {
+		return nil, fmt.Errorf("invalid headers: %v", err)
+	}
+	p := newPayload(headers)
+	p.body.Write(data[i+1:])
+	return p, nil
+}
+
+// evictLocked removes the files which exceed the spool's age and size limits,
+// oldest first. s.mu must be held.
+func (s *spool) evictLocked(now time.Time) {
+	for len(s.files) > 0 {
+		f := s.files[0]
+		expired := s.maxAge > 0 && now.Sub(f.created) > s.maxAge
+		oversized := s.maxSize > 0 && s.size > s.maxSize
+		if !expired && !oversized {
+			return
+		}
+		if err := os.Remove(filepath.Join(s.dir, f.name)); err != nil && !os.IsNotExist(err) {
+			log.Errorf("Error removing spooled payload: %v", err)
+		}
+		s.files = s.files[1:]
+		s.size -= f.size
+		atomic.AddInt64(&s.dropped, 1)
+	}
+}
+
+// len returns the number of payloads on disk.
+func (s *spool) len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.files)
+}
+
+// info returns statistics about the spool.
+func (s *spool) info() info.SpoolInfo {
+	s.mu.Lock()
+	payloads, size := int64(len(s.files)), s.size
+	s.mu.Unlock()
+	return info.SpoolInfo{
+		Payloads: payloads,
+		Bytes:    size,
+		Spooled:  atomic.LoadInt64(&s.spooled),
+		Replayed: atomic.LoadInt64(&s.replayed),
+		Dropped:  atomic.LoadInt64(&s.dropped),
+	}
+}
+
+// spoolInfo returns the combined spool statistics of the given senders, and whether
+// spooling is enabled on any of them.
+func spoolInfo(senders []*sender) (si info.SpoolInfo, ok bool) {
+	for _, s := range senders {
+		if s.cfg.spool == nil {
+			continue
+		}
+		ok = true
+		i := s.cfg.spool.info()
+		si.Payloads += i.Payloads
+		si.Bytes += i.Bytes
+		si.Spooled += i.Spooled
+		si.Replayed += i.Replayed
+		si.Dropped += i.Dropped
+	}
+	return si, ok
+}
@@ -0,0 +1,145 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package writer
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	httputils "github.com/DataDog/datadog-agent/pkg/util/http"
+)
+
+func testSpoolPayload(body string) *payload {
+	p := newPayload(map[string]string{"Content-Type": "application/x-protobuf"})
+	p.body.WriteString(body)
+	return p
+}
+
+func TestSpool(t *testing.T) {
+	t.Run("roundtrip", func(t *testing.T) {
+		s, err := newSpool(t.TempDir(), 0, 0)
+		require.NoError(t, err)
+		assert.Nil(t, s.load())
+
+		require.NoError(t, s.store(testSpoolPayload("first")))
+		require.NoError(t, s.store(testSpoolPayload("second")))
+		assert.Equal(t, 2, s.len())
+
+		p := s.load()
+		require.NotNil(t, p)
+		assert.Equal(t, "first", p.body.String())
+		assert.Equal(t, "application/x-protobuf", p.headers["Content-Type"])
+		assert.Equal(t, "second", s.load().body.String())
+		assert.Nil(t, s.load())
+
+		si := s.info()
+		assert.EqualValues(t, 2, si.Spooled)
+		assert.EqualValues(t, 2, si.Replayed)
+		assert.EqualValues(t, 0, si.Payloads)
+		assert.EqualValues(t, 0, si.Bytes)
+	})
+
+	t.Run("restart", func(t *testing.T) {
+		dir := t.TempDir()
+		s, err := newSpool(dir, 0, 0)
+		require.NoError(t, err)
+		for _, body := range []string{"1", "2", "3"} {
+			require.NoError(t, s.store(testSpoolPayload(body)))
+		}
+		// a partially written file left over by a crash
+		require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "0"+spoolFileExt+".tmp123"), []byte("x"), 0600))
+
+		s, err = newSpool(dir, 0, 0)
+		require.NoError(t, err)
+		assert.Equal(t, 3, s.len())
+		for _, body := range []string{"1", "2", "3"} {
+			assert.Equal(t, body, s.load().body.String())
+		}
+		files, err := ioutil.ReadDir(dir)
+		require.NoError(t, err)
+		assert.Empty(t, files)
+	})
+
+	t.Run("max-size", func(t *testing.T) {
+		s, err := newSpool(t.TempDir(), 0, 0)
+		require.NoError(t, err)
+		require.NoError(t, s.store(testSpoolPayload("1")))
+		s.maxSize = s.info().Bytes * 2
+		require.NoError(t, s.store(testSpoolPayload("2")))
+		require.NoError(t, s.store(testSpoolPayload("3")))
+		assert.Equal(t, 2, s.len())
+		assert.EqualValues(t, 1, s.info().Dropped)
+		assert.Equal(t, "2", s.load().body.String())
+	})
+
+	t.Run("max-age", func(t *testing.T) {
+		s, err := newSpool(t.TempDir(), 0, time.Minute)
+		require.NoError(t, err)
+		require.NoError(t, s.store(testSpoolPayload("old")))
+		s.files[0].created = time.Now().Add(-time.Hour)
+		require.NoError(t, s.store(testSpoolPayload("new")))
+		assert.Equal(t, "new", s.load().body.String())
+		assert.EqualValues(t, 1, s.info().Dropped)
+	})
+
+	t.Run("corrupt", func(t *testing.T) {
+		dir := t.TempDir()
+		require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "1"+spoolFileExt), []byte("no headers"), 0600))
+		s, err := newSpool(dir, 0, 0)
+		require.NoError(t, err)
+		assert.Nil(t, s.load())
+		assert.EqualValues(t, 1, s.info().Dropped)
+	})
+}
+
+func TestSenderSpool(t *testing.T) {
+	defer func(old time.Duration) { spoolReplayInterval = old }(spoolReplayInterval)
+	spoolReplayInterval = 10 * time.Millisecond
+
+	server := newTestServer()
+	defer server.Close()
+	u, err := url.Parse(server.URL + "/")
+	require.NoError(t, err)
+	sp, err := newSpool(t.TempDir(), 0, 0)
+	require.NoError(t, err)
+	var recorder mockRecorder
+	s := &sender{
+		cfg: &senderConfig{
+			client:    httputils.NewResetClient(0, func() *http.Client { return &http.Client{} }),
+			url:       u,
+			maxConns:  1,
+			maxQueued: 4,
+			apiKey:    testAPIKey,
+			recorder:  &recorder,
+			spool:     sp,
+		},
+		queue:  make(chan *payload, 4),
+		climit: make(chan struct{}, 1),
+		done:   make(chan struct{}),
+	}
+
+	// fill the queue before the sender runs: the overflow goes to disk
+	for i := 0; i < 6; i++ {
+		s.Push(expectResponses(200))
+	}
+	assert.Equal(t, 2, sp.len())
+	assert.Len(t, recorder.data(eventTypeSpooled), 2)
+	assert.Empty(t, recorder.data(eventTypeDropped))
+
+	go s.loop()
+	go s.replay()
+	assert.Eventually(t, func() bool { return server.Accepted() == 6 }, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, 0, sp.len())
+	assert.EqualValues(t, 2, sp.info().Replayed)
+	s.Stop()
+}
@@ -84,7 +84,7 @@ func NewStatsWriter(cfg *config.AgentConfig, in <-chan pb.StatsPayload) *StatsWr
 		qsize = int(math.Max(1, maxmem/payloadSize))
 	}
 	log.Debugf("Stats writer initialized (climit=%d qsize=%d)", climit, qsize)
-	sw.senders = newSenders(cfg, sw, pathStats, climit, qsize)
+	sw.senders = newSenders(cfg, sw, pathStats, climit, qsize, &cfg.StatsWriter.Spool)
 	return sw
 }
 
@@ -326,6 +326,11 @@ func (w *StatsWriter) report() {
 	metrics.Count("datadog.trace_agent.stats_writer.retries", atomic.SwapInt64(&w.stats.Retries, 0), nil, 1)
 	metrics.Count("datadog.trace_agent.stats_writer.splits", atomic.SwapInt64(&w.stats.Splits, 0), nil, 1)
 	metrics.Count("datadog.trace_agent.stats_writer.errors", atomic.SwapInt64(&w.stats.Errors, 0), nil, 1)
+	if si, ok := spoolInfo(w.senders); ok {
+		info.UpdateSpoolInfo("stats", si)
+		metrics.Gauge("datadog.trace_agent.stats_writer.spool.payloads", float64(si.Payloads), nil, 1)
+		metrics.Gauge("datadog.trace_agent.stats_writer.spool.bytes", float64(si.Bytes), nil, 1)
+	}
 }
 
 // recordEvent implements eventRecorder.
@@ -353,5 +358,10 @@ func (w *StatsWriter) recordEvent(t eventType, data *eventData) {
 		w.easylog.Warn("Stats writer queue full. Payload dropped (%.2fKB).", float64(data.bytes)/1024)
 		metrics.Count("datadog.trace_agent.stats_writer.dropped", 1, nil, 1)
 		metrics.Count("datadog.trace_agent.stats_writer.dropped_bytes", int64(data.bytes), nil, 1)
+
+	case eventTypeSpooled:
+		w.easylog.Warn("Stats writer queue full. Payload spooled to disk (%.2fKB).", float64(data.bytes)/1024)
+		metrics.Count("datadog.trace_agent.stats_writer.spooled", 1, nil, 1)
+		metrics.Count("datadog.trace_agent.stats_writer.spooled_bytes", int64(data.bytes), nil, 1)
 	}
 }
@@ -108,7 +108,7 @@ func NewTraceWriter(cfg *config.AgentConfig) *TraceWriter {
 		tw.tick = time.Duration(s*1000) * time.Millisecond
 	}
 	log.Debugf("Trace writer initialized (climit=%d qsize=%d)", climit, qsize)
-	tw.senders = newSenders(cfg, tw, pathTraces, climit, qsize)
+	tw.senders = newSenders(cfg, tw, pathTraces, climit, qsize, &cfg.TraceWriter.Spool)
 	return tw
 }
 
@@ -281,6 +281,11 @@ func (w *TraceWriter) report() {
 	metrics.Count("datadog.trace_agent.trace_writer.traces", atomic.SwapInt64(&w.stats.Traces, 0), nil, 1)
 	metrics.Count("datadog.trace_agent.trace_writer.events", atomic.SwapInt64(&w.stats.Events, 0), nil, 1)
 	metrics.Count("datadog.trace_agent.trace_writer.spans", atomic.SwapInt64(&w.stats.Spans, 0), nil, 1)
+	if si, ok := spoolInfo(w.senders); ok {
+		info.UpdateSpoolInfo("traces", si)
+		metrics.Gauge("datadog.trace_agent.trace_writer.spool.payloads", float64(si.Payloads), nil, 1)
+		metrics.Gauge("datadog.trace_agent.trace_writer.spool.bytes", float64(si.Bytes), nil, 1)
+	}
 }
 
 var _ eventRecorder = (*TraceWriter)(nil)
@@ -310,5 +315,10 @@ func (w *TraceWriter) recordEvent(t eventType, data *eventData) {
 		w.easylog.Warn("Trace writer queue full. Payload dropped (%.2fKB).", float64(data.bytes)/1024)
 		metrics.Count("datadog.trace_agent.trace_writer.dropped", 1, nil, 1)
 		metrics.Count("datadog.trace_agent.trace_writer.dropped_bytes", int64(data.bytes), nil, 1)
+
+	case eventTypeSpooled:
+		w.easylog.Warn("Trace writer queue full. Payload spooled to disk (%.2fKB).", float64(data.bytes)/1024)
+		metrics.Count("datadog.trace_agent.trace_writer.spooled", 1, nil, 1)
+		metrics.Count("datadog.trace_agent.trace_writer.spooled_bytes", int64(data.bytes), nil, 1)
 	}
 }
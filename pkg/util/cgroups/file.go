@@ -148,6 +148,13 @@ func parse2ColumnStatsWithMapping(fr fileReader, path string, keyColumn, valueCo
 	})
 }
 
+// ParsePSI parses a pressure stall information file, either a cgroupv2
+// *.pressure file or one of the /proc/pressure files of the host.
+// fullPsi can be nil if the "full" line is not needed.
+func ParsePSI(path string, somePsi, fullPsi *PSIStats) error {
+	return parsePSI(defaultFileReader, path, somePsi, fullPsi)
+}
+
 // format is "some avg10=0.00 avg60=0.00 avg300=0.00 total=0"
 func parsePSI(fr fileReader, path string, somePsi, fullPsi *PSIStats) error {
 	return parseColumnStats(fr, path, func(fields []string) error {
@@ -16,7 +16,7 @@ type PSIStats struct {
 	Avg10  *float64 // Percentage (0-100)
 	Avg60  *float64 // Percentage (0-100)
 	Avg300 *float64 // Percentage (0-100)
-	Total  *uint64  // Nanoseconds
+	Total  *uint64  // Microseconds
 }
 
 // MemoryStats - all metrics in bytes except if otherwise specified
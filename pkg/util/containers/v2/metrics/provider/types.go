@@ -22,9 +22,11 @@ type ContainerMemStats struct {
 	SwapLimit    *float64 // Memory+Swap Limit (>= Limit)
 
 	// Linux-only fields
-	RSS       *float64
-	Cache     *float64
-	OOMEvents *float64 // Number of events where memory allocation failed
+	RSS              *float64
+	Cache            *float64
+	OOMEvents        *float64 // Number of events where memory allocation failed
+	PartialStallTime *float64 // Correspond to PSI Some total
+	FullStallTime    *float64 // Correspond to PSI Full total
 
 	// Windows-only fields
 	PrivateWorkingSet *float64
@@ -45,6 +47,7 @@ type ContainerCPUStats struct {
 	ElapsedPeriods   *float64
 	ThrottledPeriods *float64
 	ThrottledTime    *float64
+	PartialStallTime *float64 // Correspond to PSI Some total
 }
 
 // DeviceIOStats stores Device IO stats.
@@ -65,6 +68,10 @@ type ContainerIOStats struct {
 	WriteOperations *float64
 
 	Devices map[string]DeviceIOStats
+
+	// Linux-only fields
+	PartialStallTime *float64 // Correspond to PSI Some total
+	FullStallTime    *float64 // Correspond to PSI Full total
 }
 
 // ContainerPIDStats stores stats about threads & processes.
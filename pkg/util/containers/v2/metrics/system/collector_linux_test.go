@@ -47,6 +47,10 @@ func TestBuildContainerMetrics(t *testing.T) {
 					CPUCount:         pointer.UInt64Ptr(10),
 					SchedulerPeriod:  pointer.UInt64Ptr(100),
 					SchedulerQuota:   pointer.UInt64Ptr(50),
+					PSISome: cgroups.PSIStats{
+						Avg10: pointer.Float64Ptr(0.5),
+						Total: pointer.UInt64Ptr(96),
+					},
 				},
 				Memory: &cgroups.MemoryStats{
 					UsageTotal:   pointer.UInt64Ptr(100),
@@ -58,12 +62,24 @@ func TestBuildContainerMetrics(t *testing.T) {
 					Swap:         pointer.UInt64Ptr(0),
 					SwapLimit:    pointer.UInt64Ptr(500),
 					OOMEvents:    pointer.UInt64Ptr(10),
+					PSISome: cgroups.PSIStats{
+						Total: pointer.UInt64Ptr(97),
+					},
+					PSIFull: cgroups.PSIStats{
+						Total: pointer.UInt64Ptr(98),
+					},
 				},
 				IOStats: &cgroups.IOStats{
 					ReadBytes:       pointer.UInt64Ptr(100),
 					WriteBytes:      pointer.UInt64Ptr(200),
 					ReadOperations:  pointer.UInt64Ptr(10),
 					WriteOperations: pointer.UInt64Ptr(20),
+					PSISome: cgroups.PSIStats{
+						Total: pointer.UInt64Ptr(99),
+					},
+					PSIFull: cgroups.PSIStats{
+						Total: pointer.UInt64Ptr(100),
+					},
 					// Device will be ignored as no matching device name
 					Devices: map[string]cgroups.DeviceIOStats{
 						"foo": {
@@ -90,23 +106,28 @@ func TestBuildContainerMetrics(t *testing.T) {
 					ElapsedPeriods:   pointer.Float64Ptr(500),
 					ThrottledPeriods: pointer.Float64Ptr(0),
 					ThrottledTime:    pointer.Float64Ptr(100),
+					PartialStallTime: pointer.Float64Ptr(96000),
 				},
 				Memory: &provider.ContainerMemStats{
-					UsageTotal:   pointer.Float64Ptr(100),
-					KernelMemory: pointer.Float64Ptr(40),
-					Limit:        pointer.Float64Ptr(42000),
-					Softlimit:    pointer.Float64Ptr(40000),
-					RSS:          pointer.Float64Ptr(300),
-					Cache:        pointer.Float64Ptr(200),
-					Swap:         pointer.Float64Ptr(0),
-					SwapLimit:    pointer.Float64Ptr(500),
-					OOMEvents:    pointer.Float64Ptr(10),
+					UsageTotal:       pointer.Float64Ptr(100),
+					KernelMemory:     pointer.Float64Ptr(40),
+					Limit:            pointer.Float64Ptr(42000),
+					Softlimit:        pointer.Float64Ptr(40000),
+					RSS:              pointer.Float64Ptr(300),
+					Cache:            pointer.Float64Ptr(200),
+					Swap:             pointer.Float64Ptr(0),
+					SwapLimit:        pointer.Float64Ptr(500),
+					OOMEvents:        pointer.Float64Ptr(10),
+					PartialStallTime: pointer.Float64Ptr(97000),
+					FullStallTime:    pointer.Float64Ptr(98000),
 				},
 				IO: &provider.ContainerIOStats{
-					ReadBytes:       pointer.Float64Ptr(100),
-					WriteBytes:      pointer.Float64Ptr(200),
-					ReadOperations:  pointer.Float64Ptr(10),
-					WriteOperations: pointer.Float64Ptr(20),
+					ReadBytes:        pointer.Float64Ptr(100),
+					WriteBytes:       pointer.Float64Ptr(200),
+					ReadOperations:   pointer.Float64Ptr(10),
+					WriteOperations:  pointer.Float64Ptr(20),
+					PartialStallTime: pointer.Float64Ptr(99000),
+					FullStallTime:    pointer.Float64Ptr(100000),
 				},
 				PID: &provider.ContainerPIDStats{
 					PIDs:        []int{4, 2},
@@ -16,6 +16,9 @@ var tmplVarRegex = regexp.MustCompile(`%%.+?%%`)
 // TemplateVar is the info for a parsed template variable.
 type TemplateVar struct {
 	Raw, Name, Key []byte
+	// Default is the value following a `|` in the variable, used when it can't be resolved
+	Default    []byte
+	HasDefault bool
 }
 
 // ParseString returns parsed template variables found in the input string.
@@ -28,14 +31,34 @@ func Parse(b []byte) []TemplateVar {
 	var parsed []TemplateVar
 	vars := tmplVarRegex.FindAll(b, -1)
 	for _, v := range vars {
-		name, key := parseTemplateVar(v)
-		parsed = append(parsed, TemplateVar{v, name, key})
+		variable, defaultValue, hasDefault := splitDefault(v)
+		name, key := parseTemplateVar(variable)
+		parsed = append(parsed, TemplateVar{
+			Raw:        v,
+			Name:       name,
+			Key:        key,
+			Default:    defaultValue,
+			HasDefault: hasDefault,
+		})
 	}
 	return parsed
 }
 
+// splitDefault splits a variable like %%label_app|default%% into the variable
+// and its default value
+func splitDefault(v []byte) (variable, defaultValue []byte, hasDefault bool) {
+	idx := bytes.IndexByte(v, '|')
+	if idx < 0 {
+		return v, nil, false
+	}
+	defaultValue = bytes.TrimSuffix(v[idx+1:], []byte("%%"))
+	return v[:idx], bytes.TrimSpace(defaultValue), true
+}
+
 // parseTemplateVar extracts the name of the var and the key (or index if it can be
-// cast to an int)
+// cast to an int). The key is everything after the first `_`, so %%label_app.name%%
+// and %%annotation_team_owner%% are parsed as the label `app.name` and the annotation
+// `team_owner`. The default value of the var must be removed with splitDefault first.
 func parseTemplateVar(v []byte) (name, key []byte) {
 	stripped := bytes.Map(func(r rune) rune {
 		if unicode.IsSpace(r) || r == '%' {
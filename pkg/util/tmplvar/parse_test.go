@@ -51,3 +51,12 @@ func TestParseTemplateVar(t *testing.T) {
 		})
 	}
 }
+
+func TestParseDefault(t *testing.T) {
+	assert.Equal(t, []TemplateVar{
+		{Raw: []byte("%%label_app|web%%"), Name: []byte("label"), Key: []byte("app"), Default: []byte("web"), HasDefault: true},
+		{Raw: []byte("%%port_http|%%"), Name: []byte("port"), Key: []byte("http"), HasDefault: true},
+		{Raw: []byte("%%host%%"), Name: []byte("host"), Key: []byte("")},
+		{Raw: []byte("%%container_env_DB_NAME | app db %%"), Name: []byte("container"), Key: []byte("env_DB_NAME"), Default: []byte("app db"), HasDefault: true},
+	}, ParseString("%%label_app|web%% %%port_http|%% %%host%% %%container_env_DB_NAME | app db %%"))
+}